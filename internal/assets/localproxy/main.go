@@ -26,6 +26,12 @@ import (
 const defaultHostnameSuffix = "dv.localhost"
 const defaultHappyProxyCacheMaxEntries = 512
 
+const (
+	defaultUpstreamDialTimeout           = 5 * time.Second
+	defaultUpstreamResponseHeaderTimeout = 60 * time.Second
+	defaultUpstreamIdleConnTimeout       = 90 * time.Second
+)
+
 var (
 	errAutoHealDisabled     = errors.New("auto-heal disabled")
 	errAutoHealUnavailable  = errors.New("auto-heal unavailable")
@@ -35,6 +41,7 @@ var (
 	errHostContainerInvalid = errors.New("host does not map to a container")
 
 	// Match Docker-compatible container names: [a-z0-9][a-z0-9_.-]*
+	// Keep in sync with docker.ContainerNamePattern in the dv module.
 	dockerContainerNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
 )
 
@@ -321,6 +328,7 @@ type proxyServer struct {
 	healer           *routeHealer
 	diagnostics      bool
 	diagnosticSuffix string
+	transport        http.RoundTripper
 
 	happyProxyMu         sync.RWMutex
 	happyProxy           map[string]*cachedHappyProxy
@@ -363,6 +371,9 @@ func newProxyServer(table *proxyTable, healer *routeHealer, diagnostics bool, ho
 }
 
 func (s *proxyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := ensureRequestID(r)
+	r = r.WithContext(context.WithValue(r.Context(), requestIDKey{}, requestID))
+
 	host := normalizeHost(r.Host)
 	if host == "" {
 		http.Error(w, "missing host", http.StatusBadGateway)
@@ -418,7 +429,7 @@ func (s *proxyServer) happyPathProxy(host string, target *url.URL) *httputil.Rev
 		s.evictLeastRecentlyUsedHappyProxyLocked()
 	}
 
-	proxy := buildReverseProxy(host, target, s.handleHappyPathProxyError)
+	proxy := buildReverseProxy(host, target, s.transport, s.handleHappyPathProxyError)
 	entry := &cachedHappyProxy{
 		target: targetStr,
 		proxy:  proxy,
@@ -468,7 +479,7 @@ func (s *proxyServer) handleHappyPathProxyError(w http.ResponseWriter, req *http
 	if state != nil && s.healer != nil && isRetryableMethod(req.Method) && state.retried.CompareAndSwap(false, true) {
 		healedTarget, healErr := s.healer.Heal(req.Context(), host)
 		if healErr == nil && healedTarget != nil {
-			retry := buildReverseProxy(host, healedTarget, func(w http.ResponseWriter, req *http.Request, retryErr error) {
+			retry := buildReverseProxy(host, healedTarget, s.transport, func(w http.ResponseWriter, req *http.Request, retryErr error) {
 				s.writeDiagnostic(w, req, host, diagnosticKindUpstream, classifyUpstreamError(retryErr), retryErr)
 			})
 			retry.ServeHTTP(w, req)
@@ -499,7 +510,7 @@ func classifyUpstreamError(err error) string {
 		return "Upstream connection refused"
 	case strings.Contains(msg, "no route to host"):
 		return "Upstream route unavailable"
-	case strings.Contains(msg, "i/o timeout") || strings.Contains(msg, "context deadline exceeded"):
+	case strings.Contains(msg, "i/o timeout") || strings.Contains(msg, "context deadline exceeded") || strings.Contains(msg, "timeout awaiting response headers"):
 		return "Upstream timeout"
 	default:
 		return "Upstream unavailable"
@@ -533,7 +544,38 @@ func nextDiagnosticID() string {
 	return fmt.Sprintf("%x-%x", ts, seq)
 }
 
+const requestIDHeader = "X-Request-ID"
+
+// Inbound IDs are echoed into headers and logs, so keep them short and
+// restricted to a conservative character set.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+type requestIDKey struct{}
+
+// ensureRequestID returns the inbound X-Request-ID when it is well-formed,
+// otherwise generates a fresh one and stores it on the request headers.
+func ensureRequestID(r *http.Request) string {
+	id := strings.TrimSpace(r.Header.Get(requestIDHeader))
+	if !requestIDPattern.MatchString(id) {
+		id = nextDiagnosticID()
+	}
+	r.Header.Set(requestIDHeader, id)
+	return id
+}
+
+func requestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
 func (s *proxyServer) writeDiagnostic(w http.ResponseWriter, r *http.Request, host string, kind diagnosticKind, category string, err error) {
+	requestID := requestIDFromContext(r.Context())
+	if requestID != "" {
+		w.Header().Set(requestIDHeader, requestID)
+	}
 	if !s.diagnostics {
 		http.Error(w, "proxy request failed", http.StatusBadGateway)
 		return
@@ -543,7 +585,10 @@ func (s *proxyServer) writeDiagnostic(w http.ResponseWriter, r *http.Request, ho
 		category = classifyHealFailure(err)
 	}
 	containerName, _ := containerNameFromHost(host, s.diagnosticSuffix)
-	diagnosticID := nextDiagnosticID()
+	diagnosticID := requestID
+	if diagnosticID == "" {
+		diagnosticID = nextDiagnosticID()
+	}
 	suggestions := []string{"dv list", "curl -sS http://127.0.0.1:2080/api/routes"}
 	if containerName != "" {
 		suggestions = append(suggestions, fmt.Sprintf("dv start %q", containerName))
@@ -707,10 +752,16 @@ func main() {
 	autoHealTimeout := time.Duration(envIntOrDefault("PROXY_AUTO_HEAL_TIMEOUT_MS", 1500)) * time.Millisecond
 	autoHealContainerPort := envIntOrDefault("PROXY_AUTO_HEAL_CONTAINER_PORT", 3000)
 	dockerSocketPath := envOrDefault("PROXY_DOCKER_SOCKET", "/var/run/docker.sock")
+	upstreamTimeouts := upstreamTimeouts{
+		Dial:           envDurationMSOrDefault("PROXY_UPSTREAM_DIAL_TIMEOUT_MS", defaultUpstreamDialTimeout),
+		ResponseHeader: envDurationMSOrDefault("PROXY_UPSTREAM_RESPONSE_HEADER_TIMEOUT_MS", defaultUpstreamResponseHeaderTimeout),
+		IdleConn:       envDurationMSOrDefault("PROXY_UPSTREAM_IDLE_CONN_TIMEOUT_MS", defaultUpstreamIdleConnTimeout),
+	}
 
 	table := newProxyTable()
 	healer := newRouteHealer(table, newDockerInspector(dockerSocketPath, autoHealTimeout), hostnameSuffix, autoHealContainerPort, autoHeal, autoHealTimeout)
 	proxyHandler := newProxyServer(table, healer, diagnosticHTML, hostnameSuffix)
+	proxyHandler.transport = newUpstreamTransport(upstreamTimeouts)
 
 	go func() {
 		log.Printf("local-proxy admin listening on %s", apiAddr)
@@ -875,6 +926,72 @@ func envIntOrDefault(key string, fallback int) int {
 	return n
 }
 
+func envDurationMSOrDefault(key string, fallback time.Duration) time.Duration {
+	ms := envIntOrDefault(key, 0)
+	if ms <= 0 {
+		return fallback
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+type upstreamTimeouts struct {
+	Dial           time.Duration
+	ResponseHeader time.Duration
+	IdleConn       time.Duration
+}
+
+// upstreamTransport routes streaming requests (WebSocket upgrades and SSE)
+// through a transport without a response-header timeout, so long-lived
+// streams are never cut off while regular requests to a wedged container
+// still fail fast.
+type upstreamTransport struct {
+	standard  *http.Transport
+	streaming *http.Transport
+}
+
+func newUpstreamTransport(timeouts upstreamTimeouts) *upstreamTransport {
+	standard := newHTTPTransport(timeouts)
+	streaming := newHTTPTransport(timeouts)
+	streaming.ResponseHeaderTimeout = 0
+	return &upstreamTransport{
+		standard:  standard,
+		streaming: streaming,
+	}
+}
+
+func newHTTPTransport(timeouts upstreamTimeouts) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   timeouts.Dial,
+		KeepAlive: 30 * time.Second,
+	}
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       timeouts.IdleConn,
+		ResponseHeaderTimeout: timeouts.ResponseHeader,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+func (t *upstreamTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if isStreamingRequest(req) {
+		return t.streaming.RoundTrip(req)
+	}
+	return t.standard.RoundTrip(req)
+}
+
+func isStreamingRequest(req *http.Request) bool {
+	if req == nil {
+		return false
+	}
+	if strings.TrimSpace(req.Header.Get("Upgrade")) != "" {
+		return true
+	}
+	return strings.Contains(strings.ToLower(req.Header.Get("Accept")), "text/event-stream")
+}
+
 func redirectToHTTPSHandler(externalPort int) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		host := normalizeHost(r.Host)
@@ -941,7 +1058,7 @@ func parseTarget(raw string) (*url.URL, error) {
 	return u, nil
 }
 
-func buildReverseProxy(host string, target *url.URL, onError func(http.ResponseWriter, *http.Request, error)) *httputil.ReverseProxy {
+func buildReverseProxy(host string, target *url.URL, transport http.RoundTripper, onError func(http.ResponseWriter, *http.Request, error)) *httputil.ReverseProxy {
 	targetQuery := target.RawQuery
 	director := func(req *http.Request) {
 		_, port := splitHostPortMaybe(req.Host)
@@ -981,10 +1098,23 @@ func buildReverseProxy(host string, target *url.URL, onError func(http.ResponseW
 		if ip, _, err := net.SplitHostPort(req.RemoteAddr); err == nil && ip != "" {
 			appendForwardedFor(req, ip)
 		}
+		if id := requestIDFromContext(req.Context()); id != "" {
+			req.Header.Set(requestIDHeader, id)
+		}
 	}
 	proxy := &httputil.ReverseProxy{
 		Director:      director,
+		Transport:     transport,
 		FlushInterval: 50 * time.Millisecond,
+		ModifyResponse: func(resp *http.Response) error {
+			if resp.Request == nil {
+				return nil
+			}
+			if id := requestIDFromContext(resp.Request.Context()); id != "" {
+				resp.Header.Set(requestIDHeader, id)
+			}
+			return nil
+		},
 		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
 			if onError != nil {
 				onError(w, r, err)
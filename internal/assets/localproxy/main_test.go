@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -403,3 +404,91 @@ func TestRouteHealerPanicCleansInflightEntry(t *testing.T) {
 		t.Fatal("second call blocked; stale inflight entry likely remained after panic")
 	}
 }
+
+func TestClassifyUpstreamErrorResponseHeaderTimeout(t *testing.T) {
+	err := errors.New("net/http: timeout awaiting response headers")
+	if got := classifyUpstreamError(err); got != "Upstream timeout" {
+		t.Fatalf("expected Upstream timeout, got %q", got)
+	}
+}
+
+func TestUpstreamTransportSkipsHeaderTimeoutForStreams(t *testing.T) {
+	transport := newUpstreamTransport(upstreamTimeouts{
+		Dial:           time.Second,
+		ResponseHeader: 2 * time.Second,
+		IdleConn:       3 * time.Second,
+	})
+	if transport.standard.ResponseHeaderTimeout != 2*time.Second {
+		t.Fatalf("expected standard response header timeout, got %v", transport.standard.ResponseHeaderTimeout)
+	}
+	if transport.streaming.ResponseHeaderTimeout != 0 {
+		t.Fatalf("expected streaming transport without response header timeout, got %v", transport.streaming.ResponseHeaderTimeout)
+	}
+
+	ws := httptest.NewRequest(http.MethodGet, "/cable", nil)
+	ws.Header.Set("Upgrade", "websocket")
+	if !isStreamingRequest(ws) {
+		t.Fatal("expected websocket upgrade to be treated as streaming")
+	}
+	sse := httptest.NewRequest(http.MethodGet, "/events", nil)
+	sse.Header.Set("Accept", "text/event-stream")
+	if !isStreamingRequest(sse) {
+		t.Fatal("expected SSE request to be treated as streaming")
+	}
+	if isStreamingRequest(httptest.NewRequest(http.MethodGet, "/", nil)) {
+		t.Fatal("expected plain request to use the standard transport")
+	}
+}
+
+func TestProxyServerPropagatesRequestID(t *testing.T) {
+	prevSuffix := hostnameSuffix
+	hostnameSuffix = "home.arpa"
+	t.Cleanup(func() { hostnameSuffix = prevSuffix })
+
+	var upstreamID string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamID = r.Header.Get(requestIDHeader)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	target, err := parseTarget(upstream.URL)
+	if err != nil {
+		t.Fatalf("parse target: %v", err)
+	}
+	table := newProxyTable()
+	table.set("app.home.arpa", target)
+	server := newProxyServer(table, nil, true, "home.arpa")
+
+	req := httptest.NewRequest(http.MethodGet, "http://app.home.arpa/", nil)
+	req.Header.Set(requestIDHeader, "trace-123")
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if upstreamID != "trace-123" {
+		t.Fatalf("expected upstream to receive inbound request id, got %q", upstreamID)
+	}
+	if got := rec.Header().Values(requestIDHeader); len(got) != 1 || got[0] != "trace-123" {
+		t.Fatalf("expected request id echoed once, got %v", got)
+	}
+}
+
+func TestWriteDiagnosticReusesRequestID(t *testing.T) {
+	server := newProxyServer(newProxyTable(), nil, true, "home.arpa")
+	req := httptest.NewRequest(http.MethodGet, "http://app.home.arpa/", nil)
+	req.Header.Set(requestIDHeader, "bad id with spaces")
+	id := ensureRequestID(req)
+	if id == "bad id with spaces" {
+		t.Fatal("expected malformed inbound request id to be replaced")
+	}
+	req = req.WithContext(context.WithValue(req.Context(), requestIDKey{}, id))
+
+	rec := httptest.NewRecorder()
+	server.writeDiagnostic(rec, req, "app.home.arpa", diagnosticKindUpstream, "Upstream timeout", errors.New("boom"))
+	if rec.Header().Get(requestIDHeader) != id {
+		t.Fatalf("expected diagnostic response to carry request id %q, got %q", id, rec.Header().Get(requestIDHeader))
+	}
+	if !strings.Contains(rec.Body.String(), id) {
+		t.Fatal("expected diagnostic page to show the request id")
+	}
+}
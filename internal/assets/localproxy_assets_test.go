@@ -0,0 +1,15 @@
+package assets
+
+import (
+	"bytes"
+	"testing"
+
+	"dv/internal/docker"
+)
+
+func TestLocalProxyContainerNamePatternMatchesDocker(t *testing.T) {
+	want := []byte("regexp.MustCompile(`" + docker.ContainerNamePattern + "`)")
+	if !bytes.Contains(embeddedLocalProxyMain, want) {
+		t.Fatalf("local proxy container name pattern is out of sync with docker.ContainerNamePattern (%s)", docker.ContainerNamePattern)
+	}
+}
@@ -73,6 +73,25 @@ var buildCmd = &cobra.Command{
 		disableBuildKit, _ := cmd.Flags().GetBool("classic-build")
 		withoutTestDB, _ := cmd.Flags().GetBool("without-test-db")
 		builderName, _ := cmd.Flags().GetString("builder")
+		cacheFrom, _ := cmd.Flags().GetString("cache-from")
+		cacheTo, _ := cmd.Flags().GetString("cache-to")
+		if !cmd.Flags().Changed("cache-from") {
+			cacheFrom = cfg.BuildCacheFrom
+		}
+		if !cmd.Flags().Changed("cache-to") {
+			cacheTo = cfg.BuildCacheTo
+		}
+		if _, err := docker.BuildxCacheArgs(cacheFrom, cacheTo); err != nil {
+			return err
+		}
+		platform, _ := cmd.Flags().GetString("platform")
+		if !cmd.Flags().Changed("platform") {
+			platform = cfg.BuildPlatform
+		}
+		platform = strings.TrimSpace(platform)
+		if err := docker.ValidatePlatform(platform); err != nil {
+			return err
+		}
 
 		pass := make([]string, 0, len(buildArgs)+3)
 		if noCache {
@@ -160,10 +179,18 @@ var buildCmd = &cobra.Command{
 			ExtraArgs:    pass,
 			ForceClassic: disableBuildKit,
 			Builder:      strings.TrimSpace(builderName),
+			CacheFrom:    cacheFrom,
+			CacheTo:      cacheTo,
+			Platform:     platform,
 		}
 		if err := docker.BuildFrom(imageTag, dockerfilePath, contextDir, opts); err != nil {
 			return err
 		}
+		if recordImagePlatform(&cfg, imageTag, platform) {
+			if err := config.Save(configDir, cfg); err != nil {
+				return err
+			}
+		}
 		fmt.Fprintln(cmd.OutOrStdout(), "Done.")
 		return nil
 	},
@@ -177,4 +204,32 @@ func init() {
 	buildCmd.Flags().Bool("classic-build", false, "Use legacy 'docker build' instead of buildx/BuildKit helpers")
 	buildCmd.Flags().Bool("without-test-db", false, "Skip test database migration when building the image")
 	buildCmd.Flags().String("builder", "", "Specify a buildx builder (default: Docker's current builder)")
+	buildCmd.Flags().String("cache-from", "", "buildx cache to import: a directory, registry ref, or type=... spec (default: config buildCacheFrom)")
+	buildCmd.Flags().String("platform", "", "Target platform, e.g. linux/amd64 (default: config buildPlatform, else the host's)")
+	buildCmd.Flags().String("cache-to", "", "buildx cache to export: a directory, registry ref, or type=... spec (default: config buildCacheTo)")
+}
+
+// recordImagePlatform notes the platform an image tag was built for on every
+// configured image using that tag, so containers are later created with a
+// matching --platform. It reports whether cfg changed.
+func recordImagePlatform(cfg *config.Config, tag, platform string) bool {
+	changed := false
+	for name, img := range cfg.Images {
+		if img.Tag != tag || img.Platform == platform {
+			continue
+		}
+		img.Platform = platform
+		cfg.Images[name] = img
+		changed = true
+	}
+	return changed
+}
+
+// imagePlatform is the --platform to run containers of the named image with:
+// the platform it was built for, else the configured build platform.
+func imagePlatform(cfg config.Config, imgName string) string {
+	if p := cfg.Images[imgName].Platform; p != "" {
+		return p
+	}
+	return cfg.BuildPlatform
 }
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"testing"
+
+	"dv/internal/config"
+)
+
+func TestRecordImagePlatformFeedsRunPlatform(t *testing.T) {
+	cfg := config.Config{
+		BuildPlatform: "linux/arm64",
+		Images: map[string]config.ImageConfig{
+			"discourse": {Tag: "ai_agent"},
+			"other":     {Tag: "other"},
+		},
+	}
+
+	if !recordImagePlatform(&cfg, "ai_agent", "linux/amd64") {
+		t.Fatal("expected the discourse image to be updated")
+	}
+	if recordImagePlatform(&cfg, "ai_agent", "linux/amd64") {
+		t.Fatal("recording the same platform again should be a no-op")
+	}
+	if got := imagePlatform(cfg, "discourse"); got != "linux/amd64" {
+		t.Fatalf("imagePlatform(discourse) = %q, want linux/amd64", got)
+	}
+	if got := imagePlatform(cfg, "other"); got != "linux/arm64" {
+		t.Fatalf("imagePlatform(other) = %q, want the buildPlatform fallback", got)
+	}
+}
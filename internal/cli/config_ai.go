@@ -54,6 +54,12 @@ non-interactively, for example:
   dv config ai ./cdck_qwen.json
   dv config ai cdck_qwen
 
+In the TUI, press x to export the configured LLMs (API keys omitted) to such a
+file and i to import one.
+
+Use 'dv config ai add' to add a single LLM from flags, e.g. in scripts, and
+'dv config ai default NAME|ID' to change the default LLM.
+
 ENVIRONMENT VARIABLES
 
 The following environment variables are automatically used if set:
@@ -0,0 +1,248 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"dv/internal/ai"
+	"dv/internal/discourse"
+)
+
+// aiAddOptions holds the `dv config ai add` flags; they mirror the fields of
+// the TUI's create form.
+type aiAddOptions struct {
+	Provider        string
+	DisplayName     string
+	Name            string
+	URL             string
+	Tokenizer       string
+	APIKey          string
+	APIKeyEnv       string
+	MaxPromptTokens int
+	MaxOutputTokens int
+	InputCost       float64
+	CachedInputCost float64
+	OutputCost      float64
+	SetDefault      bool
+	EnableChatBot   bool
+	Vision          bool
+}
+
+var configAIAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add an LLM without the TUI",
+	Long: `Add a Discourse AI LLM from flags, for scripts and CI.
+
+The API key is taken from --api-key, the variable named by --api-key-env, or
+the provider's usual environment variable (e.g. OPENAI_API_KEY), and is stored
+as an AI secret. The tokenizer defaults to the one the TUI would pick.
+
+  dv config ai add --provider openai --name gpt-4.1 --url https://api.openai.com/v1/responses
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := aiAddOptionsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		runtime, err := setupAIConfigRuntime(cmd)
+		if err != nil {
+			return err
+		}
+		if runtime.client == nil {
+			return nil
+		}
+
+		ctx := cmd.Context()
+		state, err := runtime.client.FetchState(ctx)
+		if err != nil {
+			return fmt.Errorf("fetch Discourse AI state: %w", err)
+		}
+		input, err := buildAIAddInput(opts, state.Meta, currentEnvironmentMap())
+		if err != nil {
+			return err
+		}
+
+		input, err = aiConfigModel{state: state}.prepareModelCredentials(ctx, runtime.client, input)
+		if err != nil {
+			return fmt.Errorf("store API key: %w", err)
+		}
+		id, err := runtime.client.CreateModel(ctx, input)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Created LLM %s (id %d, %s, %s).\n", input.DisplayName, id, input.Provider, input.Name)
+		if input.SetAsDefault {
+			fmt.Fprintln(cmd.OutOrStdout(), "Set as the default LLM.")
+		}
+		return nil
+	},
+}
+
+func init() {
+	flags := configAIAddCmd.Flags()
+	flags.String("container", "", "Container to configure (defaults to selected agent)")
+	flags.Bool("verbose", false, "Print verbose debugging output")
+	flags.String("provider", "", "Provider (openai, anthropic, openrouter, gemini, bedrock, venice, ...)")
+	flags.String("name", "", "Model name sent to the provider, e.g. gpt-4.1")
+	flags.String("display-name", "", "Display name in Discourse (defaults to --name)")
+	flags.String("url", "", "API URL (not needed for AWS Bedrock)")
+	flags.String("tokenizer", "", "Tokenizer class (defaults to the provider's usual tokenizer)")
+	flags.String("api-key", "", "API key (prefer --api-key-env to keep it out of shell history)")
+	flags.String("api-key-env", "", "Environment variable holding the API key")
+	flags.Int("max-prompt-tokens", 131072, "Max prompt tokens")
+	flags.Int("max-output-tokens", 4096, "Max output tokens")
+	flags.Float64("input-cost", 0, "Input cost ($/1M tokens)")
+	flags.Float64("cached-input-cost", 0, "Cached input cost ($/1M tokens)")
+	flags.Float64("output-cost", 0, "Output cost ($/1M tokens)")
+	flags.Bool("set-default", false, "Make this the default LLM")
+	flags.Bool("enable-chat-bot", true, "Enable the model for the AI bot")
+	flags.Bool("vision", true, "Enable vision")
+	_ = configAIAddCmd.MarkFlagRequired("provider")
+	_ = configAIAddCmd.MarkFlagRequired("name")
+	configAICmd.AddCommand(configAIAddCmd)
+}
+
+func aiAddOptionsFromFlags(cmd *cobra.Command) (aiAddOptions, error) {
+	flags := cmd.Flags()
+	var opts aiAddOptions
+	var err error
+	for _, s := range []struct {
+		name string
+		dst  *string
+	}{
+		{"provider", &opts.Provider},
+		{"display-name", &opts.DisplayName},
+		{"name", &opts.Name},
+		{"url", &opts.URL},
+		{"tokenizer", &opts.Tokenizer},
+		{"api-key", &opts.APIKey},
+		{"api-key-env", &opts.APIKeyEnv},
+	} {
+		if *s.dst, err = flags.GetString(s.name); err != nil {
+			return opts, err
+		}
+	}
+	if opts.MaxPromptTokens, err = flags.GetInt("max-prompt-tokens"); err != nil {
+		return opts, err
+	}
+	if opts.MaxOutputTokens, err = flags.GetInt("max-output-tokens"); err != nil {
+		return opts, err
+	}
+	if opts.InputCost, err = flags.GetFloat64("input-cost"); err != nil {
+		return opts, err
+	}
+	if opts.CachedInputCost, err = flags.GetFloat64("cached-input-cost"); err != nil {
+		return opts, err
+	}
+	if opts.OutputCost, err = flags.GetFloat64("output-cost"); err != nil {
+		return opts, err
+	}
+	opts.SetDefault, _ = flags.GetBool("set-default")
+	opts.EnableChatBot, _ = flags.GetBool("enable-chat-bot")
+	opts.Vision, _ = flags.GetBool("vision")
+	return opts, nil
+}
+
+// buildAIAddInput validates opts and turns them into a create request,
+// filling the same defaults newCreateForm would.
+func buildAIAddInput(opts aiAddOptions, meta ai.LLMMetadata, env map[string]string) (discourse.CreateLLMInput, error) {
+	entryID := strings.ToLower(strings.TrimSpace(opts.Provider))
+	provider := providerSlug(entryID)
+	if provider == "" {
+		return discourse.CreateLLMInput{}, errors.New("--provider is required")
+	}
+	name := strings.TrimSpace(opts.Name)
+	if name == "" {
+		return discourse.CreateLLMInput{}, errors.New("--name is required")
+	}
+	displayName := strings.TrimSpace(opts.DisplayName)
+	if displayName == "" {
+		displayName = name
+	}
+	url := strings.TrimSpace(opts.URL)
+	if url == "" && provider != "aws_bedrock" {
+		return discourse.CreateLLMInput{}, fmt.Errorf("--url is required for %s", provider)
+	}
+	tokenizer := strings.TrimSpace(opts.Tokenizer)
+	if tokenizer == "" {
+		tokenizer = defaultTokenizerFor(provider, meta)
+	}
+	if tokenizer == "" {
+		return discourse.CreateLLMInput{}, errors.New("--tokenizer is required (Discourse reported no tokenizers)")
+	}
+	if opts.MaxPromptTokens <= 0 || opts.MaxOutputTokens <= 0 {
+		return discourse.CreateLLMInput{}, errors.New("--max-prompt-tokens and --max-output-tokens must be positive")
+	}
+
+	apiKey := strings.TrimSpace(opts.APIKey)
+	if apiKey == "" && opts.APIKeyEnv != "" {
+		envName := strings.TrimSpace(opts.APIKeyEnv)
+		if apiKey = strings.TrimSpace(env[envName]); apiKey == "" {
+			return discourse.CreateLLMInput{}, fmt.Errorf("environment variable %s is empty or unset", envName)
+		}
+	}
+	if apiKey == "" {
+		apiKey, _ = firstNonEmpty(env, providerKeyHints(aiAddKeyHintID(entryID, provider))...)
+	}
+	if apiKey == "" {
+		return discourse.CreateLLMInput{}, fmt.Errorf("API key is required for %s (pass --api-key or --api-key-env)", provider)
+	}
+
+	params := map[string]interface{}{}
+	if provider == "open_ai" && entryID != "venice" {
+		params["enable_responses_api"] = true
+	}
+	if provider == "aws_bedrock" {
+		if accessKeyID, _ := firstNonEmpty(env, "AWS_ACCESS_KEY_ID"); accessKeyID != "" {
+			params["access_key_id"] = accessKeyID
+		}
+		if region, _ := firstNonEmpty(env, "AWS_REGION"); region != "" {
+			params["region"] = region
+		} else {
+			params["region"] = "us-west-2"
+		}
+	}
+
+	return discourse.CreateLLMInput{
+		DisplayName:     displayName,
+		Name:            name,
+		Provider:        provider,
+		Tokenizer:       tokenizer,
+		URL:             url,
+		APIKey:          apiKey,
+		MaxPromptTokens: opts.MaxPromptTokens,
+		MaxOutputTokens: opts.MaxOutputTokens,
+		InputCost:       opts.InputCost,
+		CachedInputCost: opts.CachedInputCost,
+		OutputCost:      opts.OutputCost,
+		EnabledChatBot:  opts.EnableChatBot,
+		VisionEnabled:   opts.Vision,
+		ProviderParams:  params,
+		SetAsDefault:    opts.SetDefault,
+	}, nil
+}
+
+// aiAddKeyHintID maps a provider slug back to the catalog entry ID that
+// providerKeyHints knows, so "open_ai" and "openai" find the same variable.
+func aiAddKeyHintID(entryID, provider string) string {
+	switch provider {
+	case "open_ai":
+		if entryID == "venice" {
+			return entryID
+		}
+		return "openai"
+	case "open_router":
+		return "openrouter"
+	case "google":
+		return "gemini"
+	case "aws_bedrock":
+		return "bedrock"
+	default:
+		return provider
+	}
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"dv/internal/ai"
+)
+
+func TestBuildAIAddInputFillsFormDefaults(t *testing.T) {
+	meta := ai.LLMMetadata{Tokenizers: []ai.TokenizerMeta{
+		{ID: "DiscourseAi::Tokenizer::AnthropicTokenizer"},
+		{ID: "DiscourseAi::Tokenizer::OpenAiTokenizer"},
+	}}
+	env := map[string]string{"OPENAI_API_KEY": "sk-env", "MY_KEY": "sk-custom"}
+	opts := aiAddOptions{
+		Provider:        "OpenAI",
+		Name:            "gpt-4.1",
+		URL:             "https://api.openai.com/v1/responses",
+		MaxPromptTokens: 1000,
+		MaxOutputTokens: 100,
+		SetDefault:      true,
+	}
+
+	input, err := buildAIAddInput(opts, meta, env)
+	if err != nil {
+		t.Fatalf("buildAIAddInput: %v", err)
+	}
+	if input.Provider != "open_ai" || input.DisplayName != "gpt-4.1" {
+		t.Fatalf("provider/display name = %q/%q", input.Provider, input.DisplayName)
+	}
+	if input.Tokenizer != "DiscourseAi::Tokenizer::OpenAiTokenizer" {
+		t.Fatalf("tokenizer = %q", input.Tokenizer)
+	}
+	if input.APIKey != "sk-env" || !input.SetAsDefault {
+		t.Fatalf("api key %q, default %v", input.APIKey, input.SetAsDefault)
+	}
+	if input.ProviderParams["enable_responses_api"] != true {
+		t.Fatalf("provider params = %v", input.ProviderParams)
+	}
+
+	opts.APIKeyEnv = "MY_KEY"
+	if input, err = buildAIAddInput(opts, meta, env); err != nil || input.APIKey != "sk-custom" {
+		t.Fatalf("api key env: key %q, err %v", input.APIKey, err)
+	}
+
+	opts.APIKeyEnv = "MISSING_KEY"
+	if _, err := buildAIAddInput(opts, meta, env); err == nil || !strings.Contains(err.Error(), "MISSING_KEY") {
+		t.Fatalf("expected missing env var error, got %v", err)
+	}
+
+	opts.APIKeyEnv = ""
+	opts.URL = ""
+	if _, err := buildAIAddInput(opts, meta, env); err == nil || !strings.Contains(err.Error(), "--url") {
+		t.Fatalf("expected missing url error, got %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"dv/internal/ai"
+)
+
+var configAIDefaultCmd = &cobra.Command{
+	Use:   "default NAME|ID",
+	Short: "Set the default LLM without the TUI",
+	Long: `Set the default Discourse AI LLM, picking a configured model by display name
+(case-insensitive) or numeric ID:
+
+  dv config ai default "GPT-4.1"
+  dv config ai default 3
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runtime, err := setupAIConfigRuntime(cmd)
+		if err != nil {
+			return err
+		}
+		if runtime.client == nil {
+			return nil
+		}
+
+		ctx := cmd.Context()
+		state, err := runtime.client.FetchState(ctx)
+		if err != nil {
+			return fmt.Errorf("fetch Discourse AI state: %w", err)
+		}
+		model, err := resolveLLMByNameOrID(state.Models, args[0])
+		if err != nil {
+			return err
+		}
+		if model.ID == state.DefaultID {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s (id %d) is already the default LLM.\n", model.DisplayName, model.ID)
+			return nil
+		}
+		if err := runtime.client.SetDefaultLLM(ctx, model.ID); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Default LLM set to %s (id %d).\n", model.DisplayName, model.ID)
+		return nil
+	},
+}
+
+func init() {
+	configAIDefaultCmd.Flags().String("container", "", "Container to configure (defaults to selected agent)")
+	configAIDefaultCmd.Flags().Bool("verbose", false, "Print verbose debugging output")
+	configAICmd.AddCommand(configAIDefaultCmd)
+}
+
+// resolveLLMByNameOrID finds the configured model whose ID or display name is
+// ref. An ID wins over a display name that happens to be numeric.
+func resolveLLMByNameOrID(models []ai.LLMModel, ref string) (ai.LLMModel, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ai.LLMModel{}, fmt.Errorf("LLM name or ID is required")
+	}
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		for _, model := range models {
+			if model.ID == id {
+				return model, nil
+			}
+		}
+	}
+
+	var matches []ai.LLMModel
+	for _, model := range models {
+		if strings.EqualFold(strings.TrimSpace(model.DisplayName), ref) {
+			matches = append(matches, model)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		names := make([]string, 0, len(models))
+		for _, model := range models {
+			names = append(names, fmt.Sprintf("%s (%d)", model.DisplayName, model.ID))
+		}
+		if len(names) == 0 {
+			return ai.LLMModel{}, fmt.Errorf("no LLM named %q: no LLMs are configured", ref)
+		}
+		return ai.LLMModel{}, fmt.Errorf("no LLM named %q; configured: %s", ref, strings.Join(names, ", "))
+	case 1:
+		return matches[0], nil
+	default:
+		ids := make([]string, 0, len(matches))
+		for _, model := range matches {
+			ids = append(ids, strconv.FormatInt(model.ID, 10))
+		}
+		return ai.LLMModel{}, fmt.Errorf("%d LLMs are named %q (ids %s); pass an ID instead", len(matches), ref, strings.Join(ids, ", "))
+	}
+}
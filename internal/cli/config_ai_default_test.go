@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"dv/internal/ai"
+)
+
+func TestResolveLLMByNameOrID(t *testing.T) {
+	models := []ai.LLMModel{
+		{ID: 1, DisplayName: "GPT-4.1"},
+		{ID: 2, DisplayName: "Claude"},
+		{ID: 3, DisplayName: "claude"},
+		{ID: 4, DisplayName: "1"},
+	}
+
+	if model, err := resolveLLMByNameOrID(models, "gpt-4.1"); err != nil || model.ID != 1 {
+		t.Fatalf("by name: got %+v, %v", model, err)
+	}
+	if model, err := resolveLLMByNameOrID(models, "1"); err != nil || model.ID != 1 {
+		t.Fatalf("by id: got %+v, %v", model, err)
+	}
+	if _, err := resolveLLMByNameOrID(models, "Claude"); err == nil || !strings.Contains(err.Error(), "ids 2, 3") {
+		t.Fatalf("expected ambiguity error, got %v", err)
+	}
+	if _, err := resolveLLMByNameOrID(models, "Gemini"); err == nil || !strings.Contains(err.Error(), "GPT-4.1 (1)") {
+		t.Fatalf("expected not found error listing models, got %v", err)
+	}
+}
@@ -30,33 +30,33 @@ const (
 type aiFileConfig struct {
 	Version      int                    `json:"version"`
 	LLMs         []aiFileLLM            `json:"llms"`
-	SiteSettings map[string]interface{} `json:"site_settings"`
+	SiteSettings map[string]interface{} `json:"site_settings,omitempty"`
 }
 
 type aiFileLLM struct {
-	ID              string                 `json:"id"`
+	ID              string                 `json:"id,omitempty"`
 	DisplayName     string                 `json:"display_name"`
 	Name            string                 `json:"name"`
 	Provider        string                 `json:"provider"`
-	Tokenizer       string                 `json:"tokenizer"`
-	URL             string                 `json:"url"`
-	BaseURL         string                 `json:"base_url"`
-	APIKey          string                 `json:"api_key"`
-	APIKeyRef       string                 `json:"api_key_ref"`
-	APIKeyEnv       string                 `json:"api_key_env"`
-	AiSecretID      int64                  `json:"ai_secret_id"`
-	AiSecretName    string                 `json:"ai_secret_name"`
-	MaxPromptTokens int                    `json:"max_prompt_tokens"`
-	MaxOutputTokens int                    `json:"max_output_tokens"`
-	InputCost       float64                `json:"input_cost"`
-	CachedInputCost float64                `json:"cached_input_cost"`
-	OutputCost      float64                `json:"output_cost"`
-	EnabledChatBot  bool                   `json:"enabled_chat_bot"`
-	VisionEnabled   bool                   `json:"vision_enabled"`
-	SetAsDefault    bool                   `json:"set_as_default"`
-	Test            bool                   `json:"test"`
-	ProviderParams  map[string]interface{} `json:"provider_params"`
-	Upsert          aiFileUpsert           `json:"upsert"`
+	Tokenizer       string                 `json:"tokenizer,omitempty"`
+	URL             string                 `json:"url,omitempty"`
+	BaseURL         string                 `json:"base_url,omitempty"`
+	APIKey          string                 `json:"api_key,omitempty"`
+	APIKeyRef       string                 `json:"api_key_ref,omitempty"`
+	APIKeyEnv       string                 `json:"api_key_env,omitempty"`
+	AiSecretID      int64                  `json:"ai_secret_id,omitempty"`
+	AiSecretName    string                 `json:"ai_secret_name,omitempty"`
+	MaxPromptTokens int                    `json:"max_prompt_tokens,omitempty"`
+	MaxOutputTokens int                    `json:"max_output_tokens,omitempty"`
+	InputCost       float64                `json:"input_cost,omitempty"`
+	CachedInputCost float64                `json:"cached_input_cost,omitempty"`
+	OutputCost      float64                `json:"output_cost,omitempty"`
+	EnabledChatBot  bool                   `json:"enabled_chat_bot,omitempty"`
+	VisionEnabled   bool                   `json:"vision_enabled,omitempty"`
+	SetAsDefault    bool                   `json:"set_as_default,omitempty"`
+	Test            bool                   `json:"test,omitempty"`
+	ProviderParams  map[string]interface{} `json:"provider_params,omitempty"`
+	Upsert          aiFileUpsert           `json:"upsert,omitzero"`
 }
 
 type aiFileUpsert struct {
@@ -397,6 +397,52 @@ func applyAIFileConfig(ctx context.Context, out io.Writer, client aiFileDiscours
 	return nil
 }
 
+// exportAIFileConfig describes the configured LLMs in the same file format,
+// so a setup can be re-applied to other instances. API keys are never
+// exported; a model backed by an AiSecret refers to it by name instead.
+func exportAIFileConfig(state ai.LLMState) aiFileConfig {
+	cfg := aiFileConfig{Version: defaultAIFileVersion}
+	for _, model := range state.Models {
+		llm := aiFileLLM{
+			DisplayName:     model.DisplayName,
+			Name:            model.Name,
+			Provider:        model.Provider,
+			Tokenizer:       model.Tokenizer,
+			URL:             model.URL,
+			MaxPromptTokens: model.MaxPromptTokens,
+			MaxOutputTokens: model.MaxOutputTokens,
+			InputCost:       model.InputCost,
+			CachedInputCost: model.CachedInputCost,
+			OutputCost:      model.OutputCost,
+			EnabledChatBot:  model.EnabledChatBot,
+			VisionEnabled:   model.VisionEnabled,
+			SetAsDefault:    model.ID == state.DefaultID,
+			ProviderParams:  cloneStringInterfaceMap(model.ProviderParams),
+		}
+		if model.AiSecretID > 0 {
+			for _, secret := range state.Meta.AiSecrets {
+				if secret.ID == model.AiSecretID {
+					llm.AiSecretName = secret.Name
+					break
+				}
+			}
+		}
+		cfg.LLMs = append(cfg.LLMs, llm)
+	}
+	return cfg
+}
+
+func writeAIFileConfig(path string, cfg aiFileConfig) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
 func buildAIFileLLMInput(llm aiFileLLM) (discourse.CreateLLMInput, error) {
 	provider := providerSlug(llm.Provider)
 	if provider == "" {
@@ -320,6 +320,43 @@ func TestApplyAIFileConfigRedactsSecretSiteSettingOnError(t *testing.T) {
 	}
 }
 
+func TestExportAIFileConfigRoundTripsWithoutSecrets(t *testing.T) {
+	state := ai.LLMState{
+		DefaultID: 2,
+		Models: []ai.LLMModel{
+			{ID: 1, DisplayName: "GPT", Name: "gpt-5", Provider: "open_ai", Tokenizer: "DiscourseAi::Tokenizer::OpenAiTokenizer", URL: "https://api.openai.com/v1/chat/completions", MaxPromptTokens: 400000, AiSecretID: 7},
+			{ID: 2, DisplayName: "Qwen", Name: "qwen", Provider: "vllm", Tokenizer: "DiscourseAi::Tokenizer::QwenTokenizer", URL: "https://vllm.example.com/v1/chat/completions", VisionEnabled: true},
+		},
+		Meta: ai.LLMMetadata{AiSecrets: []ai.AiSecret{{ID: 7, Name: "OpenAI key"}}},
+	}
+
+	path := filepath.Join(t.TempDir(), "ai", "export.json")
+	if err := writeAIFileConfig(path, exportAIFileConfig(state)); err != nil {
+		t.Fatalf("writeAIFileConfig() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"api_key"`) || strings.Contains(string(data), "ai_secret_id") {
+		t.Fatalf("export should not contain credentials:\n%s", data)
+	}
+
+	cfg, err := readAIFileConfig(path)
+	if err != nil {
+		t.Fatalf("readAIFileConfig() error = %v", err)
+	}
+	if len(cfg.LLMs) != 2 {
+		t.Fatalf("got %d LLMs, want 2", len(cfg.LLMs))
+	}
+	if cfg.LLMs[0].AiSecretName != "OpenAI key" || cfg.LLMs[0].SetAsDefault {
+		t.Fatalf("unexpected first LLM: %+v", cfg.LLMs[0])
+	}
+	if !cfg.LLMs[1].SetAsDefault || !cfg.LLMs[1].VisionEnabled || cfg.LLMs[1].AiSecretName != "" {
+		t.Fatalf("unexpected second LLM: %+v", cfg.LLMs[1])
+	}
+}
+
 func TestCompleteAIConfigAliases(t *testing.T) {
 	aiDir := filepath.Join(t.TempDir(), "ai")
 	if err := os.MkdirAll(aiDir, 0o755); err != nil {
@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -20,6 +23,8 @@ import (
 	"dv/internal/ai/providers"
 	"dv/internal/discourse"
 	"dv/internal/huh"
+	"dv/internal/paste"
+	"dv/internal/xdg"
 )
 
 type aiFocus int
@@ -38,6 +43,17 @@ const (
 	modeConfirmDelete
 	modeSaving
 	modeTesting
+	modeFilePrompt
+	modeKeySources
+	modeTestAll
+)
+
+// aiFileAction is what the file path prompt does with the path entered.
+type aiFileAction int
+
+const (
+	fileActionImport aiFileAction = iota
+	fileActionExport
 )
 
 type aiConfigOptions struct {
@@ -81,6 +97,13 @@ type aiConfigModel struct {
 	spinner         spinner.Model
 	form            *createForm
 	deleteLLM       *ai.LLMModel
+	filePrompt      textinput.Model
+	testAll         []aiModelTestResult
+	testAllRun      int
+	fileAction      aiFileAction
+	// catalogProvider narrows the catalog pane to one provider entry ID;
+	// empty shows every provider.
+	catalogProvider string
 	paneWidth       int
 	leftPaneWidth   int
 	rightPaneWidth  int
@@ -107,10 +130,10 @@ func newAiConfigModel(opts aiConfigOptions) aiConfigModel {
 	llmList.SetFilteringEnabled(true)
 	llmList.SetShowPagination(false)
 
-	providerItems := catalogItems(opts.catalog)
+	providerItems := catalogItems(opts.catalog, "")
 	providerDelegate := list.NewDefaultDelegate()
 	modelList := list.New(providerItems, providerDelegate, 0, 0)
-	modelList.Title = "Provider Catalog"
+	modelList.Title = catalogTitle(opts.catalog, "")
 	modelList.SetShowStatusBar(false)
 	modelList.SetShowPagination(false)
 
@@ -135,7 +158,9 @@ func newAiConfigModel(opts aiConfigOptions) aiConfigModel {
 	}
 }
 
-func catalogItems(cat ai.ProviderCatalog) []list.Item {
+// catalogItems flattens the catalog into list items, limited to the provider
+// entry with ID provider unless it is empty.
+func catalogItems(cat ai.ProviderCatalog, provider string) []list.Item {
 	var items []list.Item
 	for _, entry := range cat.Entries {
 		// Do not show stale cached models for providers whose credentials are not
@@ -143,6 +168,9 @@ func catalogItems(cat ai.ProviderCatalog) []list.Item {
 		if !entry.HasCredentials {
 			continue
 		}
+		if provider != "" && entry.ID != provider {
+			continue
+		}
 		for _, model := range entry.Models {
 			items = append(items, providerItem{entryID: entry.ID, model: model})
 		}
@@ -153,6 +181,57 @@ func catalogItems(cat ai.ProviderCatalog) []list.Item {
 	return items
 }
 
+// catalogProviderIDs lists the providers shown in the catalog, in order.
+func catalogProviderIDs(cat ai.ProviderCatalog) []string {
+	var ids []string
+	for _, entry := range cat.Entries {
+		if entry.HasCredentials {
+			ids = append(ids, entry.ID)
+		}
+	}
+	return ids
+}
+
+// nextCatalogProvider steps through "all providers" followed by each
+// provider in turn, wrapping around in either direction.
+func nextCatalogProvider(cat ai.ProviderCatalog, current string, step int) string {
+	options := append([]string{""}, catalogProviderIDs(cat)...)
+	idx := 0
+	for i, id := range options {
+		if id == current {
+			idx = i
+			break
+		}
+	}
+	idx = (idx + step + len(options)) % len(options)
+	return options[idx]
+}
+
+func catalogTitle(cat ai.ProviderCatalog, provider string) string {
+	if provider == "" {
+		return "Provider Catalog"
+	}
+	for _, entry := range cat.Entries {
+		if entry.ID == provider && entry.Title != "" {
+			return "Provider Catalog · " + entry.Title
+		}
+	}
+	return "Provider Catalog · " + provider
+}
+
+// applyCatalogProvider refreshes the catalog pane after the provider filter
+// or the catalog itself changed. The returned command re-applies any active
+// text filter to the new items.
+func (m *aiConfigModel) applyCatalogProvider() tea.Cmd {
+	if m.catalogProvider != "" && !slices.Contains(catalogProviderIDs(m.catalog), m.catalogProvider) {
+		m.catalogProvider = ""
+	}
+	cmd := m.modelList.SetItems(catalogItems(m.catalog, m.catalogProvider))
+	m.modelList.Title = catalogTitle(m.catalog, m.catalogProvider)
+	m.modelList.ResetSelected()
+	return cmd
+}
+
 func (m aiConfigModel) Init() tea.Cmd {
 	if m.mode == modeLoading {
 		return tea.Batch(
@@ -172,6 +251,11 @@ type aiErrMsg struct {
 	err error
 }
 
+// aiNoticeMsg reports the outcome of a background action as a toast.
+type aiNoticeMsg struct {
+	notice string
+}
+
 type aiTestMsg struct {
 	err error
 }
@@ -180,6 +264,28 @@ type aiLoadingMsg struct {
 	step string
 }
 
+// aiTestAllMsg is the outcome of testing testAll[index] during test-all run
+// run; results from a cancelled run are ignored.
+type aiTestAllMsg struct {
+	run   int
+	index int
+	err   error
+}
+
+// aiModelTestResult tracks one configured model during a test-all run.
+type aiModelTestResult struct {
+	model ai.LLMModel
+	done  bool
+	err   error
+}
+
+// aiImportProgressMsg reports one step of a running import. The import
+// sends further messages on updates until it ends with aiStateMsg or aiErrMsg.
+type aiImportProgressMsg struct {
+	line    string
+	updates <-chan tea.Msg
+}
+
 type aiInitCompleteMsg struct {
 	state   ai.LLMState
 	catalog ai.ProviderCatalog
@@ -202,6 +308,25 @@ func (m aiConfigModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.mode == modeTesting {
 			return m.updateTestingModal(msg)
 		}
+		if m.mode == modeFilePrompt {
+			return m.updateFilePrompt(msg)
+		}
+		if m.mode == modeTestAll {
+			switch msg.String() {
+			case "esc", "enter", "q", "t":
+				// Closing early cancels the remaining tests.
+				m.mode = modeBrowse
+				m.testAll = nil
+			}
+			return m, nil
+		}
+		if m.mode == modeKeySources {
+			switch msg.String() {
+			case "k", "esc", "enter", "q":
+				m.mode = modeBrowse
+			}
+			return m, nil
+		}
 
 		// Check if we're currently filtering - if so, don't process single-key shortcuts
 		isFiltering := false
@@ -240,6 +365,30 @@ func (m aiConfigModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			case "q", "esc", "ctrl+c":
 				return m, tea.Quit
+			case "p", "P":
+				if m.focus == focusCatalog {
+					step := 1
+					if msg.String() == "P" {
+						step = -1
+					}
+					m.catalogProvider = nextCatalogProvider(m.catalog, m.catalogProvider, step)
+					return m, m.applyCatalogProvider()
+				}
+			case "k":
+				m.mode = modeKeySources
+				return m, nil
+			case "t":
+				return m.startTestAll()
+			case "y":
+				if m.focus == focusConfigured {
+					if item, ok := m.llmList.SelectedItem().(llmItem); ok {
+						return m, copyLLMConfigCmd(item.model)
+					}
+				}
+			case "i":
+				return m.openFilePrompt(fileActionImport)
+			case "x":
+				return m.openFilePrompt(fileActionExport)
 			case "r":
 				m.busy = true
 				m.busyMessage = "Refreshing models..."
@@ -321,6 +470,22 @@ func (m aiConfigModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	case aiLoadingMsg:
 		m.loadingProgress = append(m.loadingProgress, msg.step)
+	case aiNoticeMsg:
+		m.toast = msg.notice
+		m.errMsg = ""
+	case aiTestAllMsg:
+		if m.mode != modeTestAll || msg.run != m.testAllRun || msg.index >= len(m.testAll) {
+			return m, nil
+		}
+		m.testAll[msg.index].done = true
+		m.testAll[msg.index].err = msg.err
+		if next := msg.index + 1; next < len(m.testAll) {
+			return m, m.testConfiguredModelCmd(next)
+		}
+		return m, nil
+	case aiImportProgressMsg:
+		m.savingMessage = msg.line
+		return m, waitForAIImport(msg.updates)
 	case aiInitCompleteMsg:
 		if msg.err != nil {
 			m.mode = modeBrowse
@@ -342,12 +507,11 @@ func (m aiConfigModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.llmList.SetFilteringEnabled(true)
 		m.llmList.SetShowPagination(false)
 
-		providerItems := catalogItems(m.catalog)
 		providerDelegate := list.NewDefaultDelegate()
-		m.modelList = list.New(providerItems, providerDelegate, 0, 0)
-		m.modelList.Title = "Provider Catalog"
+		m.modelList = list.New(nil, providerDelegate, 0, 0)
 		m.modelList.SetShowStatusBar(false)
 		m.modelList.SetShowPagination(false)
+		m.applyCatalogProvider()
 
 		m.resize()
 		m.toast = "Ready!"
@@ -355,7 +519,7 @@ func (m aiConfigModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	var cmds []tea.Cmd
 	// Only update lists if they're initialized (not in loading/saving/testing mode)
-	if m.mode != modeLoading && m.mode != modeSaving && m.mode != modeTesting {
+	if m.mode != modeLoading && m.mode != modeSaving && m.mode != modeTesting && m.mode != modeTestAll {
 		if m.focus == focusConfigured {
 			listModel, cmd := m.llmList.Update(msg)
 			m.llmList = listModel
@@ -366,7 +530,7 @@ func (m aiConfigModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = append(cmds, cmd)
 		}
 	}
-	if m.busy || m.mode == modeLoading || m.mode == modeSaving || m.mode == modeTesting {
+	if m.busy || m.mode == modeLoading || m.mode == modeSaving || m.mode == modeTesting || m.mode == modeTestAll {
 		sp, cmd := m.spinner.Update(msg)
 		m.spinner = sp
 		cmds = append(cmds, cmd)
@@ -458,6 +622,118 @@ func (m aiConfigModel) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// openFilePrompt asks for the file to import from or export to, suggesting
+// the container's alias under ~/.config/dv/ai so exports can be re-applied
+// with 'dv config ai NAME'.
+func (m aiConfigModel) openFilePrompt(action aiFileAction) (tea.Model, tea.Cmd) {
+	ti := textinput.New()
+	ti.Placeholder = "path/to/llms.json"
+	ti.CharLimit = 500
+	if configDir, err := xdg.ConfigDir(); err == nil {
+		ti.SetValue(filepath.Join(configDir, "ai", m.container+".json"))
+	}
+	m.filePrompt = ti
+	m.fileAction = action
+	m.mode = modeFilePrompt
+	m.toast = ""
+	m.errMsg = ""
+	return m, m.filePrompt.Focus()
+}
+
+func (m aiConfigModel) updateFilePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = modeBrowse
+		return m, nil
+	case "enter":
+		path := strings.TrimSpace(m.filePrompt.Value())
+		if path == "" {
+			return m, nil
+		}
+		m.mode = modeBrowse
+		if m.fileAction == fileActionExport {
+			path = expandHostPath(path)
+			cfg := exportAIFileConfig(m.state)
+			if err := writeAIFileConfig(path, cfg); err != nil {
+				m.errMsg = fmt.Sprintf("Export failed: %v", err)
+				return m, nil
+			}
+			m.toast = fmt.Sprintf("Exported %d LLMs to %s (API keys omitted)", len(cfg.LLMs), path)
+			return m, nil
+		}
+		configDir, err := xdg.ConfigDir()
+		if err != nil {
+			m.errMsg = err.Error()
+			return m, nil
+		}
+		path, err = resolveAIConfigFilePath(configDir, path)
+		if err != nil {
+			m.errMsg = err.Error()
+			return m, nil
+		}
+		cfg, err := readAIFileConfig(path)
+		if err != nil {
+			m.errMsg = err.Error()
+			return m, nil
+		}
+		m.mode = modeSaving
+		m.savingMessage = fmt.Sprintf("Importing %d LLMs...", len(cfg.LLMs))
+		return m, m.importModelsCmd(path, cfg)
+	}
+	var cmd tea.Cmd
+	m.filePrompt, cmd = m.filePrompt.Update(msg)
+	return m, cmd
+}
+
+// importModelsCmd applies an AI config file like 'dv config ai FILE',
+// creating or updating its LLMs one at a time and reporting each step.
+func (m aiConfigModel) importModelsCmd(path string, cfg aiFileConfig) tea.Cmd {
+	ctx := m.ctx
+	client, ok := m.client.(aiFileDiscourseClient)
+	if !ok {
+		return func() tea.Msg {
+			return aiErrMsg{fmt.Errorf("import is not supported by this Discourse client")}
+		}
+	}
+	return func() tea.Msg {
+		updates := make(chan tea.Msg)
+		go func() {
+			if err := applyAIFileConfig(ctx, aiImportProgressWriter{updates}, client, cfg); err != nil {
+				updates <- aiErrMsg{fmt.Errorf("import %s: %w", path, err)}
+				return
+			}
+			state, err := client.FetchState(ctx)
+			if err != nil {
+				updates <- aiErrMsg{err}
+				return
+			}
+			updates <- aiStateMsg{state: state, notice: fmt.Sprintf("Imported %d LLMs from %s", len(cfg.LLMs), path)}
+		}()
+		return <-updates
+	}
+}
+
+func waitForAIImport(updates <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-updates
+	}
+}
+
+// aiImportProgressWriter turns the progress lines applyAIFileConfig prints
+// into aiImportProgressMsg updates for the saving modal.
+type aiImportProgressWriter struct {
+	updates chan tea.Msg
+}
+
+func (w aiImportProgressWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(string(p), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			w.updates <- aiImportProgressMsg{line: line, updates: w.updates}
+		}
+	}
+	return len(p), nil
+}
+
 func (m aiConfigModel) updateDeleteConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if m.deleteLLM == nil {
 		m.mode = modeBrowse
@@ -541,13 +817,41 @@ func (m aiConfigModel) isCompactLayout() bool {
 	return m.width < 90
 }
 
+// updateLists reloads the configured models after a refresh or an edit,
+// keeping any filter and re-selecting the previously selected model while it
+// still exists (or the same position when it was deleted).
 func (m *aiConfigModel) updateLists() {
+	var selectedID int64
+	if item, ok := m.llmList.SelectedItem().(llmItem); ok {
+		selectedID = item.model.ID
+	}
+	prevIndex := m.llmList.Index()
+	filterState := m.llmList.FilterState()
+	filter := m.llmList.FilterValue()
+
 	items := make([]list.Item, 0, len(m.state.Models))
 	for _, entry := range m.state.Models {
 		items = append(items, llmItem{model: entry, isDefault: entry.ID == m.state.DefaultID})
 	}
 	m.llmList.SetItems(items)
-	_ = m.catalog // kept for future use
+	if filterState != list.Unfiltered && filter != "" {
+		// Filter synchronously so the selection below sees the filtered items.
+		m.llmList.SetFilterText(filter)
+		if filterState == list.Filtering {
+			m.llmList.SetFilterState(list.Filtering)
+		}
+	}
+
+	visible := m.llmList.VisibleItems()
+	for i, item := range visible {
+		if llm, ok := item.(llmItem); ok && llm.model.ID == selectedID {
+			m.llmList.Select(i)
+			return
+		}
+	}
+	if len(visible) > 0 {
+		m.llmList.Select(min(prevIndex, len(visible)-1))
+	}
 }
 
 func (m aiConfigModel) View() tea.View {
@@ -639,9 +943,9 @@ func (m aiConfigModel) viewString() string {
 	// Build help line (compact on small screens)
 	var helpLine string
 	if isCompact {
-		helpLine = dimStyle.Render("Tab:switch  Enter:select  e:edit  d:del  q:quit")
+		helpLine = dimStyle.Render("Tab:switch  Enter:select  e:edit  d:del  p:provider  q:quit")
 	} else {
-		helpLine = dimStyle.Render("Tab/←→:switch panes  Enter:select/default  e:edit  d:delete  r:refresh  q:quit")
+		helpLine = dimStyle.Render("Tab/←→:switch panes  Enter:select/default  e:edit  d:delete  p/P:provider  i/x:import/export  t:test all  y:copy  k:keys  r:refresh  q:quit")
 	}
 
 	// Assemble view
@@ -685,6 +989,12 @@ func (m aiConfigModel) viewString() string {
 		}
 	case modeConfirmDelete:
 		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.renderDeleteModal(), lipgloss.WithWhitespaceChars("░"), lipgloss.WithWhitespaceStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("8"))))
+	case modeTestAll:
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.renderTestAllModal(), lipgloss.WithWhitespaceChars("░"), lipgloss.WithWhitespaceStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("8"))))
+	case modeKeySources:
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.renderKeySourcesModal(), lipgloss.WithWhitespaceChars("░"), lipgloss.WithWhitespaceStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("8"))))
+	case modeFilePrompt:
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.renderFilePromptModal(), lipgloss.WithWhitespaceChars("░"), lipgloss.WithWhitespaceStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("8"))))
 	}
 	return view
 }
@@ -715,29 +1025,13 @@ func (m aiConfigModel) renderStatusLine() string {
 	activeKeyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
 	inactiveKeyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
 
-	providers := []struct {
-		Label string
-		Short string
-		Keys  []string
-	}{
-		{"OpenAI", "OAI", []string{"OPENAI_API_KEY"}},
-		{"Anthropic", "ANT", []string{"ANTHROPIC_API_KEY"}},
-		{"OpenRouter", "OR", []string{"OPENROUTER_API_KEY", "OPENROUTER_KEY"}},
-		{"Venice AI", "VEN", []string{"VENICE_API_KEY"}},
-		{"Groq", "GRQ", []string{"GROQ_API_KEY"}},
-		{"Gemini", "GEM", []string{"GEMINI_API_KEY", "GOOGLE_API_KEY"}},
-		{"GitHub", "GH", []string{"GH_TOKEN"}},
-		{"Bedrock", "AWS", []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"}},
-	}
-
 	var keyParts []string
-	for _, entry := range providers {
-		val := firstNonEmpty(m.env, entry.Keys...)
-		label := entry.Label
+	for _, status := range aiProviderKeyStatuses(m.env) {
+		label := status.Label
 		if isCompact {
-			label = entry.Short
+			label = status.Short
 		}
-		if val != "" {
+		if status.Key != "" {
 			keyParts = append(keyParts, activeKeyStyle.Render(label+"✓"))
 		} else {
 			keyParts = append(keyParts, inactiveKeyStyle.Render(label+"·"))
@@ -756,6 +1050,79 @@ func (m aiConfigModel) renderStatusLine() string {
 	return role + "\n" + dimStyle.Render("Keys: ") + strings.Join(keyParts, "  ")
 }
 
+// aiStatusProviders are the providers shown in the status line, with the
+// environment variables checked for each, in order of preference.
+var aiStatusProviders = []struct {
+	Label string
+	Short string
+	Keys  []string
+}{
+	{"OpenAI", "OAI", []string{"OPENAI_API_KEY"}},
+	{"Anthropic", "ANT", []string{"ANTHROPIC_API_KEY"}},
+	{"OpenRouter", "OR", []string{"OPENROUTER_API_KEY", "OPENROUTER_KEY"}},
+	{"Venice AI", "VEN", []string{"VENICE_API_KEY"}},
+	{"Groq", "GRQ", []string{"GROQ_API_KEY"}},
+	{"Gemini", "GEM", []string{"GEMINI_API_KEY", "GOOGLE_API_KEY"}},
+	{"GitHub", "GH", []string{"GH_TOKEN"}},
+	{"Bedrock", "AWS", []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"}},
+}
+
+// aiProviderKeyStatus records which environment variable, if any, a provider's
+// credentials were detected from.
+type aiProviderKeyStatus struct {
+	Label      string
+	Short      string
+	Key        string
+	Candidates []string
+}
+
+func aiProviderKeyStatuses(env map[string]string) []aiProviderKeyStatus {
+	statuses := make([]aiProviderKeyStatus, 0, len(aiStatusProviders))
+	for _, entry := range aiStatusProviders {
+		_, key := firstNonEmpty(env, entry.Keys...)
+		statuses = append(statuses, aiProviderKeyStatus{Label: entry.Label, Short: entry.Short, Key: key, Candidates: entry.Keys})
+	}
+	return statuses
+}
+
+// renderKeySourcesModal explains where each provider's credentials came
+// from, to debug why a provider is or isn't detected.
+func (m aiConfigModel) renderKeySourcesModal() string {
+	modalWidth := m.width - 8
+	if modalWidth > 80 {
+		modalWidth = 80
+	}
+	if modalWidth < 30 {
+		modalWidth = 30
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("243"))
+	activeKeyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+
+	lines := []string{titleStyle.Render("Provider credentials"), ""}
+	for _, status := range aiProviderKeyStatuses(m.env) {
+		label := fmt.Sprintf("%-11s", status.Label)
+		if status.Key != "" {
+			lines = append(lines, label+activeKeyStyle.Render("✓ "+status.Key)+dimStyle.Render(" (host environment)"))
+		} else {
+			lines = append(lines, label+dimStyle.Render("· not set (checked "+strings.Join(status.Candidates, ", ")+")"))
+		}
+	}
+	lines = append(lines, "",
+		dimStyle.Render("Detection uses the host environment dv runs in, not the container's."),
+		"",
+		keyStyle.Render("Esc")+" close")
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1, 2).
+		Width(modalWidth).
+		Render(strings.Join(lines, "\n"))
+}
+
 func (m aiConfigModel) renderDetail() string {
 	item, ok := m.llmList.SelectedItem().(llmItem)
 	if !ok {
@@ -850,6 +1217,135 @@ func (m aiConfigModel) testModelCmd(payload discourse.CreateLLMInput) tea.Cmd {
 	}
 }
 
+// copyLLMConfigCmd copies a configured model's settings as JSON, for sharing
+// when reporting an issue. LLMModel carries no API key, only the ID of its
+// AiSecret. Without clipboard access the JSON is written to a temp file.
+func copyLLMConfigCmd(model ai.LLMModel) tea.Cmd {
+	return func() tea.Msg {
+		data, err := json.MarshalIndent(model, "", "  ")
+		if err != nil {
+			return aiErrMsg{err}
+		}
+		if err := paste.WriteClipboard(data); err == nil {
+			return aiNoticeMsg{notice: fmt.Sprintf("Copied %s config to the clipboard", model.DisplayName)}
+		}
+		f, err := os.CreateTemp("", "dv-llm-*.json")
+		if err != nil {
+			return aiErrMsg{fmt.Errorf("copy config: %w", err)}
+		}
+		defer f.Close()
+		if _, err := f.Write(append(data, '\n')); err != nil {
+			return aiErrMsg{fmt.Errorf("copy config: %w", err)}
+		}
+		return aiNoticeMsg{notice: fmt.Sprintf("Clipboard unavailable; wrote %s config to %s", model.DisplayName, f.Name())}
+	}
+}
+
+// startTestAll tests every configured model in turn with its saved
+// credentials, e.g. after rotating keys or upgrading Discourse.
+func (m aiConfigModel) startTestAll() (tea.Model, tea.Cmd) {
+	if len(m.state.Models) == 0 {
+		m.toast = "No configured models to test"
+		return m, nil
+	}
+	m.testAll = make([]aiModelTestResult, 0, len(m.state.Models))
+	for _, model := range m.state.Models {
+		m.testAll = append(m.testAll, aiModelTestResult{model: model})
+	}
+	m.testAllRun++
+	m.mode = modeTestAll
+	m.toast = ""
+	m.errMsg = ""
+	return m, tea.Batch(m.spinner.Tick, m.testConfiguredModelCmd(0))
+}
+
+func (m aiConfigModel) testConfiguredModelCmd(index int) tea.Cmd {
+	client := m.client
+	ctx := m.ctx
+	run := m.testAllRun
+	input := configuredLLMTestInput(m.testAll[index].model)
+	return func() tea.Msg {
+		return aiTestAllMsg{run: run, index: index, err: client.TestModel(ctx, input)}
+	}
+}
+
+// configuredLLMTestInput rebuilds the test payload for a saved model. Its
+// API key is left as is: the test uses the model's AiSecret.
+func configuredLLMTestInput(model ai.LLMModel) discourse.CreateLLMInput {
+	return discourse.CreateLLMInput{
+		DisplayName:        model.DisplayName,
+		Name:               model.Name,
+		Provider:           model.Provider,
+		Tokenizer:          model.Tokenizer,
+		URL:                model.URL,
+		AiSecretID:         model.AiSecretID,
+		MaxPromptTokens:    model.MaxPromptTokens,
+		MaxOutputTokens:    model.MaxOutputTokens,
+		InputCost:          model.InputCost,
+		CachedInputCost:    model.CachedInputCost,
+		OutputCost:         model.OutputCost,
+		EnabledChatBot:     model.EnabledChatBot,
+		VisionEnabled:      model.VisionEnabled,
+		ProviderParams:     model.ProviderParams,
+		ExistingID:         model.ID,
+		ExistingAiSecretID: model.AiSecretID,
+	}
+}
+
+func (m aiConfigModel) renderTestAllModal() string {
+	modalWidth := m.width - 8
+	if modalWidth > 80 {
+		modalWidth = 80
+	}
+	if modalWidth < 35 {
+		modalWidth = 35
+	}
+	contentWidth := modalWidth - 6
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
+	successStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("203"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("243"))
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+
+	lines := []string{titleStyle.Render("Testing configured models"), ""}
+	passed, failed := 0, 0
+	running := false
+	for _, result := range m.testAll {
+		name := result.model.DisplayName
+		switch {
+		case !result.done && !running:
+			running = true
+			lines = append(lines, m.spinner.View()+" "+name)
+		case !result.done:
+			lines = append(lines, dimStyle.Render("· "+name))
+		case result.err != nil:
+			failed++
+			reason := strings.TrimSpace(strings.SplitN(result.err.Error(), "\n", 2)[0])
+			if limit := max(10, contentWidth-len(name)-3); len(reason) > limit {
+				reason = reason[:limit-1] + "…"
+			}
+			lines = append(lines, errorStyle.Render("✗ "+name)+dimStyle.Render(" "+reason))
+		default:
+			passed++
+			lines = append(lines, successStyle.Render("✓ "+name))
+		}
+	}
+	lines = append(lines, "")
+	if running {
+		lines = append(lines, keyStyle.Render("Esc")+" cancel")
+	} else {
+		lines = append(lines, fmt.Sprintf("%d passed, %d failed", passed, failed), "", keyStyle.Render("Enter")+" to close")
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1, 2).
+		Width(modalWidth).
+		Render(strings.Join(lines, "\n"))
+}
+
 func (m aiConfigModel) updateModelCmd(id int64, payload discourse.CreateLLMInput) tea.Cmd {
 	client := m.client
 	ctx := m.ctx
@@ -882,7 +1378,10 @@ func (m aiConfigModel) prepareModelCredentials(ctx context.Context, client disco
 
 	secretID := payload.ExistingAiSecretID
 	secretName := credentialSecretName(payload)
-	if secretID == 0 && secretName != "" {
+	if payload.SecretAction == discourse.AiSecretNew {
+		secretID = 0
+		secretName = uniqueAiSecretName(m.state.Meta.AiSecrets, payload.DisplayName+" API Key")
+	} else if secretID == 0 && secretName != "" {
 		secretID = findAiSecretIDByName(m.state.Meta.AiSecrets, secretName)
 	}
 
@@ -912,8 +1411,12 @@ func (m aiConfigModel) prepareBedrockCredentials(ctx context.Context, client dis
 	}
 	if payload.APIKey != "" {
 		secretID := payload.ExistingAiSecretID
-		if secretID == 0 {
-			secretID = findAiSecretIDByName(m.state.Meta.AiSecrets, "AWS Bedrock Secret Access Key")
+		secretName := "AWS Bedrock Secret Access Key"
+		if payload.SecretAction == discourse.AiSecretNew {
+			secretID = 0
+			secretName = uniqueAiSecretName(m.state.Meta.AiSecrets, payload.DisplayName+" Secret Access Key")
+		} else if secretID == 0 {
+			secretID = findAiSecretIDByName(m.state.Meta.AiSecrets, secretName)
 		}
 		if secretID > 0 {
 			if err := client.UpdateAiSecret(ctx, secretID, payload.APIKey); err != nil {
@@ -921,7 +1424,7 @@ func (m aiConfigModel) prepareBedrockCredentials(ctx context.Context, client dis
 			}
 			payload.AiSecretID = secretID
 		} else {
-			id, err := client.CreateAiSecret(ctx, "AWS Bedrock Secret Access Key", payload.APIKey)
+			id, err := client.CreateAiSecret(ctx, secretName, payload.APIKey)
 			if err != nil {
 				return payload, err
 			}
@@ -970,6 +1473,16 @@ func credentialSecretName(payload discourse.CreateLLMInput) string {
 	return ""
 }
 
+// uniqueAiSecretName returns name, or name with a numeric suffix when a
+// secret by that name already exists.
+func uniqueAiSecretName(secrets []ai.AiSecret, name string) string {
+	candidate := name
+	for i := 2; findAiSecretIDByName(secrets, candidate) > 0; i++ {
+		candidate = fmt.Sprintf("%s (%d)", name, i)
+	}
+	return candidate
+}
+
 func findAiSecretIDByName(secrets []ai.AiSecret, name string) int64 {
 	name = strings.TrimSpace(name)
 	if name == "" {
@@ -1039,6 +1552,40 @@ func (m aiConfigModel) renderDeleteModal() string {
 		Render(content)
 }
 
+func (m aiConfigModel) renderFilePromptModal() string {
+	modalWidth := m.width - 8
+	if modalWidth > 80 {
+		modalWidth = 80
+	}
+	if modalWidth < 30 {
+		modalWidth = 30
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("243"))
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+
+	title := "Import LLMs from file"
+	hint := "Creates or updates each LLM in the file, like 'dv config ai FILE'."
+	if m.fileAction == fileActionExport {
+		title = "Export LLMs to file"
+		hint = "API keys are not exported; edit the file to add api_key_env or api_key_ref."
+	}
+	m.filePrompt.SetWidth(max(10, modalWidth-6))
+
+	content := titleStyle.Render(title) + "\n\n" +
+		m.filePrompt.View() + "\n\n" +
+		dimStyle.Render(hint) + "\n\n" +
+		keyStyle.Render("Enter") + " confirm  " + keyStyle.Render("Esc") + " cancel"
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1, 2).
+		Width(modalWidth).
+		Render(content)
+}
+
 func (m aiConfigModel) renderSavingModal() string {
 	// Responsive width
 	modalWidth := m.width - 8
@@ -1298,6 +1845,7 @@ type createForm struct {
 	fields             []*formField
 	focusIndex         int
 	err                string
+	notice             string // non-fatal, e.g. a saved value that was reset
 	mode               formMode
 	editingID          int64
 	existingAiSecretID int64
@@ -1313,6 +1861,7 @@ func newCreateForm(entryID string, model ai.ProviderModel, meta ai.LLMMetadata,
 	if maxOutputTokens <= 0 {
 		maxOutputTokens = model.ContextTokens / 4
 	}
+	apiKey, _ := firstNonEmpty(env, providerKeyHints(entryID)...)
 
 	fields := []*formField{
 		newTextField("display_name", "Display Name", model.DisplayName, false),
@@ -1320,7 +1869,7 @@ func newCreateForm(entryID string, model ai.ProviderModel, meta ai.LLMMetadata,
 		newTextField("provider", "Provider", providerSlug(entryID), false),
 		newTextField("tokenizer", "Tokenizer", defaultTokenizerFor(model.Provider, meta), false),
 		newTextField("url", "API URL", model.Endpoint, false),
-		newTextField("api_key", "API Key", firstNonEmpty(env, providerKeyHints(entryID)...), true),
+		newTextField("api_key", "API Key", apiKey, true),
 		newTextField("max_prompt_tokens", "Max Prompt Tokens", safeInt(model.ContextTokens, 131072), false),
 		newTextField("max_output_tokens", "Max Output Tokens", safeInt(maxOutputTokens, 4096), false),
 		newTextField("input_cost", "Input Cost ($/1M)", fmt.Sprintf("%.4f", model.InputCost), false),
@@ -1336,16 +1885,17 @@ func newCreateForm(entryID string, model ai.ProviderModel, meta ai.LLMMetadata,
 		defaults["enable_responses_api"] = true
 	}
 	if providerKey == "aws_bedrock" {
-		if accessKeyID := firstNonEmpty(env, "AWS_ACCESS_KEY_ID"); accessKeyID != "" {
+		if accessKeyID, _ := firstNonEmpty(env, "AWS_ACCESS_KEY_ID"); accessKeyID != "" {
 			defaults["access_key_id"] = accessKeyID
 		}
-		if region := firstNonEmpty(env, "AWS_REGION"); region != "" {
+		if region, _ := firstNonEmpty(env, "AWS_REGION"); region != "" {
 			defaults["region"] = region
 		} else {
 			defaults["region"] = "us-west-2"
 		}
 	}
-	fields = append(fields, buildProviderParamFields(providerKey, meta, nil, defaults)...)
+	paramFields, _ := buildProviderParamFields(providerKey, meta, nil, defaults)
+	fields = append(fields, paramFields...)
 	vp := viewport.New(viewport.WithWidth(0), viewport.WithHeight(0))
 	f := &createForm{
 		entryID:  entryID,
@@ -1365,6 +1915,7 @@ func newEditForm(llm ai.LLMModel, meta ai.LLMMetadata, isDefault bool) *createFo
 		newTextField("tokenizer", "Tokenizer", llm.Tokenizer, false),
 		newTextField("url", "API URL", llm.URL, false),
 		newTextField("api_key", "API Key", "", true),
+		newSelectField("api_key_mode", "API Key Mode", apiKeyModeKeep, []string{apiKeyModeKeep, apiKeyModeRotate, apiKeyModeNew}),
 		newTextField("max_prompt_tokens", "Max Prompt Tokens", fmt.Sprintf("%d", llm.MaxPromptTokens), false),
 		newTextField("max_output_tokens", "Max Output Tokens", fmt.Sprintf("%d", llm.MaxOutputTokens), false),
 		newTextField("input_cost", "Input Cost ($/1M)", fmt.Sprintf("%.4f", llm.InputCost), false),
@@ -1374,7 +1925,8 @@ func newEditForm(llm ai.LLMModel, meta ai.LLMMetadata, isDefault bool) *createFo
 		newBoolField("enabled_chat_bot", "Enable chat bot", llm.EnabledChatBot),
 		newBoolField("vision_enabled", "Enable vision", llm.VisionEnabled),
 	}
-	fields = append(fields, buildProviderParamFields(llm.Provider, meta, llm.ProviderParams, nil)...)
+	paramFields, notices := buildProviderParamFields(llm.Provider, meta, llm.ProviderParams, nil)
+	fields = append(fields, paramFields...)
 	vp := viewport.New(viewport.WithWidth(0), viewport.WithHeight(0))
 	f := &createForm{
 		fields:             fields,
@@ -1382,9 +1934,10 @@ func newEditForm(llm ai.LLMModel, meta ai.LLMMetadata, isDefault bool) *createFo
 		mode:               formModeEdit,
 		editingID:          llm.ID,
 		existingAiSecretID: llm.AiSecretID,
+		notice:             strings.Join(notices, "\n"),
 		viewport:           vp,
 	}
-	fields[5].Model.Placeholder = "Leave blank to keep current key"
+	fields[5].Model.Placeholder = "Leave blank to keep current key; otherwise pick rotate or new below"
 	f.updateFocus()
 	return f
 }
@@ -1668,6 +2221,12 @@ func (f *createForm) View() string {
 			Bold(true).
 			Render("✓ Test passed")
 	}
+	if f.notice != "" && statusLine == "" {
+		statusLine = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214")).
+			Width(contentWidth).
+			Render(f.notice)
+	}
 	if f.err != "" {
 		errStyle := lipgloss.NewStyle().
 			Foreground(lipgloss.Color("203")).
@@ -1724,6 +2283,15 @@ func (f *createForm) payload() (discourse.CreateLLMInput, error) {
 	payload.Tokenizer = strings.TrimSpace(f.value("tokenizer"))
 	payload.URL = strings.TrimSpace(f.value("url"))
 	apiKey := strings.TrimSpace(f.value("api_key"))
+	if f.isEdit() {
+		payload.SecretAction = apiKeyModeActions[f.selectValue("api_key_mode")]
+		switch {
+		case payload.SecretAction == discourse.AiSecretKeep && apiKey != "":
+			return payload, fmt.Errorf("set API Key Mode to rotate or new to use the API key you entered")
+		case payload.SecretAction != discourse.AiSecretKeep && apiKey == "":
+			return payload, fmt.Errorf("enter the new API key, or set API Key Mode to keep")
+		}
+	}
 	if payload.Provider == "" {
 		if slug := providerSlug(f.entryID); slug != "" {
 			payload.Provider = slug
@@ -1768,6 +2336,21 @@ func (f *createForm) payload() (discourse.CreateLLMInput, error) {
 	return payload, nil
 }
 
+// API Key Mode choices in the edit form. Rotating updates the model's
+// current AiSecret in place, so every model sharing it picks up the new key;
+// "new" gives this model a secret of its own.
+const (
+	apiKeyModeKeep   = "keep current key"
+	apiKeyModeRotate = "rotate shared key"
+	apiKeyModeNew    = "new key for this model"
+)
+
+var apiKeyModeActions = map[string]discourse.AiSecretAction{
+	apiKeyModeKeep:   discourse.AiSecretKeep,
+	apiKeyModeRotate: discourse.AiSecretRotate,
+	apiKeyModeNew:    discourse.AiSecretNew,
+}
+
 func (f *createForm) isEdit() bool {
 	return f.mode == formModeEdit
 }
@@ -1788,6 +2371,15 @@ func (f *createForm) value(key string) string {
 	return ""
 }
 
+func (f *createForm) selectValue(key string) string {
+	for _, field := range f.fields {
+		if field.Key == key && field.Kind == fieldSelect {
+			return field.SelectValue
+		}
+	}
+	return ""
+}
+
 func (f *createForm) boolValue(key string) bool {
 	for _, field := range f.fields {
 		if field.Key == key && field.Kind == fieldBool {
@@ -1856,14 +2448,17 @@ func isNumericString(s string) bool {
 	return true
 }
 
-func buildProviderParamFields(provider string, meta ai.LLMMetadata, existing map[string]interface{}, defaults map[string]interface{}) []*formField {
+// buildProviderParamFields builds the form fields for a provider's params.
+// The notices it returns describe saved values that could not be kept, such
+// as an enum value the provider no longer offers.
+func buildProviderParamFields(provider string, meta ai.LLMMetadata, existing map[string]interface{}, defaults map[string]interface{}) ([]*formField, []string) {
 	slug := providerSlug(strings.TrimSpace(provider))
 	if slug == "" {
-		return nil
+		return nil, nil
 	}
 	specs, ok := meta.ProviderParams[slug]
 	if !ok || len(specs) == 0 {
-		return nil
+		return nil, nil
 	}
 	keys := make([]string, 0, len(specs))
 	for name := range specs {
@@ -1872,6 +2467,7 @@ func buildProviderParamFields(provider string, meta ai.LLMMetadata, existing map
 	sort.Strings(keys)
 
 	var fields []*formField
+	var notices []string
 	for _, name := range keys {
 		spec := specs[name]
 		label := strings.Title(strings.ReplaceAll(name, "_", " "))
@@ -1901,7 +2497,8 @@ func buildProviderParamFields(provider string, meta ai.LLMMetadata, existing map
 				field.IsProvider = true
 				fields = append(fields, field)
 			case "enum":
-				val := stringFromExisting(existing, name)
+				saved := stringFromExisting(existing, name)
+				val := saved
 				if val == "" {
 					val = defaultString(stringValue(def["default"]), existing, defaults, name)
 				}
@@ -1917,6 +2514,9 @@ func buildProviderParamFields(provider string, meta ai.LLMMetadata, existing map
 					field := newSelectField(name, label, val, opts)
 					field.IsProvider = true
 					fields = append(fields, field)
+					if saved != "" && field.SelectValue != saved {
+						notices = append(notices, fmt.Sprintf("%s: saved value %q is no longer available; reset to %q", label, saved, field.SelectValue))
+					}
 				} else {
 					// Fallback to text field if no options
 					field := newTextField(name, label, val, false)
@@ -1934,7 +2534,7 @@ func buildProviderParamFields(provider string, meta ai.LLMMetadata, existing map
 			fields = append(fields, field)
 		}
 	}
-	return fields
+	return fields, notices
 }
 
 func stringFromExisting(existing map[string]interface{}, key string) string {
@@ -2096,13 +2696,15 @@ func joinUsage(usages []ai.LLMUsage) string {
 	return strings.Join(names, ", ")
 }
 
-func firstNonEmpty(env map[string]string, keys ...string) string {
+// firstNonEmpty returns the first of keys set in env, along with which key
+// it was.
+func firstNonEmpty(env map[string]string, keys ...string) (string, string) {
 	for _, key := range keys {
 		if val := strings.TrimSpace(env[key]); val != "" {
-			return val
+			return val, key
 		}
 	}
-	return ""
+	return "", ""
 }
 
 func max(a, b int) int {
@@ -2,9 +2,13 @@ package cli
 
 import (
 	"context"
+	"errors"
+	"os"
 	"strings"
 	"testing"
 
+	"charm.land/bubbles/v2/list"
+
 	"dv/internal/ai"
 	"dv/internal/discourse"
 )
@@ -68,7 +72,7 @@ func TestCatalogItemsHideProvidersWithoutCredentials(t *testing.T) {
 				DisplayName: "Venice Uncensored",
 			}},
 		},
-	}})
+	}}, "")
 
 	if len(items) != 1 {
 		t.Fatalf("expected only credentialed provider models, got %d items", len(items))
@@ -93,7 +97,7 @@ func TestCatalogItemsLabelVeniceAsVenice(t *testing.T) {
 			Provider:      "open_ai",
 			ContextTokens: 400000,
 		}},
-	}}})
+	}}}, "")
 
 	if len(items) != 1 {
 		t.Fatalf("expected 1 item, got %d", len(items))
@@ -108,6 +112,166 @@ func TestCatalogItemsLabelVeniceAsVenice(t *testing.T) {
 	}
 }
 
+func TestCatalogProviderFilterCyclesAndKeepsLockedEntries(t *testing.T) {
+	cat := ai.ProviderCatalog{Entries: []ai.ProviderEntry{
+		{ID: "openai", Title: "OpenAI", HasCredentials: true, Models: []ai.ProviderModel{{ID: "gpt-5"}, {ID: "gpt-5-mini"}}},
+		{ID: "groq", Title: "Groq", HasCredentials: false, Models: []ai.ProviderModel{{ID: "cached"}}},
+		{ID: "anthropic", Title: "Anthropic", HasCredentials: true, Error: "fetch failed"},
+	}}
+
+	if got := len(catalogItems(cat, "")); got != 3 {
+		t.Fatalf("unfiltered catalog has %d items, want 3", got)
+	}
+	if got := len(catalogItems(cat, "openai")); got != 2 {
+		t.Fatalf("openai catalog has %d items, want 2", got)
+	}
+	items := catalogItems(cat, "anthropic")
+	if len(items) != 1 || !items[0].(providerItem).locked {
+		t.Fatalf("anthropic catalog = %+v, want its locked entry", items)
+	}
+
+	var seen []string
+	provider := ""
+	for i := 0; i < 3; i++ {
+		provider = nextCatalogProvider(cat, provider, 1)
+		seen = append(seen, provider)
+	}
+	if strings.Join(seen, ",") != "openai,anthropic," {
+		t.Fatalf("cycling forward visited %q", seen)
+	}
+	if got := nextCatalogProvider(cat, "", -1); got != "anthropic" {
+		t.Fatalf("cycling backward from all = %q, want anthropic", got)
+	}
+	if got := catalogTitle(cat, "openai"); got != "Provider Catalog · OpenAI" {
+		t.Fatalf("catalogTitle() = %q", got)
+	}
+}
+
+func TestUpdateListsKeepsFilterAndSelection(t *testing.T) {
+	models := []ai.LLMModel{
+		{ID: 1, DisplayName: "Claude Opus"},
+		{ID: 2, DisplayName: "GPT-5"},
+		{ID: 3, DisplayName: "Claude Sonnet"},
+	}
+	m := newAiConfigModel(aiConfigOptions{state: ai.LLMState{Models: models}})
+	m.llmList.SetSize(40, 20)
+	m.llmList.SetFilterText("claude")
+	m.llmList.Select(1)
+	if item := m.llmList.SelectedItem().(llmItem); item.model.ID != 3 {
+		t.Fatalf("setup selected %d, want 3", item.model.ID)
+	}
+
+	m.state = ai.LLMState{Models: append([]ai.LLMModel{{ID: 4, DisplayName: "Claude Haiku"}}, models...)}
+	m.updateLists()
+	if got := m.llmList.FilterValue(); got != "claude" || m.llmList.FilterState() != list.FilterApplied {
+		t.Fatalf("filter = %q (%v), want claude applied", got, m.llmList.FilterState())
+	}
+	if item := m.llmList.SelectedItem().(llmItem); item.model.ID != 3 {
+		t.Fatalf("selected %d after refresh, want 3", item.model.ID)
+	}
+
+	m.state = ai.LLMState{Models: models[:2]}
+	m.updateLists()
+	if item, ok := m.llmList.SelectedItem().(llmItem); !ok || item.model.ID != 1 {
+		t.Fatalf("selected %v after deleting the selection, want the remaining match", m.llmList.SelectedItem())
+	}
+}
+
+func TestAIProviderKeyStatusesReportMatchedKey(t *testing.T) {
+	statuses := aiProviderKeyStatuses(map[string]string{
+		"OPENROUTER_KEY": "sk-or",
+		"GEMINI_API_KEY": " ",
+		"GOOGLE_API_KEY": "g-key",
+	})
+	byLabel := map[string]aiProviderKeyStatus{}
+	for _, status := range statuses {
+		byLabel[status.Label] = status
+	}
+	if got := byLabel["OpenRouter"].Key; got != "OPENROUTER_KEY" {
+		t.Fatalf("OpenRouter key = %q, want OPENROUTER_KEY", got)
+	}
+	if got := byLabel["Gemini"].Key; got != "GOOGLE_API_KEY" {
+		t.Fatalf("Gemini key = %q, want GOOGLE_API_KEY (blank values are skipped)", got)
+	}
+	if status := byLabel["OpenAI"]; status.Key != "" || len(status.Candidates) != 1 {
+		t.Fatalf("OpenAI status = %+v, want unset with one candidate", status)
+	}
+}
+
+func TestTestAllRunsEachConfiguredModel(t *testing.T) {
+	models := []ai.LLMModel{
+		{ID: 1, DisplayName: "GPT-5", Provider: "open_ai", AiSecretID: 9},
+		{ID: 2, DisplayName: "Claude", Provider: "anthropic"},
+	}
+	m := newAiConfigModel(aiConfigOptions{state: ai.LLMState{Models: models}, client: &fakeAIConfigClient{}, ctx: context.Background()})
+	next, cmd := m.startTestAll()
+	m = next.(aiConfigModel)
+	if m.mode != modeTestAll || cmd == nil {
+		t.Fatalf("mode = %v, cmd = %v; want a running test-all", m.mode, cmd)
+	}
+
+	next, cmd = m.Update(aiTestAllMsg{run: m.testAllRun, index: 0, err: errors.New("bad key")})
+	m = next.(aiConfigModel)
+	if cmd == nil {
+		t.Fatal("expected the second model to be tested next")
+	}
+	if msg, ok := m.testConfiguredModelCmd(1)().(aiTestAllMsg); !ok || msg.index != 1 || msg.err != nil {
+		t.Fatalf("testConfiguredModelCmd(1)() = %+v", msg)
+	}
+	next, cmd = m.Update(aiTestAllMsg{run: m.testAllRun, index: 1})
+	m = next.(aiConfigModel)
+	if cmd != nil {
+		t.Fatal("expected no further tests after the last model")
+	}
+	if !m.testAll[0].done || m.testAll[0].err == nil || !m.testAll[1].done || m.testAll[1].err != nil {
+		t.Fatalf("unexpected results: %+v", m.testAll)
+	}
+
+	input := configuredLLMTestInput(models[0])
+	if input.AiSecretID != 9 || input.APIKey != "" || input.ExistingID != 1 {
+		t.Fatalf("configuredLLMTestInput() = %+v, want the saved AiSecret and no API key", input)
+	}
+}
+
+func TestEditFormNoticesResetEnumValue(t *testing.T) {
+	meta := ai.LLMMetadata{ProviderParams: map[string]map[string]interface{}{
+		"open_ai": {
+			"reasoning_effort": map[string]interface{}{
+				"type":   "enum",
+				"values": []interface{}{"low", "medium", "high"},
+			},
+		},
+	}}
+
+	form := newEditForm(ai.LLMModel{Provider: "open_ai", ProviderParams: map[string]interface{}{"reasoning_effort": "minimal"}}, meta, false)
+	if !strings.Contains(form.notice, `"minimal"`) || !strings.Contains(form.notice, `reset to "low"`) {
+		t.Fatalf("notice = %q, want the dropped value reported", form.notice)
+	}
+
+	form = newEditForm(ai.LLMModel{Provider: "open_ai", ProviderParams: map[string]interface{}{"reasoning_effort": "high"}}, meta, false)
+	if form.notice != "" {
+		t.Fatalf("notice = %q, want none for a valid value", form.notice)
+	}
+}
+
+func TestCopyLLMConfigFallsBackToTempFile(t *testing.T) {
+	t.Setenv("PATH", "")
+	t.Setenv("TMPDIR", t.TempDir())
+
+	msg, ok := copyLLMConfigCmd(ai.LLMModel{ID: 5, DisplayName: "GPT-5", Name: "gpt-5", AiSecretID: 3})().(aiNoticeMsg)
+	if !ok {
+		t.Fatalf("expected aiNoticeMsg, got %T", msg)
+	}
+	path := msg.notice[strings.LastIndex(msg.notice, " ")+1:]
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("notice %q does not name a readable file: %v", msg.notice, err)
+	}
+	if !strings.Contains(string(data), `"name": "gpt-5"`) || strings.Contains(string(data), "api_key\"") {
+		t.Fatalf("unexpected config JSON:\n%s", data)
+	}
+}
+
 func TestDisplayProviderForLLMDetectsVeniceOpenAICompat(t *testing.T) {
 	llm := ai.LLMModel{Provider: "open_ai", URL: "https://api.venice.ai/api/v1/chat/completions"}
 	if got := displayProviderForLLM(llm); got != "venice_ai" {
@@ -138,6 +302,66 @@ func TestPrepareModelCredentialsCreatesVeniceAiSecret(t *testing.T) {
 	}
 }
 
+func TestPrepareModelCredentialsNewSecretLeavesSharedSecretAlone(t *testing.T) {
+	client := &fakeAIConfigClient{createdID: 88}
+	model := aiConfigModel{state: ai.LLMState{Meta: ai.LLMMetadata{AiSecrets: []ai.AiSecret{
+		{ID: 77, Name: "OpenAI API Key"},
+		{ID: 78, Name: "GPT API Key"},
+	}}}}
+	payload, err := model.prepareModelCredentials(context.Background(), client, discourse.CreateLLMInput{
+		DisplayName:        "GPT",
+		Provider:           "open_ai",
+		APIKey:             "own-key",
+		ExistingAiSecretID: 77,
+		SecretAction:       discourse.AiSecretNew,
+	})
+	if err != nil {
+		t.Fatalf("prepareModelCredentials: %v", err)
+	}
+	if client.updatedID != 0 {
+		t.Fatalf("shared secret %d was updated", client.updatedID)
+	}
+	if client.createdName != "GPT API Key (2)" || client.createdSecret != "own-key" || payload.AiSecretID != 88 {
+		t.Fatalf("created %q/%q, payload secret %d", client.createdName, client.createdSecret, payload.AiSecretID)
+	}
+}
+
+func TestEditFormAPIKeyModeIsExplicit(t *testing.T) {
+	llm := ai.LLMModel{ID: 3, DisplayName: "GPT", Name: "gpt-5", Provider: "open_ai", Tokenizer: "tok", URL: "https://example.com", AiSecretID: 7}
+	setField := func(f *createForm, key, value string) {
+		for _, field := range f.fields {
+			if field.Key == key {
+				if field.Kind == fieldSelect {
+					field.SelectValue = value
+				} else {
+					field.Model.SetValue(value)
+				}
+			}
+		}
+	}
+
+	form := newEditForm(llm, ai.LLMMetadata{}, false)
+	payload, err := form.payload()
+	if err != nil || payload.SecretAction != discourse.AiSecretKeep {
+		t.Fatalf("payload() = %v, %v; want keep by default", payload.SecretAction, err)
+	}
+
+	setField(form, "api_key", "new-key")
+	if _, err := form.payload(); err == nil {
+		t.Fatal("expected an error for a key entered while keeping the current one")
+	}
+	setField(form, "api_key_mode", apiKeyModeRotate)
+	payload, err = form.payload()
+	if err != nil || payload.SecretAction != discourse.AiSecretRotate || payload.APIKey != "new-key" {
+		t.Fatalf("payload() = %+v, %v; want rotate with the new key", payload, err)
+	}
+
+	setField(form, "api_key", "")
+	if _, err := form.payload(); err == nil {
+		t.Fatal("expected an error when rotating without a key")
+	}
+}
+
 func TestPrepareModelCredentialsReusesExistingVeniceAiSecret(t *testing.T) {
 	client := &fakeAIConfigClient{}
 	model := aiConfigModel{state: ai.LLMState{Meta: ai.LLMMetadata{AiSecrets: []ai.AiSecret{{ID: 77, Name: "Venice AI API Key"}}}}}
@@ -0,0 +1,327 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"dv/internal/config"
+	"dv/internal/xdg"
+)
+
+// machineLocalConfigKeys describe containers on this machine, so they are
+// never exported and are ignored when importing.
+var machineLocalConfigKeys = []string{"containerImages", "customWorkdirs", "labelOverrides", "selectedAgent"}
+
+// secretConfigKeys are only exported with --include-secrets. env holds
+// literal values (often API keys) passed into containers.
+var secretConfigKeys = []string{"serveToken", "env"}
+
+// agentEnvSecretsKey names the KEY=VALUE entries of agents.*.env and
+// agentOverrides.*.env when reporting what export left out.
+const agentEnvSecretsKey = "agent env values"
+
+var configExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Print the config as JSON for moving it to another machine",
+	Long: `Print the dv config as JSON, ready for 'dv config import' on another machine:
+
+  dv config export > dv-config.json
+
+Container-specific state (image provenance, per-container workdirs, label
+overrides, selected agent) is left out. The serve token, env values and
+KEY=VALUE entries in agent env lists are left out too unless --include-secrets
+is given.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configDir, err := xdg.ConfigDir()
+		if err != nil {
+			return err
+		}
+		cfg, err := config.LoadOrCreate(configDir)
+		if err != nil {
+			return err
+		}
+		includeSecrets, _ := cmd.Flags().GetBool("include-secrets")
+		data, omitted, err := exportConfig(cfg, includeSecrets)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		if len(omitted) > 0 {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Omitted %s; pass --include-secrets to export them.\n", strings.Join(omitted, ", "))
+		}
+		return nil
+	},
+}
+
+var configImportCmd = &cobra.Command{
+	Use:   "import FILE",
+	Short: "Merge an exported config into the local config",
+	Long: `Merge a file written by 'dv config export' (or "-" for stdin) into the local
+config. Settings in the file replace local ones; map settings such as agents
+and images are merged key by key, and settings missing from the file (like an
+omitted serve token) keep their local values. Files from an older dv are
+migrated first, and values 'dv config set' would reject are refused.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var data []byte
+		var err error
+		if args[0] == "-" {
+			data, err = io.ReadAll(cmd.InOrStdin())
+		} else {
+			data, err = os.ReadFile(expandHostPath(args[0]))
+		}
+		if err != nil {
+			return err
+		}
+
+		configDir, err := xdg.ConfigDir()
+		if err != nil {
+			return err
+		}
+		cfg, err := config.LoadOrCreate(configDir)
+		if err != nil {
+			return err
+		}
+		if err := importConfig(&cfg, data); err != nil {
+			return fmt.Errorf("import %s: %w", args[0], err)
+		}
+		if err := config.Save(configDir, cfg); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Imported %s into %s\n", args[0], config.Path(configDir))
+		return nil
+	},
+}
+
+func init() {
+	configExportCmd.Flags().Bool("include-secrets", false, "Also export the serve token, env values and KEY=VALUE agent env entries")
+	configCmd.AddCommand(configExportCmd)
+	configCmd.AddCommand(configImportCmd)
+}
+
+// exportConfig renders cfg for `dv config export`, returning the secret keys
+// it left out.
+func exportConfig(cfg config.Config, includeSecrets bool) ([]byte, []string, error) {
+	var omitted []string
+	if !includeSecrets && stripAgentEnvValues(&cfg) {
+		omitted = append(omitted, agentEnvSecretsKey)
+	}
+	fields, err := configFields(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, key := range machineLocalConfigKeys {
+		delete(fields, key)
+	}
+	if !includeSecrets {
+		for _, key := range secretConfigKeys {
+			if _, ok := fields[key]; ok {
+				delete(fields, key)
+				omitted = append(omitted, key)
+			}
+		}
+	}
+	data, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, omitted, nil
+}
+
+// stripAgentEnvValues drops KEY=VALUE entries from the env lists of cfg's
+// agents and agent overrides, keeping bare passthrough names. The maps are
+// copied so the caller's config is untouched. It reports whether anything
+// was dropped.
+func stripAgentEnvValues(cfg *config.Config) bool {
+	stripped := false
+	keepNames := func(env []string) []string {
+		var names []string
+		for _, entry := range env {
+			if strings.Contains(entry, "=") {
+				stripped = true
+				continue
+			}
+			names = append(names, entry)
+		}
+		return names
+	}
+	if cfg.Agents != nil {
+		agents := make(map[string]config.AgentConfig, len(cfg.Agents))
+		for name, agent := range cfg.Agents {
+			agent.Env = keepNames(agent.Env)
+			agents[name] = agent
+		}
+		cfg.Agents = agents
+	}
+	if cfg.AgentOverrides != nil {
+		overrides := make(map[string]config.AgentOverride, len(cfg.AgentOverrides))
+		for name, override := range cfg.AgentOverrides {
+			override.Env = keepNames(override.Env)
+			overrides[name] = override
+		}
+		cfg.AgentOverrides = overrides
+	}
+	return stripped
+}
+
+// importConfig merges exported JSON into cfg. Keys present in data win; map
+// fields gain the imported entries, as json.Unmarshal into a populated map
+// does. Machine-local keys in data are ignored. Older files are migrated to
+// the current version first, and every key setConfigField knows is checked
+// the way `dv config set` checks it; nothing is merged when a check fails.
+func importConfig(cfg *config.Config, data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("invalid config JSON: %w", err)
+	}
+	var version int
+	if raw, ok := fields["version"]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return fmt.Errorf("invalid version: %w", err)
+		}
+	}
+	if version > config.CurrentVersion {
+		return fmt.Errorf("config version %d is newer than this dv supports (%d); upgrade dv first", version, config.CurrentVersion)
+	}
+
+	var imported config.Config
+	if err := json.Unmarshal(data, &imported); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	config.Migrate(&imported)
+	migrated, err := configFields(imported)
+	if err != nil {
+		return err
+	}
+
+	// Keep the local schema version; the local config is already current.
+	delete(fields, "version")
+	for _, key := range machineLocalConfigKeys {
+		delete(fields, key)
+	}
+	if len(fields) == 0 {
+		return fmt.Errorf("no settings to import")
+	}
+	for key := range fields {
+		if raw, ok := migrated[key]; ok {
+			fields[key] = raw
+		}
+	}
+
+	check := config.Default()
+	for _, key := range settableConfigKeyNames() {
+		if _, ok := fields[key]; !ok {
+			continue
+		}
+		val, err := getConfigField(imported, key)
+		if err != nil {
+			return err
+		}
+		if err := setConfigField(&check, key, val); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	if err := mergeImportedAgents(cfg, fields); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	merged, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(merged, cfg); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	return nil
+}
+
+// configFields returns cfg's JSON object keyed by field name.
+func configFields(cfg config.Config) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// mergeImportedAgents merges the agents and agentOverrides fields into cfg
+// entry by entry and removes them from fields. Export strips KEY=VALUE env
+// entries, so replacing a whole entry would drop local secrets; instead env
+// lists are merged by variable name and the other fields are overlaid.
+func mergeImportedAgents(cfg *config.Config, fields map[string]json.RawMessage) error {
+	if raw, ok := fields["agents"]; ok {
+		agents, err := mergeAgentEntries(cfg.Agents, raw, func(a *config.AgentConfig) *[]string { return &a.Env })
+		if err != nil {
+			return err
+		}
+		cfg.Agents = agents
+		delete(fields, "agents")
+	}
+	if raw, ok := fields["agentOverrides"]; ok {
+		overrides, err := mergeAgentEntries(cfg.AgentOverrides, raw, func(o *config.AgentOverride) *[]string { return &o.Env })
+		if err != nil {
+			return err
+		}
+		cfg.AgentOverrides = overrides
+		delete(fields, "agentOverrides")
+	}
+	return nil
+}
+
+func mergeAgentEntries[T any](local map[string]T, raw json.RawMessage, env func(*T) *[]string) (map[string]T, error) {
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, err
+	}
+	merged := make(map[string]T, len(local)+len(entries))
+	maps.Copy(merged, local)
+	for name, entryRaw := range entries {
+		entry, exists := local[name]
+		// Unmarshal reuses slice backing arrays, so copy the local env first.
+		localEnv := slices.Clone(*env(&entry))
+		if err := json.Unmarshal(entryRaw, &entry); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if exists {
+			*env(&entry) = mergeEnvEntries(localEnv, *env(&entry))
+		}
+		merged[name] = entry
+	}
+	return merged, nil
+}
+
+// mergeEnvEntries overlays imported env entries on local ones, matching by
+// variable name. An imported KEY=VALUE replaces the local entry, but a bare
+// imported KEY keeps a local KEY=VALUE since export strips values.
+func mergeEnvEntries(local, imported []string) []string {
+	out := slices.Clone(local)
+	index := make(map[string]int, len(out))
+	for i, entry := range out {
+		name, _, _ := strings.Cut(entry, "=")
+		index[name] = i
+	}
+	for _, entry := range imported {
+		name, _, hasValue := strings.Cut(entry, "=")
+		if i, ok := index[name]; ok {
+			if hasValue || !strings.Contains(out[i], "=") {
+				out[i] = entry
+			}
+			continue
+		}
+		index[name] = len(out)
+		out = append(out, entry)
+	}
+	return out
+}
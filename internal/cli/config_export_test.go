@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"dv/internal/config"
+)
+
+func TestExportConfigOmitsSecretsAndLocalState(t *testing.T) {
+	cfg := config.Default()
+	cfg.ServeToken = "tok"
+	cfg.Env = map[string]string{"OPENAI_API_KEY": "sk-1"}
+	cfg.ContainerImages["ai_agent"] = "discourse"
+	cfg.CustomWorkdirs["ai_agent"] = "/src"
+	cfg.SelectedAgent = "ai_agent"
+	cfg.GitUserName = "Dev"
+	cfg.Agents = map[string]config.AgentConfig{"aider": {Command: "aider", Env: []string{"AIDER_KEY=sk-2", "HOME_PROXY"}}}
+	cfg.AgentOverrides = map[string]config.AgentOverride{"codex": {Env: []string{"CODEX_TOKEN=sk-3"}}}
+
+	data, omitted, err := exportConfig(cfg, false)
+	if err != nil {
+		t.Fatalf("exportConfig: %v", err)
+	}
+	for _, key := range []string{`"serveToken"`, `"containerImages"`, `"customWorkdirs"`, `"selectedAgent"`, "sk-1", "sk-2", "sk-3"} {
+		if strings.Contains(string(data), key) {
+			t.Errorf("export contains %q:\n%s", key, data)
+		}
+	}
+	if !strings.Contains(string(data), `"gitUserName": "Dev"`) || !strings.Contains(string(data), "HOME_PROXY") {
+		t.Errorf("export is missing gitUserName or passthrough env names:\n%s", data)
+	}
+	if strings.Join(omitted, ",") != agentEnvSecretsKey+",serveToken,env" {
+		t.Errorf("omitted = %v", omitted)
+	}
+	if cfg.Agents["aider"].Env[0] != "AIDER_KEY=sk-2" {
+		t.Errorf("export modified the caller's agents: %v", cfg.Agents)
+	}
+
+	data, omitted, err = exportConfig(cfg, true)
+	if err != nil {
+		t.Fatalf("exportConfig with secrets: %v", err)
+	}
+	if !strings.Contains(string(data), `"serveToken": "tok"`) || !strings.Contains(string(data), "sk-1") ||
+		!strings.Contains(string(data), "sk-3") || len(omitted) != 0 {
+		t.Errorf("export with secrets = %s (omitted %v)", data, omitted)
+	}
+	if strings.Contains(string(data), "containerImages") {
+		t.Errorf("export with secrets still includes container state:\n%s", data)
+	}
+}
+
+func TestImportConfigMergesIntoLocal(t *testing.T) {
+	src := config.Default()
+	src.GitUserEmail = "dev@example.com"
+	src.Agents = map[string]config.AgentConfig{"aider": {Command: "aider"}}
+	src.ServeToken = "remote-token"
+	data, _, err := exportConfig(src, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	local := config.Default()
+	local.ServeToken = "local-token"
+	local.Agents = map[string]config.AgentConfig{"mine": {Command: "mine"}}
+	local.ContainerImages["ai_agent"] = "discourse"
+	if err := importConfig(&local, data); err != nil {
+		t.Fatalf("importConfig: %v", err)
+	}
+	if local.GitUserEmail != "dev@example.com" {
+		t.Errorf("GitUserEmail = %q", local.GitUserEmail)
+	}
+	if local.ServeToken != "local-token" {
+		t.Errorf("ServeToken = %q, want the local token kept", local.ServeToken)
+	}
+	if _, ok := local.Agents["mine"]; !ok {
+		t.Errorf("local agent dropped: %v", local.Agents)
+	}
+	if _, ok := local.Agents["aider"]; !ok {
+		t.Errorf("imported agent missing: %v", local.Agents)
+	}
+	if local.ContainerImages["ai_agent"] != "discourse" {
+		t.Errorf("ContainerImages = %v", local.ContainerImages)
+	}
+}
+
+func TestImportConfigRoundTripKeepsLocalAgentSecrets(t *testing.T) {
+	local := config.Default()
+	local.Agents = map[string]config.AgentConfig{
+		"aider": {Command: "aider", Env: []string{"AIDER_KEY=sk-local", "HOME_PROXY"}},
+	}
+	local.AgentOverrides = map[string]config.AgentOverride{
+		"codex": {Env: []string{"CODEX_TOKEN=sk-codex"}, Defaults: []string{"--fast"}},
+	}
+
+	src := config.Default()
+	src.Agents = map[string]config.AgentConfig{
+		"aider": {Command: "aider", Args: []string{"--no-git"}, Env: []string{"AIDER_KEY=sk-remote", "HOME_PROXY", "EXTRA"}},
+	}
+	src.AgentOverrides = map[string]config.AgentOverride{
+		"codex": {Defaults: []string{"--slow"}},
+	}
+	data, _, err := exportConfig(src, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := importConfig(&local, data); err != nil {
+		t.Fatalf("importConfig: %v", err)
+	}
+
+	aider := local.Agents["aider"]
+	if want := []string{"AIDER_KEY=sk-local", "HOME_PROXY", "EXTRA"}; strings.Join(aider.Env, ",") != strings.Join(want, ",") {
+		t.Errorf("aider env = %v, want %v", aider.Env, want)
+	}
+	if strings.Join(aider.Args, ",") != "--no-git" {
+		t.Errorf("aider args = %v, want imported args", aider.Args)
+	}
+	codex := local.AgentOverrides["codex"]
+	if strings.Join(codex.Env, ",") != "CODEX_TOKEN=sk-codex" {
+		t.Errorf("codex env = %v, want the local secret kept", codex.Env)
+	}
+	if strings.Join(codex.Defaults, ",") != "--slow" {
+		t.Errorf("codex defaults = %v, want imported defaults", codex.Defaults)
+	}
+
+	// An explicit value in the import wins over the local one.
+	withSecrets, _, err := exportConfig(src, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := importConfig(&local, withSecrets); err != nil {
+		t.Fatalf("importConfig: %v", err)
+	}
+	if got := local.Agents["aider"].Env[0]; got != "AIDER_KEY=sk-remote" {
+		t.Errorf("aider env[0] = %q, want imported value", got)
+	}
+}
+
+func TestImportConfigRejectsBadInput(t *testing.T) {
+	newer, _ := json.Marshal(map[string]any{"version": config.CurrentVersion + 1, "gitUserName": "x"})
+	for name, data := range map[string][]byte{
+		"invalid json": []byte("{"),
+		"newer":        newer,
+		"only local":   []byte(`{"containerImages": {"a": "b"}, "selectedAgent": "x"}`),
+		"wrong type":   []byte(`{"hostStartingPort": "3000"}`),
+		"bad memory":   []byte(`{"containerMemory": "lots"}`),
+		"bad cpus":     []byte(`{"containerCpus": "-1"}`),
+		"bad platform": []byte(`{"buildPlatform": "linux/sparc"}`),
+		"bad cache":    []byte(`{"buildCacheTo": "mode=max"}`),
+	} {
+		cfg := config.Default()
+		if err := importConfig(&cfg, data); err == nil {
+			t.Errorf("%s: importConfig succeeded", name)
+		}
+	}
+}
+
+func TestImportConfigMigratesOlderFiles(t *testing.T) {
+	data := []byte(`{"containerPort": 4200, "defaultContainerName": "old", "gitUserName": "Dev"}`)
+	cfg := config.Default()
+	cfg.SelectedAgent = "mine"
+	if err := importConfig(&cfg, data); err != nil {
+		t.Fatalf("importConfig: %v", err)
+	}
+	if cfg.ContainerPort != 3000 {
+		t.Errorf("ContainerPort = %d, want the legacy ember-cli port migrated", cfg.ContainerPort)
+	}
+	if cfg.SelectedAgent != "mine" || cfg.Version != config.CurrentVersion {
+		t.Errorf("SelectedAgent = %q, Version = %d", cfg.SelectedAgent, cfg.Version)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -23,76 +25,122 @@ var configMcpCmd = &cobra.Command{
 		"playwright",
 		"discourse",
 		"chrome-devtools",
+		"filesystem",
+		"fetch",
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		configDir, err := xdg.ConfigDir()
-		if err != nil {
-			return err
-		}
-		cfg, err := config.LoadOrCreate(configDir)
-		if err != nil {
+		ctx, ok, err := loadMCPCommandContext(cmd)
+		if err != nil || !ok {
 			return err
 		}
-
-		containerName, _ := cmd.Flags().GetString("name")
-		if containerName == "" {
-			containerName = currentAgentName(cfg)
-		}
-
-		if !docker.Exists(containerName) {
-			fmt.Fprintf(cmd.OutOrStdout(), "Container '%s' does not exist. Run 'dv start' first.\n", containerName)
-			return nil
-		}
-		if !docker.Running(containerName) {
-			fmt.Fprintf(cmd.OutOrStdout(), "Starting container '%s'...\n", containerName)
-			if err := startContainerWithPostStartHook(cmd, cfg, configDir, containerName, "config mcp"); err != nil {
-				return err
-			}
-		}
-
-		// Determine workdir from the associated image if known; fall back to selected image
-		imgName := cfg.ContainerImages[containerName]
-		var imgCfg config.ImageConfig
-		if imgName != "" {
-			imgCfg = cfg.Images[imgName]
-		} else {
-			_, imgCfg, err = resolveImage(cfg, "")
-			if err != nil {
-				return err
-			}
-		}
-		workdir := imgCfg.Workdir
+		containerName, workdir, envs := ctx.containerName, ctx.workdir, ctx.envs
 
 		mcpName := strings.ToLower(strings.TrimSpace(args[0]))
 
-		// Prepare env pass-through so tools like 'claude' have credentials
-		envs := collectEnvPassthrough(cfg)
 		if _, ok := os.LookupEnv("ANTHROPIC_API_KEY"); !ok {
 			fmt.Fprintln(cmd.ErrOrStderr(), "Warning: ANTHROPIC_API_KEY is not set on host; 'claude' may fail.")
 		}
 
 		switch mcpName {
 		case "playwright":
-			return configurePlaywrightMCP(cmd, containerName, workdir, envs)
+			err = configurePlaywrightMCP(cmd, containerName, workdir, envs)
 		case "discourse":
-			return configureDiscourseMCP(cmd, containerName, workdir, envs)
+			err = configureDiscourseMCP(cmd, containerName, workdir, envs)
 		case "chrome-devtools":
-			return configureChromeDevToolsMCP(cmd, containerName, workdir, envs)
+			err = configureChromeDevToolsMCP(cmd, containerName, workdir, envs)
+		case "filesystem":
+			root, _ := cmd.Flags().GetString("root")
+			if strings.TrimSpace(root) == "" {
+				root = workdir
+			}
+			err = configureFilesystemMCP(cmd, containerName, workdir, envs, root)
+		case "fetch":
+			err = configureFetchMCP(cmd, containerName, workdir, envs)
 		default:
-			return fmt.Errorf("unsupported MCP name: %s (supported: playwright, discourse, chrome-devtools)", mcpName)
+			return fmt.Errorf("unsupported MCP name: %s (supported: playwright, discourse, chrome-devtools, filesystem, fetch)", mcpName)
+		}
+		if err != nil {
+			return err
 		}
+		if verify, _ := cmd.Flags().GetBool("verify"); verify {
+			return verifyMCP(cmd, containerName, workdir, envs, mcpName)
+		}
+		return nil
 	},
 }
 
 func init() {
-	configMcpCmd.Flags().String("name", "", "Container name (defaults to selected or default)")
+	configMcpCmd.PersistentFlags().String("name", "", "Container name (defaults to selected or default)")
+	configMcpCmd.Flags().String("tools", "", "Comma-separated tools to configure: claude, codex, gemini (default all)")
+	configMcpCmd.Flags().Bool("verify", false, "After configuring, check that Claude can start and connect to the server")
+	configMcpCmd.Flags().String("root", "", "Directory the filesystem MCP may access (defaults to the workdir)")
 	configCmd.AddCommand(configMcpCmd)
 }
 
+// mcpCommandContext is the container the `dv config mcp` commands operate on.
+type mcpCommandContext struct {
+	cfg           config.Config
+	containerName string
+	workdir       string
+	envs          docker.Envs
+}
+
+// loadMCPCommandContext resolves the target container (starting it when
+// needed), its workdir, and the env pass-through so tools like 'claude' have
+// credentials. ok is false when there is nothing to operate on; a message has
+// already been printed.
+func loadMCPCommandContext(cmd *cobra.Command) (mcpCommandContext, bool, error) {
+	configDir, err := xdg.ConfigDir()
+	if err != nil {
+		return mcpCommandContext{}, false, err
+	}
+	cfg, err := config.LoadOrCreate(configDir)
+	if err != nil {
+		return mcpCommandContext{}, false, err
+	}
+
+	containerName, _ := cmd.Flags().GetString("name")
+	if containerName == "" {
+		containerName = currentAgentName(cfg)
+	}
+
+	if !docker.Exists(containerName) {
+		fmt.Fprintf(cmd.OutOrStdout(), "Container '%s' does not exist. Run 'dv start' first.\n", containerName)
+		return mcpCommandContext{}, false, nil
+	}
+	if !docker.Running(containerName) {
+		fmt.Fprintf(cmd.OutOrStdout(), "Starting container '%s'...\n", containerName)
+		if err := startContainerWithPostStartHook(cmd, cfg, configDir, containerName, "config mcp"); err != nil {
+			return mcpCommandContext{}, false, err
+		}
+	}
+
+	// Determine workdir from the associated image if known; fall back to selected image
+	imgName := cfg.ContainerImages[containerName]
+	var imgCfg config.ImageConfig
+	if imgName != "" {
+		imgCfg = cfg.Images[imgName]
+	} else {
+		_, imgCfg, err = resolveImage(cfg, "")
+		if err != nil {
+			return mcpCommandContext{}, false, err
+		}
+	}
+
+	return mcpCommandContext{
+		cfg:           cfg,
+		containerName: containerName,
+		workdir:       imgCfg.Workdir,
+		envs:          collectEnvPassthrough(cfg),
+	}, true, nil
+}
+
 // addOrReplaceTomlSection inserts or replaces a TOML table section defined by sectionHeader
 // (e.g., "mcp_servers.playwright"). The sectionBody should include the full header line and
-// any key/value lines below it, and may end with a trailing newline.
+// any key/value lines below it, and may end with a trailing newline. An empty sectionBody
+// deletes the section; see removeTomlSection.
 func addOrReplaceTomlSection(existing string, sectionHeader string, sectionBody string) string {
+	remove := strings.TrimSpace(sectionBody) == ""
 	// Normalize endings
 	existing = strings.ReplaceAll(existing, "\r\n", "\n")
 	lines := []string{}
@@ -111,6 +159,9 @@ func addOrReplaceTomlSection(existing string, sectionHeader string, sectionBody
 	}
 
 	if start == -1 {
+		if remove {
+			return existing
+		}
 		// Append section to the end
 		var b strings.Builder
 		if strings.TrimSpace(existing) != "" {
@@ -122,10 +173,12 @@ func addOrReplaceTomlSection(existing string, sectionHeader string, sectionBody
 		return b.String()
 	}
 
-	// Determine end of existing section (next header or EOF)
+	// Determine end of existing section (next header that is not one of its
+	// nested tables, such as [mcp_servers.x.env], or EOF)
 	end := len(lines)
 	for j := start + 1; j < len(lines); j++ {
-		if strings.HasPrefix(strings.TrimSpace(lines[j]), "[") {
+		name, ok := tomlTableName(lines[j])
+		if ok && !strings.HasPrefix(name, sectionHeader+".") {
 			end = j
 			break
 		}
@@ -133,9 +186,16 @@ func addOrReplaceTomlSection(existing string, sectionHeader string, sectionBody
 	// Rebuild with replacement
 	var out []string
 	out = append(out, lines[:start]...)
-	// Add new body (without trailing newline to manage joins consistently)
-	for _, l := range strings.Split(strings.TrimRight(sectionBody, "\n"), "\n") {
-		out = append(out, l)
+	if remove {
+		// Drop the blank lines that separated the removed section
+		for len(out) > 0 && strings.TrimSpace(out[len(out)-1]) == "" {
+			out = out[:len(out)-1]
+		}
+	} else {
+		// Add new body (without trailing newline to manage joins consistently)
+		for _, l := range strings.Split(strings.TrimRight(sectionBody, "\n"), "\n") {
+			out = append(out, l)
+		}
 	}
 	if end < len(lines) {
 		// Ensure a blank line between sections if not already present
@@ -145,7 +205,34 @@ func addOrReplaceTomlSection(existing string, sectionHeader string, sectionBody
 		out = append(out, lines[end:]...)
 	}
 	// Ensure trailing newline
-	return strings.TrimRight(strings.Join(out, "\n"), "\n") + "\n"
+	joined := strings.TrimRight(strings.Join(out, "\n"), "\n")
+	if joined == "" {
+		return ""
+	}
+	return joined + "\n"
+}
+
+// tomlTableName returns the dotted name of a [table] or [[array]] header
+// line, with whitespace around the brackets and any trailing comment ignored.
+func tomlTableName(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "[") {
+		return "", false
+	}
+	open, closing := "[", "]"
+	if strings.HasPrefix(line, "[[") {
+		open, closing = "[[", "]]"
+	}
+	end := strings.Index(line, closing)
+	if end < 0 {
+		return "", false
+	}
+	return strings.TrimSpace(line[len(open):end]), true
+}
+
+// removeTomlSection deletes the TOML table sectionHeader, if present.
+func removeTomlSection(existing string, sectionHeader string) string {
+	return addOrReplaceTomlSection(existing, sectionHeader, "")
 }
 
 func configurePlaywrightMCP(cmd *cobra.Command, containerName, workdir string, envs docker.Envs) error {
@@ -172,15 +259,40 @@ func configureChromeDevToolsMCP(cmd *cobra.Command, containerName, workdir strin
 	return configureMCP(cmd, containerName, workdir, envs, mcpConfig)
 }
 
+func configureFilesystemMCP(cmd *cobra.Command, containerName, workdir string, envs docker.Envs, root string) error {
+	mcpConfig := mcpConfiguration{
+		name:            "filesystem",
+		registrationCmd: "claude mcp add -s user filesystem -- npx -y @modelcontextprotocol/server-filesystem " + shellQuote(root),
+		codexCommand:    "npx",
+		codexArgs:       []string{"-y", "@modelcontextprotocol/server-filesystem", root},
+		geminiCommand:   "npx",
+		geminiArgs:      []string{"-y", "@modelcontextprotocol/server-filesystem", root},
+	}
+	return configureMCP(cmd, containerName, workdir, envs, mcpConfig)
+}
+
+// configureFetchMCP registers the reference fetch server, which is published
+// for Python only, so it runs through uvx. Without uvx in the container the
+// server could never start, so that is an error rather than a registration.
+func configureFetchMCP(cmd *cobra.Command, containerName, workdir string, envs docker.Envs) error {
+	if _, err := docker.ExecOutput(containerName, "/", nil, []string{"bash", "-lc", withUserPaths("command -v uvx")}); err != nil {
+		return fmt.Errorf("fetch MCP runs through uvx, which is not installed in '%s'; install uv in the container first (https://docs.astral.sh/uv/)", containerName)
+	}
+	mcpConfig := mcpConfiguration{
+		name:            "fetch",
+		registrationCmd: "claude mcp add -s user fetch -- uvx mcp-server-fetch",
+		codexCommand:    "uvx",
+		codexArgs:       []string{"mcp-server-fetch"},
+		geminiCommand:   "uvx",
+		geminiArgs:      []string{"mcp-server-fetch"},
+	}
+	return configureMCP(cmd, containerName, workdir, envs, mcpConfig)
+}
+
 func configureDiscourseMCP(cmd *cobra.Command, containerName, workdir string, envs docker.Envs) error {
-	// Dynamically determine the home directory for the discourse user
-	homeDirRaw, err := docker.ExecOutput(containerName, "/", nil, []string{"bash", "-lc", "echo $HOME"})
+	homeDir, err := mcpHomeDir(containerName)
 	if err != nil {
-		return fmt.Errorf("failed to determine home directory in container: %w", err)
-	}
-	homeDir := strings.TrimSpace(homeDirRaw)
-	if homeDir == "" {
-		homeDir = "/home/discourse" // fallback
+		return err
 	}
 
 	profilePath := filepath.Join(homeDir, ".config/discourse-mcp/local.json")
@@ -300,21 +412,117 @@ type mcpConfiguration struct {
 	env             map[string]string // Environment variables for the MCP server
 }
 
-// configureMCP registers an MCP server with Claude, Codex, and Gemini
+// verifyMCP asks Claude to start the server and reports whether it connected,
+// so profile or API key problems surface at setup time.
+func verifyMCP(cmd *cobra.Command, containerName, workdir string, envs docker.Envs, name string) error {
+	if tools, err := mcpToolsFromFlags(cmd); err == nil && !tools["claude"] {
+		fmt.Fprintln(cmd.ErrOrStderr(), "Skipping --verify: it checks the server through Claude, which was not selected with --tools.")
+		return nil
+	}
+	getCmd := "claude mcp get " + shellQuote(name)
+	fmt.Fprintf(cmd.OutOrStdout(), "\nVerifying MCP '%s'...\nRunning: %s\n", name, getCmd)
+	out, err := docker.ExecCombinedOutput(containerName, workdir, envs, []string{"bash", "-lc", withUserPaths(getCmd)})
+	connected, status := claudeMCPStatus(out)
+	if status == "" {
+		if err != nil {
+			return fmt.Errorf("could not verify MCP '%s': %w\n%s", name, err, strings.TrimSpace(out))
+		}
+		return fmt.Errorf("could not verify MCP '%s': no status in 'claude mcp get' output\n%s", name, strings.TrimSpace(out))
+	}
+	if !connected {
+		return fmt.Errorf("MCP '%s' failed verification: %s", name, status)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "MCP '%s' verified: %s\n", name, status)
+	return nil
+}
+
+// claudeMCPStatus reads the "Status:" line of `claude mcp get`, e.g.
+// "Status: ✓ Connected" or "Status: ✗ Failed to connect".
+func claudeMCPStatus(out string) (bool, string) {
+	for _, line := range strings.Split(out, "\n") {
+		status, ok := strings.CutPrefix(strings.TrimSpace(line), "Status:")
+		if !ok {
+			continue
+		}
+		status = strings.TrimSpace(status)
+		return strings.Contains(strings.ToLower(status), "connected") && !strings.Contains(strings.ToLower(status), "fail"), status
+	}
+	return false, ""
+}
+
+// customMCPConfiguration builds the configuration for an arbitrary MCP server
+// started as command args..., registered under the same name with every tool.
+func customMCPConfiguration(name, command string, args []string, env map[string]string) mcpConfiguration {
+	// Claude's -e takes several values, so it goes after the server name and
+	// the "--" ends it before the server command.
+	registration := []string{name}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		registration = append(registration, "-e", k+"="+env[k])
+	}
+	registration = append(registration, "--", command)
+	registration = append(registration, args...)
+	return mcpConfiguration{
+		name:            name,
+		registrationCmd: "claude mcp add -s user " + shellJoin(registration),
+		codexCommand:    command,
+		codexArgs:       args,
+		geminiCommand:   command,
+		geminiArgs:      args,
+		env:             env,
+	}
+}
+
+// configureMCP registers an MCP server with Claude, Codex, and Gemini. A
+// --tools flag on cmd narrows that list, and tools whose binary is missing
+// from the container are skipped with a warning.
 func configureMCP(cmd *cobra.Command, containerName, workdir string, envs docker.Envs, mcpConfig mcpConfiguration) error {
-	// Dynamically determine the home directory for the discourse user
-	homeDirRaw, err := docker.ExecOutput(containerName, "/", nil, []string{"bash", "-lc", "echo $HOME"})
+	tools, err := mcpToolsFromFlags(cmd)
 	if err != nil {
-		return fmt.Errorf("failed to determine home directory in container: %w", err)
+		return err
 	}
-	homeDir := strings.TrimSpace(homeDirRaw)
-	if homeDir == "" {
-		homeDir = "/home/discourse" // fallback
+	tools = installedMCPTools(cmd, containerName, tools)
+	if len(tools) == 0 {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: none of the selected tools are installed in '%s'; MCP '%s' was not configured.\n", containerName, mcpConfig.name)
+		return nil
+	}
+
+	homeDir, err := mcpHomeDir(containerName)
+	if err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "mcp-config-*")
+	if err != nil {
+		return err
 	}
+	defer os.RemoveAll(tmpDir)
 
-	codexConfigPath := filepath.Join(homeDir, ".codex/config.toml")
-	geminiConfigPath := filepath.Join(homeDir, ".gemini/settings.json")
+	if tools["claude"] {
+		if err := configureClaudeMCP(cmd, containerName, workdir, envs, mcpConfig); err != nil {
+			return err
+		}
+	}
+	if tools["codex"] {
+		if err := configureCodexMCP(cmd, containerName, workdir, filepath.Join(homeDir, ".codex/config.toml"), tmpDir, mcpConfig); err != nil {
+			return err
+		}
+	}
+	if tools["gemini"] {
+		if err := configureGeminiMCP(cmd, containerName, workdir, filepath.Join(homeDir, ".gemini/settings.json"), tmpDir, mcpConfig); err != nil {
+			return err
+		}
+	}
 
+	fmt.Fprintf(cmd.OutOrStdout(), "%s MCP configuration complete.\n", strings.Title(mcpConfig.name))
+	return nil
+}
+
+func configureClaudeMCP(cmd *cobra.Command, containerName, workdir string, envs docker.Envs, mcpConfig mcpConfiguration) error {
 	// Remove existing Claude MCP entry
 	removeEchoCmd := fmt.Sprintf("claude mcp remove -s user %s", mcpConfig.name)
 	removeCmd := removeEchoCmd + " || true"
@@ -329,17 +537,10 @@ func configureMCP(cmd *cobra.Command, containerName, workdir string, envs docker
 	fmt.Fprintf(cmd.OutOrStdout(), "Registering MCP '%s' with Claude inside container '%s'...\n", mcpConfig.name, containerName)
 	fmt.Fprintf(cmd.OutOrStdout(), "Running: %s\n\n", mcpConfig.registrationCmd)
 
-	if err := docker.ExecInteractive(containerName, workdir, envs, []string{"bash", "-lc", mcpConfig.registrationCmd}); err != nil {
-		return err
-	}
-
-	tmpDir, err := os.MkdirTemp("", "mcp-config-*")
-	if err != nil {
-		return err
-	}
-	defer os.RemoveAll(tmpDir)
+	return docker.ExecInteractive(containerName, workdir, envs, []string{"bash", "-lc", mcpConfig.registrationCmd})
+}
 
-	// Configure Codex
+func configureCodexMCP(cmd *cobra.Command, containerName, workdir, codexConfigPath, tmpDir string, mcpConfig mcpConfiguration) error {
 	fmt.Fprintf(cmd.OutOrStdout(), "\nConfiguring Codex to use the %s MCP (updating ~/.codex/config.toml)...\n", mcpConfig.name)
 
 	_, _ = docker.ExecOutput(containerName, workdir, nil, []string{"bash", "-lc", "mkdir -p ~/.codex"})
@@ -360,16 +561,10 @@ func configureMCP(cmd *cobra.Command, containerName, workdir string, envs docker
 	}
 
 	codexSectionHeader := fmt.Sprintf("mcp_servers.%s", mcpConfig.name)
-	argsJSON, err := json.Marshal(mcpConfig.codexArgs)
+	codexSectionBody, err := codexMCPSection(mcpConfig)
 	if err != nil {
-		return fmt.Errorf("failed to marshal codex args: %w", err)
+		return err
 	}
-	codexSectionBody := strings.Join([]string{
-		"[" + codexSectionHeader + "]",
-		fmt.Sprintf("command = %q", mcpConfig.codexCommand),
-		fmt.Sprintf("args = %s", string(argsJSON)),
-		"",
-	}, "\n")
 
 	codexUpdated := addOrReplaceTomlSection(codexContent, codexSectionHeader, codexSectionBody)
 	if err := os.WriteFile(hostCodexCfg, []byte(codexUpdated), 0o644); err != nil {
@@ -379,12 +574,14 @@ func configureMCP(cmd *cobra.Command, containerName, workdir string, envs docker
 	if err := docker.CopyToContainerWithOwnership(containerName, hostCodexCfg, codexConfigPath, false); err != nil {
 		return fmt.Errorf("failed to copy Codex config into container: %w", err)
 	}
+	return nil
+}
 
-	// Configure Gemini
+func configureGeminiMCP(cmd *cobra.Command, containerName, workdir, geminiConfigPath, tmpDir string, mcpConfig mcpConfiguration) error {
 	fmt.Fprintf(cmd.OutOrStdout(), "\nConfiguring Gemini CLI to use the %s MCP (updating ~/.gemini/settings.json)...\n", mcpConfig.name)
 
 	_, _ = docker.ExecOutput(containerName, workdir, nil, []string{"bash", "-lc", "mkdir -p ~/.gemini"})
-	existsOut, _ = docker.ExecOutput(containerName, workdir, nil, []string{"bash", "-lc", "test -f " + geminiConfigPath + " && echo EXISTS || echo MISSING"})
+	existsOut, _ := docker.ExecOutput(containerName, workdir, nil, []string{"bash", "-lc", "test -f " + geminiConfigPath + " && echo EXISTS || echo MISSING"})
 	hasGeminiConfig := strings.Contains(existsOut, "EXISTS")
 
 	hostGeminiCfg := filepath.Join(tmpDir, "gemini-settings.json")
@@ -436,11 +633,134 @@ func configureMCP(cmd *cobra.Command, containerName, workdir string, envs docker
 	if err := docker.CopyToContainerWithOwnership(containerName, hostGeminiCfg, geminiConfigPath, false); err != nil {
 		return fmt.Errorf("failed to copy Gemini settings into container: %w", err)
 	}
-
-	fmt.Fprintf(cmd.OutOrStdout(), "%s MCP configuration complete.\n", strings.Title(mcpConfig.name))
 	return nil
 }
 
+// mcpToolsFromFlags returns the tools named by cmd's --tools flag, or all of
+// them when the flag is unset or cmd does not have it (e.g. templates).
+func mcpToolsFromFlags(cmd *cobra.Command) (map[string]bool, error) {
+	value := ""
+	if f := cmd.Flags().Lookup("tools"); f != nil {
+		value = f.Value.String()
+	}
+	return parseMCPTools(value)
+}
+
+// parseMCPTools parses a comma-separated --tools value.
+func parseMCPTools(value string) (map[string]bool, error) {
+	tools := map[string]bool{}
+	for _, t := range strings.Split(value, ",") {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" {
+			continue
+		}
+		if !slices.Contains(mcpTools, t) {
+			return nil, fmt.Errorf("unknown tool %q in --tools (supported: %s)", t, strings.Join(mcpTools, ", "))
+		}
+		tools[t] = true
+	}
+	if len(tools) == 0 {
+		for _, t := range mcpTools {
+			tools[t] = true
+		}
+	}
+	return tools, nil
+}
+
+// installedMCPTools drops tools whose binary is not on the discourse user's
+// PATH in the container, warning about each one.
+func installedMCPTools(cmd *cobra.Command, containerName string, tools map[string]bool) map[string]bool {
+	installed := map[string]bool{}
+	for _, t := range mcpTools {
+		if !tools[t] {
+			continue
+		}
+		if _, err := docker.ExecOutput(containerName, "/", nil, []string{"bash", "-lc", withUserPaths("command -v " + t)}); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: '%s' is not installed in '%s'; skipping its MCP configuration.\n", t, containerName)
+			continue
+		}
+		installed[t] = true
+	}
+	return installed
+}
+
+// mcpHomeDir returns the discourse user's home directory in the container,
+// where the tools keep their MCP configuration.
+func mcpHomeDir(containerName string) (string, error) {
+	homeDirRaw, err := docker.ExecOutput(containerName, "/", nil, []string{"bash", "-lc", "echo $HOME"})
+	if err != nil {
+		return "", fmt.Errorf("failed to determine home directory in container: %w", err)
+	}
+	homeDir := strings.TrimSpace(homeDirRaw)
+	if homeDir == "" {
+		homeDir = "/home/discourse" // fallback
+	}
+	return homeDir, nil
+}
+
+// codexMCPSection renders the [mcp_servers.<name>] table for Codex, followed by
+// a nested [mcp_servers.<name>.env] table when the server has env vars.
+func codexMCPSection(mcpConfig mcpConfiguration) (string, error) {
+	header := fmt.Sprintf("mcp_servers.%s", mcpConfig.name)
+	argsJSON, err := json.Marshal(mcpConfig.codexArgs)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal codex args: %w", err)
+	}
+	lines := []string{
+		"[" + header + "]",
+		"command = " + tomlString(mcpConfig.codexCommand),
+		fmt.Sprintf("args = %s", string(argsJSON)),
+	}
+	if len(mcpConfig.env) > 0 {
+		keys := make([]string, 0, len(mcpConfig.env))
+		for k := range mcpConfig.env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		lines = append(lines, "", "["+header+".env]")
+		for _, k := range keys {
+			lines = append(lines, tomlKey(k)+" = "+tomlString(mcpConfig.env[k]))
+		}
+	}
+	return strings.Join(append(lines, ""), "\n"), nil
+}
+
+// tomlKey returns k as a bare TOML key when possible, quoted otherwise.
+func tomlKey(k string) string {
+	if k != "" && mcpNamePattern.MatchString(k) {
+		return k
+	}
+	return tomlString(k)
+}
+
+// tomlString quotes s as a TOML basic string.
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
 func extractAuthPairs(profile map[string]any) []map[string]any {
 	raw, ok := profile["auth_pairs"]
 	if !ok {
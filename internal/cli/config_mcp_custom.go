@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// mcpNamePattern keeps custom server names usable as a bare TOML key in
+// Codex's [mcp_servers.<name>] table and as a Claude/Gemini server name.
+var mcpNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+var configMcpCustomCmd = &cobra.Command{
+	Use:   "custom NAME --command CMD [--arg ARG...] [--env KEY=VALUE...]",
+	Short: "Register an arbitrary MCP server with Claude, Codex and Gemini in the container",
+	Long: `Registers the MCP server started by --command (plus any --arg values) under NAME,
+the same way templates register custom "mcp:" entries. --env values are passed to the
+server process. The container is still selected with --name.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mcpName := strings.TrimSpace(args[0])
+		if !mcpNamePattern.MatchString(mcpName) {
+			return fmt.Errorf("invalid MCP name %q (use letters, digits, '-' and '_')", mcpName)
+		}
+		command, _ := cmd.Flags().GetString("command")
+		command = strings.TrimSpace(command)
+		if command == "" {
+			return fmt.Errorf("--command is required")
+		}
+		mcpArgs, _ := cmd.Flags().GetStringArray("arg")
+		envPairs, _ := cmd.Flags().GetStringArray("env")
+		env, err := parseMCPEnv(envPairs)
+		if err != nil {
+			return err
+		}
+
+		ctx, ok, err := loadMCPCommandContext(cmd)
+		if err != nil || !ok {
+			return err
+		}
+		if err := configureMCP(cmd, ctx.containerName, ctx.workdir, ctx.envs, customMCPConfiguration(mcpName, command, mcpArgs, env)); err != nil {
+			return err
+		}
+		if verify, _ := cmd.Flags().GetBool("verify"); verify {
+			return verifyMCP(cmd, ctx.containerName, ctx.workdir, ctx.envs, mcpName)
+		}
+		return nil
+	},
+}
+
+func init() {
+	configMcpCustomCmd.Flags().String("command", "", "Executable that starts the MCP server inside the container")
+	configMcpCustomCmd.Flags().StringArray("arg", nil, "Argument for the server command (repeatable, kept in order)")
+	configMcpCustomCmd.Flags().StringArray("env", nil, "Environment variable for the server as KEY=VALUE (repeatable)")
+	configMcpCustomCmd.Flags().String("tools", "", "Comma-separated tools to configure: claude, codex, gemini (default all)")
+	configMcpCustomCmd.Flags().Bool("verify", false, "After configuring, check that Claude can start and connect to the server")
+	configMcpCmd.AddCommand(configMcpCustomCmd)
+}
+
+// parseMCPEnv turns --env KEY=VALUE flags into the server env map.
+func parseMCPEnv(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	env := make(map[string]string, len(pairs))
+	for _, kv := range pairs {
+		key, value, ok := strings.Cut(kv, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --env %q (expected KEY=VALUE)", kv)
+		}
+		env[key] = value
+	}
+	return env, nil
+}
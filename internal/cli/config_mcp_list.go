@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"dv/internal/docker"
+)
+
+// mcpTools are the tools `dv config mcp` writes configuration for, in the
+// order they are reported.
+var mcpTools = []string{"claude", "codex", "gemini"}
+
+var configMcpListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the MCP servers registered with Claude, Codex and Gemini in the container",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, ok, err := loadMCPCommandContext(cmd)
+		if err != nil || !ok {
+			return err
+		}
+
+		registered := map[string]map[string]bool{}
+		add := func(tool string, names []string) {
+			for _, n := range names {
+				if registered[n] == nil {
+					registered[n] = map[string]bool{}
+				}
+				registered[n][tool] = true
+			}
+		}
+
+		claudeOut, err := docker.ExecOutput(ctx.containerName, ctx.workdir, ctx.envs, []string{"bash", "-lc", withUserPaths("if command -v claude >/dev/null 2>&1; then claude mcp list 2>/dev/null; else echo " + mcpToolMissing + "; fi")})
+		if err != nil || strings.TrimSpace(claudeOut) == mcpToolMissing {
+			fmt.Fprintln(cmd.ErrOrStderr(), "Note: could not run 'claude mcp list' in the container; Claude servers are not shown.")
+		} else {
+			add("claude", parseClaudeMCPList(claudeOut))
+		}
+
+		codexOut, _ := docker.ExecOutput(ctx.containerName, "/", nil, []string{"bash", "-lc", "cat ~/.codex/config.toml 2>/dev/null || true"})
+		add("codex", parseCodexMCPServers(codexOut))
+
+		geminiOut, _ := docker.ExecOutput(ctx.containerName, "/", nil, []string{"bash", "-lc", "cat ~/.gemini/settings.json 2>/dev/null || true"})
+		geminiNames, err := parseGeminiMCPServers(geminiOut)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: could not parse ~/.gemini/settings.json: %v\n", err)
+		}
+		add("gemini", geminiNames)
+
+		if len(registered) == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "No MCP servers configured in '%s'. Run 'dv config mcp NAME' to add one.\n", ctx.containerName)
+			return nil
+		}
+		fmt.Fprint(cmd.OutOrStdout(), formatMCPTable(registered))
+		return nil
+	},
+}
+
+func init() {
+	configMcpCmd.AddCommand(configMcpListCmd)
+}
+
+// mcpToolMissing is echoed instead of tool output when its binary is absent.
+const mcpToolMissing = "__dv_tool_missing__"
+
+// parseClaudeMCPList extracts server names from `claude mcp list`, whose
+// entries look like "name: npx -y pkg - ✓ Connected".
+func parseClaudeMCPList(out string) []string {
+	var names []string
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		name, rest, ok := strings.Cut(line, ": ")
+		if !ok || name == "" || strings.ContainsAny(name, " \t") || !strings.Contains(rest, " - ") {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// parseCodexMCPServers returns the names of [mcp_servers.<name>] tables,
+// ignoring nested tables such as [mcp_servers.<name>.env].
+func parseCodexMCPServers(toml string) []string {
+	var names []string
+	for _, line := range strings.Split(toml, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "[mcp_servers.") || !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+			continue
+		}
+		key := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "[mcp_servers."), "]"))
+		if strings.HasPrefix(key, `"`) {
+			end := strings.Index(key[1:], `"`)
+			if end < 0 || strings.TrimSpace(key[end+2:]) != "" {
+				continue
+			}
+			key = key[1 : end+1]
+		} else if strings.Contains(key, ".") {
+			continue
+		}
+		if key != "" {
+			names = append(names, key)
+		}
+	}
+	return names
+}
+
+// parseGeminiMCPServers returns the keys of mcpServers in Gemini's settings.
+func parseGeminiMCPServers(settings string) ([]string, error) {
+	if strings.TrimSpace(settings) == "" {
+		return nil, nil
+	}
+	var parsed struct {
+		MCPServers map[string]json.RawMessage `json:"mcpServers"`
+	}
+	if err := json.Unmarshal([]byte(settings), &parsed); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(parsed.MCPServers))
+	for name := range parsed.MCPServers {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// formatMCPTable renders server name -> tools as a table sorted by name.
+func formatMCPTable(registered map[string]map[string]bool) string {
+	names := make([]string, 0, len(registered))
+	width := len("NAME")
+	for name := range registered {
+		names = append(names, name)
+		width = max(width, len(name))
+	}
+	sort.Strings(names)
+
+	row := func(first string, cells []string) string {
+		line := fmt.Sprintf("%-*s", width, first)
+		for _, c := range cells {
+			line += fmt.Sprintf("  %-6s", c)
+		}
+		return strings.TrimRight(line, " ") + "\n"
+	}
+
+	header := make([]string, len(mcpTools))
+	for i, tool := range mcpTools {
+		header[i] = strings.ToUpper(tool)
+	}
+	var b strings.Builder
+	b.WriteString(row("NAME", header))
+	for _, name := range names {
+		cells := make([]string, len(mcpTools))
+		for i, tool := range mcpTools {
+			cells[i] = "-"
+			if registered[name][tool] {
+				cells[i] = "yes"
+			}
+		}
+		b.WriteString(row(name, cells))
+	}
+	return b.String()
+}
@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"dv/internal/docker"
+)
+
+var configMcpRemoveCmd = &cobra.Command{
+	Use:   "remove NAME",
+	Short: "Remove an MCP server from Claude, Codex and Gemini in the container",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, ok, err := loadMCPCommandContext(cmd)
+		if err != nil || !ok {
+			return err
+		}
+		return removeMCP(cmd, ctx.containerName, ctx.workdir, ctx.envs, strings.TrimSpace(args[0]))
+	},
+}
+
+func init() {
+	configMcpRemoveCmd.Flags().String("tools", "", "Comma-separated tools to remove the server from: claude, codex, gemini (default all)")
+	configMcpCmd.AddCommand(configMcpRemoveCmd)
+}
+
+// removeMCP undoes configureMCP: it unregisters name from Claude and deletes
+// its entries from the Codex and Gemini configs, limited to the tools chosen
+// with --tools. Tools that never had the server are left untouched.
+func removeMCP(cmd *cobra.Command, containerName, workdir string, envs docker.Envs, name string) error {
+	if name == "" {
+		return fmt.Errorf("MCP name is required")
+	}
+	tools, err := mcpToolsFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	homeDir, err := mcpHomeDir(containerName)
+	if err != nil {
+		return err
+	}
+
+	if tools["claude"] {
+		removeCmd := fmt.Sprintf("claude mcp remove -s user %s", shellQuote(name))
+		fmt.Fprintf(cmd.OutOrStdout(), "Removing Claude MCP '%s' (safe to ignore failures)...\n", name)
+		fmt.Fprintf(cmd.OutOrStdout(), "Running: %s\n\n", removeCmd)
+		_ = docker.ExecInteractive(containerName, workdir, envs, []string{"bash", "-lc", removeCmd + " || true"})
+	}
+
+	if tools["codex"] {
+		if err := removeCodexMCP(cmd, containerName, filepath.Join(homeDir, ".codex/config.toml"), name); err != nil {
+			return err
+		}
+	}
+	if tools["gemini"] {
+		if err := removeGeminiMCP(cmd, containerName, filepath.Join(homeDir, ".gemini/settings.json"), name); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "%s MCP removed.\n", name)
+	return nil
+}
+
+// removeCodexMCP deletes the [mcp_servers.<name>] table from the Codex config.
+func removeCodexMCP(cmd *cobra.Command, containerName, codexConfigPath, name string) error {
+	codexContent, err := readMCPConfigFile(containerName, codexConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read Codex config: %w", err)
+	}
+	if updated := removeTomlSection(codexContent, "mcp_servers."+name); updated != codexContent {
+		if err := writeMCPConfigFile(containerName, codexConfigPath, []byte(updated)); err != nil {
+			return fmt.Errorf("failed to update Codex config: %w", err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Removed '%s' from ~/.codex/config.toml.\n", name)
+	}
+	return nil
+}
+
+// removeGeminiMCP deletes mcpServers[name] from the Gemini settings.
+func removeGeminiMCP(cmd *cobra.Command, containerName, geminiConfigPath, name string) error {
+	geminiContent, err := readMCPConfigFile(containerName, geminiConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read Gemini settings: %w", err)
+	}
+	geminiUpdated, removed, err := removeGeminiMCPServer([]byte(geminiContent), name)
+	if err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: existing Gemini settings are invalid JSON, leaving them unchanged: %v\n", err)
+	} else if removed {
+		if err := writeMCPConfigFile(containerName, geminiConfigPath, geminiUpdated); err != nil {
+			return fmt.Errorf("failed to update Gemini settings: %w", err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Removed '%s' from ~/.gemini/settings.json.\n", name)
+	}
+	return nil
+}
+
+// removeGeminiMCPServer deletes mcpServers[name] from Gemini settings JSON,
+// reporting whether it was present.
+func removeGeminiMCPServer(settings []byte, name string) ([]byte, bool, error) {
+	if len(strings.TrimSpace(string(settings))) == 0 {
+		return settings, false, nil
+	}
+	parsed := map[string]any{}
+	if err := json.Unmarshal(settings, &parsed); err != nil {
+		return nil, false, err
+	}
+	servers, _ := parsed["mcpServers"].(map[string]any)
+	if _, ok := servers[name]; !ok {
+		return settings, false, nil
+	}
+	delete(servers, name)
+	updated, err := json.MarshalIndent(parsed, "", "  ")
+	if err != nil {
+		return nil, false, err
+	}
+	return updated, true, nil
+}
+
+// readMCPConfigFile returns the contents of a tool config file in the
+// container, or "" when it does not exist.
+func readMCPConfigFile(containerName, path string) (string, error) {
+	script := fmt.Sprintf("if [ -f %[1]s ]; then cat %[1]s; fi", shellQuote(path))
+	return docker.ExecOutput(containerName, "/", nil, []string{"bash", "-c", script})
+}
+
+// writeMCPConfigFile replaces a tool config file in the container, keeping it
+// owned by the discourse user.
+func writeMCPConfigFile(containerName, path string, data []byte) error {
+	tmpDir, err := os.MkdirTemp("", "mcp-config-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	hostPath := filepath.Join(tmpDir, filepath.Base(path))
+	if err := os.WriteFile(hostPath, data, 0o644); err != nil {
+		return err
+	}
+	return docker.CopyToContainerWithOwnership(containerName, hostPath, path, false)
+}
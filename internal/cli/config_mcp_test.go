@@ -0,0 +1,285 @@
+package cli
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseClaudeMCPList(t *testing.T) {
+	out := "Checking MCP server health...\n\n" +
+		"playwright: npx -y @playwright/mcp@latest --isolated - ✓ Connected\n" +
+		"discourse: npx -y @discourse/mcp@latest --profile /home/discourse/.config/discourse-mcp/local.json - ✗ Failed to connect\n" +
+		"remote: https://example.com/mcp (HTTP) - ✓ Connected\n"
+	got := parseClaudeMCPList(out)
+	want := []string{"playwright", "discourse", "remote"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseClaudeMCPList() = %#v, want %#v", got, want)
+	}
+	if got := parseClaudeMCPList("No MCP servers configured. Use `claude mcp add` to add a server.\n"); len(got) != 0 {
+		t.Fatalf("parseClaudeMCPList(empty) = %#v", got)
+	}
+}
+
+func TestParseCodexMCPServers(t *testing.T) {
+	toml := `model = "gpt-5"
+
+[mcp_servers.playwright]
+command = "npx"
+args = ["-y", "@playwright/mcp@latest"]
+
+[mcp_servers.custom.env]
+TOKEN = "x"
+
+[mcp_servers."my.server"]
+command = "x"
+
+[profiles.fast]
+model = "o4-mini"
+`
+	got := parseCodexMCPServers(toml)
+	want := []string{"playwright", "my.server"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseCodexMCPServers() = %#v, want %#v", got, want)
+	}
+}
+
+func TestParseGeminiMCPServers(t *testing.T) {
+	got, err := parseGeminiMCPServers(`{"theme":"x","mcpServers":{"playwright":{"command":"npx"},"discourse":{}}}`)
+	if err != nil {
+		t.Fatalf("parseGeminiMCPServers() error = %v", err)
+	}
+	sort.Strings(got)
+	if want := []string{"discourse", "playwright"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseGeminiMCPServers() = %#v, want %#v", got, want)
+	}
+	if _, err := parseGeminiMCPServers("{not json"); err == nil {
+		t.Fatal("expected error for invalid JSON")
+	}
+}
+
+func TestFormatMCPTable(t *testing.T) {
+	got := formatMCPTable(map[string]map[string]bool{
+		"playwright": {"claude": true, "codex": true, "gemini": true},
+		"discourse":  {"codex": true},
+	})
+	want := "NAME        CLAUDE  CODEX   GEMINI\n" +
+		"discourse   -       yes     -\n" +
+		"playwright  yes     yes     yes\n"
+	if got != want {
+		t.Fatalf("formatMCPTable() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRemoveTomlSection(t *testing.T) {
+	existing := `model = "gpt-5"
+
+[mcp_servers.playwright]
+command = "npx"
+args = ["-y", "@playwright/mcp@latest"]
+
+[mcp_servers.discourse]
+command = "npx"
+`
+	got := removeTomlSection(existing, "mcp_servers.playwright")
+	want := `model = "gpt-5"
+
+[mcp_servers.discourse]
+command = "npx"
+`
+	if got != want {
+		t.Fatalf("removeTomlSection(middle) =\n%s\nwant\n%s", got, want)
+	}
+
+	got = removeTomlSection(want, "mcp_servers.discourse")
+	if got != "model = \"gpt-5\"\n" {
+		t.Fatalf("removeTomlSection(last) = %q", got)
+	}
+
+	if got := removeTomlSection(existing, "mcp_servers.missing"); got != existing {
+		t.Fatalf("removeTomlSection(missing) changed the file:\n%s", got)
+	}
+	if got := removeTomlSection("[mcp_servers.only]\ncommand = \"x\"\n", "mcp_servers.only"); got != "" {
+		t.Fatalf("removeTomlSection(only) = %q, want empty", got)
+	}
+}
+
+func TestRemoveGeminiMCPServer(t *testing.T) {
+	settings := []byte(`{"theme":"Default","mcpServers":{"playwright":{"command":"npx"},"discourse":{"command":"npx"}}}`)
+	updated, removed, err := removeGeminiMCPServer(settings, "playwright")
+	if err != nil || !removed {
+		t.Fatalf("removeGeminiMCPServer() = %v, %v", removed, err)
+	}
+	names, err := parseGeminiMCPServers(string(updated))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"discourse"}) {
+		t.Fatalf("remaining servers = %#v", names)
+	}
+	if !strings.Contains(string(updated), `"theme": "Default"`) {
+		t.Fatalf("other settings dropped: %s", updated)
+	}
+
+	if _, removed, err := removeGeminiMCPServer(settings, "missing"); err != nil || removed {
+		t.Fatalf("removeGeminiMCPServer(missing) = %v, %v", removed, err)
+	}
+	if _, removed, err := removeGeminiMCPServer(nil, "playwright"); err != nil || removed {
+		t.Fatalf("removeGeminiMCPServer(empty) = %v, %v", removed, err)
+	}
+}
+
+func TestCustomMCPConfiguration(t *testing.T) {
+	env, err := parseMCPEnv([]string{"TOKEN=a b", "MODE=ro=1"})
+	if err != nil {
+		t.Fatalf("parseMCPEnv() error = %v", err)
+	}
+	cfg := customMCPConfiguration("search", "npx", []string{"-y", "search-mcp", "--root", "/var/www"}, env)
+	want := "claude mcp add -s user 'search' '-e' 'MODE=ro=1' '-e' 'TOKEN=a b' '--' 'npx' '-y' 'search-mcp' '--root' '/var/www'"
+	if cfg.registrationCmd != want {
+		t.Fatalf("registrationCmd = %s\nwant %s", cfg.registrationCmd, want)
+	}
+	if cfg.codexCommand != "npx" || cfg.geminiCommand != "npx" || len(cfg.codexArgs) != 4 || cfg.env["TOKEN"] != "a b" {
+		t.Fatalf("unexpected configuration: %+v", cfg)
+	}
+
+	if _, err := parseMCPEnv([]string{"NOVALUE"}); err == nil {
+		t.Fatal("expected error for --env without =")
+	}
+	if env, err := parseMCPEnv(nil); err != nil || env != nil {
+		t.Fatalf("parseMCPEnv(nil) = %v, %v", env, err)
+	}
+}
+
+func TestAddOrReplaceTomlSectionNestedTables(t *testing.T) {
+	existing := `[mcp_servers.custom]
+command = "old"
+
+[mcp_servers.custom.env]
+TOKEN = "old"
+
+[mcp_servers.customer]
+command = "keep"
+
+[[mcp_servers.custom.extra]]
+x = 1
+`
+	body := "[mcp_servers.custom]\ncommand = \"new\"\n\n[mcp_servers.custom.env]\nTOKEN = \"new\"\n"
+	got := addOrReplaceTomlSection(existing, "mcp_servers.custom", body)
+	want := `[mcp_servers.custom]
+command = "new"
+
+[mcp_servers.custom.env]
+TOKEN = "new"
+
+[mcp_servers.customer]
+command = "keep"
+
+[[mcp_servers.custom.extra]]
+x = 1
+`
+	if got != want {
+		t.Fatalf("addOrReplaceTomlSection() =\n%s\nwant\n%s", got, want)
+	}
+
+	got = removeTomlSection("[mcp_servers.custom]\ncommand = \"x\"\n\n  [ mcp_servers.custom.env ]  # nested\nTOKEN = \"x\"\n\n[profiles.fast]\nmodel = \"o4-mini\"\n", "mcp_servers.custom")
+	if got != "[profiles.fast]\nmodel = \"o4-mini\"\n" {
+		t.Fatalf("removeTomlSection() = %q, want only the profiles table", got)
+	}
+}
+
+func TestCodexMCPSectionWithoutEnv(t *testing.T) {
+	got, err := codexMCPSection(mcpConfiguration{name: "playwright", codexCommand: "npx", codexArgs: []string{"-y", "@playwright/mcp@latest"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[mcp_servers.playwright]\ncommand = \"npx\"\nargs = [\"-y\",\"@playwright/mcp@latest\"]\n"
+	if got != want {
+		t.Fatalf("codexMCPSection() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCodexMCPSectionEnvRoundTrip(t *testing.T) {
+	env := map[string]string{
+		"TOKEN":      `a"b\c`,
+		"MULTI_LINE": "one\ntwo\tthree",
+		"dotted.key": "x",
+	}
+	body, err := codexMCPSection(mcpConfiguration{name: "search", codexCommand: "npx", codexArgs: []string{"search-mcp"}, env: env})
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated := addOrReplaceTomlSection("[mcp_servers.search]\ncommand = \"old\"\n\n[mcp_servers.search.env]\nSTALE = \"1\"\n", "mcp_servers.search", body)
+
+	// Read the env table back; TOML basic strings with these escapes are
+	// valid Go string literals.
+	got := map[string]string{}
+	inEnv := false
+	for _, line := range strings.Split(updated, "\n") {
+		if name, ok := tomlTableName(line); ok {
+			inEnv = name == "mcp_servers.search.env"
+			continue
+		}
+		key, value, ok := strings.Cut(line, " = ")
+		if !inEnv || !ok {
+			continue
+		}
+		if strings.HasPrefix(key, `"`) {
+			if key, err = strconv.Unquote(key); err != nil {
+				t.Fatalf("unquote key %s: %v", line, err)
+			}
+		}
+		if got[key], err = strconv.Unquote(value); err != nil {
+			t.Fatalf("unquote value %s: %v", line, err)
+		}
+	}
+	if !reflect.DeepEqual(got, env) {
+		t.Fatalf("env after round trip = %#v, want %#v\n%s", got, env, updated)
+	}
+	if names := parseCodexMCPServers(updated); !reflect.DeepEqual(names, []string{"search"}) {
+		t.Fatalf("parseCodexMCPServers() = %#v", names)
+	}
+}
+
+func TestClaudeMCPStatus(t *testing.T) {
+	cases := []struct {
+		out       string
+		connected bool
+		status    string
+	}{
+		{"discourse:\n  Scope: User config (available in all your projects)\n  Status: ✓ Connected\n  Type: stdio\n", true, "✓ Connected"},
+		{"discourse:\n  Status: ✗ Failed to connect\n", false, "✗ Failed to connect"},
+		{"No MCP server found with name: discourse\n", false, ""},
+	}
+	for _, tc := range cases {
+		connected, status := claudeMCPStatus(tc.out)
+		if connected != tc.connected || status != tc.status {
+			t.Errorf("claudeMCPStatus(%q) = %v, %q; want %v, %q", tc.out, connected, status, tc.connected, tc.status)
+		}
+	}
+}
+
+func TestParseMCPTools(t *testing.T) {
+	cases := []struct {
+		value string
+		want  map[string]bool
+	}{
+		{"", map[string]bool{"claude": true, "codex": true, "gemini": true}},
+		{"claude,codex", map[string]bool{"claude": true, "codex": true}},
+		{" Gemini , ,claude", map[string]bool{"claude": true, "gemini": true}},
+	}
+	for _, tc := range cases {
+		got, err := parseMCPTools(tc.value)
+		if err != nil {
+			t.Fatalf("parseMCPTools(%q) error = %v", tc.value, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("parseMCPTools(%q) = %v, want %v", tc.value, got, tc.want)
+		}
+	}
+	if _, err := parseMCPTools("claude,cursor"); err == nil {
+		t.Fatal("expected error for unknown tool")
+	}
+}
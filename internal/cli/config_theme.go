@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/spf13/cobra"
@@ -63,79 +64,30 @@ In both cases the workdir override is updated and an AGENTS.md guide is written
 theme root so AI tooling understands the layout.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		configDir, err := xdg.ConfigDir()
-		if err != nil {
-			return err
-		}
-		cfg, err := config.LoadOrCreate(configDir)
-		if err != nil {
+		ctx, ok, err := loadThemeCommandContext(cmd)
+		if err != nil || !ok {
 			return err
 		}
 
-		containerOverride, _ := cmd.Flags().GetString("container")
-		containerName := strings.TrimSpace(containerOverride)
-		if containerName == "" {
-			containerName = currentAgentName(cfg)
-		}
-		if strings.TrimSpace(containerName) == "" {
-			fmt.Fprintln(cmd.ErrOrStderr(), "No container selected. Run 'dv start' first.")
-			return nil
-		}
-
-		if !docker.Exists(containerName) {
-			fmt.Fprintf(cmd.OutOrStdout(), "Container '%s' does not exist. Run 'dv start' first.\n", containerName)
-			return nil
-		}
-		if !docker.Running(containerName) {
-			fmt.Fprintf(cmd.OutOrStdout(), "Starting container '%s'...\n", containerName)
-			if err := startContainerWithPostStartHook(cmd, cfg, configDir, containerName, "config theme"); err != nil {
-				return err
-			}
-		}
-
-		imgName := cfg.ContainerImages[containerName]
-		var imgCfg config.ImageConfig
-		if imgName != "" {
-			imgCfg = cfg.Images[imgName]
-		} else {
-			if _, resolved, err := resolveImage(cfg, ""); err == nil {
-				imgCfg = resolved
-			} else {
-				return err
-			}
-		}
-
-		dataDir, err := xdg.DataDir()
-		if err != nil {
-			return err
-		}
-
-		verboseFlag, _ := cmd.Flags().GetBool("verbose")
-
-		discourseRoot := strings.TrimSpace(imgCfg.Workdir)
-		if discourseRoot == "" {
-			discourseRoot = "/var/www/discourse"
-		}
-
-		ctx := themeCommandContext{
-			cfg:           &cfg,
-			configDir:     configDir,
-			containerName: containerName,
-			discourseRoot: discourseRoot,
-			dataDir:       dataDir,
-			verbose:       verboseFlag,
-			envs:          collectEnvPassthrough(cfg),
-		}
-
 		themeNameFlag, _ := cmd.Flags().GetString("theme-name")
 		themeNameFlag = strings.TrimSpace(themeNameFlag)
 
+		branchFlag, _ := cmd.Flags().GetString("branch")
+		branchFlag = strings.TrimSpace(branchFlag)
+
 		if len(args) == 0 {
+			if branchFlag != "" {
+				return fmt.Errorf("--branch only applies when cloning a theme repo")
+			}
 			return handleThemeScaffold(cmd, ctx, themeNameFlag)
 		}
+		if cmd.Flags().Changed("parent") {
+			return fmt.Errorf("--parent only applies when scaffolding a new component")
+		}
 		t := templateTheme{
 			Repo:      args[0],
 			Name:      themeNameFlag,
+			Branch:    branchFlag,
 			AutoWatch: false,
 			Path:      "", // default
 		}
@@ -145,12 +97,86 @@ theme root so AI tooling understands the layout.`,
 
 func init() {
 	configThemeCmd.Flags().String("theme-name", "", "Friendly name to use for the theme (defaults to input)")
-	configThemeCmd.Flags().String("container", "", "Container to configure (defaults to the selected agent)")
+	configThemeCmd.PersistentFlags().String("container", "", "Container to configure (defaults to the selected agent)")
 	configThemeCmd.Flags().String("kind", "", "Scaffold as 'theme' or 'component' (prompts when omitted)")
-	configThemeCmd.Flags().Bool("verbose", false, "Print diagnostic output during theme setup")
+	configThemeCmd.Flags().Bool("relink", false, "If the theme directory already exists as a git repo, skip cloning/scaffolding and only restore the watcher, API key, workdir and AGENTS.md")
+	configThemeCmd.Flags().String("branch", "", "Branch or tag to check out when cloning REPO")
+	configThemeCmd.Flags().String("parent", "", "With --kind component, attach the new component to this parent theme (by name)")
+	configThemeCmd.Flags().String("license", "", "Add a LICENSE file to a scaffolded theme (supported: MIT)")
+	configThemeCmd.Flags().Bool("full", false, "Scaffold mobile/, javascripts/, locales/ and settings.yml in addition to the minimal layout")
+	configThemeCmd.PersistentFlags().Bool("verbose", false, "Print diagnostic output during theme setup")
 	configCmd.AddCommand(configThemeCmd)
 }
 
+// loadThemeCommandContext resolves the target container (starting it when
+// needed) and its Discourse root for the theme commands. ok is false when
+// there is nothing to operate on; a message has already been printed.
+func loadThemeCommandContext(cmd *cobra.Command) (themeCommandContext, bool, error) {
+	configDir, err := xdg.ConfigDir()
+	if err != nil {
+		return themeCommandContext{}, false, err
+	}
+	cfg, err := config.LoadOrCreate(configDir)
+	if err != nil {
+		return themeCommandContext{}, false, err
+	}
+
+	containerOverride, _ := cmd.Flags().GetString("container")
+	containerName := strings.TrimSpace(containerOverride)
+	if containerName == "" {
+		containerName = currentAgentName(cfg)
+	}
+	if strings.TrimSpace(containerName) == "" {
+		fmt.Fprintln(cmd.ErrOrStderr(), "No container selected. Run 'dv start' first.")
+		return themeCommandContext{}, false, nil
+	}
+
+	if !docker.Exists(containerName) {
+		fmt.Fprintf(cmd.OutOrStdout(), "Container '%s' does not exist. Run 'dv start' first.\n", containerName)
+		return themeCommandContext{}, false, nil
+	}
+	if !docker.Running(containerName) {
+		fmt.Fprintf(cmd.OutOrStdout(), "Starting container '%s'...\n", containerName)
+		if err := startContainerWithPostStartHook(cmd, cfg, configDir, containerName, "config theme"); err != nil {
+			return themeCommandContext{}, false, err
+		}
+	}
+
+	imgName := cfg.ContainerImages[containerName]
+	var imgCfg config.ImageConfig
+	if imgName != "" {
+		imgCfg = cfg.Images[imgName]
+	} else {
+		_, resolved, err := resolveImage(cfg, "")
+		if err != nil {
+			return themeCommandContext{}, false, err
+		}
+		imgCfg = resolved
+	}
+
+	dataDir, err := xdg.DataDir()
+	if err != nil {
+		return themeCommandContext{}, false, err
+	}
+
+	verboseFlag, _ := cmd.Flags().GetBool("verbose")
+
+	discourseRoot := strings.TrimSpace(imgCfg.Workdir)
+	if discourseRoot == "" {
+		discourseRoot = "/var/www/discourse"
+	}
+
+	return themeCommandContext{
+		cfg:           &cfg,
+		configDir:     configDir,
+		containerName: containerName,
+		discourseRoot: discourseRoot,
+		dataDir:       dataDir,
+		verbose:       verboseFlag,
+		envs:          collectEnvPassthrough(cfg),
+	}, true, nil
+}
+
 func handleThemeScaffold(cmd *cobra.Command, ctx themeCommandContext, flagName string) error {
 	name := flagName
 	if name == "" {
@@ -161,27 +187,55 @@ func handleThemeScaffold(cmd *cobra.Command, ctx themeCommandContext, flagName s
 		}
 	}
 
-	kindFlag, _ := cmd.Flags().GetString("kind")
-	isComponent, err := resolveThemeKind(cmd, kindFlag)
-	if err != nil {
-		return err
-	}
-
 	dirSlug := themeDirSlug(name)
 	serviceName := fmt.Sprintf("theme-watch-%s", dirSlug)
 	themePath := path.Join("/home/discourse", dirSlug)
 	hostMirrorPath := ctx.hostMirrorPath(dirSlug)
+	if relinked, err := maybeRelinkTheme(cmd, ctx, finalizeThemeOptions{
+		DisplayName:    name,
+		ThemePath:      themePath,
+		Slug:           dirSlug,
+		ServiceName:    serviceName,
+		HostMirrorPath: hostMirrorPath,
+	}); err != nil || relinked {
+		return err
+	}
 	if err := ensureContainerPathAvailable(ctx.containerName, themePath); err != nil {
 		return err
 	}
 
+	kindFlag, _ := cmd.Flags().GetString("kind")
+	isComponent, err := resolveThemeKind(cmd, kindFlag)
+	if err != nil {
+		return err
+	}
+	licenseFlag, _ := cmd.Flags().GetString("license")
+	license, err := normalizeThemeLicense(licenseFlag)
+	if err != nil {
+		return err
+	}
+	parentTheme, err := resolveParentThemeFlag(cmd, isComponent)
+	if err != nil {
+		return err
+	}
+
 	fmt.Fprintf(cmd.OutOrStdout(), "Installing discourse_theme gem inside '%s'...\n", ctx.containerName)
 	if err := installDiscourseThemeGem(cmd, ctx.containerName); err != nil {
 		return err
 	}
 
 	fmt.Fprintf(cmd.OutOrStdout(), "Creating theme skeleton at %s...\n", themePath)
-	if err := scaffoldThemeIntoContainer(ctx, name, isComponent, serviceName, themePath, "", hostMirrorPath); err != nil {
+	full, _ := cmd.Flags().GetBool("full")
+	if err := scaffoldThemeIntoContainer(ctx, themeSkeletonPayload{
+		DisplayName:       name,
+		IsComponent:       isComponent,
+		ServiceName:       serviceName,
+		ThemePath:         themePath,
+		HostDiscoursePath: hostMirrorPath,
+		Full:              full,
+		License:           license,
+		ParentTheme:       parentTheme,
+	}); err != nil {
 		return err
 	}
 
@@ -190,14 +244,29 @@ func handleThemeScaffold(cmd *cobra.Command, ctx themeCommandContext, flagName s
 		return err
 	}
 
+	uploadedThemeID := 0
+	if parentTheme != "" {
+		result, err := uploadThemeIntoDiscourse(cmd, ctx, themePath, false)
+		if err != nil {
+			return err
+		}
+		uploadedThemeID = result.ID
+		fmt.Fprintf(cmd.OutOrStdout(), "Attaching component to parent theme '%s'...\n", parentTheme)
+		if err := attachComponentToParentTheme(ctx, result.ID, parentTheme); err != nil {
+			return err
+		}
+	}
+
 	serviceName, err = finalizeThemeWorkspace(cmd, ctx, finalizeThemeOptions{
-		DisplayName:    name,
-		ThemePath:      themePath,
-		RepoURL:        "",
-		IsComponent:    isComponent,
-		Slug:           dirSlug,
-		ServiceName:    serviceName,
-		HostMirrorPath: hostMirrorPath,
+		DisplayName:     name,
+		ThemePath:       themePath,
+		RepoURL:         "",
+		IsComponent:     isComponent,
+		Slug:            dirSlug,
+		ServiceName:     serviceName,
+		HostMirrorPath:  hostMirrorPath,
+		UploadedThemeID: uploadedThemeID,
+		ParentTheme:     parentTheme,
 	})
 	if err != nil {
 		return err
@@ -225,10 +294,27 @@ func handleThemeClone(cmd *cobra.Command, ctx themeCommandContext, theme templat
 		themePath = path.Join("/home/discourse", dirSlug)
 	}
 	hostMirrorPath := ctx.hostMirrorPath(dirSlug)
+	if relinked, err := maybeRelinkTheme(cmd, ctx, finalizeThemeOptions{
+		DisplayName:    name,
+		ThemePath:      themePath,
+		RepoURL:        repoURL,
+		Slug:           dirSlug,
+		ServiceName:    serviceName,
+		HostMirrorPath: hostMirrorPath,
+		Branch:         theme.Branch,
+	}); err != nil || relinked {
+		return err
+	}
 	if err := ensureContainerPathAvailable(ctx.containerName, themePath); err != nil {
 		return err
 	}
 
+	if theme.Branch != "" {
+		if err := validateThemeBranch(ctx, repoURL, theme.Branch); err != nil {
+			return err
+		}
+	}
+
 	cloneArgs := []string{"git", "clone"}
 	if theme.Branch != "" {
 		cloneArgs = append(cloneArgs, "--branch", theme.Branch)
@@ -273,6 +359,7 @@ func handleThemeClone(cmd *cobra.Command, ctx themeCommandContext, theme templat
 		ServiceName:     serviceName,
 		HostMirrorPath:  hostMirrorPath,
 		UploadedThemeID: installResult.ID,
+		Branch:          theme.Branch,
 	})
 	if err != nil {
 		return err
@@ -291,6 +378,8 @@ type finalizeThemeOptions struct {
 	ServiceName     string
 	HostMirrorPath  string
 	UploadedThemeID int
+	Branch          string
+	ParentTheme     string
 }
 
 func finalizeThemeWorkspace(cmd *cobra.Command, ctx themeCommandContext, opts finalizeThemeOptions) (string, error) {
@@ -302,7 +391,7 @@ func finalizeThemeWorkspace(cmd *cobra.Command, ctx themeCommandContext, opts fi
 	if hostMirror == "" {
 		hostMirror = ctx.hostMirrorPath(opts.Slug)
 	}
-	if err := writeAgentFileToContainer(ctx, opts.ThemePath, opts.DisplayName, opts.RepoURL, serviceName, opts.IsComponent, hostMirror); err != nil {
+	if err := writeAgentFileToContainer(ctx, opts, serviceName, hostMirror); err != nil {
 		return "", err
 	}
 	if err := configureThemeWatcher(cmd, ctx, opts, serviceName); err != nil {
@@ -314,6 +403,126 @@ func finalizeThemeWorkspace(cmd *cobra.Command, ctx themeCommandContext, opts fi
 	return serviceName, nil
 }
 
+// resolveParentThemeFlag validates --parent, which only makes sense for a
+// component and must name a theme when given.
+func resolveParentThemeFlag(cmd *cobra.Command, isComponent bool) (string, error) {
+	if !cmd.Flags().Changed("parent") {
+		return "", nil
+	}
+	value, _ := cmd.Flags().GetString("parent")
+	parent := strings.TrimSpace(value)
+	if parent == "" {
+		return "", errors.New("--parent cannot be empty")
+	}
+	if !isComponent {
+		return "", errors.New("--parent requires --kind component")
+	}
+	return parent, nil
+}
+
+// attachComponentToParentTheme adds the uploaded component as a child of the
+// full theme named parentName, so it is active as soon as scaffolding ends.
+func attachComponentToParentTheme(ctx themeCommandContext, componentID int, parentName string) error {
+	ruby := `component = Theme.find(ENV.fetch("DV_COMPONENT_ID").to_i)
+parent = Theme.not_components.find_by(name: ENV.fetch("DV_PARENT_THEME"))
+raise "Parent theme #{ENV["DV_PARENT_THEME"].inspect} not found (it must be a full theme, not a component)" if parent.nil?
+parent.add_relative_theme!(:child, component) unless parent.child_theme_ids.include?(component.id)
+Theme.clear_cache!
+`
+	runner := fmt.Sprintf("DV_COMPONENT_ID=%s DV_PARENT_THEME=%s RAILS_ENV=development bundle exec rails runner - <<'RUBY'\n%s\nRUBY", shellQuote(strconv.Itoa(componentID)), shellQuote(parentName), ruby)
+	out, err := docker.ExecCombinedOutput(ctx.containerName, ctx.discourseRoot, ctx.envs, []string{"bash", "-lc", runner})
+	if err != nil {
+		if trimmed := strings.TrimSpace(out); trimmed != "" {
+			return fmt.Errorf("failed to attach component to %q: %w\n%s", parentName, err, trimmed)
+		}
+		return fmt.Errorf("failed to attach component to %q: %w", parentName, err)
+	}
+	return nil
+}
+
+// existingThemeWorkspace describes a theme directory already present in the
+// container, as reported by themeWorkspaceProbeScript.
+type existingThemeWorkspace struct {
+	Exists      bool
+	IsGitRepo   bool
+	IsComponent bool
+	RepoURL     string
+	Branch      string
+}
+
+func themeWorkspaceProbeScript(themePath string) string {
+	return fmt.Sprintf(`if [ ! -e %[1]s ]; then exit 0; fi
+echo __DV_EXISTS__
+cd %[1]s || exit 0
+if git rev-parse --is-inside-work-tree >/dev/null 2>&1; then
+  echo __DV_GIT__
+  echo "__DV_REMOTE__$(git remote get-url origin 2>/dev/null)"
+  echo "__DV_BRANCH__$(git symbolic-ref --short -q HEAD 2>/dev/null)"
+fi
+if grep -Eq '"component"[[:space:]]*:[[:space:]]*true' about.json 2>/dev/null; then
+  echo __DV_COMPONENT__
+fi
+`, shellQuote(themePath))
+}
+
+func parseThemeWorkspaceProbe(out string) existingThemeWorkspace {
+	var ws existingThemeWorkspace
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "__DV_EXISTS__":
+			ws.Exists = true
+		case line == "__DV_GIT__":
+			ws.IsGitRepo = true
+		case line == "__DV_COMPONENT__":
+			ws.IsComponent = true
+		case strings.HasPrefix(line, "__DV_REMOTE__"):
+			ws.RepoURL = strings.TrimPrefix(line, "__DV_REMOTE__")
+		case strings.HasPrefix(line, "__DV_BRANCH__"):
+			if branch := strings.TrimPrefix(line, "__DV_BRANCH__"); branch != "HEAD" {
+				ws.Branch = branch
+			}
+		}
+	}
+	return ws
+}
+
+// maybeRelinkTheme handles --relink: when opts.ThemePath already holds a git
+// checkout it skips clone/scaffold and only re-runs finalizeThemeWorkspace
+// (API key, watcher, workdir, AGENTS.md). It reports whether it did so.
+func maybeRelinkTheme(cmd *cobra.Command, ctx themeCommandContext, opts finalizeThemeOptions) (bool, error) {
+	if relink, _ := cmd.Flags().GetBool("relink"); !relink {
+		return false, nil
+	}
+	out, err := docker.ExecOutput(ctx.containerName, "/home/discourse", nil, []string{"bash", "-c", themeWorkspaceProbeScript(opts.ThemePath)})
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect %s: %w", opts.ThemePath, err)
+	}
+	ws := parseThemeWorkspaceProbe(out)
+	if !ws.Exists {
+		ctx.verboseLog(cmd, "%s does not exist yet; creating it normally", opts.ThemePath)
+		return false, nil
+	}
+	if !ws.IsGitRepo {
+		return false, fmt.Errorf("%s exists but is not a git repository; cannot relink", opts.ThemePath)
+	}
+	opts.IsComponent = ws.IsComponent
+	if ws.RepoURL != "" {
+		opts.RepoURL = ws.RepoURL
+	}
+	if ws.Branch != "" {
+		opts.Branch = ws.Branch
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Relinking existing theme workspace at %s...\n", opts.ThemePath)
+	serviceName, err := finalizeThemeWorkspace(cmd, ctx, opts)
+	if err != nil {
+		return true, err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Theme '%s' relinked at %s. Watcher service '%s' now tracks changes.\n", opts.DisplayName, opts.ThemePath, serviceName)
+	return true, nil
+}
+
 func promptThemeName(cmd *cobra.Command) (string, error) {
 	if !isTerminalInput() {
 		return "", errors.New("stdin is not interactive; pass --theme-name instead")
@@ -400,6 +609,21 @@ func themeDirSlug(name string) string {
 	return slug
 }
 
+// validateThemeBranch checks that branch exists on repoURL before cloning.
+// Only a definite "not found" from git ls-remote is an error; network or auth
+// failures are left for git clone to report.
+func validateThemeBranch(ctx themeCommandContext, repoURL, branch string) error {
+	script := shellJoin([]string{"git", "ls-remote", "--exit-code", "--heads", "--tags", repoURL, branch}) + " >/dev/null 2>&1; echo \"__DV_LS_REMOTE__$?\""
+	out, err := docker.ExecOutput(ctx.containerName, "/home/discourse", ctx.envs, []string{"bash", "-c", script})
+	if err != nil {
+		return nil
+	}
+	if strings.Contains(out, "__DV_LS_REMOTE__2") {
+		return fmt.Errorf("branch or tag %q not found in %s", branch, repoURL)
+	}
+	return nil
+}
+
 func ensureContainerPathAvailable(containerName, themePath string) error {
 	script := fmt.Sprintf("if [ -e %s ]; then echo '__DV_EXISTS__'; fi", shellQuote(themePath))
 	out, err := docker.ExecOutput(containerName, "/home/discourse", nil, []string{"bash", "-lc", script})
@@ -427,7 +651,7 @@ func installDiscourseThemeGem(cmd *cobra.Command, containerName string) error {
 	return nil
 }
 
-func scaffoldThemeIntoContainer(ctx themeCommandContext, displayName string, isComponent bool, serviceName, themePath, repoURL, hostMirrorPath string) error {
+func scaffoldThemeIntoContainer(ctx themeCommandContext, payload themeSkeletonPayload) error {
 	tempDir, err := os.MkdirTemp("", "dv-theme-")
 	if err != nil {
 		return err
@@ -439,35 +663,31 @@ func scaffoldThemeIntoContainer(ctx themeCommandContext, displayName string, isC
 		return err
 	}
 
-	if err := writeThemeSkeleton(root, themeSkeletonPayload{
-		DisplayName:            displayName,
-		IsComponent:            isComponent,
-		ServiceName:            serviceName,
-		ThemePath:              themePath,
-		ContainerName:          ctx.containerName,
-		ContainerDiscoursePath: ctx.discourseRoot,
-		HostDiscoursePath:      hostMirrorPath,
-		RepositoryURL:          repoURL,
-	}); err != nil {
+	payload.ContainerName = ctx.containerName
+	payload.ContainerDiscoursePath = ctx.discourseRoot
+	if err := writeThemeSkeleton(root, payload); err != nil {
 		return err
 	}
 
-	if err := docker.CopyToContainerWithOwnership(ctx.containerName, root, themePath, true); err != nil {
+	if err := docker.CopyToContainerWithOwnership(ctx.containerName, root, payload.ThemePath, true); err != nil {
 		return err
 	}
 	return nil
 }
 
-func writeAgentFileToContainer(ctx themeCommandContext, themePath, displayName, repoURL, serviceName string, isComponent bool, hostMirrorPath string) error {
+func writeAgentFileToContainer(ctx themeCommandContext, opts finalizeThemeOptions, serviceName, hostMirrorPath string) error {
+	themePath := opts.ThemePath
 	content, err := resources.RenderThemeAgent(resources.ThemeAgentData{
-		ThemeName:              displayName,
+		ThemeName:              opts.DisplayName,
 		ThemePath:              themePath,
 		ContainerName:          ctx.containerName,
 		ContainerDiscoursePath: ctx.discourseRoot,
 		HostDiscoursePath:      hostMirrorPath,
-		RepositoryURL:          repoURL,
+		RepositoryURL:          opts.RepoURL,
+		Branch:                 opts.Branch,
+		ParentTheme:            opts.ParentTheme,
 		ServiceName:            serviceName,
-		IsComponent:            isComponent,
+		IsComponent:            opts.IsComponent,
 	})
 	if err != nil {
 		return err
@@ -529,6 +749,62 @@ type themeSkeletonPayload struct {
 	ContainerDiscoursePath string
 	HostDiscoursePath      string
 	RepositoryURL          string
+	// Full adds mobile/, javascripts/, locales/ and settings.yml stubs on top
+	// of the minimal common/desktop layout.
+	Full bool
+	// ParentTheme names the full theme a component is attached to.
+	ParentTheme string
+	// License is an SPDX id for a LICENSE file to generate; only "MIT" is
+	// supported and empty skips it.
+	License string
+}
+
+const (
+	mitLicenseURL = "https://opensource.org/licenses/MIT"
+
+	themeGitignore = `.discourse-site
+node_modules/
+dist/
+tmp/
+*.log
+.DS_Store
+`
+
+	mitLicenseTemplate = `MIT License
+
+Copyright (c) %d %s contributors
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+`
+)
+
+// normalizeThemeLicense validates the --license flag value.
+func normalizeThemeLicense(value string) (string, error) {
+	trimmed := strings.TrimSpace(value)
+	switch strings.ToUpper(trimmed) {
+	case "":
+		return "", nil
+	case "MIT":
+		return "MIT", nil
+	default:
+		return "", fmt.Errorf("unsupported --license %q; only MIT is supported", value)
+	}
 }
 
 func writeThemeSkeleton(root string, payload themeSkeletonPayload) error {
@@ -536,20 +812,30 @@ func writeThemeSkeleton(root string, payload themeSkeletonPayload) error {
 		"common",
 		"desktop",
 	}
+	if payload.Full {
+		dirs = append(dirs, "mobile", "locales", filepath.Join("javascripts", "discourse", "api-initializers"))
+	}
 	for _, dir := range dirs {
 		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
 			return err
 		}
 	}
 
+	licenseURL := ""
+	if payload.License == "MIT" {
+		licenseURL = mitLicenseURL
+	}
 	about := map[string]any{
 		"name":          payload.DisplayName,
 		"about_url":     "",
-		"license_url":   "",
+		"license_url":   licenseURL,
 		"component":     payload.IsComponent,
 		"assets":        map[string]any{},
 		"color_schemes": map[string]any{},
 	}
+	if payload.IsComponent {
+		about["modifiers"] = map[string]any{}
+	}
 	jsonBytes, err := json.MarshalIndent(about, "", "  ")
 	if err != nil {
 		return err
@@ -565,6 +851,20 @@ func writeThemeSkeleton(root string, payload themeSkeletonPayload) error {
 	if err := os.WriteFile(filepath.Join(root, "desktop", "desktop.scss"), []byte("/* Desktop-only SCSS */\n"), 0o644); err != nil {
 		return err
 	}
+	if payload.Full {
+		if err := writeFullThemeStubs(root, payload); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte(themeGitignore), 0o644); err != nil {
+		return err
+	}
+	if payload.License == "MIT" {
+		license := fmt.Sprintf(mitLicenseTemplate, time.Now().Year(), payload.DisplayName)
+		if err := os.WriteFile(filepath.Join(root, "LICENSE"), []byte(license), 0o644); err != nil {
+			return err
+		}
+	}
 	readme := fmt.Sprintf("# %s\n\nBootstrapped via `dv config theme`.\n", payload.DisplayName)
 	if err := os.WriteFile(filepath.Join(root, "README.md"), []byte(readme), 0o644); err != nil {
 		return err
@@ -577,6 +877,7 @@ func writeThemeSkeleton(root string, payload themeSkeletonPayload) error {
 		ContainerDiscoursePath: payload.ContainerDiscoursePath,
 		HostDiscoursePath:      payload.HostDiscoursePath,
 		RepositoryURL:          payload.RepositoryURL,
+		ParentTheme:            payload.ParentTheme,
 		ServiceName:            payload.ServiceName,
 		IsComponent:            payload.IsComponent,
 	})
@@ -586,6 +887,28 @@ func writeThemeSkeleton(root string, payload themeSkeletonPayload) error {
 	return os.WriteFile(filepath.Join(root, "AGENTS.md"), []byte(content), 0o644)
 }
 
+func writeFullThemeStubs(root string, payload themeSkeletonPayload) error {
+	initializer := `import { apiInitializer } from "discourse/lib/api";
+
+export default apiInitializer((api) => {
+  // Customize Discourse here, e.g. api.renderInOutlet(...)
+});
+`
+	locale := fmt.Sprintf("en:\n  theme_metadata:\n    description: %s\n", strconv.Quote(payload.DisplayName))
+	files := map[string]string{
+		filepath.Join("mobile", "mobile.scss"): "/* Mobile-only SCSS */\n",
+		filepath.Join("locales", "en.yml"):     locale,
+		filepath.Join("javascripts", "discourse", "api-initializers", themeDirSlug(payload.DisplayName)+".gjs"): initializer,
+		"settings.yml": "# Theme settings (https://meta.discourse.org/t/82557)\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type themeInstallResult struct {
 	ID        int    `json:"id"`
 	Name      string `json:"name"`
@@ -791,6 +1114,11 @@ func installWatcherService(cmd *cobra.Command, ctx themeCommandContext, serviceN
 	if _, err := docker.ExecAsRoot(ctx.containerName, "/", nil, []string{"bash", "-lc", fmt.Sprintf("mkdir -p %s", shellQuote(serviceDir))}); err != nil {
 		return err
 	}
+	// Set up the log service before the run script exists so runsv picks it
+	// up when it first starts the watcher.
+	if err := installWatcherLogService(ctx, serviceName); err != nil {
+		ctx.verboseLog(cmd, "Could not set up svlogd for %s (continuing anyway): %v", serviceName, err)
+	}
 	runContent := fmt.Sprintf(`#!/bin/bash
 set -euo pipefail
 
@@ -858,19 +1186,81 @@ exec chpst -u discourse:discourse -U discourse:discourse ruby "$WATCHER_BIN"
 	return nil
 }
 
+// themeWatcherLogDir is where svlogd keeps a watcher's output; the current
+// log is always at <dir>/current.
+func themeWatcherLogDir(serviceName string) string {
+	return path.Join("/var/log", serviceName)
+}
+
+// installWatcherLogService adds a runit log/run so watcher output is kept by
+// svlogd instead of disappearing into runsvdir's stdout.
+func installWatcherLogService(ctx themeCommandContext, serviceName string) error {
+	logDir := themeWatcherLogDir(serviceName)
+	logServiceDir := path.Join("/etc/service", serviceName, "log")
+	script := fmt.Sprintf(`set -e
+mkdir -p %[1]s %[2]s
+cat > %[1]s/run <<'RUN'
+#!/bin/sh
+exec svlogd -tt %[2]s
+RUN
+chmod +x %[1]s/run
+`, shellQuote(logServiceDir), shellQuote(logDir))
+	_, err := docker.ExecAsRoot(ctx.containerName, "/", nil, []string{"bash", "-c", script})
+	return err
+}
+
+// resolveInternalDiscourseURL finds the in-container port that actually serves
+// Discourse so the watcher authenticates against the right endpoint. It probes
+// UNICORN_PORT, then 3000 (puma/unicorn), then 4200 (ember-cli proxy) and uses
+// the first that answers /srv/status, falling back to the first candidate.
 func resolveInternalDiscourseURL(ctx themeCommandContext) (string, error) {
-	out, err := docker.ExecOutput(ctx.containerName, ctx.discourseRoot, nil, []string{"bash", "-lc", "echo -n ${UNICORN_PORT:-3000}"})
+	out, err := docker.ExecOutput(ctx.containerName, ctx.discourseRoot, nil, []string{"bash", "-lc", "echo -n ${UNICORN_PORT:-}"})
 	if err != nil {
 		return "", err
 	}
-	port := strings.TrimSpace(out)
-	if port == "" {
-		port = "3000"
+	candidates, err := internalDiscoursePortCandidates(strings.TrimSpace(out))
+	if err != nil {
+		return "", err
 	}
-	if _, err := strconv.Atoi(port); err != nil {
-		return "", fmt.Errorf("invalid UNICORN_PORT value: %s", port)
+	port := candidates[0]
+	probeOut, err := docker.ExecOutput(ctx.containerName, ctx.discourseRoot, nil, []string{"bash", "-c", discoursePortProbeScript(candidates)})
+	if err == nil {
+		if probed, convErr := strconv.Atoi(strings.TrimSpace(probeOut)); convErr == nil && probed > 0 {
+			port = probed
+		}
 	}
-	return fmt.Sprintf("http://127.0.0.1:%s", port), nil
+	return fmt.Sprintf("http://127.0.0.1:%d", port), nil
+}
+
+func internalDiscoursePortCandidates(unicornPort string) ([]int, error) {
+	var ports []int
+	if unicornPort != "" {
+		p, err := strconv.Atoi(unicornPort)
+		if err != nil || p <= 0 {
+			return nil, fmt.Errorf("invalid UNICORN_PORT value: %s", unicornPort)
+		}
+		ports = append(ports, p)
+	}
+	for _, p := range []int{3000, 4200} {
+		if len(ports) == 0 || ports[0] != p {
+			ports = append(ports, p)
+		}
+	}
+	return ports, nil
+}
+
+// discoursePortProbeScript prints the first port whose /srv/status responds.
+func discoursePortProbeScript(ports []int) string {
+	list := make([]string, len(ports))
+	for i, p := range ports {
+		list[i] = strconv.Itoa(p)
+	}
+	return fmt.Sprintf(`for p in %s; do
+  if curl -fsS -m 2 -o /dev/null "http://127.0.0.1:$p/srv/status" 2>/dev/null; then
+    echo "$p"
+    exit 0
+  fi
+done`, strings.Join(list, " "))
 }
 
 func themeKeyPath(slug string) string {
@@ -957,8 +1347,8 @@ func normalizeThemeCloneSpec(theme templateTheme) (templateTheme, string, string
 	}
 	theme.Repo = repoURL
 	if theme.PR != 0 {
-		owner, repo := ownerRepoFromURL(repoURL)
-		if owner == "" || repo == "" {
+		host, owner, repo := ownerRepoFromURL(repoURL)
+		if host != "github.com" || owner == "" || repo == "" {
 			return theme, "", "", fmt.Errorf("theme PR checkout requires a GitHub repo, got %q", repoURL)
 		}
 	}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"dv/internal/docker"
+)
+
+// themeWatcherListScript prints one tab-separated line per theme watcher
+// service: service name, the raw THEME_DIR assignment from its run script,
+// and the first line of `sv status`.
+const themeWatcherListScript = `for d in /etc/service/theme-watch-*; do
+  [ -f "$d/run" ] || continue
+  svc=$(basename "$d")
+  dir=$(sed -n 's/^THEME_DIR=//p' "$d/run" | head -n1)
+  status=$(sv status "$svc" 2>&1 | head -n1)
+  printf '%s\t%s\t%s\n' "$svc" "$dir" "$status"
+done`
+
+type themeWatcherInfo struct {
+	Slug    string
+	Path    string
+	Service string
+	Status  string
+}
+
+// State returns the runit state word ("run", "down", "fail", ...) from Status.
+func (t themeWatcherInfo) State() string {
+	if idx := strings.Index(t.Status, ":"); idx > 0 {
+		return strings.TrimSpace(t.Status[:idx])
+	}
+	if t.Status == "" {
+		return "unknown"
+	}
+	return t.Status
+}
+
+var configThemeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List theme workspaces and the health of their watcher services",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, ok, err := loadThemeCommandContext(cmd)
+		if err != nil || !ok {
+			return err
+		}
+		themes, err := listThemeWatchers(ctx)
+		if err != nil {
+			return err
+		}
+		if len(themes) == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "No theme watchers configured in '%s'. Run 'dv config theme' to create one.\n", ctx.containerName)
+			return nil
+		}
+		slugWidth := len("SLUG")
+		for _, t := range themes {
+			if len(t.Slug) > slugWidth {
+				slugWidth = len(t.Slug)
+			}
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%-*s %-6s %s\n", slugWidth, "SLUG", "STATE", "PATH")
+		for _, t := range themes {
+			fmt.Fprintf(cmd.OutOrStdout(), "%-*s %-6s %s\n", slugWidth, t.Slug, t.State(), t.Path)
+			ctx.verboseLog(cmd, "  service: %s\n  status:  %s", t.Service, t.Status)
+		}
+		return nil
+	},
+}
+
+func init() {
+	configThemeCmd.AddCommand(configThemeListCmd)
+}
+
+func listThemeWatchers(ctx themeCommandContext) ([]themeWatcherInfo, error) {
+	out, err := docker.ExecAsRoot(ctx.containerName, "/", nil, []string{"bash", "-c", themeWatcherListScript})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list theme watchers: %w", err)
+	}
+	return parseThemeWatcherList(out), nil
+}
+
+func parseThemeWatcherList(out string) []themeWatcherInfo {
+	var themes []themeWatcherInfo
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(strings.TrimRight(line, "\r"), "\t", 3)
+		if len(fields) < 2 || !strings.HasPrefix(fields[0], "theme-watch-") {
+			continue
+		}
+		info := themeWatcherInfo{
+			Service: fields[0],
+			Slug:    strings.TrimPrefix(fields[0], "theme-watch-"),
+			Path:    shellUnquote(strings.TrimSpace(fields[1])),
+		}
+		if len(fields) == 3 {
+			info.Status = strings.TrimSpace(fields[2])
+		}
+		themes = append(themes, info)
+	}
+	sort.Slice(themes, func(i, j int) bool { return themes[i].Slug < themes[j].Slug })
+	return themes
+}
+
+// shellUnquote reverses shellQuote and plain double quoting, which is enough
+// to read back the assignments dv writes into watcher run scripts.
+func shellUnquote(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				b.WriteString(s[i+1:])
+				return b.String()
+			}
+			b.WriteString(s[i+1 : i+1+end])
+			i += end + 1
+		case '"':
+			for i++; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
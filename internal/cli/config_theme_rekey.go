@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"dv/internal/discourse"
+	"dv/internal/docker"
+)
+
+var configThemeRekeyCmd = &cobra.Command{
+	Use:   "rekey SLUG",
+	Short: "Rotate the API key used by a theme watcher and restart it",
+	Long: `Generates a fresh Discourse API key for the theme-watch-SLUG watcher (revoking the
+previous one), rewrites the stored key file and the ~/.discourse_theme entry for the
+theme, and restarts the watcher. Use it after a key leak or a database reset.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, ok, err := loadThemeCommandContext(cmd)
+		if err != nil || !ok {
+			return err
+		}
+		slug := themeDirSlug(args[0])
+		serviceName := fmt.Sprintf("theme-watch-%s", slug)
+
+		watchers, err := listThemeWatchers(ctx)
+		if err != nil {
+			return err
+		}
+		var watcher *themeWatcherInfo
+		for i := range watchers {
+			if watchers[i].Slug == slug {
+				watcher = &watchers[i]
+				break
+			}
+		}
+		if watcher == nil || strings.TrimSpace(watcher.Path) == "" {
+			return fmt.Errorf("no watcher service %s in container %s; see 'dv config theme list'", serviceName, ctx.containerName)
+		}
+
+		keyPath := themeKeyPath(slug)
+		fmt.Fprintf(cmd.OutOrStdout(), "Generating a new API key for %s...\n", serviceName)
+		apiKey, _, err := discourse.RegenerateAPIKeyForService(ctx.containerName, ctx.discourseRoot, serviceName, keyPath, ctx.envs, ctx.verbose)
+		if err != nil {
+			return err
+		}
+
+		discourseURL, err := resolveInternalDiscourseURL(ctx)
+		if err != nil {
+			return err
+		}
+		if err := writeThemeCLIConfig(cmd, ctx, watcher.Path, discourseURL, apiKey, 0); err != nil {
+			return err
+		}
+
+		if out, err := docker.ExecAsRoot(ctx.containerName, "/", nil, []string{"sv", "restart", serviceName}); err != nil {
+			if trimmed := strings.TrimSpace(out); trimmed != "" {
+				return fmt.Errorf("key rotated but sv restart %s failed: %w: %s", serviceName, err, trimmed)
+			}
+			return fmt.Errorf("key rotated but sv restart %s failed: %w", serviceName, err)
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Rotated API key for '%s'; new key stored at %s and %s restarted.\n", slug, keyPath, serviceName)
+		return nil
+	},
+}
+
+func init() {
+	configThemeCmd.AddCommand(configThemeRekeyCmd)
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"dv/internal/config"
+	"dv/internal/docker"
+)
+
+var configThemeRemoveCmd = &cobra.Command{
+	Use:   "remove SLUG",
+	Short: "Stop a theme watcher and remove its service, API key, and optionally its files",
+	Long: `Tears down what 'dv config theme' set up for SLUG: the theme-watch-SLUG runit
+service and its stored API key. If the container workdir override points at the
+theme it is cleared so the image default applies again. Pass --delete-files to
+also remove the theme directory inside the container.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, ok, err := loadThemeCommandContext(cmd)
+		if err != nil || !ok {
+			return err
+		}
+		slug := themeDirSlug(args[0])
+		serviceName := fmt.Sprintf("theme-watch-%s", slug)
+		themePath := path.Join("/home/discourse", slug)
+
+		watchers, err := listThemeWatchers(ctx)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, w := range watchers {
+			if w.Slug == slug {
+				found = true
+				if strings.TrimSpace(w.Path) != "" {
+					themePath = path.Clean(w.Path)
+				}
+				break
+			}
+		}
+
+		deleteFiles, _ := cmd.Flags().GetBool("delete-files")
+		if deleteFiles {
+			if err := validateThemeRemovalPath(themePath); err != nil {
+				return err
+			}
+		}
+
+		force, _ := cmd.Flags().GetBool("force")
+		if !force {
+			fmt.Fprintf(cmd.OutOrStdout(), "This will remove theme '%s' from container '%s':\n", slug, ctx.containerName)
+			if found {
+				fmt.Fprintf(cmd.OutOrStdout(), "  - stop and delete service %s\n", serviceName)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "  - delete API key %s\n", themeKeyPath(slug))
+			if deleteFiles {
+				fmt.Fprintf(cmd.OutOrStdout(), "  - DELETE theme directory %s\n", themePath)
+			}
+			yes, err := promptYesNo(cmd.InOrStdin(), cmd.OutOrStdout(), "Continue? (y/N): ")
+			if err != nil {
+				return err
+			}
+			if !yes {
+				fmt.Fprintln(cmd.OutOrStdout(), "Aborted.")
+				return nil
+			}
+		}
+
+		if found {
+			fmt.Fprintf(cmd.OutOrStdout(), "Stopping watcher service %s...\n", serviceName)
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "No watcher service found for '%s'; cleaning up remaining files.\n", slug)
+		}
+		script := themeRemovalScript(serviceName, themeKeyPath(slug), themePath, deleteFiles)
+		ctx.verboseLog(cmd, "Running cleanup script:\n%s", script)
+		if out, err := docker.ExecAsRoot(ctx.containerName, "/", nil, []string{"bash", "-c", script}); err != nil {
+			if trimmed := strings.TrimSpace(out); trimmed != "" {
+				return fmt.Errorf("failed to remove theme %s: %w: %s", slug, err, trimmed)
+			}
+			return fmt.Errorf("failed to remove theme %s: %w", slug, err)
+		}
+
+		if ctx.cfg.CustomWorkdirs != nil {
+			if current, ok := ctx.cfg.CustomWorkdirs[ctx.containerName]; ok && path.Clean(current) == themePath {
+				delete(ctx.cfg.CustomWorkdirs, ctx.containerName)
+				if err := config.Save(ctx.configDir, *ctx.cfg); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Cleared workdir override for %s; image defaults restored.\n", ctx.containerName)
+			}
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Removed theme '%s'.\n", slug)
+		return nil
+	},
+}
+
+func init() {
+	configThemeRemoveCmd.Flags().Bool("delete-files", false, "Also delete the theme directory inside the container")
+	configThemeRemoveCmd.Flags().BoolP("force", "f", false, "Skip the confirmation prompt")
+	configThemeCmd.AddCommand(configThemeRemoveCmd)
+}
+
+// validateThemeRemovalPath guards --delete-files against watcher run scripts
+// that point somewhere other than a directory under /home/discourse.
+func validateThemeRemovalPath(themePath string) error {
+	cleaned := path.Clean(themePath)
+	if !strings.HasPrefix(cleaned, "/home/discourse/") || strings.Count(cleaned, "/") < 3 {
+		return fmt.Errorf("refusing to delete %s: theme directories must live under /home/discourse", themePath)
+	}
+	return nil
+}
+
+func themeRemovalScript(serviceName, keyPath, themePath string, deleteFiles bool) string {
+	serviceDir := path.Join("/etc/service", serviceName)
+	lines := []string{
+		fmt.Sprintf("if [ -d %s ]; then", shellQuote(serviceDir)),
+		fmt.Sprintf("  sv force-stop %s >/dev/null 2>&1 || true", shellQuote(serviceName)),
+		fmt.Sprintf("  sv exit %s >/dev/null 2>&1 || true", shellQuote(serviceName)),
+		fmt.Sprintf("  rm -rf %s", shellQuote(serviceDir)),
+		"fi",
+		fmt.Sprintf("rm -f %s", shellQuote(keyPath)),
+	}
+	if deleteFiles {
+		lines = append(lines, fmt.Sprintf("rm -rf %s", shellQuote(themePath)))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -1,6 +1,11 @@
 package cli
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -111,3 +116,184 @@ func TestResolveThemeSpecsRejectsDuplicatePaths(t *testing.T) {
 		t.Fatal("resolveThemeSpecs() error = nil, want duplicate path error")
 	}
 }
+
+func TestShellUnquoteRoundTrip(t *testing.T) {
+	for _, in := range []string{"/home/discourse/my-theme", "it's here", "", `a "b" c`} {
+		if got := shellUnquote(shellQuote(in)); got != in {
+			t.Fatalf("shellUnquote(shellQuote(%q)) = %q", in, got)
+		}
+	}
+	if got := shellUnquote(`"/home/discourse/x"`); got != "/home/discourse/x" {
+		t.Fatalf("double-quoted: got %q", got)
+	}
+}
+
+func TestParseThemeWatcherList(t *testing.T) {
+	out := "theme-watch-zeta\t'/home/discourse/zeta'\tdown: theme-watch-zeta: 3s, normally up\n" +
+		"garbage line\n" +
+		"theme-watch-alpha\t'/home/discourse/alpha'\trun: theme-watch-alpha: (pid 42) 120s\n"
+	got := parseThemeWatcherList(out)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 watchers, got %d: %+v", len(got), got)
+	}
+	if got[0].Slug != "alpha" || got[0].Path != "/home/discourse/alpha" || got[0].Service != "theme-watch-alpha" || got[0].State() != "run" {
+		t.Fatalf("unexpected first watcher: %+v", got[0])
+	}
+	if got[1].Slug != "zeta" || got[1].State() != "down" {
+		t.Fatalf("unexpected second watcher: %+v", got[1])
+	}
+}
+
+func TestValidateThemeRemovalPath(t *testing.T) {
+	for _, p := range []string{"/home/discourse/my-theme", "/home/discourse/nested/theme"} {
+		if err := validateThemeRemovalPath(p); err != nil {
+			t.Fatalf("validateThemeRemovalPath(%q) unexpected error: %v", p, err)
+		}
+	}
+	for _, p := range []string{"/", "/home/discourse", "/home/discourse/", "/var/www/discourse", "/home/discourse/../root"} {
+		if err := validateThemeRemovalPath(p); err == nil {
+			t.Fatalf("validateThemeRemovalPath(%q) expected error", p)
+		}
+	}
+}
+
+func TestThemeRemovalScript(t *testing.T) {
+	script := themeRemovalScript("theme-watch-foo", "/home/discourse/.dv/theme_api_keys/foo.key", "/home/discourse/foo", false)
+	if !strings.Contains(script, "sv force-stop 'theme-watch-foo'") || !strings.Contains(script, "rm -rf '/etc/service/theme-watch-foo'") {
+		t.Fatalf("script missing service teardown:\n%s", script)
+	}
+	if strings.Contains(script, "rm -rf '/home/discourse/foo'") {
+		t.Fatalf("script should not delete theme files without deleteFiles:\n%s", script)
+	}
+	script = themeRemovalScript("theme-watch-foo", "/k", "/home/discourse/foo", true)
+	if !strings.Contains(script, "rm -rf '/home/discourse/foo'") {
+		t.Fatalf("script missing theme directory removal:\n%s", script)
+	}
+}
+
+func TestWriteThemeSkeletonFull(t *testing.T) {
+	payload := themeSkeletonPayload{DisplayName: "My Theme", ThemePath: "/home/discourse/my-theme"}
+
+	minimal := t.TempDir()
+	if err := writeThemeSkeleton(minimal, payload); err != nil {
+		t.Fatalf("writeThemeSkeleton minimal: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(minimal, "settings.yml")); !os.IsNotExist(err) {
+		t.Fatalf("minimal skeleton should not include settings.yml (err=%v)", err)
+	}
+
+	full := t.TempDir()
+	payload.Full = true
+	if err := writeThemeSkeleton(full, payload); err != nil {
+		t.Fatalf("writeThemeSkeleton full: %v", err)
+	}
+	for _, rel := range []string{
+		"common/common.scss",
+		"desktop/desktop.scss",
+		"mobile/mobile.scss",
+		"settings.yml",
+		"locales/en.yml",
+		"javascripts/discourse/api-initializers/my-theme.gjs",
+	} {
+		if _, err := os.Stat(filepath.Join(full, filepath.FromSlash(rel))); err != nil {
+			t.Fatalf("expected %s in full skeleton: %v", rel, err)
+		}
+	}
+}
+
+func TestInternalDiscoursePortCandidates(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []int
+	}{
+		{"", []int{3000, 4200}},
+		{"9292", []int{9292, 3000, 4200}},
+		{"3000", []int{3000, 4200}},
+	}
+	for _, tt := range tests {
+		got, err := internalDiscoursePortCandidates(tt.in)
+		if err != nil {
+			t.Fatalf("internalDiscoursePortCandidates(%q) error: %v", tt.in, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("internalDiscoursePortCandidates(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	if _, err := internalDiscoursePortCandidates("abc"); err == nil {
+		t.Fatal("expected error for non-numeric UNICORN_PORT")
+	}
+}
+
+func TestParseThemeWorkspaceProbe(t *testing.T) {
+	if ws := parseThemeWorkspaceProbe(""); ws.Exists {
+		t.Fatalf("empty output should mean missing workspace: %+v", ws)
+	}
+	ws := parseThemeWorkspaceProbe("__DV_EXISTS__\n__DV_GIT__\n__DV_REMOTE__https://github.com/discourse/foo.git\n__DV_BRANCH__main\n__DV_COMPONENT__\n")
+	want := existingThemeWorkspace{Exists: true, IsGitRepo: true, IsComponent: true, RepoURL: "https://github.com/discourse/foo.git", Branch: "main"}
+	if ws != want {
+		t.Fatalf("parseThemeWorkspaceProbe() = %+v, want %+v", ws, want)
+	}
+	ws = parseThemeWorkspaceProbe("__DV_EXISTS__\n__DV_GIT__\n__DV_REMOTE__\n__DV_BRANCH__HEAD\n")
+	if ws.RepoURL != "" || ws.Branch != "" || ws.IsComponent {
+		t.Fatalf("detached scaffold without remote parsed as %+v", ws)
+	}
+}
+
+func TestWriteThemeSkeletonGitignoreAndLicense(t *testing.T) {
+	root := t.TempDir()
+	payload := themeSkeletonPayload{DisplayName: "My Theme", ThemePath: "/home/discourse/my-theme", License: "MIT"}
+	if err := writeThemeSkeleton(root, payload); err != nil {
+		t.Fatalf("writeThemeSkeleton: %v", err)
+	}
+	gitignore, err := os.ReadFile(filepath.Join(root, ".gitignore"))
+	if err != nil || !strings.Contains(string(gitignore), "node_modules/") {
+		t.Fatalf(".gitignore missing node_modules (err=%v): %q", err, gitignore)
+	}
+	license, err := os.ReadFile(filepath.Join(root, "LICENSE"))
+	if err != nil || !strings.Contains(string(license), "MIT License") || !strings.Contains(string(license), "My Theme contributors") {
+		t.Fatalf("unexpected LICENSE (err=%v): %q", err, license)
+	}
+	var about map[string]any
+	data, _ := os.ReadFile(filepath.Join(root, "about.json"))
+	if err := json.Unmarshal(data, &about); err != nil {
+		t.Fatalf("about.json: %v", err)
+	}
+	if about["license_url"] != mitLicenseURL {
+		t.Fatalf("license_url = %v, want %s", about["license_url"], mitLicenseURL)
+	}
+}
+
+func TestNormalizeThemeLicense(t *testing.T) {
+	for in, want := range map[string]string{"": "", "mit": "MIT", " MIT ": "MIT"} {
+		got, err := normalizeThemeLicense(in)
+		if err != nil || got != want {
+			t.Fatalf("normalizeThemeLicense(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := normalizeThemeLicense("GPL-3.0"); err == nil {
+		t.Fatal("expected error for unsupported license")
+	}
+}
+
+func TestWriteThemeSkeletonComponentModifiers(t *testing.T) {
+	root := t.TempDir()
+	payload := themeSkeletonPayload{DisplayName: "Widget", ThemePath: "/home/discourse/widget", IsComponent: true, ParentTheme: "Horizon"}
+	if err := writeThemeSkeleton(root, payload); err != nil {
+		t.Fatalf("writeThemeSkeleton: %v", err)
+	}
+	var about map[string]any
+	data, _ := os.ReadFile(filepath.Join(root, "about.json"))
+	if err := json.Unmarshal(data, &about); err != nil {
+		t.Fatalf("about.json: %v", err)
+	}
+	if about["component"] != true {
+		t.Fatalf("component = %v, want true", about["component"])
+	}
+	if _, ok := about["modifiers"].(map[string]any); !ok {
+		t.Fatalf("expected modifiers object in about.json, got %v", about["modifiers"])
+	}
+	agents, _ := os.ReadFile(filepath.Join(root, "AGENTS.md"))
+	if !strings.Contains(string(agents), "Parent theme: `Horizon`") {
+		t.Fatalf("AGENTS.md does not mention parent theme:\n%s", agents)
+	}
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"dv/internal/docker"
+)
+
+var configThemeWatchCmd = &cobra.Command{
+	Use:   "watch SLUG",
+	Short: "Show, restart, or tail the logs of a theme watcher service",
+	Long: `Without flags, prints 'sv status' for the theme-watch-SLUG service.
+--restart restarts the watcher and --logs follows its svlogd output.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		restart, _ := cmd.Flags().GetBool("restart")
+		logs, _ := cmd.Flags().GetBool("logs")
+		lines, _ := cmd.Flags().GetInt("lines")
+
+		ctx, ok, err := loadThemeCommandContext(cmd)
+		if err != nil || !ok {
+			return err
+		}
+		serviceName := fmt.Sprintf("theme-watch-%s", themeDirSlug(args[0]))
+		serviceDir := path.Join("/etc/service", serviceName)
+		if _, err := docker.ExecAsRoot(ctx.containerName, "/", nil, []string{"test", "-d", serviceDir}); err != nil {
+			return fmt.Errorf("no watcher service %s in container %s; see 'dv config theme list'", serviceName, ctx.containerName)
+		}
+
+		if restart {
+			fmt.Fprintf(cmd.OutOrStdout(), "Restarting %s...\n", serviceName)
+			if out, err := docker.ExecAsRoot(ctx.containerName, "/", nil, []string{"sv", "restart", serviceName}); err != nil {
+				if trimmed := strings.TrimSpace(out); trimmed != "" {
+					return fmt.Errorf("sv restart %s failed: %w: %s", serviceName, err, trimmed)
+				}
+				return fmt.Errorf("sv restart %s failed: %w", serviceName, err)
+			}
+		}
+
+		if !logs {
+			out, err := docker.ExecAsRoot(ctx.containerName, "/", nil, []string{"sv", "status", serviceName})
+			if trimmed := strings.TrimSpace(out); trimmed != "" {
+				fmt.Fprintln(cmd.OutOrStdout(), trimmed)
+			}
+			if err != nil {
+				return fmt.Errorf("sv status %s failed: %w", serviceName, err)
+			}
+			return nil
+		}
+
+		logFile := path.Join(themeWatcherLogDir(serviceName), "current")
+		if _, err := docker.ExecAsRoot(ctx.containerName, "/", nil, []string{"test", "-f", logFile}); err != nil {
+			return fmt.Errorf("no log at %s; watchers created by older dv versions log to the container output instead (try 'docker logs %s')", logFile, ctx.containerName)
+		}
+		if lines <= 0 {
+			lines = 50
+		}
+		return docker.ExecStream(ctx.containerName, "/", nil, []string{"tail", "-n", strconv.Itoa(lines), "-F", logFile}, cmd.OutOrStdout(), cmd.ErrOrStderr())
+	},
+}
+
+func init() {
+	configThemeWatchCmd.Flags().Bool("restart", false, "Restart the watcher service")
+	configThemeWatchCmd.Flags().Bool("logs", false, "Follow the watcher service log")
+	configThemeWatchCmd.Flags().IntP("lines", "n", 50, "Number of existing log lines to show with --logs")
+	configThemeCmd.AddCommand(configThemeWatchCmd)
+}
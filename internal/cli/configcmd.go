@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"reflect"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"dv/internal/config"
+	"dv/internal/docker"
 	"dv/internal/xdg"
 )
 
@@ -18,14 +21,10 @@ var configCmd = &cobra.Command{
 }
 
 var configGetCmd = &cobra.Command{
-	Use:   "get KEY",
-	Short: "Get a config value",
-	Args:  cobra.ExactArgs(1),
-	ValidArgs: []string{
-		"imageTag", "defaultContainerName", "workdir", "customWorkdir",
-		"hostStartingPort", "containerPort", "selectedAgent", "discourseRepo",
-		"extractBranchPrefix", "defaultTemplate", "hooks",
-	},
+	Use:       "get KEY",
+	Short:     "Get a config value",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: settableConfigKeyNames(),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		configDir, err := xdg.ConfigDir()
 		if err != nil {
@@ -46,14 +45,10 @@ var configGetCmd = &cobra.Command{
 }
 
 var configSetCmd = &cobra.Command{
-	Use:   "set KEY VALUE",
-	Short: "Set a config value",
-	Args:  cobra.ExactArgs(2),
-	ValidArgs: []string{
-		"imageTag", "defaultContainerName", "workdir", "customWorkdir",
-		"hostStartingPort", "containerPort", "selectedAgent", "discourseRepo",
-		"extractBranchPrefix", "defaultTemplate", "hooks",
-	},
+	Use:       "set KEY VALUE",
+	Short:     "Set a config value",
+	Args:      cobra.ExactArgs(2),
+	ValidArgs: settableConfigKeyNames(),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		configDir, err := xdg.ConfigDir()
 		if err != nil {
@@ -167,6 +162,82 @@ func init() {
 	configCmd.AddCommand(configResetCmd)
 }
 
+// configKeyInfo describes a config key supported by getConfigField and
+// setConfigField. Keys are the JSON names used in config.json.
+type configKeyInfo struct {
+	Key         string
+	Description string
+}
+
+// settableConfigKeys lists every key setConfigField accepts, in display order.
+var settableConfigKeys = []configKeyInfo{
+	{Key: "imageTag", Description: "Legacy Docker image tag used when no image is selected"},
+	{Key: "defaultContainerName", Description: "Container name used when no agent is selected"},
+	{Key: "workdir", Description: "Default working directory inside containers"},
+	{Key: "customWorkdir", Description: "Global working directory override for all containers"},
+	{Key: "hostStartingPort", Description: "First host port to try when publishing a container"},
+	{Key: "containerPort", Description: "Port Discourse listens on inside the container"},
+	{Key: "selectedAgent", Description: "Currently selected container"},
+	{Key: "discourseRepo", Description: "Git URL of the Discourse repository"},
+	{Key: "extractBranchPrefix", Description: "Branch prefix used by dv extract"},
+	{Key: "defaultTemplate", Description: "Template applied by dv new when none is given"},
+	{Key: "containerMemory", Description: "Memory limit for new containers, e.g. 2g (empty means unlimited)"},
+	{Key: "containerCpus", Description: "CPU limit for new containers, e.g. 1.5 (empty means unlimited)"},
+	{Key: "gitUserName", Description: "git user.name configured in new agents"},
+	{Key: "gitUserEmail", Description: "git user.email configured in new agents"},
+	{Key: "buildCacheFrom", Description: "buildx cache to read when building images (directory, registry ref, or type=... spec)"},
+	{Key: "buildCacheTo", Description: "buildx cache to write when building images (directory, registry ref, or type=... spec)"},
+	{Key: "buildPlatform", Description: "Default --platform for image builds, e.g. linux/amd64 (empty means the host's)"},
+	{Key: "hooks", Description: "Host-side lifecycle hooks (JSON)"},
+}
+
+func settableConfigKeyNames() []string {
+	names := make([]string, 0, len(settableConfigKeys))
+	for _, k := range settableConfigKeys {
+		names = append(names, k.Key)
+	}
+	return names
+}
+
+// configSchemaField is the serve API representation of a settable config key.
+type configSchemaField struct {
+	Key         string      `json:"key"`
+	Type        string      `json:"type"`
+	Value       interface{} `json:"value"`
+	Description string      `json:"description"`
+}
+
+// configSchema describes each settable key along with its Go type (derived
+// from the config.Config field carrying the matching JSON tag) and current value.
+func configSchema(cfg config.Config) []configSchemaField {
+	fields := configFieldsByJSONName()
+	v := reflect.ValueOf(cfg)
+	out := make([]configSchemaField, 0, len(settableConfigKeys))
+	for _, k := range settableConfigKeys {
+		entry := configSchemaField{Key: k.Key, Description: k.Description}
+		if idx, ok := fields[k.Key]; ok {
+			entry.Type = v.Field(idx).Type().String()
+			entry.Value = v.Field(idx).Interface()
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// configFieldsByJSONName maps config.Config JSON field names to struct field indexes.
+func configFieldsByJSONName() map[string]int {
+	t := reflect.TypeOf(config.Config{})
+	out := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		out[name] = i
+	}
+	return out
+}
+
 func getConfigField(cfg config.Config, key string) (string, error) {
 	switch key {
 	case "imageTag":
@@ -189,6 +260,20 @@ func getConfigField(cfg config.Config, key string) (string, error) {
 		return cfg.ExtractBranchPrefix, nil
 	case "defaultTemplate":
 		return cfg.DefaultTemplate, nil
+	case "containerMemory":
+		return cfg.ContainerMemory, nil
+	case "containerCpus":
+		return cfg.ContainerCPUs, nil
+	case "gitUserName":
+		return cfg.GitUserName, nil
+	case "gitUserEmail":
+		return cfg.GitUserEmail, nil
+	case "buildCacheFrom":
+		return cfg.BuildCacheFrom, nil
+	case "buildCacheTo":
+		return cfg.BuildCacheTo, nil
+	case "buildPlatform":
+		return cfg.BuildPlatform, nil
 	case "hooks":
 		b, err := json.MarshalIndent(cfg.Hooks, "", "  ")
 		if err != nil {
@@ -232,6 +317,35 @@ func setConfigField(cfg *config.Config, key, val string) error {
 		cfg.ExtractBranchPrefix = val
 	case "defaultTemplate":
 		cfg.DefaultTemplate = val
+	case "containerMemory":
+		if err := docker.ValidateMemoryLimit(val); err != nil {
+			return err
+		}
+		cfg.ContainerMemory = val
+	case "containerCpus":
+		if err := docker.ValidateCPULimit(val); err != nil {
+			return err
+		}
+		cfg.ContainerCPUs = val
+	case "gitUserName":
+		cfg.GitUserName = val
+	case "gitUserEmail":
+		cfg.GitUserEmail = val
+	case "buildCacheFrom":
+		if _, err := docker.NormalizeBuildCacheSpec(val, false); err != nil {
+			return err
+		}
+		cfg.BuildCacheFrom = val
+	case "buildCacheTo":
+		if _, err := docker.NormalizeBuildCacheSpec(val, true); err != nil {
+			return err
+		}
+		cfg.BuildCacheTo = val
+	case "buildPlatform":
+		if err := docker.ValidatePlatform(val); err != nil {
+			return err
+		}
+		cfg.BuildPlatform = val
 	case "hooks":
 		var hooks config.HooksConfig
 		if err := json.Unmarshal([]byte(val), &hooks); err != nil {
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"dv/internal/config"
+)
+
+// validatedSampleValues are valid samples for keys whose values are checked
+// beyond their Go type.
+var validatedSampleValues = map[string]string{
+	"containerMemory": "2g",
+	"containerCpus":   "1.5",
+	"buildPlatform":   "linux/amd64",
+}
+
+// sampleConfigValue returns a string setConfigField can parse for key, a
+// field of type t.
+func sampleConfigValue(key string, t reflect.Type) string {
+	if v, ok := validatedSampleValues[key]; ok {
+		return v
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		return "4200"
+	case reflect.Struct, reflect.Map:
+		return "{}"
+	case reflect.Slice:
+		return "[]"
+	default:
+		return "sample"
+	}
+}
+
+func TestConfigSchemaCoversEverySettableKey(t *testing.T) {
+	t.Parallel()
+
+	inSchema := map[string]bool{}
+	for _, f := range configSchema(config.Config{}) {
+		inSchema[f.Key] = true
+	}
+
+	cfgType := reflect.TypeOf(config.Config{})
+	for name, idx := range configFieldsByJSONName() {
+		cfg := config.Config{}
+		err := setConfigField(&cfg, name, sampleConfigValue(name, cfgType.Field(idx).Type))
+		if err != nil && strings.Contains(err.Error(), "unknown key") {
+			continue
+		}
+		if !inSchema[name] {
+			t.Errorf("setConfigField accepts %q but it is missing from settableConfigKeys", name)
+		}
+	}
+}
+
+func TestConfigSchemaKeysAreSettable(t *testing.T) {
+	t.Parallel()
+
+	cfgType := reflect.TypeOf(config.Config{})
+	fields := configFieldsByJSONName()
+	for _, f := range configSchema(config.Config{}) {
+		idx, ok := fields[f.Key]
+		if !ok {
+			t.Errorf("schema key %q does not map to a config.Config field", f.Key)
+			continue
+		}
+		if f.Type != cfgType.Field(idx).Type.String() {
+			t.Errorf("schema key %q type = %q, want %q", f.Key, f.Type, cfgType.Field(idx).Type.String())
+		}
+		if f.Description == "" {
+			t.Errorf("schema key %q is missing a description", f.Key)
+		}
+		cfg := config.Config{}
+		if err := setConfigField(&cfg, f.Key, sampleConfigValue(f.Key, cfgType.Field(idx).Type)); err != nil {
+			t.Errorf("setConfigField(%q) failed: %v", f.Key, err)
+		}
+	}
+}
+
+func TestConfigSchemaReportsCurrentValues(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{ContainerPort: 4200, SelectedAgent: "agent-1"}
+	values := map[string]interface{}{}
+	for _, f := range configSchema(cfg) {
+		values[f.Key] = f.Value
+	}
+	if values["containerPort"] != 4200 {
+		t.Errorf("containerPort value = %v, want 4200", values["containerPort"])
+	}
+	if values["selectedAgent"] != "agent-1" {
+		t.Errorf("selectedAgent value = %v, want agent-1", values["selectedAgent"])
+	}
+}
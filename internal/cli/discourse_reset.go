@@ -155,25 +155,53 @@ func buildDiscourseResetScript(checkoutCmds []string, opts discourseResetScriptO
 }
 
 // buildPRCheckoutCommands generates git commands to fetch and checkout a PR.
-// It uses the actual branch name from GitHub to maintain branch identity.
-func buildPRCheckoutCommands(prNumber int, branchName string) []string {
-	refspec := fmt.Sprintf("+refs/pull/%d/head:refs/remotes/origin/pull/%d/head", prNumber, prNumber)
-	prRef := fmt.Sprintf("refs/pull/%d/head", prNumber)
+// It uses the actual branch name from the forge to maintain branch identity.
+// prRef is where the forge publishes the PR head (see prForge.prRef). Those
+// refs only exist on the repository the PR was opened against, so when the
+// checkout is a fork with an upstream remote the PR is fetched from there.
+func buildPRCheckoutCommands(prNumber int, prRef, branchName string) []string {
 
 	return []string{
 		fmt.Sprintf("pr_branch=%s", shellQuote(branchName)),
 		fmt.Sprintf("pr_ref=%s", shellQuote(prRef)),
-		fmt.Sprintf("pr_refspec=%s", shellQuote(refspec)),
-		fmt.Sprintf("echo 'Fetching PR #%d (branch: %s) from origin...'", prNumber, branchName),
-		"if ! git config --get-all remote.origin.fetch | grep -qxF \"$pr_refspec\"; then git config --add remote.origin.fetch \"$pr_refspec\"; fi",
-		"git fetch origin \"$pr_refspec\"",
-		fmt.Sprintf("git checkout -B \"$pr_branch\" origin/pull/%d/head", prNumber),
-		"git config branch.\"${pr_branch}\".remote origin",
+		"pr_remote=origin",
+		"if git remote get-url upstream >/dev/null 2>&1; then pr_remote=upstream; fi",
+		fmt.Sprintf("pr_refspec=\"+${pr_ref}:refs/remotes/${pr_remote}/pull/%d/head\"", prNumber),
+		fmt.Sprintf("printf 'Fetching PR #%d (branch: %%s) from %%s...\\n' \"$pr_branch\" \"$pr_remote\"", prNumber),
+		"if ! git config --get-all \"remote.${pr_remote}.fetch\" | grep -qxF \"$pr_refspec\"; then git config --add \"remote.${pr_remote}.fetch\" \"$pr_refspec\"; fi",
+		"git fetch \"$pr_remote\" \"$pr_refspec\"",
+		fmt.Sprintf("git checkout -B \"$pr_branch\" \"${pr_remote}/pull/%d/head\"", prNumber),
+		"git config branch.\"${pr_branch}\".remote \"$pr_remote\"",
 		"git config branch.\"${pr_branch}\".merge \"$pr_ref\"",
-		fmt.Sprintf("echo \"Branch ${pr_branch} now tracks origin/pull/%d/head for git pull.\"", prNumber),
+		fmt.Sprintf("echo \"Branch ${pr_branch} now tracks ${pr_remote}/pull/%d/head for git pull.\"", prNumber),
 	}
 }
 
+// buildPRNewBranchCommands fetches PR prNumber's base branch (or, with
+// fromHead, its head) from the remote the PR was opened against and creates
+// branchName from it, leaving the PR branch itself alone.
+func buildPRNewBranchCommands(prNumber int, prRef, baseRef, branchName string, fromHead bool) []string {
+	cmds := []string{
+		"pr_remote=origin",
+		"if git remote get-url upstream >/dev/null 2>&1; then pr_remote=upstream; fi",
+	}
+	var startRef string
+	if fromHead {
+		cmds = append(cmds,
+			fmt.Sprintf("printf 'Fetching PR #%d head from %%s...\\n' \"$pr_remote\"", prNumber),
+			fmt.Sprintf("git fetch \"$pr_remote\" \"+%s:refs/remotes/${pr_remote}/pull/%d/head\"", prRef, prNumber),
+		)
+		startRef = fmt.Sprintf("\"${pr_remote}/pull/%d/head\"", prNumber)
+	} else {
+		cmds = append(cmds,
+			fmt.Sprintf("printf 'Fetching PR #%d base %%s from %%s...\\n' %s \"$pr_remote\"", prNumber, shellQuote(baseRef)),
+			fmt.Sprintf("git fetch \"$pr_remote\" %s", shellQuote(baseRef)),
+		)
+		startRef = "\"${pr_remote}/\"" + shellQuote(baseRef)
+	}
+	return append(cmds, buildNewBranchFromRefCommands(branchName, startRef)...)
+}
+
 // buildBranchCheckoutCommands generates git commands to checkout a branch.
 func buildBranchCheckoutCommands(branchName string) []string {
 	return []string{
@@ -188,9 +216,23 @@ func buildBranchCheckoutCommands(branchName string) []string {
 // a new branch from the default remote branch (origin/main or origin/master).
 // If the branch already exists locally, it just switches to it and warns if out of sync.
 func buildNewBranchCheckoutCommands(branchName string) []string {
+	return buildNewBranchFromRefCommands(branchName, "")
+}
+
+// buildNewBranchFromRefCommands is buildNewBranchCheckoutCommands starting
+// from startRef, a shell word naming a commit-ish (it may expand variables
+// set by earlier commands), instead of the default remote branch. The caller
+// is expected to have fetched startRef; it is not re-fetched with --prune,
+// which would drop refs outside the remote's fetch refspec such as PR heads.
+func buildNewBranchFromRefCommands(branchName, startRef string) []string {
 	quotedBranch := shellQuote(branchName)
-	return []string{
-		"git fetch origin --tags --prune",
+	var cmds []string
+	chooseStart := "  default_ref=" + startRef
+	if startRef == "" {
+		cmds = append(cmds, "git fetch origin --tags --prune")
+		chooseStart = "  if git show-ref -q refs/remotes/origin/main; then default_ref=origin/main; else default_ref=origin/master; fi"
+	}
+	return append(cmds,
 		fmt.Sprintf("_branch=%s", quotedBranch),
 		"if git show-ref -q \"refs/heads/$_branch\"; then",
 		"  printf 'Branch %s already exists locally, switching to it...\\n' \"$_branch\"",
@@ -209,11 +251,11 @@ func buildNewBranchCheckoutCommands(branchName string) []string {
 		"    fi",
 		"  fi",
 		"else",
-		"  printf 'Creating new branch %s from origin...\\n' \"$_branch\"",
-		"  if git show-ref -q refs/remotes/origin/main; then default_ref=origin/main; else default_ref=origin/master; fi",
+		chooseStart,
+		"  printf 'Creating new branch %s from %s...\\n' \"$_branch\" \"$default_ref\"",
 		"  git checkout -b \"$_branch\" \"$default_ref\"",
 		"fi",
-	}
+	)
 }
 
 // buildCurrentBranchResetCommands generates commands to reset the current branch
@@ -50,3 +50,36 @@ func TestBuildMaintenanceScript_WithTestDB(t *testing.T) {
 		t.Fatal("missing test database migration")
 	}
 }
+
+func TestBuildPRCheckoutCommands_PrefersUpstreamRemote(t *testing.T) {
+	t.Parallel()
+
+	script := strings.Join(buildPRCheckoutCommands(42, "refs/pull/42/head", "feature"), "\n")
+
+	if !strings.Contains(script, "git remote get-url upstream") {
+		t.Fatalf("missing upstream remote detection:\n%s", script)
+	}
+	if !strings.Contains(script, `git fetch "$pr_remote" "$pr_refspec"`) {
+		t.Fatalf("PR should be fetched from the detected remote:\n%s", script)
+	}
+	if strings.Contains(script, "refs/remotes/origin/") {
+		t.Fatalf("refspec should not hard-code origin:\n%s", script)
+	}
+}
+
+func TestBuildPRNewBranchCommands(t *testing.T) {
+	t.Parallel()
+
+	base := strings.Join(buildPRNewBranchCommands(42, "refs/pull/42/head", "main", "extend", false), "\n")
+	if !strings.Contains(base, `git fetch "$pr_remote" 'main'`) || !strings.Contains(base, `default_ref="${pr_remote}/"'main'`) {
+		t.Fatalf("branch should start from the PR base on the PR remote:\n%s", base)
+	}
+	if strings.Contains(base, "--prune") {
+		t.Fatalf("PR refs must not be pruned after fetching:\n%s", base)
+	}
+
+	head := strings.Join(buildPRNewBranchCommands(42, "refs/pull/42/head", "main", "extend", true), "\n")
+	if !strings.Contains(head, `"+refs/pull/42/head:refs/remotes/${pr_remote}/pull/42/head"`) || !strings.Contains(head, `default_ref="${pr_remote}/pull/42/head"`) {
+		t.Fatalf("--from-head should branch from the fetched PR head:\n%s", head)
+	}
+}
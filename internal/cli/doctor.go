@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"dv/internal/config"
+	"dv/internal/docker"
+	"dv/internal/localproxy"
+	"dv/internal/xdg"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that docker, buildx, SSH agent, proxy and config are ready for dv",
+	Long: `Run read-only checks of the environment dv depends on and print a checklist
+with a hint for each problem. Nothing is created, started or rewritten.
+
+Exits non-zero when any check fails; warnings do not affect the exit code.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configDir, err := xdg.ConfigDir()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
+		defer cancel()
+
+		results := runDoctorChecks(ctx, defaultDoctorProbes(configDir))
+		failed := printDoctorResults(cmd.OutOrStdout(), results)
+		if failed > 0 {
+			return fmt.Errorf("%d check(s) failed", failed)
+		}
+		return nil
+	},
+}
+
+type doctorStatus int
+
+const (
+	doctorPass doctorStatus = iota
+	doctorWarn
+	doctorFail
+	doctorSkip
+)
+
+// doctorResult is one line of the `dv doctor` checklist.
+type doctorResult struct {
+	Name   string
+	Status doctorStatus
+	Detail string
+	Hint   string
+}
+
+// doctorProbes are the read-only lookups dv doctor makes, swappable in tests.
+type doctorProbes struct {
+	configPath    string
+	loadConfig    func() (config.Config, error)
+	lookPath      func(string) (string, error)
+	serverVersion func(context.Context) (string, error)
+	buildx        func() error
+	sshAuthSock   string
+	sshSocket     func(sshAuthSock string) (socket, source string)
+	socketExists  func(path string) bool
+	proxyRunning  func(config.LocalProxyConfig) bool
+	imageExists   func(tag string) bool
+}
+
+func defaultDoctorProbes(configDir string) doctorProbes {
+	return doctorProbes{
+		configPath:    config.Path(configDir),
+		loadConfig:    func() (config.Config, error) { return config.Load(configDir) },
+		lookPath:      exec.LookPath,
+		serverVersion: docker.ServerVersion,
+		buildx:        docker.CheckBuildx,
+		sshAuthSock:   os.Getenv("SSH_AUTH_SOCK"),
+		sshSocket:     docker.SSHAgentSocket,
+		socketExists: func(path string) bool {
+			_, err := os.Stat(path)
+			return err == nil
+		},
+		proxyRunning: localproxy.Running,
+		imageExists:  docker.ImageExists,
+	}
+}
+
+func runDoctorChecks(ctx context.Context, p doctorProbes) []doctorResult {
+	var results []doctorResult
+	add := func(r doctorResult) { results = append(results, r) }
+
+	cfg, cfgResult := doctorConfigCheck(p)
+	dockerOK := false
+	if path, err := p.lookPath("docker"); err != nil {
+		add(doctorResult{Name: "docker CLI", Status: doctorFail, Detail: "docker not found on PATH",
+			Hint: "Install Docker Desktop, OrbStack, or docker engine, and make sure `docker` is on PATH."})
+	} else {
+		add(doctorResult{Name: "docker CLI", Status: doctorPass, Detail: path})
+		if version, err := p.serverVersion(ctx); err != nil {
+			add(doctorResult{Name: "docker daemon", Status: doctorFail, Detail: firstLine(err.Error()),
+				Hint: "Start Docker (or your docker VM) and check that `docker ps` works for this user."})
+		} else {
+			dockerOK = true
+			add(doctorResult{Name: "docker daemon", Status: doctorPass, Detail: "server " + version})
+		}
+	}
+
+	if !dockerOK {
+		add(doctorResult{Name: "docker buildx", Status: doctorSkip, Detail: "needs a reachable docker daemon"})
+	} else if err := p.buildx(); err != nil {
+		add(doctorResult{Name: "docker buildx", Status: doctorWarn, Detail: "`docker buildx version` failed; builds fall back to classic docker build",
+			Hint: "Install the buildx plugin (docker-buildx) for BuildKit caching and --platform builds."})
+	} else {
+		add(doctorResult{Name: "docker buildx", Status: doctorPass, Detail: "available"})
+	}
+
+	add(doctorSSHAgentCheck(p))
+	add(cfgResult)
+
+	switch {
+	case !cfg.LocalProxy.Enabled:
+		add(doctorResult{Name: "local proxy", Status: doctorSkip, Detail: "not enabled"})
+	case !dockerOK:
+		add(doctorResult{Name: "local proxy", Status: doctorSkip, Detail: "needs a reachable docker daemon"})
+	case p.proxyRunning(cfg.LocalProxy):
+		add(doctorResult{Name: "local proxy", Status: doctorPass, Detail: cfg.LocalProxy.ContainerName + " is running"})
+	default:
+		add(doctorResult{Name: "local proxy", Status: doctorWarn, Detail: cfg.LocalProxy.ContainerName + " is not running; agents fall back to host ports",
+			Hint: "Run `dv config local-proxy` to start it again."})
+	}
+
+	imgName, imgCfg, err := resolveImage(cfg, "")
+	switch {
+	case err != nil:
+		add(doctorResult{Name: "selected image", Status: doctorFail, Detail: err.Error(),
+			Hint: "Pick a configured image with `dv image select NAME`."})
+	case !dockerOK:
+		add(doctorResult{Name: "selected image", Status: doctorSkip, Detail: "needs a reachable docker daemon"})
+	case !p.imageExists(imgCfg.Tag):
+		add(doctorResult{Name: "selected image", Status: doctorFail, Detail: fmt.Sprintf("%s (%s) has not been built", imgName, imgCfg.Tag),
+			Hint: "Run `dv pull` to download the prebuilt image, or `dv build` to build it locally."})
+	default:
+		add(doctorResult{Name: "selected image", Status: doctorPass, Detail: fmt.Sprintf("%s (%s)", imgName, imgCfg.Tag)})
+	}
+	return results
+}
+
+// doctorConfigCheck loads the config without writing it. It returns the
+// defaults when the file is missing or broken so later checks still run.
+func doctorConfigCheck(p doctorProbes) (config.Config, doctorResult) {
+	cfg, err := p.loadConfig()
+	if errors.Is(err, fs.ErrNotExist) {
+		return config.Default(), doctorResult{Name: "config", Status: doctorWarn, Detail: p.configPath + " does not exist yet",
+			Hint: "It is created with defaults the first time another dv command runs."}
+	}
+	if err != nil {
+		return config.Default(), doctorResult{Name: "config", Status: doctorFail, Detail: err.Error(),
+			Hint: "Fix it with `dv config edit`, or start over with `dv config reset`."}
+	}
+	var problems []string
+	if cfg.Version > config.CurrentVersion {
+		problems = append(problems, fmt.Sprintf("written by a newer dv (version %d)", cfg.Version))
+	}
+	for key, validate := range map[string]func(string) error{
+		"buildPlatform":   docker.ValidatePlatform,
+		"containerMemory": docker.ValidateMemoryLimit,
+		"containerCpus":   docker.ValidateCPULimit,
+	} {
+		val, _ := getConfigField(cfg, key)
+		if err := validate(val); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", key, err))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return cfg, doctorResult{Name: "config", Status: doctorFail, Detail: strings.Join(problems, "; "),
+			Hint: "Correct the values with `dv config set KEY VALUE`, or upgrade dv."}
+	}
+	return cfg, doctorResult{Name: "config", Status: doctorPass, Detail: p.configPath}
+}
+
+func doctorSSHAgentCheck(p doctorProbes) doctorResult {
+	if strings.TrimSpace(p.sshAuthSock) == "" {
+		return doctorResult{Name: "SSH agent", Status: doctorWarn, Detail: "SSH_AUTH_SOCK is not set; containers get no SSH agent",
+			Hint: "Start ssh-agent and `ssh-add` your key if agents need to push over SSH."}
+	}
+	socket, source := p.sshSocket(p.sshAuthSock)
+	if !p.socketExists(socket) {
+		return doctorResult{Name: "SSH agent", Status: doctorWarn, Detail: fmt.Sprintf("%s (%s) does not exist", socket, source),
+			Hint: "Restart your SSH agent, or fix IdentityAgent in ~/.ssh/config."}
+	}
+	return doctorResult{Name: "SSH agent", Status: doctorPass, Detail: fmt.Sprintf("%s (%s)", socket, source)}
+}
+
+// printDoctorResults writes the checklist and returns how many checks failed.
+func printDoctorResults(w io.Writer, results []doctorResult) int {
+	failed := 0
+	for _, r := range results {
+		mark := "✓"
+		switch r.Status {
+		case doctorWarn:
+			mark = "!"
+		case doctorFail:
+			mark = "✗"
+			failed++
+		case doctorSkip:
+			mark = "-"
+		}
+		fmt.Fprintf(w, "%s %s: %s\n", mark, r.Name, r.Detail)
+		if r.Hint != "" && (r.Status == doctorWarn || r.Status == doctorFail) {
+			fmt.Fprintf(w, "    %s\n", r.Hint)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"dv/internal/config"
+)
+
+func healthyDoctorProbes() doctorProbes {
+	cfg := config.Default()
+	cfg.LocalProxy.Enabled = true
+	return doctorProbes{
+		configPath:    "/home/me/.config/dv/config.json",
+		loadConfig:    func() (config.Config, error) { return cfg, nil },
+		lookPath:      func(string) (string, error) { return "/usr/bin/docker", nil },
+		serverVersion: func(context.Context) (string, error) { return "27.1.1", nil },
+		buildx:        func() error { return nil },
+		sshAuthSock:   "/tmp/agent.sock",
+		sshSocket:     func(sock string) (string, string) { return sock, "SSH_AUTH_SOCK" },
+		socketExists:  func(string) bool { return true },
+		proxyRunning:  func(config.LocalProxyConfig) bool { return true },
+		imageExists:   func(string) bool { return true },
+	}
+}
+
+func doctorStatuses(results []doctorResult) map[string]doctorStatus {
+	statuses := map[string]doctorStatus{}
+	for _, r := range results {
+		statuses[r.Name] = r.Status
+	}
+	return statuses
+}
+
+func TestRunDoctorChecksAllPass(t *testing.T) {
+	results := runDoctorChecks(context.Background(), healthyDoctorProbes())
+	for _, r := range results {
+		if r.Status != doctorPass {
+			t.Errorf("%s: status %d (%s)", r.Name, r.Status, r.Detail)
+		}
+	}
+	var out bytes.Buffer
+	if failed := printDoctorResults(&out, results); failed != 0 {
+		t.Fatalf("failed = %d\n%s", failed, out.String())
+	}
+	if !strings.Contains(out.String(), "✓ docker daemon: server 27.1.1") {
+		t.Fatalf("unexpected checklist:\n%s", out.String())
+	}
+}
+
+func TestRunDoctorChecksDaemonDownSkipsDockerChecks(t *testing.T) {
+	p := healthyDoctorProbes()
+	p.serverVersion = func(context.Context) (string, error) {
+		return "", errors.New("Cannot connect to the Docker daemon\nIs the docker daemon running?")
+	}
+	p.buildx = func() error { t.Fatal("buildx probed without a daemon"); return nil }
+	p.imageExists = func(string) bool { t.Fatal("image probed without a daemon"); return false }
+
+	results := runDoctorChecks(context.Background(), p)
+	got := doctorStatuses(results)
+	if got["docker daemon"] != doctorFail || got["docker buildx"] != doctorSkip ||
+		got["local proxy"] != doctorSkip || got["selected image"] != doctorSkip {
+		t.Fatalf("statuses = %v", got)
+	}
+	var out bytes.Buffer
+	if failed := printDoctorResults(&out, results); failed != 1 {
+		t.Fatalf("failed = %d\n%s", failed, out.String())
+	}
+	if strings.Contains(out.String(), "Is the docker daemon running") {
+		t.Errorf("expected only the first line of the daemon error:\n%s", out.String())
+	}
+}
+
+func TestRunDoctorChecksReportsProblems(t *testing.T) {
+	p := healthyDoctorProbes()
+	p.buildx = func() error { return errors.New("unknown command") }
+	p.socketExists = func(string) bool { return false }
+	p.proxyRunning = func(config.LocalProxyConfig) bool { return false }
+	p.imageExists = func(string) bool { return false }
+	cfg := config.Default()
+	cfg.LocalProxy.Enabled = true
+	cfg.ContainerMemory = "lots"
+	p.loadConfig = func() (config.Config, error) { return cfg, nil }
+
+	got := doctorStatuses(runDoctorChecks(context.Background(), p))
+	want := map[string]doctorStatus{
+		"docker buildx":  doctorWarn,
+		"SSH agent":      doctorWarn,
+		"local proxy":    doctorWarn,
+		"config":         doctorFail,
+		"selected image": doctorFail,
+	}
+	for name, status := range want {
+		if got[name] != status {
+			t.Errorf("%s: status %d, want %d", name, got[name], status)
+		}
+	}
+}
+
+func TestDoctorConfigCheckMissingAndInvalid(t *testing.T) {
+	p := healthyDoctorProbes()
+	p.loadConfig = func() (config.Config, error) { return config.Config{}, fs.ErrNotExist }
+	cfg, r := doctorConfigCheck(p)
+	if r.Status != doctorWarn || cfg.SelectedImage != "discourse" {
+		t.Fatalf("missing config: %+v, selected image %q", r, cfg.SelectedImage)
+	}
+
+	p.loadConfig = func() (config.Config, error) { return config.Config{}, errors.New("invalid config: unexpected EOF") }
+	if _, r := doctorConfigCheck(p); r.Status != doctorFail || !strings.Contains(r.Hint, "dv config edit") {
+		t.Fatalf("invalid config: %+v", r)
+	}
+}
@@ -35,9 +35,9 @@ var enterCmd = &cobra.Command{
 
 		asRoot, _ := cmd.Flags().GetBool("root")
 		if asRoot {
-			return docker.ExecInteractiveAsRoot(ctx.name, ctx.workdir, ctx.envs, execArgs)
+			return withExitStatus(docker.ExecInteractiveAsRoot(ctx.name, ctx.workdir, ctx.envs, execArgs))
 		}
-		return docker.ExecInteractive(ctx.name, ctx.workdir, ctx.envs, execArgs)
+		return withExitStatus(docker.ExecInteractive(ctx.name, ctx.workdir, ctx.envs, execArgs))
 	},
 }
 
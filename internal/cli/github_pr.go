@@ -3,8 +3,11 @@ package cli
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	neturl "net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -45,6 +48,7 @@ type ghPRDetail struct {
 
 type prCompletionCache struct {
 	FetchedAt time.Time `json:"fetched_at"`
+	Host      string    `json:"host"`
 	Owner     string    `json:"owner"`
 	Repo      string    `json:"repo"`
 	Query     string    `json:"query"`
@@ -54,6 +58,21 @@ type prCompletionCache struct {
 
 const prCompletionCacheTTL = 45 * time.Second
 
+// prDetailCacheEntry is one PR in the detail cache, keyed by prDetailCacheKey.
+type prDetailCacheEntry struct {
+	FetchedAt time.Time  `json:"fetched_at"`
+	Detail    ghPRDetail `json:"detail"`
+}
+
+// prDetailCacheTTL is longer than the completion TTL: a PR's branch names
+// rarely change, and the cache mostly spares repeated lookups when several
+// dv commands run back to back.
+const prDetailCacheTTL = 5 * time.Minute
+
+// prDetailCacheMaxAge bounds how long expired entries are kept as a fallback
+// for when the forge is unreachable.
+const prDetailCacheMaxAge = 30 * 24 * time.Hour
+
 var ghAuthTokenOnce sync.Once
 var ghAuthTokenCached string
 
@@ -83,6 +102,97 @@ func applyGitHubHeaders(req *http.Request) {
 	}
 }
 
+// githubRateLimitError reports that GitHub refused a request because the
+// API rate limit is used up.
+type githubRateLimitError struct {
+	status        string
+	reset         time.Time
+	authenticated bool
+}
+
+func (e *githubRateLimitError) Error() string {
+	msg := "GitHub API rate limit exceeded (" + e.status + ")"
+	if !e.reset.IsZero() {
+		wait := time.Until(e.reset).Round(time.Second)
+		if wait < 0 {
+			wait = 0
+		}
+		msg += fmt.Sprintf("; it resets at %s (in %s)", e.reset.Local().Format("15:04:05"), wait)
+	}
+	if !e.authenticated {
+		msg += "; set GITHUB_TOKEN or run 'gh auth login' for a higher limit"
+	}
+	return msg
+}
+
+// githubMaxRetryAfter bounds how long githubGet waits before its retry;
+// longer Retry-After values fail straight away with the reset time.
+const githubMaxRetryAfter = time.Minute
+
+var githubRetrySleep = time.Sleep
+
+// githubGet sends an authenticated GET to the GitHub API and returns the
+// response when it succeeds. A 403/429 with Retry-After is retried once
+// after waiting; a used-up rate limit becomes a *githubRateLimitError.
+func githubGet(client *http.Client, url string) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		applyGitHubHeaders(req)
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return resp, nil
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+			return nil, fmt.Errorf("GitHub API error: %s", resp.Status)
+		}
+		retryAfter, hasRetryAfter := githubRetryAfter(resp.Header)
+		if hasRetryAfter && attempt == 0 && retryAfter <= githubMaxRetryAfter {
+			githubRetrySleep(retryAfter)
+			continue
+		}
+		if !hasRetryAfter && resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") != "0" {
+			return nil, fmt.Errorf("GitHub API error: %s", resp.Status)
+		}
+		rlErr := &githubRateLimitError{status: resp.Status, authenticated: req.Header.Get("Authorization") != ""}
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil && reset > 0 {
+			rlErr.reset = time.Unix(reset, 0)
+		} else if hasRetryAfter {
+			rlErr.reset = time.Now().Add(retryAfter)
+		}
+		return nil, rlErr
+	}
+}
+
+// githubRetryAfter parses the Retry-After header, which GitHub sends in
+// seconds.
+func githubRetryAfter(h http.Header) (time.Duration, bool) {
+	secs, err := strconv.Atoi(strings.TrimSpace(h.Get("Retry-After")))
+	if err != nil || secs < 0 {
+		return 0, false
+	}
+	return time.Duration(secs) * time.Second, true
+}
+
+var githubTokenWarningOnce sync.Once
+
+// warnIfNoGitHubToken points out, once per run, that unauthenticated GitHub
+// requests share a 60/hour limit.
+func warnIfNoGitHubToken(w io.Writer, forge prForge) {
+	if _, ok := forge.(githubForge); !ok || githubAuthToken() != "" {
+		return
+	}
+	githubTokenWarningOnce.Do(func() {
+		fmt.Fprintln(w, "Warning: no GitHub token found; API requests are limited to 60/hour. Set GITHUB_TOKEN or run 'gh auth login'.")
+	})
+}
+
 func prCompletionCachePath() (string, error) {
 	cacheDir, err := xdg.CacheDir()
 	if err != nil {
@@ -91,7 +201,9 @@ func prCompletionCachePath() (string, error) {
 	return filepath.Join(cacheDir, "pr-completion.json"), nil
 }
 
-func loadPRCompletionCache(owner, repo, query string, limit int) ([]ghPR, bool) {
+// loadPRCompletionCache returns cached PRs for the query while they are
+// fresh, or at any age with allowStale (used when the forge is unreachable).
+func loadPRCompletionCache(host, owner, repo, query string, limit int, allowStale bool) ([]ghPR, bool) {
 	cachePath, err := prCompletionCachePath()
 	if err != nil {
 		return nil, false
@@ -104,16 +216,16 @@ func loadPRCompletionCache(owner, repo, query string, limit int) ([]ghPR, bool)
 	if err := json.Unmarshal(data, &cache); err != nil {
 		return nil, false
 	}
-	if cache.Owner != owner || cache.Repo != repo || cache.Query != strings.ToLower(query) || cache.Limit != limit {
+	if cache.Host != host || cache.Owner != owner || cache.Repo != repo || cache.Query != strings.ToLower(query) || cache.Limit != limit {
 		return nil, false
 	}
-	if cache.FetchedAt.IsZero() || time.Since(cache.FetchedAt) > prCompletionCacheTTL {
+	if !allowStale && (cache.FetchedAt.IsZero() || time.Since(cache.FetchedAt) > prCompletionCacheTTL) {
 		return nil, false
 	}
 	return cache.PRs, true
 }
 
-func savePRCompletionCache(owner, repo, query string, limit int, prs []ghPR) {
+func savePRCompletionCache(host, owner, repo, query string, limit int, prs []ghPR) {
 	cachePath, err := prCompletionCachePath()
 	if err != nil {
 		return
@@ -123,6 +235,7 @@ func savePRCompletionCache(owner, repo, query string, limit int, prs []ghPR) {
 	}
 	cache := prCompletionCache{
 		FetchedAt: time.Now().UTC(),
+		Host:      host,
 		Owner:     owner,
 		Repo:      repo,
 		Query:     strings.ToLower(query),
@@ -136,23 +249,111 @@ func savePRCompletionCache(owner, repo, query string, limit int, prs []ghPR) {
 	_ = os.WriteFile(cachePath, data, 0o644)
 }
 
-// fetchPRDetail fetches details for a specific PR from GitHub API
-func fetchPRDetail(owner, repo string, prNumber int) (*ghPRDetail, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/pulls/%d", owner, repo, prNumber)
-	req, err := http.NewRequest("GET", url, nil)
+func prDetailCachePath() (string, error) {
+	cacheDir, err := xdg.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "pr-details.json"), nil
+}
+
+func prDetailCacheKey(host, owner, repo string, prNumber int) string {
+	return fmt.Sprintf("%s/%s/%s#%d", strings.ToLower(host), strings.ToLower(owner), strings.ToLower(repo), prNumber)
+}
+
+func loadPRDetailCache() map[string]prDetailCacheEntry {
+	entries := map[string]prDetailCacheEntry{}
+	cachePath, err := prDetailCachePath()
+	if err != nil {
+		return entries
+	}
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		return entries
+	}
+	_ = json.Unmarshal(data, &entries)
+	return entries
+}
+
+func savePRDetailCache(entries map[string]prDetailCacheEntry) {
+	cachePath, err := prDetailCachePath()
 	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0o755); err != nil {
+		return
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(cachePath, data, 0o644)
+}
+
+// cachedPRDetail returns PR details from the on-disk cache while they are
+// fresh, fetching and storing them otherwise. noCache (--no-cache) skips
+// the cached entry but still refreshes it.
+func cachedPRDetail(forge prForge, host, owner, repo string, prNumber int, noCache bool) (*ghPRDetail, error) {
+	key := prDetailCacheKey(host, owner, repo, prNumber)
+	entries := loadPRDetailCache()
+	if entry, ok := entries[key]; ok && !noCache && !entry.FetchedAt.IsZero() && time.Since(entry.FetchedAt) <= prDetailCacheTTL {
+		detail := entry.Detail
+		return &detail, nil
+	}
+	detail, err := forge.fetchPRDetail(owner, repo, prNumber)
+	if err != nil {
+		// Branch names rarely change, so an expired entry beats failing
+		// outright on a flaky network.
+		if entry, ok := entries[key]; ok && !noCache && isForgeUnreachable(err) {
+			detail := entry.Detail
+			return &detail, nil
+		}
 		return nil, err
 	}
-	applyGitHubHeaders(req)
+	for k, entry := range entries {
+		if time.Since(entry.FetchedAt) > prDetailCacheMaxAge {
+			delete(entries, k)
+		}
+	}
+	entries[key] = prDetailCacheEntry{FetchedAt: time.Now().UTC(), Detail: *detail}
+	savePRDetailCache(entries)
+	return detail, nil
+}
+
+// isForgeUnreachable reports whether err is a transport failure (DNS,
+// refused connection, timeout) rather than an API response.
+func isForgeUnreachable(err error) bool {
+	var urlErr *neturl.Error
+	return errors.As(err, &urlErr)
+}
+
+// explainForgeUnreachable replaces a raw network error with advice to
+// bypass the PR lookup.
+func explainForgeUnreachable(forge prForge, err error) error {
+	if !isForgeUnreachable(err) {
+		return err
+	}
+	return fmt.Errorf("%s unreachable; pass an explicit branch with --branch instead of --pr (%w)", forge.name(), err)
+}
+
+// githubForge looks up pull requests through the GitHub REST API.
+type githubForge struct{}
+
+func (githubForge) name() string { return "GitHub" }
+
+func (githubForge) prRef(prNumber int) string {
+	return fmt.Sprintf("refs/pull/%d/head", prNumber)
+}
+
+// fetchPRDetail fetches details for a specific PR from GitHub API
+func (githubForge) fetchPRDetail(owner, repo string, prNumber int) (*ghPRDetail, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/pulls/%d", owner, repo, prNumber)
 	client := &http.Client{Timeout: 8 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := githubGet(client, url)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("GitHub API error: %s", resp.Status)
-	}
 	var pr ghPRDetail
 	if err := json.NewDecoder(resp.Body).Decode(&pr); err != nil {
 		return nil, err
@@ -161,7 +362,7 @@ func fetchPRDetail(owner, repo string, prNumber int) (*ghPRDetail, error) {
 }
 
 // listOpenPRs queries GitHub REST API for open PRs, paginated up to limit.
-func listOpenPRs(owner, repo string, limit int) ([]ghPR, error) {
+func (githubForge) listOpenPRs(owner, repo string, limit int) ([]ghPR, error) {
 	if limit <= 0 {
 		limit = 30
 	}
@@ -177,19 +378,10 @@ func listOpenPRs(owner, repo string, limit int) ([]ghPR, error) {
 	client := &http.Client{Timeout: 8 * time.Second}
 	for len(all) < limit {
 		url := fmt.Sprintf("https://api.github.com/repos/%s/%s/pulls?state=open&per_page=%d&page=%d&sort=updated&direction=desc", owner, repo, perPage, page)
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return nil, err
-		}
-		applyGitHubHeaders(req)
-		resp, err := client.Do(req)
+		resp, err := githubGet(client, url)
 		if err != nil {
 			return nil, err
 		}
-		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			resp.Body.Close()
-			return nil, fmt.Errorf("GitHub API error: %s", resp.Status)
-		}
 		var prs []ghPR
 		if err := json.NewDecoder(resp.Body).Decode(&prs); err != nil {
 			resp.Body.Close()
@@ -212,7 +404,7 @@ func listOpenPRs(owner, repo string, limit int) ([]ghPR, error) {
 }
 
 // searchOpenPRs uses GitHub search API to find open PRs with query in title/body.
-func searchOpenPRs(owner, repo, query string, limit int) ([]ghPR, error) {
+func (githubForge) searchOpenPRs(owner, repo, query string, limit int) ([]ghPR, error) {
 	if limit <= 0 {
 		limit = 30
 	}
@@ -221,27 +413,32 @@ func searchOpenPRs(owner, repo, query string, limit int) ([]ghPR, error) {
 	}
 	// Use search issues API with in:title,body filter
 	q := fmt.Sprintf("repo:%s/%s+is:pr+is:open+in:title,body+%s", owner, repo, query)
-	url := fmt.Sprintf("https://api.github.com/search/issues?q=%s&per_page=%d&sort=updated&order=desc", urlQueryEscape(q), limit)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-	applyGitHubHeaders(req)
+	return githubSearchPRs(urlQueryEscape(q), limit)
+}
+
+// openPRsForBranch finds open PRs whose head branch is branch.
+func (githubForge) openPRsForBranch(owner, repo, branch string) ([]ghPR, error) {
+	q := fmt.Sprintf("repo:%s/%s is:pr is:open head:%s", owner, repo, branch)
+	return githubSearchPRs(neturl.QueryEscape(q), 10)
+}
+
+// githubSearchPRs runs an already escaped issue search query.
+func githubSearchPRs(q string, limit int) ([]ghPR, error) {
+	url := fmt.Sprintf("https://api.github.com/search/issues?q=%s&per_page=%d&sort=updated&order=desc", q, limit)
 	client := &http.Client{Timeout: 8 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := githubGet(client, url)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("GitHub API error: %s", resp.Status)
-	}
+	// Issue search results carry "draft" for pull requests too.
 	var res struct {
 		Items []struct {
 			Number    int       `json:"number"`
 			Title     string    `json:"title"`
 			Body      string    `json:"body"`
 			UpdatedAt time.Time `json:"updated_at"`
+			Draft     bool      `json:"draft"`
 		} `json:"items"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
@@ -249,7 +446,7 @@ func searchOpenPRs(owner, repo, query string, limit int) ([]ghPR, error) {
 	}
 	out := make([]ghPR, 0, len(res.Items))
 	for _, it := range res.Items {
-		out = append(out, ghPR{Number: it.Number, Title: it.Title, Body: it.Body, UpdatedAt: it.UpdatedAt})
+		out = append(out, ghPR{Number: it.Number, Title: it.Title, Body: it.Body, UpdatedAt: it.UpdatedAt, Draft: it.Draft})
 	}
 	return out, nil
 }
@@ -274,11 +471,11 @@ func isNumeric(s string) bool {
 }
 
 // repoOwnerRepoFromContainer tries to read remote.origin.url inside the container
-// and parse it for a GitHub owner/repo pair. Returns empty strings on failure.
-func repoOwnerRepoFromContainer(cfg config.Config, containerName string) (string, string) {
+// and parse it for a host/owner/repo triple. Returns empty strings on failure.
+func repoOwnerRepoFromContainer(cfg config.Config, containerName string) (string, string, string) {
 	// If container isn't running, avoid starting it just for completion
 	if !docker.Exists(containerName) || !docker.Running(containerName) {
-		return "", ""
+		return "", "", ""
 	}
 	// Determine workdir
 	imgName := cfg.ContainerImages[containerName]
@@ -300,67 +497,39 @@ func repoOwnerRepoFromContainer(cfg config.Config, containerName string) (string
 		remoteURL = strings.TrimSpace(out)
 	}
 	if remoteURL == "" {
-		return "", ""
+		return "", "", ""
 	}
-	owner, repo := ownerRepoFromURL(remoteURL)
-	return owner, repo
+	return ownerRepoFromURL(remoteURL)
 }
 
 // prSearchOwnerRepoFromContainer chooses the best owner/repo for PR search.
 // Prefer upstream remote; if origin is a fork of 'discourse' repo, normalize owner to 'discourse'.
-func prSearchOwnerRepoFromContainer(cfg config.Config, containerName string) (string, string) {
-	owner, repo := repoOwnerRepoFromContainer(cfg, containerName)
-	if repo == "" {
-		return owner, repo
-	}
-	// Normalize common fork case: searching PRs on upstream 'discourse' rather than fork
-	if strings.EqualFold(repo, "discourse") && !strings.EqualFold(owner, "discourse") {
-		return "discourse", repo
-	}
-	return owner, repo
-}
-
-// ownerRepoFromURL extracts GitHub owner/repo from common remote URL formats.
-// Supports https and ssh formats; strips .git suffix.
-func ownerRepoFromURL(url string) (string, string) {
-	s := strings.TrimSpace(url)
-	s = strings.TrimSuffix(s, ".git")
-	// Normalize
-	// Examples:
-	//  https://github.com/discourse/discourse
-	//  git@github.com:discourse/discourse
-	//  ssh://git@github.com/discourse/discourse
-	var hostIdx int
-	if i := strings.Index(s, "github.com"); i >= 0 {
-		hostIdx = i + len("github.com")
-	} else {
-		return "", ""
-	}
-	tail := s[hostIdx:]
-	// Remove leading separators like ':' or '/'
-	tail = strings.TrimLeft(tail, ":/")
-	parts := strings.Split(tail, "/")
-	if len(parts) < 2 {
-		return "", ""
-	}
-	owner, repo := parts[0], parts[1]
-	owner = strings.TrimSpace(owner)
-	repo = strings.TrimSpace(repo)
-	if owner == "" || repo == "" {
-		return "", ""
+func prSearchOwnerRepoFromContainer(cfg config.Config, containerName string) (string, string, string) {
+	return prSearchRepo(repoOwnerRepoFromContainer(cfg, containerName))
+}
+
+// prSearchRepo normalizes the common fork case: PRs against a GitHub fork
+// of 'discourse' are searched on upstream discourse/discourse.
+func prSearchRepo(host, owner, repo string) (string, string, string) {
+	if host == "github.com" && strings.EqualFold(repo, "discourse") && !strings.EqualFold(owner, "discourse") {
+		return host, "discourse", repo
 	}
-	return owner, repo
+	return host, owner, repo
 }
 
-func prHeadBranchName(cfg config.Config, prNumber int) (string, error) {
-	owner, repo := ownerRepoFromURL(cfg.DiscourseRepo)
+func prHeadBranchName(cfg config.Config, prNumber int, noCache bool) (string, error) {
+	host, owner, repo := ownerRepoFromURL(cfg.DiscourseRepo)
 	if owner == "" || repo == "" {
 		return "", fmt.Errorf("unable to determine repository owner/name; check 'discourseRepo' in config")
 	}
-	prDetail, err := fetchPRDetail(owner, repo, prNumber)
+	forge, err := forgeForHost(host)
 	if err != nil {
 		return "", err
 	}
+	prDetail, err := cachedPRDetail(forge, host, owner, repo, prNumber, noCache)
+	if err != nil {
+		return "", explainForgeUnreachable(forge, err)
+	}
 	if ref := strings.TrimSpace(prDetail.Head.Ref); ref != "" {
 		return ref, nil
 	}
@@ -416,11 +585,65 @@ func filterPRs(prs []ghPR, query string) []ghPR {
 	return out
 }
 
-// SuggestPRNumbers returns completion candidates for PR numbers.
-func SuggestPRNumbers(owner, repo string, toComplete string) ([]string, cobra.ShellCompDirective) {
+// cachedOpenPRs returns open PRs matching query (all open PRs when it is
+// empty), served from the completion cache while it is fresh. When the forge
+// cannot be reached, a stale cached result is returned instead and stale is
+// set so callers can say so.
+func cachedOpenPRs(forge prForge, host, owner, repo, query string, limit int) (prs []ghPR, stale bool, err error) {
+	lower := strings.ToLower(query)
+	if prs, ok := loadPRCompletionCache(host, owner, repo, lower, limit, false); ok {
+		return prs, false, nil
+	}
+	if query != "" && !isNumeric(lower) {
+		prs, err = forge.searchOpenPRs(owner, repo, query, limit)
+		if (err != nil && !isForgeUnreachable(err)) || (err == nil && len(prs) == 0) {
+			prs, err = forge.listOpenPRs(owner, repo, limit)
+		}
+	} else {
+		prs, err = forge.listOpenPRs(owner, repo, limit)
+	}
+	if err != nil {
+		if isForgeUnreachable(err) {
+			if cached, ok := loadPRCompletionCache(host, owner, repo, lower, limit, true); ok {
+				return cached, true, nil
+			}
+		}
+		return nil, false, err
+	}
+	prs = filterPRs(prs, lower)
+	savePRCompletionCache(host, owner, repo, lower, limit, prs)
+	return prs, false, nil
+}
+
+// withoutDrafts drops draft PRs.
+func withoutDrafts(prs []ghPR) []ghPR {
+	out := make([]ghPR, 0, len(prs))
+	for _, pr := range prs {
+		if !pr.Draft {
+			out = append(out, pr)
+		}
+	}
+	return out
+}
+
+// prCompletionTitle is the completion description for pr, marking drafts.
+func prCompletionTitle(pr ghPR) string {
+	if pr.Draft {
+		return "[draft] " + pr.Title
+	}
+	return pr.Title
+}
+
+// SuggestPRNumbers returns completion candidates for PR numbers, leaving out
+// drafts when noDrafts is set.
+func SuggestPRNumbers(host, owner, repo string, toComplete string, noDrafts bool) ([]string, cobra.ShellCompDirective) {
 	if owner == "" || repo == "" {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
+	forge, err := forgeForHost(host)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
 
 	limit := 100
 	if v := os.Getenv("DV_PR_COMPLETE_LIMIT"); v != "" {
@@ -430,22 +653,15 @@ func SuggestPRNumbers(owner, repo string, toComplete string) ([]string, cobra.Sh
 	}
 	baseQuery, ordinal, hasOrdinal := splitCompletionQuery(toComplete)
 	query := strings.ToLower(baseQuery)
-	prs, ok := loadPRCompletionCache(owner, repo, query, limit)
-	if !ok {
-		var err error
-		if baseQuery != "" && !isNumeric(query) {
-			prs, err = searchOpenPRs(owner, repo, baseQuery, limit)
-			if err != nil || len(prs) == 0 {
-				prs, err = listOpenPRs(owner, repo, limit)
-			}
-		} else {
-			prs, err = listOpenPRs(owner, repo, limit)
-		}
-		if err != nil {
-			return nil, cobra.ShellCompDirectiveNoFileComp
-		}
-		prs = filterPRs(prs, query)
-		savePRCompletionCache(owner, repo, query, limit, prs)
+	prs, stale, err := cachedOpenPRs(forge, host, owner, repo, baseQuery, limit)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if stale {
+		cobra.CompDebugln(fmt.Sprintf("%s is unreachable; completing from cached PRs", forge.name()), true)
+	}
+	if noDrafts {
+		prs = withoutDrafts(prs)
 	}
 	if hasOrdinal {
 		if ordinal < 1 || ordinal > len(prs) {
@@ -467,16 +683,28 @@ func SuggestPRNumbers(owner, repo string, toComplete string) ([]string, cobra.Sh
 			}
 			val = fmt.Sprintf("%s%d:%d", baseQuery, displayOrdinal, pr.Number)
 		}
-		out = append(out, fmt.Sprintf("%s\t%s", val, pr.Title))
+		out = append(out, fmt.Sprintf("%s\t%s", val, prCompletionTitle(pr)))
 	}
 	return out, cobra.ShellCompDirectiveNoFileComp
 }
 
-// ResolvePR takes a numeric string (PR number) or a search query and returns the PR number.
-// If multiple PRs match a search query, it prompts the user to select one.
-func ResolvePR(cmd *cobra.Command, cfg config.Config, input string) (int, error) {
+// ResolvePR takes a numeric string (PR number), a PR URL or a search query
+// and returns the PR number, searching host/owner/repo: the repository the
+// caller checks PRs out from. If multiple PRs match a search query, it
+// prompts the user to select one.
+func ResolvePR(cmd *cobra.Command, host, owner, repo, input string) (int, error) {
 	input = strings.TrimSpace(input)
 
+	// Pasted PR links carry the number; make sure they are for this repository.
+	if urlHost, urlOwner, urlRepo, num, ok := prNumberFromURL(input); ok {
+		urlHost, urlOwner, urlRepo = prSearchRepo(urlHost, urlOwner, urlRepo)
+		host, owner, repo := prSearchRepo(host, owner, repo)
+		if repo != "" && (urlHost != host || !strings.EqualFold(urlOwner, owner) || !strings.EqualFold(urlRepo, repo)) {
+			return 0, fmt.Errorf("PR URL is for %s/%s/%s, but PRs are checked out from %s/%s/%s", urlHost, urlOwner, urlRepo, host, owner, repo)
+		}
+		return num, nil
+	}
+
 	// Handle prefixed values from SuggestPRNumbers completion (e.g. "caro:1234")
 	if i := strings.LastIndex(input, ":"); i >= 0 {
 		remainder := strings.TrimSpace(input[i+1:])
@@ -498,10 +726,13 @@ func ResolvePR(cmd *cobra.Command, cfg config.Config, input string) (int, error)
 		return num, nil
 	}
 
-	owner, repo := ownerRepoFromURL(cfg.DiscourseRepo)
 	if owner == "" || repo == "" {
 		return 0, fmt.Errorf("unable to determine repository owner/name; check 'discourseRepo' in config")
 	}
+	forge, err := forgeForHost(host)
+	if err != nil {
+		return 0, err
+	}
 
 	query := strings.TrimSpace(input)
 	if query == "" {
@@ -509,13 +740,15 @@ func ResolvePR(cmd *cobra.Command, cfg config.Config, input string) (int, error)
 	}
 
 	const resultLimit = 10
+	warnIfNoGitHubToken(cmd.ErrOrStderr(), forge)
+
 	fmt.Fprintf(cmd.ErrOrStderr(), "Searching for open PRs matching '%s'...\n", query)
-	prs, err := searchOpenPRs(owner, repo, query, resultLimit)
+	prs, err := forge.searchOpenPRs(owner, repo, query, resultLimit)
 	if err == nil {
 		prs = filterPRs(prs, strings.ToLower(query))
 	}
 	if err != nil || len(prs) == 0 {
-		fallback, fallbackErr := listOpenPRs(owner, repo, 100)
+		fallback, fallbackErr := forge.listOpenPRs(owner, repo, 100)
 		if fallbackErr != nil {
 			if err != nil {
 				return 0, fmt.Errorf("search PRs: %w", err)
@@ -529,6 +762,16 @@ func ResolvePR(cmd *cobra.Command, cfg config.Config, input string) (int, error)
 	}
 
 	if len(prs) == 0 {
+		// A query without spaces may be a branch name the text search missed.
+		if !strings.ContainsAny(query, " \t") {
+			if prs, err := forge.openPRsForBranch(owner, repo, query); err == nil && len(prs) > 0 {
+				if len(prs) == 1 {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Found PR #%d for branch '%s': %s\n", prs[0].Number, query, prs[0].Title)
+					return prs[0].Number, nil
+				}
+				return pickPR(cmd, fmt.Sprintf("for branch '%s'", query), prs)
+			}
+		}
 		return 0, fmt.Errorf("no open PRs found matching '%s'", query)
 	}
 
@@ -537,8 +780,12 @@ func ResolvePR(cmd *cobra.Command, cfg config.Config, input string) (int, error)
 		return prs[0].Number, nil
 	}
 
-	// Multiple matches - interactive picker
-	fmt.Fprintf(cmd.ErrOrStderr(), "\nMultiple PRs found matching '%s':\n", query)
+	return pickPR(cmd, fmt.Sprintf("matching '%s'", query), prs)
+}
+
+// pickPR asks the user to choose one of several matching PRs.
+func pickPR(cmd *cobra.Command, what string, prs []ghPR) (int, error) {
+	fmt.Fprintf(cmd.ErrOrStderr(), "\nMultiple PRs found %s:\n", what)
 	for i, pr := range prs {
 		fmt.Fprintf(cmd.ErrOrStderr(), "  %d) #%d: %s\n", i+1, pr.Number, pr.Title)
 	}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// gitlabForge looks up merge requests through the GitLab REST API (v4), on
+// gitlab.com or a self-hosted instance.
+type gitlabForge struct {
+	apiBase string
+}
+
+func newGitLabForge(host string) gitlabForge {
+	return gitlabForge{apiBase: "https://" + host + "/api/v4"}
+}
+
+// gitlabMR is the subset of a GitLab merge request dv reads.
+type gitlabMR struct {
+	IID          int       `json:"iid"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	Draft        bool      `json:"draft"`
+	SourceBranch string    `json:"source_branch"`
+	TargetBranch string    `json:"target_branch"`
+}
+
+func (mr gitlabMR) summary() ghPR {
+	return ghPR{Number: mr.IID, Title: mr.Title, Body: mr.Description, UpdatedAt: mr.UpdatedAt, Draft: mr.Draft}
+}
+
+func gitlabAuthToken() string {
+	if tok := strings.TrimSpace(os.Getenv("GITLAB_TOKEN")); tok != "" {
+		return tok
+	}
+	return strings.TrimSpace(os.Getenv("GL_TOKEN"))
+}
+
+func (gitlabForge) name() string { return "GitLab" }
+
+func (gitlabForge) prRef(prNumber int) string {
+	return fmt.Sprintf("refs/merge-requests/%d/head", prNumber)
+}
+
+// projectURL returns the API URL of a project, addressed by its URL-encoded
+// full path since nested group namespaces contain slashes.
+func (f gitlabForge) projectURL(owner, repo string) string {
+	return f.apiBase + "/projects/" + url.PathEscape(owner+"/"+repo)
+}
+
+func (f gitlabForge) get(apiURL string, v any) error {
+	req, err := http.NewRequest("GET", apiURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "dv-cli")
+	if tok := gitlabAuthToken(); tok != "" {
+		req.Header.Set("PRIVATE-TOKEN", tok)
+	}
+	client := &http.Client{Timeout: 8 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("GitLab API error: %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// fetchPRDetail fetches a merge request by its project-scoped IID.
+func (f gitlabForge) fetchPRDetail(owner, repo string, prNumber int) (*ghPRDetail, error) {
+	var mr gitlabMR
+	if err := f.get(fmt.Sprintf("%s/merge_requests/%d", f.projectURL(owner, repo), prNumber), &mr); err != nil {
+		return nil, err
+	}
+	detail := &ghPRDetail{Number: mr.IID, Title: mr.Title, Body: mr.Description}
+	detail.Head.Ref = mr.SourceBranch
+	detail.Base.Ref = mr.TargetBranch
+	return detail, nil
+}
+
+// listOpenPRs returns open merge requests, most recently updated first.
+func (f gitlabForge) listOpenPRs(owner, repo string, limit int) ([]ghPR, error) {
+	return f.openMRs(owner, repo, nil, limit)
+}
+
+// searchOpenPRs returns open merge requests with query in title/description.
+func (f gitlabForge) searchOpenPRs(owner, repo, query string, limit int) ([]ghPR, error) {
+	if limit > 100 {
+		limit = 100
+	}
+	return f.openMRs(owner, repo, url.Values{"search": {query}, "in": {"title,description"}}, limit)
+}
+
+// openPRsForBranch returns open merge requests whose source branch is branch.
+func (f gitlabForge) openPRsForBranch(owner, repo, branch string) ([]ghPR, error) {
+	return f.openMRs(owner, repo, url.Values{"source_branch": {branch}}, 10)
+}
+
+// openMRs lists open merge requests, most recently updated first, narrowed
+// by any extra API filters.
+func (f gitlabForge) openMRs(owner, repo string, filters url.Values, limit int) ([]ghPR, error) {
+	if limit <= 0 {
+		limit = 30
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	perPage := min(limit, 100)
+	var all []ghPR
+	for page := 1; len(all) < limit; page++ {
+		params := url.Values{}
+		for k, v := range filters {
+			params[k] = v
+		}
+		params.Set("state", "opened")
+		params.Set("order_by", "updated_at")
+		params.Set("sort", "desc")
+		params.Set("per_page", fmt.Sprint(perPage))
+		params.Set("page", fmt.Sprint(page))
+		var mrs []gitlabMR
+		if err := f.get(f.projectURL(owner, repo)+"/merge_requests?"+params.Encode(), &mrs); err != nil {
+			return nil, err
+		}
+		if len(mrs) == 0 {
+			break
+		}
+		for _, mr := range mrs {
+			all = append(all, mr.summary())
+		}
+	}
+	if len(all) > limit {
+		all = all[:limit]
+	}
+	return all, nil
+}
@@ -14,6 +14,7 @@ import (
 
 	"dv/internal/config"
 	"dv/internal/docker"
+	"dv/internal/logging"
 	"dv/internal/xdg"
 )
 
@@ -84,10 +85,20 @@ func enrichHostHookContextForContainer(cfg config.Config, hookName string, ctx h
 	if ctx.ImageTag == "" {
 		ctx.ImageTag = imgCfg.Tag
 	}
-	if ctx.Workdir == "" {
-		if workdir, err := docker.GetContainerWorkdir(ctx.ContainerName); err == nil {
-			ctx.Workdir = workdir
+	// Inspect the container at most once for the workdir and host port.
+	var inspected *docker.ContainerInspect
+	inspect := func() *docker.ContainerInspect {
+		if inspected == nil {
+			if ci, err := docker.Inspect(ctx.ContainerName); err == nil {
+				inspected = ci
+			} else {
+				inspected = &docker.ContainerInspect{}
+			}
 		}
+		return inspected
+	}
+	if ctx.Workdir == "" {
+		ctx.Workdir = strings.TrimSpace(inspect().Config.WorkingDir)
 	}
 	if ctx.Workdir == "" {
 		ctx.Workdir = config.EffectiveWorkdir(cfg, imgCfg, ctx.ContainerName)
@@ -99,7 +110,7 @@ func enrichHostHookContextForContainer(cfg config.Config, hookName string, ctx h
 		ctx.ContainerPort = cfg.ContainerPort
 	}
 	if ctx.HostPort == 0 && ctx.ContainerPort > 0 {
-		if hostPort, err := docker.GetContainerHostPort(ctx.ContainerName, ctx.ContainerPort); err == nil {
+		if hostPort, err := inspect().HostPort(ctx.ContainerPort); err == nil {
 			ctx.HostPort = hostPort
 		}
 	}
@@ -139,7 +150,7 @@ func runConfiguredHostHooks(cmd *cobra.Command, cfg config.Config, hookName stri
 	runnableHooks := runnableHostHooks(hooks)
 	for i, hook := range runnableHooks {
 		fmt.Fprintf(out, "Running %s hook %d/%d...\n", hookName, i+1, len(runnableHooks))
-		if isTruthyEnv("DV_VERBOSE") {
+		if logging.DebugEnabled() {
 			fmt.Fprintf(out, "Hook command: %s\n", hook.Command)
 		}
 
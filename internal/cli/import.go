@@ -16,6 +16,7 @@ import (
 
 	"dv/internal/config"
 	"dv/internal/docker"
+	"dv/internal/logging"
 	"dv/internal/xdg"
 )
 
@@ -28,7 +29,7 @@ var importCmd = &cobra.Command{
 	Args:  cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		verbose, _ := cmd.Flags().GetBool("verbose")
-		verbose = verbose || isTruthyEnv("DV_VERBOSE")
+		verbose = verbose || logging.DebugEnabled()
 		stderr := cmd.ErrOrStderr()
 
 		// Resolve config and container
@@ -1,9 +1,11 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -125,17 +127,9 @@ var listCmd = &cobra.Command{
 
 		withSessions, _ := cmd.Flags().GetBool("sessions")
 		if withSessions {
-			for i, agent := range agents {
-				if agent.status == "Running" {
-					s, err := docker.ExecSessions(agent.name)
-					if err != nil {
-						fmt.Fprintf(cmd.ErrOrStderr(), "Warning: could not check sessions for '%s': %v\n", agent.name, err)
-						agents[i].sessions = -1
-					} else {
-						agents[i].sessions = len(s)
-					}
-				}
-			}
+			countAgentSessions(cmd.Context(), agents, dockerSessionCounter(agents), func(name string, err error) {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: could not check sessions for '%s': %v\n", name, err)
+			})
 		}
 
 		// Print in ls -l style format
@@ -270,6 +264,74 @@ type agentInfo struct {
 	sessions  int
 }
 
+// Session counting runs docker top once per running agent; these bound how
+// many run at once and how long a whole listing may spend on them.
+const (
+	sessionCountWorkers = 8
+	sessionCountTimeout = 10 * time.Second
+)
+
+// countAgentSessions sets sessions on every running agent, counting up to
+// sessionCountWorkers containers concurrently under one sessionCountTimeout.
+// Agents whose count fails or is cut off get -1 and are reported to onErr
+// (which may be nil). agents keeps its order.
+func countAgentSessions(ctx context.Context, agents []agentInfo, count func(ctx context.Context, name string) (int, error), onErr func(name string, err error)) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, sessionCountTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var errMu sync.Mutex
+	sem := make(chan struct{}, sessionCountWorkers)
+	for i := range agents {
+		if agents[i].status != "Running" {
+			continue
+		}
+		wg.Add(1)
+		go func(a *agentInfo) {
+			defer wg.Done()
+			var n int
+			var err error
+			select {
+			case sem <- struct{}{}:
+				n, err = count(ctx, a.name)
+				<-sem
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+			if err == nil {
+				a.sessions = n
+				return
+			}
+			a.sessions = -1
+			if onErr != nil {
+				errMu.Lock()
+				onErr(a.name, err)
+				errMu.Unlock()
+			}
+		}(&agents[i])
+	}
+	wg.Wait()
+}
+
+// dockerSessionCounter counts exec sessions with docker top, reading every
+// running agent's init PID from a single up-front docker inspect.
+func dockerSessionCounter(agents []agentInfo) func(ctx context.Context, name string) (int, error) {
+	var running []string
+	for _, a := range agents {
+		if a.status == "Running" {
+			running = append(running, a.name)
+		}
+	}
+	inspected, _ := docker.InspectAll(running...)
+	return func(ctx context.Context, name string) (int, error) {
+		sessions, err := docker.ExecSessionsFor(ctx, name, inspected[name])
+		return len(sessions), err
+	}
+}
+
 // calculateMaxNameWidth finds the longest agent name and returns an appropriate column width
 // with reasonable limits (minimum 10, maximum 50 characters)
 func calculateMaxNameWidth(agents []agentInfo) int {
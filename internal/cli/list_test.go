@@ -1,8 +1,14 @@
 package cli
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestParseLabels(t *testing.T) {
@@ -120,3 +126,71 @@ func TestParseLabels(t *testing.T) {
 		})
 	}
 }
+
+func TestCountAgentSessionsRunsConcurrentlyAndKeepsOrder(t *testing.T) {
+	t.Parallel()
+
+	var agents []agentInfo
+	for i := 0; i < 3*sessionCountWorkers; i++ {
+		agents = append(agents, agentInfo{name: fmt.Sprintf("agent-%02d", i), status: "Running"})
+	}
+	agents = append(agents, agentInfo{name: "stopped", status: "Stopped"}, agentInfo{name: "broken", status: "Running"})
+
+	var inFlight, peak atomic.Int32
+	count := func(ctx context.Context, name string) (int, error) {
+		if name == "broken" {
+			return 0, errors.New("docker top failed")
+		}
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		var idx int
+		fmt.Sscanf(name, "agent-%d", &idx)
+		return idx, nil
+	}
+	var mu sync.Mutex
+	var failed []string
+	countAgentSessions(context.Background(), agents, count, func(name string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		failed = append(failed, name)
+	})
+
+	for i := 0; i < 3*sessionCountWorkers; i++ {
+		if agents[i].name != fmt.Sprintf("agent-%02d", i) || agents[i].sessions != i {
+			t.Fatalf("agents[%d] = %+v, want agent-%02d with %d sessions", i, agents[i], i, i)
+		}
+	}
+	if agents[len(agents)-2].sessions != 0 {
+		t.Errorf("stopped agent was counted: %+v", agents[len(agents)-2])
+	}
+	if agents[len(agents)-1].sessions != -1 || !reflect.DeepEqual(failed, []string{"broken"}) {
+		t.Errorf("broken agent = %+v, failures %v", agents[len(agents)-1], failed)
+	}
+	if p := peak.Load(); p < 2 || p > sessionCountWorkers {
+		t.Errorf("peak concurrency = %d, want between 2 and %d", p, sessionCountWorkers)
+	}
+}
+
+func TestCountAgentSessionsStopsAtSharedDeadline(t *testing.T) {
+	t.Parallel()
+
+	agents := []agentInfo{{name: "a", status: "Running"}, {name: "b", status: "Running"}}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	countAgentSessions(ctx, agents, func(ctx context.Context, name string) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}, nil)
+	for _, a := range agents {
+		if a.sessions != -1 {
+			t.Errorf("%s sessions = %d, want -1 after the deadline", a.name, a.sessions)
+		}
+	}
+}
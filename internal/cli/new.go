@@ -1,18 +1,19 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"os/exec"
+	"path"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 
 	"dv/internal/config"
 	"dv/internal/docker"
+	"dv/internal/logging"
 	"dv/internal/session"
 	"dv/internal/xdg"
 )
@@ -40,29 +41,9 @@ var newCmd = &cobra.Command{
 
 		var tpl *templateConfig
 		if templatePath != "" {
-			var data []byte
-			if strings.HasPrefix(templatePath, "http://") || strings.HasPrefix(templatePath, "https://") {
-				resp, fetchErr := http.Get(templatePath)
-				if fetchErr != nil {
-					return fmt.Errorf("fetch template URL: %w", fetchErr)
-				}
-				defer resp.Body.Close()
-				if resp.StatusCode != http.StatusOK {
-					return fmt.Errorf("fetch template URL: %s returned status %d", templatePath, resp.StatusCode)
-				}
-				data, err = io.ReadAll(resp.Body)
-				if err != nil {
-					return fmt.Errorf("read template body: %w", err)
-				}
-			} else {
-				data, err = os.ReadFile(templatePath)
-				if err != nil {
-					return fmt.Errorf("read template: %w", err)
-				}
-			}
-			tpl = &templateConfig{}
-			if err = yaml.Unmarshal(data, tpl); err != nil {
-				return fmt.Errorf("parse template YAML: %w", err)
+			tpl, err = loadTemplate(templatePath)
+			if err != nil {
+				return err
 			}
 		}
 
@@ -78,7 +59,7 @@ var newCmd = &cobra.Command{
 			tpl.Plugins = append(tpl.Plugins, pluginFlags...)
 			for _, input := range pluginInputs {
 				if pluginSpecNeedsSSH(input) {
-					tpl.Git.SSHForward = true
+					tpl.Git.SSHForward = boolPtr(true)
 					break
 				}
 			}
@@ -96,7 +77,7 @@ var newCmd = &cobra.Command{
 			tpl.Themes = append(tpl.Themes, themeFlags...)
 			for _, input := range themeInputs {
 				if gitSpecNeedsSSH(input) {
-					tpl.Git.SSHForward = true
+					tpl.Git.SSHForward = boolPtr(true)
 					break
 				}
 			}
@@ -107,7 +88,8 @@ var newCmd = &cobra.Command{
 		var prFlag int
 		if prFlagStr != "" {
 			var prErr error
-			prFlag, prErr = ResolvePR(cmd, cfg, prFlagStr)
+			host, owner, repo := ownerRepoFromURL(cfg.DiscourseRepo)
+			prFlag, prErr = ResolvePR(cmd, host, owner, repo, prFlagStr)
 			if prErr != nil {
 				return prErr
 			}
@@ -121,7 +103,8 @@ var newCmd = &cobra.Command{
 		if len(args) == 1 {
 			name = args[0]
 		} else if prFlag > 0 {
-			targetBranch, err := prHeadBranchName(cfg, prFlag)
+			noCache, _ := cmd.Flags().GetBool("no-cache")
+			targetBranch, err := prHeadBranchName(cfg, prFlag, noCache)
 			if err != nil {
 				return err
 			}
@@ -134,10 +117,14 @@ var newCmd = &cobra.Command{
 			}
 			name = uniqueAgentName(agentNameSlug(pname))
 		}
+		resume, _ := cmd.Flags().GetBool("resume")
+		if resume && !explicitName {
+			return fmt.Errorf("--resume needs the name of the agent to resume")
+		}
 		if name == "" {
 			name = autogenName()
 		}
-		if explicitName {
+		if explicitName && !resume {
 			proceed, err := confirmInvalidRailsHostname(cmd, name)
 			if err != nil {
 				return err
@@ -147,40 +134,47 @@ var newCmd = &cobra.Command{
 				return nil
 			}
 		}
-		if docker.Exists(name) {
-			return fmt.Errorf("an agent named '%s' already exists", name)
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		fromAgent, _ := cmd.Flags().GetString("from")
+		fromAgent = strings.TrimSpace(fromAgent)
+		if resume {
+			if dryRun || fromAgent != "" || len(localPluginInputs) > 0 {
+				return fmt.Errorf("--resume cannot be combined with --dry-run, --from, or --plugin-local")
+			}
+			if volumes, _ := cmd.Flags().GetStringArray("volume"); len(volumes) > 0 {
+				return fmt.Errorf("--resume cannot add volumes; mounts are fixed when the container is created")
+			}
+			if !docker.Exists(name) {
+				return fmt.Errorf("no agent named '%s' to resume", name)
+			}
+			if imageOverride == "" {
+				imageOverride = sourceAgentImageName(cfg, name)
+			}
+		} else if !dryRun && docker.Exists(name) {
+			return fmt.Errorf("an agent named '%s' already exists (use --resume to finish provisioning it)", name)
 		}
 
-		// Cleanup on failure
-		keepOnFailure, _ := cmd.Flags().GetBool("keep-on-failure")
-		verbose, _ := cmd.Flags().GetBool("verbose")
-		containerCreated := false
-		provisioningComplete := false
-		previousSessionAgent := session.GetCurrentAgent()
-		sessionSelectionSet := false
-		defer func() {
-			if shouldRollbackNewSelection(err, provisioningComplete) && sessionSelectionSet {
-				if previousSessionAgent != "" {
-					_ = session.SetCurrentAgent(previousSessionAgent)
+		if fromAgent != "" {
+			if fromAgent == name {
+				return fmt.Errorf("--from must name a different agent than '%s'", name)
+			}
+			// A dry run doesn't touch Docker: the source is only looked up in
+			// the config, and the plan marks it as unverified.
+			if !dryRun && !docker.Exists(fromAgent) {
+				return fmt.Errorf("source agent '%s' does not exist", fromAgent)
+			}
+			// Keep the clone associated with the source's image so list/select treat it alike.
+			if imageOverride == "" {
+				if dryRun {
+					imageOverride = strings.TrimSpace(cfg.ContainerImages[fromAgent])
 				} else {
-					_ = session.ClearCurrentAgent()
+					imageOverride = sourceAgentImageName(cfg, fromAgent)
 				}
 			}
-			if shouldCleanupNewContainer(err, containerCreated, provisioningComplete, keepOnFailure) {
-				fmt.Fprintf(cmd.ErrOrStderr(), "\nProvisioning failed: %v\n", err)
-				fmt.Fprintf(cmd.ErrOrStderr(), "Cleaning up container '%s' (use --keep-on-failure to bypass)...\n", name)
-				_ = docker.Stop(name)
-				_ = docker.Remove(name)
-			}
-		}()
-
-		if err := session.SetCurrentAgent(name); err != nil {
-			return fmt.Errorf("could not save session state: %w", err)
 		}
-		sessionSelectionSet = true
-		cfg.SelectedAgent = name
 
-		if verbose || isTruthyEnv("DV_VERBOSE") {
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		if verbose || logging.DebugEnabled() {
 			fmt.Fprintf(cmd.OutOrStdout(), "Resolving image for agent '%s' (image override: '%s')...\n", name, imageOverride)
 		}
 		// Determine which image to use
@@ -219,8 +213,108 @@ var newCmd = &cobra.Command{
 			}
 		}
 
+		volumeInputs, _ := cmd.Flags().GetStringArray("volume")
+		forceVolume, _ := cmd.Flags().GetBool("force-volume")
+		// A resumed container already has its mounts.
+		if tpl != nil && !resume {
+			volumeInputs = append(append([]string{}, tpl.Volumes...), volumeInputs...)
+		}
+		for _, spec := range volumeInputs {
+			mount, vErr := resolveVolumeMount(spec, workdir, forceVolume)
+			if vErr != nil {
+				return vErr
+			}
+			if tpl == nil {
+				tpl = &templateConfig{}
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Mounting volume %s -> %s\n", mount.Host, mount.Container)
+			tpl.Mounts = append(tpl.Mounts, mount)
+		}
+
+		healthTimeoutFlag, _ := cmd.Flags().GetInt("health-timeout")
+		if healthTimeoutFlag < 0 {
+			return fmt.Errorf("--health-timeout must not be negative")
+		}
+		if tpl == nil && (prFlag > 0 || branchFlag != "") {
+			tpl = &templateConfig{}
+		}
+		if tpl == nil && healthTimeoutFlag > 0 {
+			// Without a template nothing is provisioned and no health wait runs.
+			return fmt.Errorf("--health-timeout only applies when provisioning with --template, --pr or --branch")
+		}
+		if tpl != nil {
+			if healthTimeoutFlag > 0 {
+				tpl.HealthTimeoutSeconds = healthTimeoutFlag
+			}
+			if prFlag > 0 {
+				tpl.Discourse.PR = prFlag
+			}
+			if branchFlag != "" {
+				tpl.Discourse.Branch = branchFlag
+			}
+		}
+
+		withoutTestDB, _ := cmd.Flags().GetBool("without-test-db")
+		if resume {
+			return resumeProvisioning(cmd, cfg, configDir, name, workdir, tpl, verbose, withoutTestDB)
+		}
+		if dryRun {
+			plan := provisionPlan{
+				Name:          name,
+				ImageName:     imgName,
+				ImageTag:      imageTag,
+				Workdir:       workdir,
+				TemplatePath:  templatePath,
+				Template:      tpl,
+				WithoutTestDB: withoutTestDB,
+				FromAgent:     fromAgent,
+			}
+			plan.GitUserName, plan.GitUserEmail = resolveGitIdentity(cfg, tpl)
+			if tpl != nil && tpl.Discourse.Repo != "" {
+				plan.DiscourseUpstream = discourseForkUpstream(cfg, tpl.Discourse.Repo)
+			}
+			if fromAgent != "" {
+				plan.ImageTag = fromSnapshotImageTag(name)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Dry run: the following steps would run (nothing was changed):\n")
+			for i, step := range plan.steps() {
+				fmt.Fprintf(cmd.OutOrStdout(), "%2d. %s\n", i+1, step)
+			}
+			return nil
+		}
+
+		// Cleanup on failure
+		keepOnFailure, _ := cmd.Flags().GetBool("keep-on-failure")
+		containerCreated := false
+		provisioningComplete := false
+		previousSessionAgent := session.GetCurrentAgent()
+		sessionSelectionSet := false
+		defer func() {
+			if shouldRollbackNewSelection(err, provisioningComplete) && sessionSelectionSet {
+				if previousSessionAgent != "" {
+					_ = session.SetCurrentAgent(previousSessionAgent)
+				} else {
+					_ = session.ClearCurrentAgent()
+				}
+			}
+			if shouldCleanupNewContainer(err, containerCreated, provisioningComplete, keepOnFailure) {
+				fmt.Fprintf(cmd.ErrOrStderr(), "\nProvisioning failed: %v\n", err)
+				fmt.Fprintf(cmd.ErrOrStderr(), "Cleaning up container '%s' (use --keep-on-failure to bypass)...\n", name)
+				_ = docker.Stop(name)
+				_ = docker.Remove(name)
+			} else if err != nil && containerCreated && !provisioningComplete && keepOnFailure && tpl != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "\nProvisioning failed: %v\nContainer '%s' was kept; fix the problem and run 'dv new %s --resume' to continue.\n", err, name, name)
+			}
+		}()
+
+		if err := session.SetCurrentAgent(name); err != nil {
+			return fmt.Errorf("could not save session state: %w", err)
+		}
+		sessionSelectionSet = true
+		cfg.SelectedAgent = name
+
 		sshAuthSock := ""
-		if tpl != nil && tpl.Git.SSHForward {
+		if tpl != nil && tpl.sshForward() {
 			sshAuthSock = os.Getenv("SSH_AUTH_SOCK")
 			if sshAuthSock == "" {
 				fmt.Fprintln(cmd.ErrOrStderr(), "Warning: ssh_forward enabled in template but SSH_AUTH_SOCK is not set on host.")
@@ -230,7 +324,7 @@ var newCmd = &cobra.Command{
 				// ssh -T returns exit code 1 even on success ("successfully authenticated")
 				if err != nil && !strings.Contains(string(out), "successfully authenticated") {
 					fmt.Fprintln(cmd.ErrOrStderr(), "Warning: SSH to GitHub failed. Check your SSH key setup.")
-					if verbose || isTruthyEnv("DV_VERBOSE") {
+					if verbose || logging.DebugEnabled() {
 						fmt.Fprintf(cmd.ErrOrStderr(), "  SSH output: %s\n", strings.TrimSpace(string(out)))
 					}
 				}
@@ -238,8 +332,6 @@ var newCmd = &cobra.Command{
 		}
 
 		// Apply template-specific config changes before saving
-		withoutTestDB, _ := cmd.Flags().GetBool("without-test-db")
-
 		if tpl != nil {
 			// Add copy rules
 			for _, rule := range tpl.Copy {
@@ -248,12 +340,27 @@ var newCmd = &cobra.Command{
 			}
 		}
 
-		if verbose || isTruthyEnv("DV_VERBOSE") {
+		if verbose || logging.DebugEnabled() {
 			fmt.Fprintf(cmd.OutOrStdout(), "Saving config with selected agent '%s'...\n", name)
 		}
 		if err = config.Save(configDir, cfg); err != nil {
 			return err
 		}
+		var extraLabels map[string]string
+		snapshotTag := ""
+		if fromAgent != "" {
+			snapshotTag = fromSnapshotImageTag(name)
+			fmt.Fprintf(cmd.OutOrStdout(), "Snapshotting agent '%s' into image '%s'...\n", fromAgent, snapshotTag)
+			if err = docker.CommitContainer(fromAgent, snapshotTag); err != nil {
+				return fmt.Errorf("failed to snapshot agent '%s': %w", fromAgent, err)
+			}
+			extraLabels = map[string]string{
+				"com.dv.cloned-from": fromAgent,
+				// Record the image the source was built from, not the temporary snapshot tag.
+				"com.dv.image-tag": imageTag,
+			}
+			imageTag = snapshotTag
+		}
 		if templatePath != "" {
 			fmt.Fprintf(cmd.OutOrStdout(), "Creating agent '%s' from image '%s' (using template: %s)...\n", name, imageTag, templatePath)
 		} else {
@@ -272,7 +379,12 @@ var newCmd = &cobra.Command{
 				})
 			}
 		}
-		lifecycle, err := ensureContainerRunningWithWorkdirResult(cmd, cfg, name, workdir, imageTag, imgName, false, sshAuthSock, templateEnvs, templateMounts)
+		lifecycle, err := ensureContainerRunningWithWorkdirResult(cmd, cfg, name, workdir, imageTag, imgName, false, sshAuthSock, templateEnvs, templateMounts, extraLabels)
+		if snapshotTag != "" {
+			// A created container keeps the snapshot layers alive on its own, and on
+			// failure nothing references them; either way the tag can go.
+			_ = docker.RemoveImageQuiet(snapshotTag)
+		}
 		if err != nil {
 			return err
 		}
@@ -281,25 +393,19 @@ var newCmd = &cobra.Command{
 		if cfg.ContainerImages == nil {
 			cfg.ContainerImages = map[string]string{}
 		}
-		if verbose || isTruthyEnv("DV_VERBOSE") {
+		if verbose || logging.DebugEnabled() {
 			fmt.Fprintf(cmd.OutOrStdout(), "Updating container-image mapping for '%s' to '%s'...\n", name, imgName)
 		}
 		cfg.ContainerImages[name] = imgName
 		_ = config.Save(configDir, cfg)
 
-		if tpl == nil && (prFlag > 0 || branchFlag != "") {
-			tpl = &templateConfig{}
-		}
-
 		if tpl != nil {
-			if prFlag > 0 {
-				tpl.Discourse.PR = prFlag
-			}
-			if branchFlag != "" {
-				tpl.Discourse.Branch = branchFlag
+			if err = executeTemplate(cmd, cfg, name, workdir, tpl, sshAuthSock, verbose, withoutTestDB, newProvisionProgress(name)); err != nil {
+				return err
 			}
-
-			if err = executeTemplate(cmd, cfg, name, workdir, tpl, sshAuthSock, verbose, withoutTestDB); err != nil {
+		} else {
+			userName, userEmail := resolveGitIdentity(cfg, nil)
+			if err = configureGitIdentity(cmd, name, workdir, userName, userEmail); err != nil {
 				return err
 			}
 		}
@@ -331,6 +437,25 @@ var newCmd = &cobra.Command{
 	},
 }
 
+// fromSnapshotImageTag names the temporary image `dv new --from` commits the
+// source agent into. Docker repository names must be lowercase.
+func fromSnapshotImageTag(name string) string {
+	return strings.ToLower(name) + "-dv-from-snapshot"
+}
+
+// sourceAgentImageName returns the dv image an existing agent was created
+// from, or "" when neither the config nor its labels record one.
+func sourceAgentImageName(cfg config.Config, name string) string {
+	if img := strings.TrimSpace(cfg.ContainerImages[name]); img != "" {
+		return img
+	}
+	labels, err := docker.Labels(name)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(labels["com.dv.image-name"])
+}
+
 func shouldRollbackNewSelection(err error, provisioningComplete bool) bool {
 	return err != nil && !provisioningComplete
 }
@@ -350,28 +475,108 @@ func confirmInvalidRailsHostname(cmd *cobra.Command, name string) (bool, error)
 }
 
 func checkoutPR(cmd *cobra.Command, cfg config.Config, name, workdir string, prNumber int, envs docker.Envs, withoutTestDB bool) error {
-	owner, repo := prSearchOwnerRepoFromContainer(cfg, name)
+	host, owner, repo := prSearchOwnerRepoFromContainer(cfg, name)
 	if owner == "" || repo == "" {
-		owner, repo = ownerRepoFromURL(cfg.DiscourseRepo)
+		host, owner, repo = ownerRepoFromURL(cfg.DiscourseRepo)
 	}
 	if owner == "" || repo == "" {
 		return fmt.Errorf("unable to determine repository owner/name")
 	}
-	prDetail, err := fetchPRDetail(owner, repo, prNumber)
+	forge, err := forgeForHost(host)
 	if err != nil {
 		return err
 	}
+	noCache, _ := cmd.Flags().GetBool("no-cache")
+	prDetail, err := cachedPRDetail(forge, host, owner, repo, prNumber, noCache)
+	if err != nil {
+		return explainForgeUnreachable(forge, err)
+	}
 	branchName := prDetail.Head.Ref
-	checkoutCmds := buildPRCheckoutCommands(prNumber, branchName)
+	checkoutCmds := buildPRCheckoutCommands(prNumber, forge.prRef(prNumber), branchName)
 	script := buildDiscourseResetScript(checkoutCmds, discourseResetScriptOpts{WithoutTestDB: withoutTestDB})
 	return docker.ExecInteractive(name, workdir, envs, []string{"bash", "-lc", script})
 }
 
-func configureDiscourseRepo(cmd *cobra.Command, name, workdir, repoURL string, envs docker.Envs) error {
+// resolveGitIdentity returns the git user.name/user.email for a new agent:
+// template values win, falling back to the config defaults field by field.
+func resolveGitIdentity(cfg config.Config, tpl *templateConfig) (string, string) {
+	userName := strings.TrimSpace(cfg.GitUserName)
+	userEmail := strings.TrimSpace(cfg.GitUserEmail)
+	if tpl != nil {
+		if v := strings.TrimSpace(tpl.Git.UserName); v != "" {
+			userName = v
+		}
+		if v := strings.TrimSpace(tpl.Git.UserEmail); v != "" {
+			userEmail = v
+		}
+	}
+	return userName, userEmail
+}
+
+// configureGitIdentity sets the discourse user's global git identity so
+// commits made in the container (core and plugins alike) are attributed.
+func configureGitIdentity(cmd *cobra.Command, name, workdir, userName, userEmail string) error {
+	if userName == "" && userEmail == "" {
+		return nil
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Configuring git identity %s...\n", gitIdentityLabel(userName, userEmail))
+	var lines []string
+	if userName != "" {
+		lines = append(lines, "git config --global user.name "+shellQuote(userName))
+	}
+	if userEmail != "" {
+		lines = append(lines, "git config --global user.email "+shellQuote(userEmail))
+	}
+	if out, err := docker.ExecCombinedOutput(name, workdir, nil, []string{"bash", "-c", strings.Join(lines, " && ")}); err != nil {
+		return fmt.Errorf("failed to configure git identity: %w: %s", err, strings.TrimSpace(out))
+	}
+	return nil
+}
+
+// gitIdentityLabel formats an identity the way git prints authors.
+func gitIdentityLabel(userName, userEmail string) string {
+	if userEmail == "" {
+		return userName
+	}
+	return strings.TrimSpace(userName + " <" + userEmail + ">")
+}
+
+// discourseForkUpstream returns the repository to register as the upstream
+// remote when repoURL is a fork of the configured Discourse repository, or ""
+// when repoURL already is that repository.
+func discourseForkUpstream(cfg config.Config, repoURL string) string {
+	upstream := strings.TrimSpace(cfg.DiscourseRepo)
+	if upstream == "" {
+		upstream = "https://github.com/discourse/discourse.git"
+	}
+	forkHost, forkOwner, forkRepo := ownerRepoFromURL(repoURL)
+	upHost, upOwner, upRepo := ownerRepoFromURL(upstream)
+	if forkRepo != "" && upRepo != "" {
+		if forkHost == upHost && strings.EqualFold(forkOwner, upOwner) && strings.EqualFold(forkRepo, upRepo) {
+			return ""
+		}
+		return upstream
+	}
+	normalize := func(s string) string {
+		return strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(s), "/"), ".git")
+	}
+	if normalize(repoURL) == normalize(upstream) {
+		return ""
+	}
+	return upstream
+}
+
+// configureDiscourseRepo points origin at repoURL and, for forks, adds an
+// upstream remote so PR checkouts and PR lookups use the original repository.
+func configureDiscourseRepo(cmd *cobra.Command, name, workdir, repoURL, upstreamURL string, envs docker.Envs) error {
 	fmt.Fprintf(cmd.OutOrStdout(), "Configuring Discourse repository %s...\n", repoURL)
+	if upstreamURL != "" {
+		fmt.Fprintf(cmd.OutOrStdout(), "Adding upstream remote %s for the fork...\n", upstreamURL)
+	}
 	script := fmt.Sprintf(`
 set -euo pipefail
 repo_url=%s
+upstream_url=%s
 current_url=$(git remote get-url origin 2>/dev/null || true)
 if [ -z "$current_url" ]; then
   git remote add origin "$repo_url"
@@ -380,7 +585,16 @@ elif [ "$current_url" != "$repo_url" ]; then
 fi
 echo "Fetching from origin..."
 git fetch origin --tags --prune --force
-`, shellQuote(repoURL))
+if [ -n "$upstream_url" ]; then
+  if git remote get-url upstream >/dev/null 2>&1; then
+    git remote set-url upstream "$upstream_url"
+  else
+    git remote add upstream "$upstream_url"
+  fi
+  echo "Fetching from upstream..."
+  git fetch upstream --prune
+fi
+`, shellQuote(repoURL), shellQuote(upstreamURL))
 	return docker.ExecInteractive(name, workdir, envs, []string{"bash", "-lc", script})
 }
 
@@ -484,7 +698,7 @@ func buildMaintenanceScript(withoutTestDB bool) string {
 	return strings.Join(lines, "\n")
 }
 
-func executeTemplate(cmd *cobra.Command, cfg config.Config, name, workdir string, tpl *templateConfig, sshAuthSock string, verbose bool, withoutTestDB bool) (err error) {
+func executeTemplate(cmd *cobra.Command, cfg config.Config, name, workdir string, tpl *templateConfig, sshAuthSock string, verbose bool, withoutTestDB bool, progress *provisionProgress) (err error) {
 	// 1. Env variables
 	envList := collectEnvPassthrough(cfg)
 	if len(tpl.Env) > 0 {
@@ -495,7 +709,7 @@ func executeTemplate(cmd *cobra.Command, cfg config.Config, name, workdir string
 	}
 
 	// 1.5 SSH Forwarding setup
-	if tpl.Git.SSHForward && sshAuthSock != "" {
+	if tpl.sshForward() && sshAuthSock != "" {
 		envList = append(envList, "SSH_AUTH_SOCK=/tmp/ssh-agent.sock")
 		if err := setupContainerSSHForwarding(cmd, name, workdir, false); err != nil {
 			return err
@@ -506,38 +720,51 @@ func executeTemplate(cmd *cobra.Command, cfg config.Config, name, workdir string
 	defer stopServicesForProvisioning(cmd, name, workdir)()
 
 	// 3. Discourse repository/branch/PR foundation
-	if tpl.Discourse.Repo != "" {
-		if err := configureDiscourseRepo(cmd, name, workdir, tpl.Discourse.Repo, envList); err != nil {
-			return err
-		}
-	}
-	if tpl.Discourse.PR != 0 {
-		fmt.Fprintf(cmd.OutOrStdout(), "Checking out PR %d...\n", tpl.Discourse.PR)
-		if err := checkoutPR(cmd, cfg, name, workdir, tpl.Discourse.PR, envList, withoutTestDB); err != nil {
-			return err
-		}
-	} else if tpl.Discourse.Branch != "" {
+	if !progress.skip(cmd, "foundation", "repository and branch setup") {
 		if tpl.Discourse.Repo != "" {
-			if err := checkoutBranchFromOrigin(cmd, name, workdir, tpl.Discourse.Branch, envList); err != nil {
+			upstreamURL := discourseForkUpstream(cfg, tpl.Discourse.Repo)
+			if err := configureDiscourseRepo(cmd, name, workdir, tpl.Discourse.Repo, upstreamURL, envList); err != nil {
 				return err
 			}
-		} else {
-			fmt.Fprintf(cmd.OutOrStdout(), "Checking out branch %s...\n", tpl.Discourse.Branch)
-			if err := checkoutBranch(cmd, cfg, name, workdir, tpl.Discourse.Branch, envList, withoutTestDB); err != nil {
+		}
+		if tpl.Discourse.PR != 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "Checking out PR %d...\n", tpl.Discourse.PR)
+			if err := checkoutPR(cmd, cfg, name, workdir, tpl.Discourse.PR, envList, withoutTestDB); err != nil {
 				return err
 			}
+		} else if tpl.Discourse.Branch != "" {
+			if tpl.Discourse.Repo != "" {
+				if err := checkoutBranchFromOrigin(cmd, name, workdir, tpl.Discourse.Branch, envList); err != nil {
+					return err
+				}
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "Checking out branch %s...\n", tpl.Discourse.Branch)
+				if err := checkoutBranch(cmd, cfg, name, workdir, tpl.Discourse.Branch, envList, withoutTestDB); err != nil {
+					return err
+				}
+			}
 		}
+		progress.mark(cmd, "foundation")
+	}
+
+	// 3.5 Git identity for commits made inside the container
+	userName, userEmail := resolveGitIdentity(cfg, tpl)
+	if err := configureGitIdentity(cmd, name, workdir, userName, userEmail); err != nil {
+		return err
 	}
 
 	// 4. Repository Operations (Plugins)
-	if len(tpl.Plugins) > 0 && (verbose || isTruthyEnv("DV_VERBOSE")) {
+	if len(tpl.Plugins) > 0 && (verbose || logging.DebugEnabled()) {
 		// Test SSH connectivity inside container
 		fmt.Fprintf(cmd.OutOrStdout(), "Testing SSH inside container...\n")
 		testCmd := "echo \"SSH_AUTH_SOCK=$SSH_AUTH_SOCK\"; ls -la $SSH_AUTH_SOCK 2>&1 || echo 'Socket not found'; ssh -T -o BatchMode=yes -o ConnectTimeout=5 git@github.com 2>&1 || true"
 		_ = docker.ExecInteractive(name, workdir, envList, []string{"bash", "-lc", testCmd})
 	}
-	if err := installPlugins(cmd, name, workdir, envList, tpl.Plugins); err != nil {
-		return err
+	if !progress.skip(cmd, "plugins", "plugin clones") {
+		if err := installPlugins(cmd, name, workdir, envList, tpl.Plugins); err != nil {
+			return err
+		}
+		progress.mark(cmd, "plugins")
 	}
 
 	// 4.5. Copy configured files (credentials, etc.) into the container according to template rules
@@ -561,16 +788,17 @@ func executeTemplate(cmd *cobra.Command, cfg config.Config, name, workdir string
 
 	// 5. Maintenance (Bundle and Migrate)
 	// Now that core is foundation-ed and plugins are cloned, we bundle and migrate.
-	if err := runMaintenance(cmd, name, workdir, envList, withoutTestDB); err != nil {
-		return err
+	if !progress.skip(cmd, "maintenance", "bundle install and migrations") {
+		if err := runMaintenance(cmd, name, workdir, envList, withoutTestDB); err != nil {
+			return err
+		}
+		progress.mark(cmd, "maintenance")
 	}
 
 	// 6. Start Services
-	// Only block on the HTTP health check when a later step needs a live
-	// Rails/API (site settings, themes, on_create commands, MCP). With none of
-	// those, the wait is dead time: services are started either way and finish
-	// booting in the background.
-	needsHealth := len(tpl.Settings) > 0 || len(tpl.Themes) > 0 || len(tpl.OnCreate) > 0 || len(tpl.MCP) > 0
+	// Without a reason to wait, the health check is dead time: services are
+	// started either way and finish booting in the background.
+	needsHealth := tpl.waitsForHealth()
 	if needsHealth {
 		fmt.Fprintf(cmd.OutOrStdout(), "Provisioning complete. Starting Discourse and waiting for it to be ready...\n")
 	} else {
@@ -581,12 +809,19 @@ func executeTemplate(cmd *cobra.Command, cfg config.Config, name, workdir string
 		return fmt.Errorf("failed to start services: %w", err)
 	}
 
-	// Wait for health check (max 120s) only when a subsequent step requires it.
+	// Wait for health check only when a subsequent step requires it.
 	if needsHealth {
-		// Hit Rails directly.
-		healthCmd := "timeout 120 bash -c 'until curl -s -f http://localhost:3000/srv/status > /dev/null 2>&1; do sleep 2; done' || exit 1"
-		if _, err = docker.ExecOutput(name, workdir, nil, []string{"bash", "-lc", healthCmd}); err != nil {
-			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: Discourse did not become healthy within 120s. Some settings might fail.\n")
+		timeout := tpl.healthTimeout()
+		if err = docker.WaitHealthy(cmd.Context(), name, workdir, docker.DefaultHealthProbe, time.Duration(timeout)*time.Second); err != nil {
+			var timeoutErr *docker.HealthTimeoutError
+			if !errors.As(err, &timeoutErr) {
+				return err
+			}
+			if tpl.requireHealthy() {
+				return fmt.Errorf("Discourse did not become healthy within %ds (require_healthy is set)", timeout)
+			}
+			err = nil
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: Discourse did not become healthy within %ds. Some settings might fail.\n", timeout)
 		} else {
 			fmt.Fprintf(cmd.OutOrStdout(), "Discourse is ready.\n")
 		}
@@ -596,15 +831,20 @@ func executeTemplate(cmd *cobra.Command, cfg config.Config, name, workdir string
 	// These require the API or a healthy Rails environment
 
 	// Site Settings
-	if len(tpl.Settings) > 0 {
+	if len(tpl.Settings) > 0 && !progress.skip(cmd, "settings", "site settings") {
 		fmt.Fprintf(cmd.OutOrStdout(), "Applying site settings...\n")
 		if err = ApplySiteSettings(cmd, cfg, name, tpl.Settings, envList, false, "template"); err != nil {
 			return fmt.Errorf("failed to apply site settings: %w", err)
 		}
+		progress.mark(cmd, "settings")
 	}
 
 	// Themes
-	for _, t := range tpl.Themes {
+	for i, t := range tpl.Themes {
+		step := fmt.Sprintf("theme:%d", i)
+		if progress.skip(cmd, step, "theme "+t.Repo) {
+			continue
+		}
 		if t.Enabled == nil {
 			t.Enabled = boolPtr(true)
 		}
@@ -617,20 +857,32 @@ func executeTemplate(cmd *cobra.Command, cfg config.Config, name, workdir string
 			containerName: name,
 			discourseRoot: workdir,
 			dataDir:       dataDir,
-			verbose:       verbose || isTruthyEnv("DV_VERBOSE"),
+			verbose:       verbose || logging.DebugEnabled(),
 			envs:          envList,
 		}
 
 		if err := handleThemeClone(cmd, ctx, t); err != nil {
 			return fmt.Errorf("failed to install theme %s: %w", t.Repo, err)
 		}
+		progress.mark(cmd, step)
 	}
 
 	// On Create Commands (run last so themes/settings are available)
-	for i, c := range tpl.OnCreate {
-		fmt.Fprintf(cmd.OutOrStdout(), "Running on_create command: %s...\n", c)
+	for i, entry := range tpl.OnCreate {
+		c := entry.Cmd
+		step := fmt.Sprintf("on_create:%d", i)
+		if progress.skip(cmd, step, "on_create command "+firstLine(c)) {
+			continue
+		}
+		run := docker.ExecInteractive
+		if entry.Root {
+			run = docker.ExecInteractiveAsRoot
+			fmt.Fprintf(cmd.OutOrStdout(), "Running on_create command as root: %s...\n", c)
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "Running on_create command: %s...\n", c)
+		}
 		var actualCmd string
-		if verbose || isTruthyEnv("DV_VERBOSE") {
+		if verbose || logging.DebugEnabled() {
 			actualCmd = c
 		} else {
 			// Redirecting to a log file inside the container to avoid noise.
@@ -641,8 +893,8 @@ func executeTemplate(cmd *cobra.Command, cfg config.Config, name, workdir string
 			actualCmd = fmt.Sprintf(": >> %s; %s >> %s 2>&1; : >> %s", logFile, c, logFile, logFile)
 		}
 
-		if err = docker.ExecInteractive(name, workdir, envList, []string{"bash", "-lc", actualCmd}); err != nil {
-			if !verbose && !isTruthyEnv("DV_VERBOSE") {
+		if err = run(name, workdir, envList, []string{"bash", "-lc", actualCmd}); err != nil {
+			if !verbose && !logging.DebugEnabled() {
 				logFile := fmt.Sprintf("/tmp/dv-on-create-%d.log", i)
 				fmt.Fprintf(cmd.ErrOrStderr(), "on_create command failed. Log content:\n")
 				if logContent, logErr := docker.ExecOutput(name, workdir, nil, []string{"cat", logFile}); logErr == nil {
@@ -653,26 +905,24 @@ func executeTemplate(cmd *cobra.Command, cfg config.Config, name, workdir string
 			}
 			return fmt.Errorf("on_create command failed: %s: %w", c, err)
 		}
+		progress.mark(cmd, step)
 	}
 
 	// MCP
-	for _, m := range tpl.MCP {
-		fmt.Fprintf(cmd.OutOrStdout(), "Configuring MCP %s...\n", m.Name)
-		mcpCfg := mcpConfiguration{
-			name: m.Name,
+	for i, m := range tpl.MCP {
+		step := fmt.Sprintf("mcp:%d", i)
+		if progress.skip(cmd, step, "MCP "+m.Name) {
+			continue
 		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Configuring MCP %s...\n", m.Name)
 		if m.Command != "" {
 			// Custom MCP
-			mcpCfg.registrationCmd = fmt.Sprintf("claude mcp add -s user %s -- %s %s", m.Name, m.Command, strings.Join(m.Args, " "))
-			mcpCfg.codexCommand = m.Command
-			mcpCfg.codexArgs = m.Args
-			mcpCfg.geminiCommand = m.Command
-			mcpCfg.geminiArgs = m.Args
+			mcpCfg := customMCPConfiguration(m.Name, m.Command, m.Args, nil)
 			if err = configureMCP(cmd, name, workdir, envList, mcpCfg); err != nil {
 				return fmt.Errorf("failed to configure custom MCP %s: %w", m.Name, err)
 			}
 		} else {
-			// Stock MCP (playwright, discourse, chrome-devtools)
+			// Stock MCP (playwright, discourse, chrome-devtools, filesystem, fetch)
 			switch m.Name {
 			case "playwright":
 				if err = configurePlaywrightMCP(cmd, name, workdir, envList); err != nil {
@@ -686,12 +936,22 @@ func executeTemplate(cmd *cobra.Command, cfg config.Config, name, workdir string
 				if err = configureChromeDevToolsMCP(cmd, name, workdir, envList); err != nil {
 					return err
 				}
+			case "filesystem":
+				if err = configureFilesystemMCP(cmd, name, workdir, envList, workdir); err != nil {
+					return err
+				}
+			case "fetch":
+				if err = configureFetchMCP(cmd, name, workdir, envList); err != nil {
+					return err
+				}
 			default:
 				return fmt.Errorf("unknown stock MCP: %s", m.Name)
 			}
 		}
+		progress.mark(cmd, step)
 	}
 
+	progress.mark(cmd, provisionStepComplete)
 	return nil
 }
 
@@ -701,11 +961,18 @@ func init() {
 	newCmd.Flags().Bool("keep-on-failure", false, "Keep the container even if provisioning fails")
 	newCmd.Flags().BoolP("verbose", "v", false, "Print verbose debugging output")
 	newCmd.Flags().String("pr", "", "PR number or search query to checkout")
+	newCmd.Flags().Bool("no-cache", false, "Refetch PR details instead of using the short-lived local cache")
 	newCmd.Flags().String("branch", "", "Branch to checkout")
 	newCmd.Flags().StringArray("plugin", nil, "Clone plugin into the new agent (NAME, OWNER/REPO, or git URL; repeatable)")
 	newCmd.Flags().StringArray("plugin-local", nil, "Bind-mount a local plugin directory into the new agent (PATH to a plugin repo; repeatable)")
 	newCmd.Flags().StringArray("theme", nil, "Install and enable theme/component (NAME, OWNER/REPO[#PR], git URL, or GitHub PR URL; repeatable)")
 	newCmd.Flags().Bool("without-test-db", false, "Skip test database migration during provisioning")
+	newCmd.Flags().StringArray("volume", nil, "Bind-mount a host directory into the new agent (HOST:CONTAINER[:ro]; repeatable)")
+	newCmd.Flags().Int("health-timeout", 0, "Seconds to wait for Discourse to become healthy after provisioning with --template, --pr or --branch (overrides the template; default 120)")
+	newCmd.Flags().Bool("dry-run", false, "Print the provisioning plan without creating anything")
+	newCmd.Flags().Bool("force-volume", false, "Allow volumes that would shadow the Discourse workdir")
+	newCmd.Flags().String("from", "", "Start from a snapshot of an existing agent instead of the image")
+	newCmd.Flags().Bool("resume", false, "Finish provisioning an existing agent whose earlier dv new failed (see --keep-on-failure)")
 
 	newCmd.RegisterFlagCompletionFunc("pr", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		configDir, err := xdg.ConfigDir()
@@ -716,7 +983,46 @@ func init() {
 		if err != nil {
 			return nil, cobra.ShellCompDirectiveNoFileComp
 		}
-		owner, repo := ownerRepoFromURL(cfg.DiscourseRepo)
-		return SuggestPRNumbers(owner, repo, toComplete)
+		host, owner, repo := ownerRepoFromURL(cfg.DiscourseRepo)
+		return SuggestPRNumbers(host, owner, repo, toComplete, false)
 	})
 }
+
+// resolveVolumeMount parses a "HOST:CONTAINER[:ro|rw]" volume spec, checks
+// that the host path exists, and rejects container paths that would hide the
+// Discourse checkout at workdir (the workdir itself or any parent) unless
+// force is set.
+func resolveVolumeMount(spec, workdir string, force bool) (templateMount, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return templateMount{}, fmt.Errorf("invalid volume %q: expected HOST:CONTAINER[:ro]", spec)
+	}
+	readOnly := false
+	if len(parts) == 3 {
+		switch parts[2] {
+		case "ro":
+			readOnly = true
+		case "rw":
+		default:
+			return templateMount{}, fmt.Errorf("invalid volume %q: mode must be ro or rw", spec)
+		}
+	}
+	if !path.IsAbs(parts[1]) {
+		return templateMount{}, fmt.Errorf("invalid volume %q: container path must be absolute", spec)
+	}
+	container := path.Clean(parts[1])
+
+	host := expandHostPath(parts[0])
+	if _, err := os.Stat(host); err != nil {
+		return templateMount{}, fmt.Errorf("volume %q: host path %s does not exist", spec, host)
+	}
+
+	if workdir == "" {
+		workdir = "/var/www/discourse"
+	}
+	workdir = path.Clean(workdir)
+	if !force && (container == workdir || container == "/" || strings.HasPrefix(workdir, container+"/")) {
+		return templateMount{}, fmt.Errorf("volume %q would shadow %s; pass --force-volume to allow it", spec, workdir)
+	}
+	return templateMount{Host: host, Container: container, ReadOnly: readOnly}, nil
+}
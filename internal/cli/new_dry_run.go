@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// provisionPlan describes what `dv new` would do, for --dry-run. steps mirrors
+// the order of newCmd and executeTemplate; keep them in sync.
+type provisionPlan struct {
+	Name          string
+	ImageName     string
+	ImageTag      string
+	Workdir       string
+	TemplatePath  string
+	Template      *templateConfig
+	WithoutTestDB bool
+	FromAgent     string
+	GitUserName   string
+	GitUserEmail  string
+	// DiscourseUpstream is the upstream remote added when the template's
+	// discourse.repo is a fork.
+	DiscourseUpstream string
+}
+
+func (p provisionPlan) steps() []string {
+	var steps []string
+	add := func(format string, args ...any) {
+		steps = append(steps, fmt.Sprintf(format, args...))
+	}
+
+	if p.TemplatePath != "" {
+		add("Load template %s", p.TemplatePath)
+	}
+	if p.FromAgent != "" {
+		add("Snapshot agent '%s' (not checked in a dry run) into temporary image %s", p.FromAgent, p.ImageTag)
+	}
+	add("Use image '%s' (%s) with workdir %s", p.ImageName, p.ImageTag, p.Workdir)
+
+	tpl := p.Template
+	if tpl == nil {
+		add("Create and start container '%s'", p.Name)
+		add("Select '%s' as the current agent", p.Name)
+		p.addGitIdentityStep(add)
+		add("Run post_create/post_start host hooks, if configured")
+		return steps
+	}
+
+	if len(tpl.Mounts) > 0 {
+		mounts := make([]string, 0, len(tpl.Mounts))
+		for _, m := range tpl.Mounts {
+			spec := m.Host + ":" + m.Container
+			if m.ReadOnly {
+				spec += ":ro"
+			}
+			mounts = append(mounts, spec)
+		}
+		add("Create and start container '%s' with mounts %s", p.Name, strings.Join(mounts, ", "))
+	} else {
+		add("Create and start container '%s'", p.Name)
+	}
+	add("Select '%s' as the current agent", p.Name)
+
+	if len(tpl.Env) > 0 {
+		keys := make([]string, 0, len(tpl.Env))
+		for k := range tpl.Env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		add("Set environment variables %s", strings.Join(keys, ", "))
+	}
+	if tpl.sshForward() {
+		add("Forward the host SSH agent into the container")
+	}
+	add("Stop rails/ember for provisioning")
+
+	if tpl.Discourse.Repo != "" {
+		add("Point origin at %s and fetch", tpl.Discourse.Repo)
+		if p.DiscourseUpstream != "" {
+			add("Add upstream remote %s and fetch", p.DiscourseUpstream)
+		}
+	}
+	switch {
+	case tpl.Discourse.PR != 0:
+		add("Check out Discourse PR #%d and reset databases", tpl.Discourse.PR)
+	case tpl.Discourse.Branch != "" && tpl.Discourse.Repo != "":
+		add("Check out branch %s from origin", tpl.Discourse.Branch)
+	case tpl.Discourse.Branch == "main" || tpl.Discourse.Branch == "master":
+		add("Update %s branch", tpl.Discourse.Branch)
+	case tpl.Discourse.Branch != "":
+		add("Check out branch %s and reset databases", tpl.Discourse.Branch)
+	}
+
+	p.addGitIdentityStep(add)
+
+	for _, pl := range tpl.Plugins {
+		dst := strings.TrimSpace(pl.Path)
+		if dst == "" {
+			dst = path.Join("plugins", pluginRepoName(pl.Repo))
+		}
+		if pl.Branch != "" {
+			add("Clone plugin %s (branch %s) into %s", pl.Repo, pl.Branch, dst)
+		} else {
+			add("Clone plugin %s into %s", pl.Repo, dst)
+		}
+	}
+	for _, rule := range tpl.Copy {
+		add("Copy %s to %s", rule.Host, rule.Container)
+	}
+
+	if p.WithoutTestDB {
+		add("Run maintenance: bundle install, migrate dev database")
+	} else {
+		add("Run maintenance: bundle install, migrate dev and test databases")
+	}
+
+	if tpl.waitsForHealth() {
+		onTimeout := "warn"
+		if tpl.requireHealthy() {
+			onTimeout = "fail"
+		}
+		add("Start rails/ember and wait up to %ds for /srv/status (%s on timeout)", tpl.healthTimeout(), onTimeout)
+	} else {
+		add("Start rails/ember")
+	}
+
+	if len(tpl.Settings) > 0 {
+		keys := make([]string, 0, len(tpl.Settings))
+		for k := range tpl.Settings {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		add("Apply site settings %s", strings.Join(keys, ", "))
+	}
+	for _, t := range tpl.Themes {
+		desc := t.Repo
+		if t.Branch != "" {
+			desc += " (branch " + t.Branch + ")"
+		} else if t.PR != 0 {
+			desc += fmt.Sprintf(" (PR #%d)", t.PR)
+		}
+		add("Install theme %s", desc)
+	}
+	for _, c := range tpl.OnCreate {
+		if c.Root {
+			add("Run on_create as root: %s", firstLine(c.Cmd))
+		} else {
+			add("Run on_create: %s", firstLine(c.Cmd))
+		}
+	}
+	for _, m := range tpl.MCP {
+		if m.Command != "" {
+			add("Register custom MCP server %s (%s)", m.Name, strings.TrimSpace(m.Command+" "+strings.Join(m.Args, " ")))
+		} else {
+			add("Register stock MCP server %s", m.Name)
+		}
+	}
+	add("Run post_create/post_start host hooks, if configured")
+	return steps
+}
+
+func (p provisionPlan) addGitIdentityStep(add func(string, ...any)) {
+	if p.GitUserName != "" || p.GitUserEmail != "" {
+		add("Set git identity %s", gitIdentityLabel(p.GitUserName, p.GitUserEmail))
+	}
+}
+
+// firstLine shortens multi-line commands for one-line plan output.
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if idx := strings.IndexByte(s, '\n'); idx >= 0 {
+		return s[:idx] + " ..."
+	}
+	return s
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"dv/internal/config"
+	"dv/internal/docker"
+	"dv/internal/session"
+)
+
+// provisionProgressPath records the executeTemplate steps that finished, one
+// per line, so `dv new NAME --resume` can pick up after a failure.
+const provisionProgressPath = "/home/discourse/.dv-provision-progress"
+
+// provisionStepComplete is written once executeTemplate returns successfully.
+const provisionStepComplete = "complete"
+
+type provisionProgress struct {
+	container string
+	done      map[string]bool
+}
+
+// newProvisionProgress starts tracking a fresh provision, discarding any
+// marker left in the container (e.g. one committed into a --from snapshot).
+func newProvisionProgress(container string) *provisionProgress {
+	_, _ = docker.ExecOutput(container, "/", nil, []string{"rm", "-f", provisionProgressPath})
+	return &provisionProgress{container: container, done: map[string]bool{}}
+}
+
+// loadProvisionProgress reads the marker written by an earlier provision.
+func loadProvisionProgress(container string) *provisionProgress {
+	out, _ := docker.ExecOutput(container, "/", nil, []string{"bash", "-c", "cat " + provisionProgressPath + " 2>/dev/null || true"})
+	return &provisionProgress{container: container, done: parseProvisionProgress(out)}
+}
+
+func parseProvisionProgress(out string) map[string]bool {
+	done := map[string]bool{}
+	for _, line := range strings.Split(out, "\n") {
+		if step := strings.TrimSpace(line); step != "" {
+			done[step] = true
+		}
+	}
+	return done
+}
+
+// skip reports whether step already finished, printing a note when it did.
+func (p *provisionProgress) skip(cmd *cobra.Command, step, desc string) bool {
+	if !p.done[step] {
+		return false
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Skipping %s (already done).\n", desc)
+	return true
+}
+
+// mark records step as finished. Failing to write the marker only costs a
+// repeated step on resume, so it is not fatal.
+func (p *provisionProgress) mark(cmd *cobra.Command, step string) {
+	p.done[step] = true
+	script := fmt.Sprintf("echo %s >> %s", shellQuote(step), provisionProgressPath)
+	if _, err := docker.ExecOutput(p.container, "/", nil, []string{"bash", "-c", script}); err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: could not record provisioning step %s: %v\n", step, err)
+	}
+}
+
+// resumeProvisioning re-runs executeTemplate against an existing container,
+// skipping the steps its progress marker lists as done. Host hooks that the
+// failed `dv new` never reached run once provisioning completes.
+func resumeProvisioning(cmd *cobra.Command, cfg config.Config, configDir, name, workdir string, tpl *templateConfig, verbose, withoutTestDB bool) error {
+	if tpl == nil {
+		return fmt.Errorf("nothing to resume for '%s': pass the same --template (or --pr/--branch/--plugin flags) used to create it", name)
+	}
+	progress := loadProvisionProgress(name)
+	if progress.done[provisionStepComplete] {
+		fmt.Fprintf(cmd.OutOrStdout(), "Provisioning of '%s' already completed; nothing to resume.\n", name)
+		return nil
+	}
+
+	started := false
+	if !docker.Running(name) {
+		fmt.Fprintf(cmd.OutOrStdout(), "Starting container '%s'...\n", name)
+		if err := docker.Start(name); err != nil {
+			return err
+		}
+		started = true
+	}
+
+	if err := session.SetCurrentAgent(name); err != nil {
+		return fmt.Errorf("could not save session state: %w", err)
+	}
+	cfg.SelectedAgent = name
+	if err := config.Save(configDir, cfg); err != nil {
+		return err
+	}
+
+	sshAuthSock := ""
+	if tpl.sshForward() {
+		sshAuthSock = os.Getenv("SSH_AUTH_SOCK")
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Resuming provisioning of '%s'...\n", name)
+	if err := executeTemplate(cmd, cfg, name, workdir, tpl, sshAuthSock, verbose, withoutTestDB, progress); err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "\nProvisioning failed: %v\nFix the problem and run 'dv new %s --resume' again.\n", err, name)
+		return err
+	}
+
+	hookCtx := hostHookContext{
+		CommandName:   "new",
+		ContainerName: name,
+		Workdir:       workdir,
+		ContainerPort: cfg.ContainerPort,
+		ConfigDir:     configDir,
+	}
+	if hostPort, err := docker.GetContainerHostPort(name, cfg.ContainerPort); err == nil {
+		hookCtx.HostPort = hostPort
+	}
+	if err := runHostHooksForContainer(cmd, cfg, hostHookPostCreate, hookCtx); err != nil {
+		return err
+	}
+	if started {
+		if err := runHostHooksForContainer(cmd, cfg, hostHookPostStart, hookCtx); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Agent '%s' is ready and selected.\n", name)
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/spf13/cobra"
+
+	"dv/internal/config"
 )
 
 func TestConfirmInvalidRailsHostname(t *testing.T) {
@@ -102,3 +104,205 @@ func TestNewFailureCleanupGuards(t *testing.T) {
 type errTestNewFailure struct{}
 
 func (errTestNewFailure) Error() string { return "boom" }
+
+func TestResolveVolumeMount(t *testing.T) {
+	t.Parallel()
+
+	host := t.TempDir()
+	tests := []struct {
+		name    string
+		spec    string
+		force   bool
+		want    templateMount
+		wantErr string
+	}{
+		{name: "read write", spec: host + ":/home/discourse/data", want: templateMount{Host: host, Container: "/home/discourse/data"}},
+		{name: "read only", spec: host + ":/home/discourse/data/:ro", want: templateMount{Host: host, Container: "/home/discourse/data", ReadOnly: true}},
+		{name: "plugin subdirectory allowed", spec: host + ":/var/www/discourse/plugins/foo", want: templateMount{Host: host, Container: "/var/www/discourse/plugins/foo"}},
+		{name: "missing host", spec: host + "/missing:/data", wantErr: "does not exist"},
+		{name: "relative container", spec: host + ":data", wantErr: "must be absolute"},
+		{name: "bad mode", spec: host + ":/data:rx", wantErr: "mode must be ro or rw"},
+		{name: "no container", spec: host, wantErr: "expected HOST:CONTAINER"},
+		{name: "shadows workdir", spec: host + ":/var/www/discourse", wantErr: "would shadow"},
+		{name: "shadows parent", spec: host + ":/var/www", wantErr: "would shadow"},
+		{name: "shadows root", spec: host + ":/", wantErr: "would shadow"},
+		{name: "forced", spec: host + ":/var/www/discourse", force: true, want: templateMount{Host: host, Container: "/var/www/discourse"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := resolveVolumeMount(tt.spec, "/var/www/discourse", tt.force)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolveVolumeMount(%q) error = %v, want %q", tt.spec, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveVolumeMount(%q) unexpected error: %v", tt.spec, err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveVolumeMount(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProvisionPlanSteps(t *testing.T) {
+	t.Parallel()
+
+	tpl := &templateConfig{}
+	tpl.Discourse.PR = 123
+	tpl.Env = map[string]string{"B": "2", "A": "1"}
+	tpl.Plugins = []templatePlugin{{Repo: "https://github.com/discourse/discourse-ai.git"}}
+	tpl.Settings = map[string]any{"title": "x"}
+	tpl.OnCreate = []templateCommand{{Cmd: "echo one\necho two"}}
+	tpl.MCP = []templateMCP{{Name: "playwright"}}
+
+	steps := provisionPlan{
+		Name:      "agent",
+		ImageName: "discourse",
+		ImageTag:  "ai_agent",
+		Workdir:   "/var/www/discourse",
+		Template:  tpl,
+	}.steps()
+	joined := strings.Join(steps, "\n")
+
+	ordered := []string{
+		"Use image 'discourse' (ai_agent)",
+		"Create and start container 'agent'",
+		"Set environment variables A, B",
+		"Check out Discourse PR #123",
+		"Clone plugin https://github.com/discourse/discourse-ai.git into plugins/discourse-ai",
+		"migrate dev and test databases",
+		"Start rails/ember and wait up to 120s for /srv/status (warn on timeout)",
+		"Apply site settings title",
+		"Run on_create: echo one ...",
+		"Register stock MCP server playwright",
+	}
+	last := -1
+	for _, want := range ordered {
+		idx := strings.Index(joined, want)
+		if idx < 0 {
+			t.Fatalf("plan missing %q:\n%s", want, joined)
+		}
+		if idx < last {
+			t.Fatalf("plan step %q out of order:\n%s", want, joined)
+		}
+		last = idx
+	}
+}
+
+func TestProvisionPlanStepsFromAgent(t *testing.T) {
+	t.Parallel()
+
+	steps := provisionPlan{
+		Name:      "copy",
+		ImageName: "discourse",
+		ImageTag:  fromSnapshotImageTag("Copy"),
+		Workdir:   "/var/www/discourse",
+		FromAgent: "orig",
+	}.steps()
+	if len(steps) < 2 {
+		t.Fatalf("plan too short: %v", steps)
+	}
+	if want := "Snapshot agent 'orig' (not checked in a dry run) into temporary image copy-dv-from-snapshot"; steps[0] != want {
+		t.Fatalf("steps[0] = %q, want %q", steps[0], want)
+	}
+	if !strings.Contains(steps[1], "(copy-dv-from-snapshot)") {
+		t.Fatalf("steps[1] = %q, want snapshot tag", steps[1])
+	}
+}
+
+func TestHealthTimeout(t *testing.T) {
+	t.Parallel()
+
+	tpl := &templateConfig{}
+	if got := tpl.healthTimeout(); got != defaultHealthTimeoutSeconds {
+		t.Fatalf("default healthTimeout() = %d, want %d", got, defaultHealthTimeoutSeconds)
+	}
+	if tpl.waitsForHealth() {
+		t.Fatal("empty template should not wait for health")
+	}
+	tpl.HealthTimeoutSeconds = 600
+	if got := tpl.healthTimeout(); got != 600 {
+		t.Fatalf("healthTimeout() = %d, want 600", got)
+	}
+	if !tpl.waitsForHealth() {
+		t.Fatal("an explicit health timeout should make provisioning wait")
+	}
+}
+
+func TestResolveGitIdentity(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{GitUserName: "Default Dev", GitUserEmail: "dev@example.com"}
+	withName := &templateConfig{}
+	withName.Git.UserName = "Template Bot"
+
+	tests := []struct {
+		name      string
+		cfg       config.Config
+		tpl       *templateConfig
+		wantName  string
+		wantEmail string
+	}{
+		{name: "config only", cfg: cfg, wantName: "Default Dev", wantEmail: "dev@example.com"},
+		{name: "template overrides field by field", cfg: cfg, tpl: withName, wantName: "Template Bot", wantEmail: "dev@example.com"},
+		{name: "nothing configured", tpl: &templateConfig{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotName, gotEmail := resolveGitIdentity(tt.cfg, tt.tpl)
+			if gotName != tt.wantName || gotEmail != tt.wantEmail {
+				t.Errorf("resolveGitIdentity() = %q, %q; want %q, %q", gotName, gotEmail, tt.wantName, tt.wantEmail)
+			}
+		})
+	}
+}
+
+func TestDiscourseForkUpstream(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{DiscourseRepo: "https://github.com/discourse/discourse.git"}
+	tests := []struct {
+		name string
+		cfg  config.Config
+		repo string
+		want string
+	}{
+		{name: "upstream itself", cfg: cfg, repo: "git@github.com:discourse/discourse.git", want: ""},
+		{name: "fork over https", cfg: cfg, repo: "https://github.com/me/discourse", want: "https://github.com/discourse/discourse.git"},
+		{name: "renamed fork", cfg: cfg, repo: "git@github.com:acme/discourse-fork.git", want: "https://github.com/discourse/discourse.git"},
+		{name: "empty config uses default", repo: "https://github.com/me/discourse.git", want: "https://github.com/discourse/discourse.git"},
+		{name: "non-github mirror of itself", cfg: config.Config{DiscourseRepo: "https://git.example.com/discourse.git"}, repo: "https://git.example.com/discourse/", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := discourseForkUpstream(tt.cfg, tt.repo); got != tt.want {
+				t.Errorf("discourseForkUpstream(%q) = %q, want %q", tt.repo, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseProvisionProgress(t *testing.T) {
+	t.Parallel()
+
+	done := parseProvisionProgress("foundation\nplugins\n\n theme:0 \n")
+	for _, step := range []string{"foundation", "plugins", "theme:0"} {
+		if !done[step] {
+			t.Errorf("step %q not parsed as done: %v", step, done)
+		}
+	}
+	if done["maintenance"] || done[provisionStepComplete] {
+		t.Errorf("unexpected steps marked done: %v", done)
+	}
+	if got := parseProvisionProgress(""); len(got) != 0 {
+		t.Errorf("parseProvisionProgress(\"\") = %v, want empty", got)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/spf13/cobra"
 
@@ -171,8 +172,24 @@ func stopServicesForProvisioning(cmd *cobra.Command, name, workdir string) func(
 	}
 }
 
+// pluginCloneConcurrency bounds how many plugin clones run at once.
+const pluginCloneConcurrency = 4
+
+type pluginCloneResult struct {
+	output  string
+	err     error
+	skipped bool
+}
+
+// installPlugins clones plugins concurrently (at most pluginCloneConcurrency at
+// a time), buffering each clone's output and printing it in template order.
+// The first failure, in that order, aborts; clones not yet started are skipped.
 func installPlugins(cmd *cobra.Command, containerName, workdir string, envs docker.Envs, plugins []templatePlugin) error {
-	for _, p := range plugins {
+	if len(plugins) == 0 {
+		return nil
+	}
+	dsts := make([]string, len(plugins))
+	for i, p := range plugins {
 		pPath := strings.TrimSpace(p.Path)
 		if pPath == "" {
 			pPath = path.Join("plugins", pluginRepoName(p.Repo))
@@ -180,16 +197,80 @@ func installPlugins(cmd *cobra.Command, containerName, workdir string, envs dock
 		if pPath == "" || pPath == "plugins" || pPath == "." {
 			return fmt.Errorf("could not determine plugin path for %s", p.Repo)
 		}
+		dsts[i] = pPath
+	}
 
-		fmt.Fprintf(cmd.OutOrStdout(), "Installing plugin %s into %s...\n", p.Repo, pPath)
-		cloneCmd := buildPluginCloneScript(p.Repo, pPath, p.Branch)
-		if err := docker.ExecInteractive(containerName, workdir, envs, []string{"bash", "-lc", cloneCmd}); err != nil {
-			return fmt.Errorf("failed to clone plugin %s: %w", p.Repo, err)
+	if len(plugins) > 1 {
+		fmt.Fprintf(cmd.OutOrStdout(), "Cloning %d plugins (up to %d at a time)...\n", len(plugins), pluginCloneConcurrency)
+	}
+	// Parallel clones have no terminal to prompt on: fail fast on missing
+	// credentials, and accept (but still verify later) unknown SSH host keys
+	// rather than stalling on the known_hosts prompt.
+	cloneEnvs := append(append(docker.Envs{}, envs...), "GIT_TERMINAL_PROMPT=0", "GIT_SSH_COMMAND="+pluginCloneSSHCommand)
+	results := runPluginClones(len(plugins), pluginCloneConcurrency, func(i int) (string, error) {
+		cloneCmd := buildPluginCloneScript(plugins[i].Repo, dsts[i], plugins[i].Branch)
+		return docker.ExecCombinedOutput(containerName, workdir, cloneEnvs, []string{"bash", "-lc", cloneCmd})
+	})
+
+	for i, p := range plugins {
+		res := results[i]
+		if res.skipped {
+			continue
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Installing plugin %s into %s...\n", p.Repo, dsts[i])
+		if out := strings.TrimSpace(res.output); out != "" {
+			fmt.Fprintln(cmd.OutOrStdout(), out)
+		}
+		if res.err != nil {
+			return fmt.Errorf("failed to clone plugin %s: %w", p.Repo, res.err)
 		}
 	}
 	return nil
 }
 
+// pluginCloneSSHCommand is the ssh git uses for plugin clones.
+const pluginCloneSSHCommand = "ssh -o StrictHostKeyChecking=accept-new -o BatchMode=yes"
+
+// runPluginClones runs clone(i) for i in [0,n) with at most limit in flight.
+// Once any clone fails, clones that have not started yet are skipped.
+func runPluginClones(n, limit int, clone func(i int) (string, error)) []pluginCloneResult {
+	results := make([]pluginCloneResult, n)
+	if limit < 1 {
+		limit = 1
+	}
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed bool
+	)
+	sem := make(chan struct{}, limit)
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		mu.Lock()
+		stop := failed
+		mu.Unlock()
+		if stop {
+			<-sem
+			results[i].skipped = true
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			out, err := clone(i)
+			results[i] = pluginCloneResult{output: out, err: err}
+			if err != nil {
+				mu.Lock()
+				failed = true
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
 func buildPluginCloneScript(repo, dst, branch string) string {
 	cloneArgs := []string{"git", "clone"}
 	if branch != "" {
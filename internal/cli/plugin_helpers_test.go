@@ -1,9 +1,13 @@
 package cli
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestResolvePluginSpec(t *testing.T) {
@@ -129,3 +133,53 @@ func TestPluginNameFromRB(t *testing.T) {
 		}
 	}
 }
+
+func TestRunPluginClonesBoundsConcurrency(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	results := runPluginClones(10, 3, func(i int) (string, error) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return fmt.Sprintf("cloned %d", i), nil
+	})
+	if maxInFlight > 3 {
+		t.Fatalf("max concurrent clones = %d, want <= 3", maxInFlight)
+	}
+	for i, res := range results {
+		if res.err != nil || res.skipped || res.output != fmt.Sprintf("cloned %d", i) {
+			t.Fatalf("result %d = %+v", i, res)
+		}
+	}
+}
+
+func TestRunPluginClonesSkipsAfterFailure(t *testing.T) {
+	t.Parallel()
+
+	results := runPluginClones(5, 1, func(i int) (string, error) {
+		if i == 1 {
+			return "boom", errors.New("clone failed")
+		}
+		return "", nil
+	})
+	if results[0].err != nil || results[0].skipped {
+		t.Fatalf("result 0 = %+v, want success", results[0])
+	}
+	if results[1].err == nil {
+		t.Fatalf("result 1 = %+v, want error", results[1])
+	}
+	for i := 2; i < 5; i++ {
+		if !results[i].skipped {
+			t.Fatalf("result %d = %+v, want skipped after failure", i, results[i])
+		}
+	}
+}
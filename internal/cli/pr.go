@@ -12,7 +12,7 @@ import (
 )
 
 // prCmd implements: dv pr [--name NAME] [--no-reset] NUMBER
-// - Checks out the given GitHub PR in the container's repo workdir
+// - Checks out the given GitHub PR (or GitLab merge request) in the container's repo workdir
 // - Resets DB and runs migrations and seed (mirrors Dockerfile init) unless --no-reset is specified
 var prCmd = &cobra.Command{
 	Use:   "pr [--name NAME] [--no-reset] NUMBER",
@@ -40,12 +40,13 @@ var prCmd = &cobra.Command{
 		}
 
 		// Determine repo owner/name from container remotes (prefer upstream for forks)
-		owner, repo := prSearchOwnerRepoFromContainer(cfg, name)
+		host, owner, repo := prSearchOwnerRepoFromContainer(cfg, name)
 		if owner == "" || repo == "" {
 			// Fallback to configured discourse repo
-			owner, repo = ownerRepoFromURL(cfg.DiscourseRepo)
+			host, owner, repo = ownerRepoFromURL(cfg.DiscourseRepo)
 		}
-		return SuggestPRNumbers(owner, repo, toComplete)
+		noDrafts, _ := cmd.Flags().GetBool("no-drafts")
+		return SuggestPRNumbers(host, owner, repo, toComplete, noDrafts)
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Load config and container details
@@ -58,88 +59,135 @@ var prCmd = &cobra.Command{
 			return err
 		}
 
-		// Parse PR number or search query
-		prNumber, err := ResolvePR(cmd, cfg, strings.TrimSpace(args[0]))
-		if err != nil {
-			return err
-		}
-
 		name, _ := cmd.Flags().GetString("name")
 		if name == "" {
 			name = currentAgentName(cfg)
 		}
-
-		if !docker.Exists(name) {
-			fmt.Fprintf(cmd.OutOrStdout(), "Container '%s' does not exist. Run 'dv start' first.\n", name)
-			return nil
-		}
-		if !docker.Running(name) {
-			fmt.Fprintf(cmd.OutOrStdout(), "Starting container '%s'...\n", name)
-			if err := startContainerWithPostStartHook(cmd, cfg, configDir, name, "pr"); err != nil {
-				return err
-			}
+		// Resolve against the repo the PR is checked out from: the container's
+		// remotes (upstream for forks), else the configured discourse repo.
+		host, owner, repo := prSearchOwnerRepoFromContainer(cfg, name)
+		if owner == "" || repo == "" {
+			host, owner, repo = ownerRepoFromURL(cfg.DiscourseRepo)
 		}
 
-		// Determine workdir from associated image
-		imgName := cfg.ContainerImages[name]
-		var imgCfg config.ImageConfig
-		if imgName != "" {
-			imgCfg = cfg.Images[imgName]
-		} else {
-			_, imgCfg, err = resolveImage(cfg, "")
-			if err != nil {
-				return err
-			}
-		}
-		workdir := imgCfg.Workdir
-		if strings.TrimSpace(workdir) == "" {
-			workdir = "/var/www/discourse"
-		}
-		if imgCfg.Kind != "discourse" {
-			return fmt.Errorf("'dv pr' is only supported for discourse image kind; current: %q", imgCfg.Kind)
+		// Parse PR number or search query
+		prNumber, err := ResolvePR(cmd, host, owner, repo, strings.TrimSpace(args[0]))
+		if err != nil {
+			return err
 		}
 
-		// Determine owner/repo for fetching PR details
-		owner, repo := prSearchOwnerRepoFromContainer(cfg, name)
-		if owner == "" || repo == "" {
-			// Fallback to configured discourse repo
-			owner, repo = ownerRepoFromURL(cfg.DiscourseRepo)
-		}
-		if owner == "" || repo == "" {
-			return fmt.Errorf("unable to determine repository owner/name for fetching PR details")
+		return checkoutPRInContainer(cmd, cfg, configDir, prNumber)
+	},
+}
+
+// checkoutPRInContainer checks out prNumber in the --name (or selected)
+// container, honouring --no-reset and --no-cache.
+func checkoutPRInContainer(cmd *cobra.Command, cfg config.Config, configDir string, prNumber int) error {
+	name, _ := cmd.Flags().GetString("name")
+	if name == "" {
+		name = currentAgentName(cfg)
+	}
+
+	if !docker.Exists(name) {
+		fmt.Fprintf(cmd.OutOrStdout(), "Container '%s' does not exist. Run 'dv start' first.\n", name)
+		return nil
+	}
+	if !docker.Running(name) {
+		fmt.Fprintf(cmd.OutOrStdout(), "Starting container '%s'...\n", name)
+		if err := startContainerWithPostStartHook(cmd, cfg, configDir, name, "pr"); err != nil {
+			return err
 		}
+	}
 
-		// Fetch PR details to get the actual branch name
-		fmt.Fprintf(cmd.OutOrStdout(), "Fetching PR #%d details from GitHub...\n", prNumber)
-		prDetail, err := fetchPRDetail(owner, repo, prNumber)
+	// Determine workdir from associated image
+	imgName := cfg.ContainerImages[name]
+	var imgCfg config.ImageConfig
+	if imgName != "" {
+		imgCfg = cfg.Images[imgName]
+	} else {
+		var err error
+		_, imgCfg, err = resolveImage(cfg, "")
 		if err != nil {
-			return fmt.Errorf("failed to fetch PR details: %w", err)
+			return err
 		}
-
+	}
+	workdir := imgCfg.Workdir
+	if strings.TrimSpace(workdir) == "" {
+		workdir = "/var/www/discourse"
+	}
+	if imgCfg.Kind != "discourse" {
+		return fmt.Errorf("'dv pr' is only supported for discourse image kind; current: %q", imgCfg.Kind)
+	}
+
+	// Determine owner/repo for fetching PR details
+	host, owner, repo := prSearchOwnerRepoFromContainer(cfg, name)
+	if owner == "" || repo == "" {
+		// Fallback to configured discourse repo
+		host, owner, repo = ownerRepoFromURL(cfg.DiscourseRepo)
+	}
+	if owner == "" || repo == "" {
+		return fmt.Errorf("unable to determine repository owner/name for fetching PR details")
+	}
+	forge, err := forgeForHost(host)
+	if err != nil {
+		return err
+	}
+
+	// Fetch PR details to get the actual branch name
+	warnIfNoGitHubToken(cmd.ErrOrStderr(), forge)
+	fmt.Fprintf(cmd.OutOrStdout(), "Fetching PR #%d details from %s...\n", prNumber, forge.name())
+	noCache, _ := cmd.Flags().GetBool("no-cache")
+	prDetail, err := cachedPRDetail(forge, host, owner, repo, prNumber, noCache)
+	if err != nil {
+		return fmt.Errorf("failed to fetch PR details: %w", explainForgeUnreachable(forge, err))
+	}
+
+	newBranch, _ := cmd.Flags().GetString("new-branch")
+	newBranch = strings.TrimSpace(newBranch)
+	fromHead, _ := cmd.Flags().GetBool("from-head")
+	if fromHead && newBranch == "" {
+		return fmt.Errorf("--from-head requires --new-branch")
+	}
+
+	var checkoutCmds []string
+	if newBranch != "" {
+		baseRef := strings.TrimSpace(prDetail.Base.Ref)
+		if baseRef == "" && !fromHead {
+			return fmt.Errorf("PR #%d has no base branch (base.ref is empty)", prNumber)
+		}
+		start := fmt.Sprintf("the base of PR #%d (%s)", prNumber, baseRef)
+		if fromHead {
+			start = fmt.Sprintf("the head of PR #%d", prNumber)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Creating branch %s from %s in container '%s'...\n", newBranch, start, name)
+		checkoutCmds = buildPRNewBranchCommands(prNumber, forge.prRef(prNumber), baseRef, newBranch, fromHead)
+	} else {
 		branchName := prDetail.Head.Ref
 		if branchName == "" {
 			return fmt.Errorf("PR #%d has no branch name (head.ref is empty)", prNumber)
 		}
-
-		noReset, _ := cmd.Flags().GetBool("no-reset")
-
 		fmt.Fprintf(cmd.OutOrStdout(), "Checking out PR #%d (%s) in container '%s'...\n", prNumber, branchName, name)
-
 		// Build shell script to fetch and checkout PR branch using the actual branch name
-		checkoutCmds := buildPRCheckoutCommands(prNumber, branchName)
-		script := buildDiscourseResetScript(checkoutCmds, discourseResetScriptOpts{SkipDBReset: noReset})
-
-		// Run interactively to stream output to the user
-		argv := []string{"bash", "-lc", script}
-		if err := docker.ExecInteractive(name, workdir, nil, argv); err != nil {
-			return fmt.Errorf("container: failed to checkout PR and migrate: %w", err)
-		}
-		return nil
-	},
+		checkoutCmds = buildPRCheckoutCommands(prNumber, forge.prRef(prNumber), branchName)
+	}
+
+	noReset, _ := cmd.Flags().GetBool("no-reset")
+	script := buildDiscourseResetScript(checkoutCmds, discourseResetScriptOpts{SkipDBReset: noReset})
+
+	// Run interactively to stream output to the user
+	argv := []string{"bash", "-lc", script}
+	if err := docker.ExecInteractive(name, workdir, nil, argv); err != nil {
+		return fmt.Errorf("container: failed to checkout PR and migrate: %w", err)
+	}
+	return nil
 }
 
 func init() {
-	prCmd.Flags().String("name", "", "Container name (defaults to selected or default)")
-	prCmd.Flags().Bool("no-reset", false, "Do not reset DB or run migrations; only checkout and reinstall deps")
+	prCmd.PersistentFlags().String("name", "", "Container name (defaults to selected or default)")
+	prCmd.PersistentFlags().Bool("no-reset", false, "Do not reset DB or run migrations; only checkout and reinstall deps")
+	prCmd.PersistentFlags().Bool("no-cache", false, "Refetch PR details instead of using the short-lived local cache")
+	prCmd.PersistentFlags().Bool("no-drafts", false, "Leave draft PRs out of completion and 'dv pr list'")
+	prCmd.Flags().String("new-branch", "", "Create this new local branch from the PR's base branch instead of checking out the PR")
+	prCmd.Flags().Bool("from-head", false, "With --new-branch, start the branch from the PR head instead of its base")
 	rootCmd.AddCommand(prCmd)
 }
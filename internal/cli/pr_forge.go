@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// prForge is the code host that pull requests are looked up on. GitHub calls
+// them pull requests and GitLab merge requests; both map onto ghPR/ghPRDetail.
+type prForge interface {
+	// name is the host's display name, used in progress messages.
+	name() string
+	// prRef is the ref the host publishes a PR's head commit under, so it
+	// can be fetched without knowing which fork the branch lives in.
+	prRef(prNumber int) string
+	fetchPRDetail(owner, repo string, prNumber int) (*ghPRDetail, error)
+	listOpenPRs(owner, repo string, limit int) ([]ghPR, error)
+	searchOpenPRs(owner, repo, query string, limit int) ([]ghPR, error)
+	// openPRsForBranch returns open PRs opened from the given head branch.
+	openPRsForBranch(owner, repo, branch string) ([]ghPR, error)
+}
+
+// forgeForHost picks the PR API for a remote host as returned by
+// ownerRepoFromURL. Self-hosted GitLab is recognised by "gitlab" in its name.
+func forgeForHost(host string) (prForge, error) {
+	switch {
+	case host == "github.com":
+		return githubForge{}, nil
+	case isGitLabHost(host):
+		return newGitLabForge(host), nil
+	case host == "":
+		return nil, fmt.Errorf("unable to determine the repository host")
+	default:
+		return nil, fmt.Errorf("pull requests on %s are not supported (supported: GitHub, GitLab)", host)
+	}
+}
+
+func isGitLabHost(host string) bool {
+	return host == "gitlab.com" || strings.HasPrefix(host, "gitlab.") || strings.Contains(host, ".gitlab.")
+}
+
+// prNumberFromURL recognises a pasted PR link, such as
+// https://github.com/discourse/discourse/pull/1234 (optionally followed by
+// /files etc.) or a GitLab .../-/merge_requests/12 link, and returns the
+// repository and PR number it points at.
+func prNumberFromURL(input string) (string, string, string, int, bool) {
+	if !strings.Contains(input, "://") {
+		return "", "", "", 0, false
+	}
+	u, err := url.Parse(strings.TrimSpace(input))
+	if err != nil {
+		return "", "", "", 0, false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if (parts[i] != "pull" && parts[i] != "merge_requests") || !isNumeric(parts[i+1]) {
+			continue
+		}
+		num, err := strconv.Atoi(parts[i+1])
+		if err != nil || num <= 0 {
+			return "", "", "", 0, false
+		}
+		host, owner, repo := ownerRepoFromURL(u.Scheme + "://" + u.Host + "/" + strings.Join(parts[:i], "/"))
+		return host, owner, repo, num, owner != "" && repo != ""
+	}
+	return "", "", "", 0, false
+}
+
+// ownerRepoFromURL extracts host and owner/repo from common remote URL
+// formats. Supports https and ssh formats; strips .git suffix. GitLab
+// projects may live in nested groups, so there owner is the full namespace
+// (e.g. "group/subgroup").
+func ownerRepoFromURL(remoteURL string) (string, string, string) {
+	s := strings.TrimSpace(remoteURL)
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "/"), ".git")
+	// Examples:
+	//  https://github.com/discourse/discourse
+	//  git@github.com:discourse/discourse
+	//  ssh://git@github.com/discourse/discourse
+	//  https://gitlab.example.com/group/subgroup/discourse
+	var host, path string
+	if strings.Contains(s, "://") {
+		u, err := url.Parse(s)
+		if err != nil {
+			return "", "", ""
+		}
+		host, path = u.Hostname(), u.Path
+	} else if at := strings.Index(s, "@"); at >= 0 {
+		hostPart, rest, ok := strings.Cut(s[at+1:], ":")
+		if !ok {
+			return "", "", ""
+		}
+		host, path = hostPart, rest
+	} else {
+		return "", "", ""
+	}
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	if host == "" {
+		return "", "", ""
+	}
+
+	var parts []string
+	for _, p := range strings.Split(path, "/") {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if isGitLabHost(host) {
+		// Drop web UI suffixes such as /-/merge_requests/12.
+		for i, p := range parts {
+			if p == "-" {
+				parts = parts[:i]
+				break
+			}
+		}
+		if len(parts) < 2 {
+			return "", "", ""
+		}
+		return host, strings.Join(parts[:len(parts)-1], "/"), parts[len(parts)-1]
+	}
+	if len(parts) < 2 {
+		return "", "", ""
+	}
+	return host, parts[0], parts[1]
+}
@@ -0,0 +1,340 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOwnerRepoFromURL(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		url               string
+		host, owner, repo string
+	}{
+		{"https://github.com/discourse/discourse.git", "github.com", "discourse", "discourse"},
+		{"git@github.com:discourse/discourse", "github.com", "discourse", "discourse"},
+		{"ssh://git@github.com/me/discourse.git", "github.com", "me", "discourse"},
+		{"https://github.com/discourse/discourse/pull/123", "github.com", "discourse", "discourse"},
+		{"https://gitlab.com/acme/discourse.git", "gitlab.com", "acme", "discourse"},
+		{"git@gitlab.example.com:forks/team/discourse.git", "gitlab.example.com", "forks/team", "discourse"},
+		{"https://gitlab.example.com/forks/discourse/-/merge_requests/7", "gitlab.example.com", "forks", "discourse"},
+		{"ssh://git@gitlab.com:2222/acme/discourse.git", "gitlab.com", "acme", "discourse"},
+		{"discourse/discourse", "", "", ""},
+		{"https://github.com/discourse", "", "", ""},
+	}
+	for _, tc := range cases {
+		host, owner, repo := ownerRepoFromURL(tc.url)
+		if host != tc.host || owner != tc.owner || repo != tc.repo {
+			t.Errorf("ownerRepoFromURL(%q) = %q, %q, %q; want %q, %q, %q", tc.url, host, owner, repo, tc.host, tc.owner, tc.repo)
+		}
+	}
+}
+
+func TestPRNumberFromURL(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		input             string
+		host, owner, repo string
+		num               int
+		ok                bool
+	}{
+		{"https://github.com/discourse/discourse/pull/1234", "github.com", "discourse", "discourse", 1234, true},
+		{"https://github.com/discourse/discourse/pull/1234/files#diff-1", "github.com", "discourse", "discourse", 1234, true},
+		{"https://gitlab.example.com/group/sub/discourse/-/merge_requests/12", "gitlab.example.com", "group/sub", "discourse", 12, true},
+		{"https://github.com/discourse/discourse/issues/1234", "", "", "", 0, false},
+		{"1234", "", "", "", 0, false},
+		{"feature/pull/12", "", "", "", 0, false},
+	}
+	for _, tc := range cases {
+		host, owner, repo, num, ok := prNumberFromURL(tc.input)
+		if host != tc.host || owner != tc.owner || repo != tc.repo || num != tc.num || ok != tc.ok {
+			t.Errorf("prNumberFromURL(%q) = %q, %q, %q, %d, %v", tc.input, host, owner, repo, num, ok)
+		}
+	}
+}
+
+func TestForgeForHost(t *testing.T) {
+	t.Parallel()
+
+	if f, err := forgeForHost("github.com"); err != nil || f.name() != "GitHub" {
+		t.Fatalf("forgeForHost(github.com) = %v, %v", f, err)
+	}
+	f, err := forgeForHost("gitlab.example.com")
+	if err != nil || f.name() != "GitLab" {
+		t.Fatalf("forgeForHost(gitlab.example.com) = %v, %v", f, err)
+	}
+	if got := f.prRef(12); got != "refs/merge-requests/12/head" {
+		t.Fatalf("GitLab prRef = %q", got)
+	}
+	if _, err := forgeForHost("bitbucket.org"); err == nil {
+		t.Fatal("expected error for unsupported host")
+	}
+}
+
+func TestGitLabForgeFetchesMergeRequests(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/forks%2Fteam%2Fdiscourse/merge_requests/7":
+			w.Write([]byte(`{"iid":7,"title":"Fix it","description":"body","source_branch":"fix-it","target_branch":"main"}`))
+		case "/api/v4/projects/forks%2Fteam%2Fdiscourse/merge_requests":
+			if r.URL.Query().Get("state") != "opened" || r.URL.Query().Get("search") != "fix" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			if r.URL.Query().Get("page") != "1" {
+				w.Write([]byte(`[]`))
+				return
+			}
+			w.Write([]byte(`[{"iid":7,"title":"Fix it","updated_at":"2025-01-02T03:04:05Z"},{"iid":5,"title":"Fix that","draft":true}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	forge := gitlabForge{apiBase: srv.URL + "/api/v4"}
+
+	detail, err := forge.fetchPRDetail("forks/team", "discourse", 7)
+	if err != nil {
+		t.Fatalf("fetchPRDetail() error = %v", err)
+	}
+	if detail.Number != 7 || detail.Head.Ref != "fix-it" || detail.Base.Ref != "main" || detail.Body != "body" {
+		t.Fatalf("unexpected detail: %+v", detail)
+	}
+
+	prs, err := forge.searchOpenPRs("forks/team", "discourse", "fix", 10)
+	if err != nil {
+		t.Fatalf("searchOpenPRs() error = %v", err)
+	}
+	if len(prs) != 2 || prs[0].Number != 7 || prs[0].UpdatedAt.IsZero() || !prs[1].Draft {
+		t.Fatalf("unexpected merge requests: %+v", prs)
+	}
+
+	if _, err := forge.fetchPRDetail("forks/team", "discourse", 99); err == nil {
+		t.Fatal("expected error for missing merge request")
+	}
+}
+
+// countingForge is a prForge that records how often PR details are fetched.
+type countingForge struct {
+	githubForge
+	fetches *int
+}
+
+func (f countingForge) fetchPRDetail(owner, repo string, prNumber int) (*ghPRDetail, error) {
+	*f.fetches++
+	detail := &ghPRDetail{Number: prNumber}
+	detail.Head.Ref = "feature"
+	return detail, nil
+}
+
+func TestCachedPRDetail(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	fetches := 0
+	forge := countingForge{fetches: &fetches}
+	for i := 0; i < 2; i++ {
+		detail, err := cachedPRDetail(forge, "github.com", "discourse", "discourse", 42, false)
+		if err != nil {
+			t.Fatalf("cachedPRDetail() error = %v", err)
+		}
+		if detail.Head.Ref != "feature" {
+			t.Fatalf("Head.Ref = %q", detail.Head.Ref)
+		}
+	}
+	if fetches != 1 {
+		t.Fatalf("fetches = %d, want 1 (second lookup should hit the cache)", fetches)
+	}
+
+	if _, err := cachedPRDetail(forge, "github.com", "discourse", "discourse", 43, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cachedPRDetail(forge, "github.com", "discourse", "discourse", 42, true); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 3 {
+		t.Fatalf("fetches = %d, want 3 (other PR and --no-cache should refetch)", fetches)
+	}
+}
+
+func TestCachedPRDetailKeepsExpiredEntriesForOfflineUse(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	expired := prDetailCacheEntry{FetchedAt: time.Now().Add(-time.Hour)}
+	expired.Detail.Head.Ref = "old-feature"
+	ancient := prDetailCacheEntry{FetchedAt: time.Now().Add(-2 * prDetailCacheMaxAge)}
+	savePRDetailCache(map[string]prDetailCacheEntry{
+		prDetailCacheKey("github.com", "discourse", "discourse", 1): expired,
+		prDetailCacheKey("github.com", "discourse", "discourse", 2): ancient,
+	})
+
+	fetches := 0
+	if _, err := cachedPRDetail(countingForge{fetches: &fetches}, "github.com", "discourse", "discourse", 3, false); err != nil {
+		t.Fatal(err)
+	}
+	entries := loadPRDetailCache()
+	if _, ok := entries[prDetailCacheKey("github.com", "discourse", "discourse", 2)]; ok {
+		t.Fatal("entry older than prDetailCacheMaxAge was kept")
+	}
+
+	detail, err := cachedPRDetail(offlineForge{}, "github.com", "discourse", "discourse", 1, false)
+	if err != nil || detail.Head.Ref != "old-feature" {
+		t.Fatalf("cachedPRDetail() offline = %+v, %v; want the expired entry", detail, err)
+	}
+}
+
+func TestGitHubGetRetriesOnceAfterRetryAfter(t *testing.T) {
+	var slept []time.Duration
+	orig := githubRetrySleep
+	githubRetrySleep = func(d time.Duration) { slept = append(slept, d) }
+	t.Cleanup(func() { githubRetrySleep = orig })
+
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	resp, err := githubGet(srv.Client(), srv.URL)
+	if err != nil {
+		t.Fatalf("githubGet() error = %v", err)
+	}
+	resp.Body.Close()
+	if requests != 2 || len(slept) != 1 || slept[0] != 2*time.Second {
+		t.Fatalf("requests = %d, slept = %v; want one retry after 2s", requests, slept)
+	}
+}
+
+func TestGitHubGetReportsRateLimitReset(t *testing.T) {
+	reset := time.Now().Add(10 * time.Minute).Unix()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	_, err := githubGet(srv.Client(), srv.URL)
+	var rlErr *githubRateLimitError
+	if !errors.As(err, &rlErr) {
+		t.Fatalf("githubGet() error = %v, want *githubRateLimitError", err)
+	}
+	if rlErr.reset.Unix() != reset || !strings.Contains(err.Error(), "resets at") {
+		t.Fatalf("unexpected rate limit error: %v", err)
+	}
+}
+
+func (f countingForge) searchOpenPRs(owner, repo, query string, limit int) ([]ghPR, error) {
+	*f.fetches++
+	return []ghPR{{Number: 1, Title: "Fix composer"}, {Number: 2, Title: "Unrelated"}}, nil
+}
+
+func TestCachedOpenPRsUsesCompletionCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	fetches := 0
+	forge := countingForge{fetches: &fetches}
+	for i := 0; i < 2; i++ {
+		prs, _, err := cachedOpenPRs(forge, "github.com", "discourse", "discourse", "Composer", 100)
+		if err != nil {
+			t.Fatalf("cachedOpenPRs() error = %v", err)
+		}
+		if len(prs) != 1 || prs[0].Number != 1 {
+			t.Fatalf("cachedOpenPRs() = %+v, want only #1", prs)
+		}
+	}
+	if fetches != 1 {
+		t.Fatalf("fetches = %d, want 1 (second call should hit the cache)", fetches)
+	}
+}
+
+func TestDraftPRsAreMarkedAndFiltered(t *testing.T) {
+	t.Parallel()
+
+	prs := []ghPR{{Number: 1, Title: "Ready"}, {Number: 2, Title: "WIP", Draft: true}}
+	if got := prCompletionTitle(prs[1]); got != "[draft] WIP" {
+		t.Fatalf("prCompletionTitle(draft) = %q", got)
+	}
+	if got := prCompletionTitle(prs[0]); got != "Ready" {
+		t.Fatalf("prCompletionTitle(ready) = %q", got)
+	}
+	if got := withoutDrafts(prs); len(got) != 1 || got[0].Number != 1 {
+		t.Fatalf("withoutDrafts() = %+v", got)
+	}
+}
+
+// offlineForge is a prForge whose every request fails as if the network
+// were down.
+type offlineForge struct {
+	githubForge
+}
+
+func (offlineForge) searchOpenPRs(owner, repo, query string, limit int) ([]ghPR, error) {
+	return nil, &url.Error{Op: "Get", URL: "https://api.github.com/search/issues", Err: errors.New("no such host")}
+}
+
+func (offlineForge) listOpenPRs(owner, repo string, limit int) ([]ghPR, error) {
+	return nil, &url.Error{Op: "Get", URL: "https://api.github.com/repos/x/y/pulls", Err: errors.New("no such host")}
+}
+
+func (offlineForge) fetchPRDetail(owner, repo string, prNumber int) (*ghPRDetail, error) {
+	return nil, &url.Error{Op: "Get", URL: "https://api.github.com/repos/x/y/pulls/1", Err: errors.New("no such host")}
+}
+
+func TestOfflineForgeFallsBackToStaleCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	if _, _, err := cachedOpenPRs(offlineForge{}, "github.com", "discourse", "discourse", "composer", 100); err == nil {
+		t.Fatal("expected error with nothing cached")
+	}
+
+	cachePath, err := prCompletionCachePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(prCompletionCache{
+		FetchedAt: time.Now().Add(-time.Hour),
+		Host:      "github.com",
+		Owner:     "discourse",
+		Repo:      "discourse",
+		Query:     "composer",
+		Limit:     100,
+		PRs:       []ghPR{{Number: 1, Title: "Fix composer"}},
+	})
+	if err := os.WriteFile(cachePath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	prs, stale, err := cachedOpenPRs(offlineForge{}, "github.com", "discourse", "discourse", "composer", 100)
+	if err != nil || !stale || len(prs) != 1 || prs[0].Number != 1 {
+		t.Fatalf("cachedOpenPRs() = %+v, %v, %v; want stale #1", prs, stale, err)
+	}
+
+	_, err = cachedPRDetail(offlineForge{}, "github.com", "discourse", "discourse", 1, false)
+	err = explainForgeUnreachable(offlineForge{}, err)
+	if err == nil || !strings.Contains(err.Error(), "GitHub unreachable; pass an explicit branch") {
+		t.Fatalf("explainForgeUnreachable() = %v", err)
+	}
+	if err := explainForgeUnreachable(githubForge{}, errors.New("GitHub API error: 404 Not Found")); strings.Contains(err.Error(), "unreachable") {
+		t.Fatalf("API errors should pass through, got %v", err)
+	}
+}
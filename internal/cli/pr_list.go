@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	list "charm.land/bubbles/v2/list"
+	tea "charm.land/bubbletea/v2"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"dv/internal/config"
+	"dv/internal/xdg"
+)
+
+var prListCmd = &cobra.Command{
+	Use:   "list [QUERY]",
+	Short: "Browse open PRs and check out the one you pick",
+	Long: `Lists open PRs (optionally narrowed by QUERY) in an interactive picker with
+live filtering; pressing enter checks the PR out in the container, like 'dv pr NUMBER'.
+When stdout is not a terminal the PRs are printed instead.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configDir, err := xdg.ConfigDir()
+		if err != nil {
+			return err
+		}
+		cfg, err := config.LoadOrCreate(configDir)
+		if err != nil {
+			return err
+		}
+		name, _ := cmd.Flags().GetString("name")
+		if name == "" {
+			name = currentAgentName(cfg)
+		}
+
+		host, owner, repo := prSearchOwnerRepoFromContainer(cfg, name)
+		if owner == "" || repo == "" {
+			host, owner, repo = ownerRepoFromURL(cfg.DiscourseRepo)
+		}
+		if owner == "" || repo == "" {
+			return fmt.Errorf("unable to determine repository owner/name; check 'discourseRepo' in config")
+		}
+		forge, err := forgeForHost(host)
+		if err != nil {
+			return err
+		}
+		warnIfNoGitHubToken(cmd.ErrOrStderr(), forge)
+
+		query := ""
+		if len(args) == 1 {
+			query = strings.TrimSpace(args[0])
+		}
+		prs, stale, err := cachedOpenPRs(forge, host, owner, repo, query, 100)
+		if err != nil {
+			return fmt.Errorf("list open PRs: %w", err)
+		}
+		if stale {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s is unreachable; showing cached PRs, which may be out of date.\n", forge.name())
+		}
+		if noDrafts, _ := cmd.Flags().GetBool("no-drafts"); noDrafts {
+			prs = withoutDrafts(prs)
+		}
+		if len(prs) == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "No open PRs found in %s/%s.\n", owner, repo)
+			return nil
+		}
+
+		if !term.IsTerminal(int(os.Stdout.Fd())) {
+			for _, pr := range prs {
+				fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\n", pr.Number, prCompletionTitle(pr))
+			}
+			return nil
+		}
+
+		final, err := tea.NewProgram(newPRListModel(fmt.Sprintf("Open PRs in %s/%s", owner, repo), prs)).Run()
+		if err != nil {
+			return err
+		}
+		m, ok := final.(prListModel)
+		if !ok || m.selected == 0 {
+			return nil
+		}
+		return checkoutPRInContainer(cmd, cfg, configDir, m.selected)
+	},
+}
+
+func init() {
+	prCmd.AddCommand(prListCmd)
+}
+
+type prItem struct {
+	pr ghPR
+}
+
+func (i prItem) Title() string {
+	return fmt.Sprintf("#%d %s", i.pr.Number, prCompletionTitle(i.pr))
+}
+
+func (i prItem) Description() string {
+	if i.pr.UpdatedAt.IsZero() {
+		return ""
+	}
+	return "updated " + i.pr.UpdatedAt.Local().Format(time.DateTime)
+}
+
+func (i prItem) FilterValue() string { return fmt.Sprintf("#%d %s", i.pr.Number, i.pr.Title) }
+
+// prListModel is the `dv pr list` picker; selected is the chosen PR number,
+// or 0 when the user quit without choosing.
+type prListModel struct {
+	list     list.Model
+	selected int
+}
+
+func newPRListModel(title string, prs []ghPR) prListModel {
+	items := make([]list.Item, 0, len(prs))
+	for _, pr := range prs {
+		items = append(items, prItem{pr: pr})
+	}
+	m := prListModel{list: list.New(items, list.NewDefaultDelegate(), 0, 0)}
+	m.list.Title = title
+	m.list.SetFilteringEnabled(true)
+	if w, h, ok := measureTerminal(); ok {
+		m.list.SetSize(w, h)
+	}
+	return m
+}
+
+func (m prListModel) Init() tea.Cmd { return nil }
+
+func (m prListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch t := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.SetSize(t.Width, t.Height)
+	case tea.KeyPressMsg:
+		if t.String() == "enter" && !m.list.SettingFilter() {
+			if item, ok := m.list.SelectedItem().(prItem); ok {
+				m.selected = item.pr.Number
+				return m, tea.Quit
+			}
+		}
+	}
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m prListModel) View() tea.View {
+	view := tea.NewView(m.list.View())
+	view.AltScreen = true
+	return view
+}
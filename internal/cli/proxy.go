@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"dv/internal/config"
+	"dv/internal/docker"
+	"dv/internal/localproxy"
+	"dv/internal/xdg"
+)
+
+var proxyCmd = &cobra.Command{
+	Use:   "proxy",
+	Short: "Inspect, restart and read logs of the local proxy",
+	Long: `Manage the local proxy container set up by 'dv config local-proxy', which
+serves agents as NAME.dv.localhost.`,
+}
+
+var proxyStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the local proxy is configured, running and healthy",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _, err := loadLocalProxyConfig()
+		if err != nil {
+			return err
+		}
+		lp := cfg.LocalProxy
+		lp.ApplyDefaults()
+
+		state := "missing"
+		if docker.Exists(lp.ContainerName) {
+			state = "unknown"
+			if ci, err := docker.Inspect(lp.ContainerName); err == nil {
+				state = ci.State.Status
+			}
+		}
+		var healthErr error
+		if state == "running" {
+			healthErr = localproxy.Healthy(lp, 2*time.Second)
+		}
+		writeProxyStatus(cmd.OutOrStdout(), cfg.LocalProxy.Enabled, lp, state, healthErr)
+		return nil
+	},
+}
+
+var proxyRestartCmd = &cobra.Command{
+	Use:   "restart",
+	Short: "Recreate the local proxy container from the current config",
+	Long: `Recreate the local proxy container using the saved local-proxy settings
+(ports, hostname, HTTPS, public binding), then re-register every running agent
+that has a proxy hostname. Builds the proxy image first if it is missing.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, configDir, err := loadLocalProxyConfig()
+		if err != nil {
+			return err
+		}
+		if !cfg.LocalProxy.Enabled {
+			return fmt.Errorf("local proxy is not enabled; set it up with 'dv config local-proxy'")
+		}
+		lp := cfg.LocalProxy
+		lp.ApplyDefaults()
+
+		if !docker.ImageExists(lp.ImageTag) {
+			fmt.Fprintf(cmd.OutOrStdout(), "Building local proxy image '%s'...\n", lp.ImageTag)
+			if err := localproxy.BuildImage(configDir, lp); err != nil {
+				return err
+			}
+		}
+		if lp.HTTPS {
+			if err := localproxy.EnsureMKCertTLS(configDir, lp.Hostname); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Restarting local proxy '%s'...\n", lp.ContainerName)
+		if err := localproxy.EnsureContainer(configDir, lp, true); err != nil {
+			return err
+		}
+		if err := localproxy.Healthy(lp, 5*time.Second); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", err)
+		}
+
+		agents, err := docker.ListContainersWithLabel(localproxy.LabelHost, "running")
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: failed to list agents to re-register: %v\n", err)
+		}
+		for _, agent := range agents {
+			registerContainerFromLabels(cmd, cfg, agent.Name)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Local proxy '%s' restarted; re-registered %d running agent(s).\n", lp.ContainerName, len(agents))
+		return nil
+	},
+}
+
+var proxyLogsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Show the local proxy container's logs",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _, err := loadLocalProxyConfig()
+		if err != nil {
+			return err
+		}
+		lp := cfg.LocalProxy
+		lp.ApplyDefaults()
+
+		opts := docker.LogsOptions{Stdout: cmd.OutOrStdout(), Stderr: cmd.ErrOrStderr()}
+		opts.Follow, _ = cmd.Flags().GetBool("follow")
+		opts.Tail, _ = cmd.Flags().GetInt("tail")
+		opts.Since, _ = cmd.Flags().GetString("since")
+		err = docker.Logs(cmd.Context(), lp.ContainerName, opts)
+		if errors.Is(err, docker.ErrContainerNotFound) {
+			return fmt.Errorf("local proxy container '%s' does not exist; start it with 'dv config local-proxy'", lp.ContainerName)
+		}
+		return err
+	},
+}
+
+func init() {
+	proxyLogsCmd.Flags().BoolP("follow", "f", false, "Keep streaming new log output")
+	proxyLogsCmd.Flags().Int("tail", 100, "Number of lines to show from the end of the logs (0 for all)")
+	proxyLogsCmd.Flags().String("since", "", "Only show logs since a duration (e.g. 10m) or timestamp")
+	proxyCmd.AddCommand(proxyStatusCmd)
+	proxyCmd.AddCommand(proxyRestartCmd)
+	proxyCmd.AddCommand(proxyLogsCmd)
+}
+
+func loadLocalProxyConfig() (config.Config, string, error) {
+	configDir, err := xdg.ConfigDir()
+	if err != nil {
+		return config.Config{}, "", err
+	}
+	cfg, err := config.LoadOrCreate(configDir)
+	if err != nil {
+		return config.Config{}, "", err
+	}
+	return cfg, configDir, nil
+}
+
+// writeProxyStatus prints `dv proxy status` for lp (with defaults applied),
+// whose container is in docker state state ("missing" when absent).
+// healthErr is only meaningful while the container is running.
+func writeProxyStatus(w io.Writer, enabled bool, lp config.LocalProxyConfig, state string, healthErr error) {
+	enabledText := "yes"
+	if !enabled {
+		enabledText = "no (set up with 'dv config local-proxy')"
+	}
+	binding := "localhost only"
+	if lp.Public {
+		binding = "public"
+	}
+	ports := fmt.Sprintf("HTTP %d", lp.HTTPPort)
+	if lp.HTTPS {
+		ports += fmt.Sprintf(", HTTPS %d", lp.HTTPSPort)
+	}
+	ports += fmt.Sprintf(", API %d (%s)", lp.APIPort, binding)
+
+	fmt.Fprintf(w, "Enabled:   %s\n", enabledText)
+	fmt.Fprintf(w, "Container: %s (%s)\n", lp.ContainerName, state)
+	fmt.Fprintf(w, "Image:     %s\n", lp.ImageTag)
+	fmt.Fprintf(w, "Hostnames: NAME.%s\n", lp.Hostname)
+	fmt.Fprintf(w, "Ports:     %s\n", ports)
+	switch {
+	case state != "running":
+		hint := "run 'dv proxy restart'"
+		if !enabled {
+			hint = "run 'dv config local-proxy'"
+		}
+		fmt.Fprintf(w, "Health:    not running; %s\n", hint)
+	case healthErr != nil:
+		fmt.Fprintf(w, "Health:    API not responding: %v (see 'dv proxy logs')\n", healthErr)
+	default:
+		fmt.Fprintln(w, "Health:    ok")
+	}
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"dv/internal/config"
+)
+
+func TestWriteProxyStatus(t *testing.T) {
+	lp := config.LocalProxyConfig{HTTPS: true}
+	lp.ApplyDefaults()
+
+	var out bytes.Buffer
+	writeProxyStatus(&out, true, lp, "running", nil)
+	for _, want := range []string{
+		"Container: dv-local-proxy (running)",
+		"Hostnames: NAME.dv.localhost",
+		"Ports:     HTTP 80, HTTPS 443, API 2080 (localhost only)",
+		"Health:    ok",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("status missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	writeProxyStatus(&out, true, lp, "running", errors.New("connection refused"))
+	if !strings.Contains(out.String(), "API not responding: connection refused") {
+		t.Errorf("unhealthy status:\n%s", out.String())
+	}
+
+	out.Reset()
+	writeProxyStatus(&out, false, lp, "missing", nil)
+	if !strings.Contains(out.String(), "Enabled:   no") || !strings.Contains(out.String(), "not running; run 'dv config local-proxy'") {
+		t.Errorf("disabled status:\n%s", out.String())
+	}
+}
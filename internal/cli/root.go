@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -26,6 +27,39 @@ func Execute() error {
 	return rootCmd.Execute()
 }
 
+// ExitStatusError carries the exit code of a command dv ran in a container,
+// so dv can exit with the same code.
+type ExitStatusError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitStatusError) Error() string { return e.Err.Error() }
+
+func (e *ExitStatusError) Unwrap() error { return e.Err }
+
+// withExitStatus wraps err in an *ExitStatusError when it carries a non-zero
+// exit code, so `dv run-agent ... && next` stops when the agent fails.
+func withExitStatus(err error) error {
+	if code := exitCode(err); code > 0 {
+		return &ExitStatusError{Code: code, Err: err}
+	}
+	return err
+}
+
+// ExitCode returns the process exit code for an error returned by Execute:
+// the container command's own code when known, otherwise 1.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var statusErr *ExitStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code
+	}
+	return 1
+}
+
 func addPersistentFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose output")
 }
@@ -92,6 +126,8 @@ Use "{{.CommandPath}} [command] --help" for more information about a command.{{e
 	rootCmd.AddCommand(pullCmd)
 	rootCmd.AddCommand(updateCmd)
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(proxyCmd)
 	rootCmd.AddCommand(serveCmd)
 
 	setupUpdateChecks()
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+)
+
+func TestExitCodePropagatesCommandStatus(t *testing.T) {
+	t.Parallel()
+
+	runErr := exec.Command("sh", "-c", "exit 3").Run()
+	if runErr == nil {
+		t.Fatal("expected sh to fail")
+	}
+	if got := ExitCode(withExitStatus(runErr)); got != 3 {
+		t.Errorf("ExitCode(command exit 3) = %d, want 3", got)
+	}
+	if got := ExitCode(fmt.Errorf("agent: %w", withExitStatus(runErr))); got != 3 {
+		t.Errorf("ExitCode(wrapped) = %d, want 3", got)
+	}
+	if got := ExitCode(withExitStatus(errors.New("no container"))); got != 1 {
+		t.Errorf("ExitCode(other error) = %d, want 1", got)
+	}
+	if withExitStatus(nil) != nil || ExitCode(nil) != 0 {
+		t.Error("nil error should map to exit code 0")
+	}
+}
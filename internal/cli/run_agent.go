@@ -1,15 +1,21 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
+	"text/template"
 
 	textarea "charm.land/bubbles/v2/textarea"
 	tea "charm.land/bubbletea/v2"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
 	"dv/internal/config"
 	"dv/internal/docker"
@@ -22,14 +28,21 @@ import (
 //
 //	dv ra <agent> [prompt_file|prompt words...]
 //	dv ra <agent> -- [raw agent args...]
+//	echo "prompt" | dv ra <agent> [-]
 //
 // If no prompt/args provided, an editor is opened to enter a multiline prompt.
+// A "-" argument, or piped stdin with no prompt, reads the prompt from stdin.
 // Prompt files are read from ~/.config/dv/prompts/ and autocompleted.
 var runAgentCmd = &cobra.Command{
 	Use:     "run-agent [--name NAME] AGENT [PROMPT_FILE|-- ARGS...|PROMPT ...]",
 	Aliases: []string{"ra"},
 	Short:   "Run an AI agent inside the container with a prompt or prompt file",
-	Args:    cobra.MinimumNArgs(1),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if list, _ := cmd.Flags().GetBool("list"); list {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		// First arg: agent name completion
 		if len(args) == 0 {
@@ -127,12 +140,29 @@ var runAgentCmd = &cobra.Command{
 		}
 		workdir := config.EffectiveWorkdir(cfg, imgCfg, name)
 
+		if list, _ := cmd.Flags().GetBool("list"); list {
+			return listAgents(cmd, cfg, name, workdir)
+		}
+
 		// Parse args: first token is the agent name (resolve aliases, returns lowercase)
 		agent := resolveAgentAliasWithConfig(cfg, args[0])
 
 		// Copy configured files (auth, etc.) into the container as in `enter`,
 		// but scoped to the requested agent when configured.
-		copyConfiguredFiles(cmd, cfg, name, workdir, agent)
+		printCommand, _ := cmd.Flags().GetBool("print-command")
+		if !printCommand {
+			copyConfiguredFiles(cmd, cfg, name, workdir, agent)
+		}
+
+		if cwd, _ := cmd.Flags().GetString("cwd"); strings.TrimSpace(cwd) != "" {
+			workdir, err = agentWorkdir(workdir, cwd)
+			if err != nil {
+				return err
+			}
+			if _, err := docker.ExecOutput(name, "/", nil, []string{"test", "-d", workdir}); err != nil {
+				return fmt.Errorf("--cwd: %s does not exist in container '%s'", workdir, name)
+			}
+		}
 
 		envs := buildAgentEnv(cfg, agent)
 
@@ -163,7 +193,33 @@ var runAgentCmd = &cobra.Command{
 
 		// Check if the first argument after agent is a prompt file
 		var promptFromFile string
-		if len(rest) > 0 {
+		promptFromStdin := false
+		stdinIsTTY := term.IsTerminal(int(os.Stdin.Fd()))
+		if len(rest) > 0 && rest[0] == "-" {
+			// "-" reads the prompt from stdin, like a prompt file
+			if len(rest) > 1 {
+				return fmt.Errorf("unexpected arguments after '-': the prompt is read from stdin")
+			}
+			prompt, err := readPromptFromStdin(cmd)
+			if err != nil {
+				return err
+			}
+			if prompt == "" {
+				return fmt.Errorf("no prompt received on stdin")
+			}
+			promptFromFile = prompt
+			promptFromStdin = true
+			rest = rest[1:]
+		} else if len(rest) == 0 && len(rawArgs) == 0 && !stdinIsTTY {
+			// Piped stdin without a prompt: use it instead of starting an
+			// interactive session, which would have no terminal to talk to.
+			prompt, err := readPromptFromStdin(cmd)
+			if err != nil {
+				return err
+			}
+			promptFromFile = prompt
+			promptFromStdin = true
+		} else if len(rest) > 0 {
 			firstArg := rest[0]
 			// 1) Prefer an actual host filesystem path if it exists (supports relative/absolute)
 			hostPath := expandHostPath(firstArg)
@@ -185,13 +241,63 @@ var runAgentCmd = &cobra.Command{
 			}
 		}
 
+		if promptFromFile != "" {
+			rendered, err := applyPromptVars(cmd, promptFromFile, promptFromStdin)
+			if err != nil {
+				return err
+			}
+			promptFromFile = rendered
+		} else if cmd.Flags().Changed("var") {
+			return fmt.Errorf("--var only applies to prompt files")
+		}
+
+		model, _ := cmd.Flags().GetString("model")
+		model = strings.TrimSpace(model)
+		if model != "" {
+			if len(rawArgs) > 0 {
+				return fmt.Errorf("--model cannot be combined with raw args after --; pass the agent's own model flag there instead")
+			}
+			if !agentSupportsModel(cfg, agent) {
+				return fmt.Errorf("dv does not know how to select a model for agent %q; pass its model flag after -- instead", agent)
+			}
+		}
+
+		// Stage attached images / clipboard contents before building the
+		// prompt so one-shot runs can reference their container paths.
+		images, _ := cmd.Flags().GetStringArray("image")
+		useClipboard, _ := cmd.Flags().GetBool("clipboard")
+		var attached []string
+		if printCommand && (len(images) > 0 || useClipboard) {
+			return fmt.Errorf("--print-command does not stage --image/--clipboard files; drop them to see the command")
+		}
+		if len(images) > 0 || useClipboard {
+			files, err := collectAttachments(images, useClipboard)
+			if err != nil {
+				return err
+			}
+			attached, err = paste.StageFiles(paste.DockerExecConfig{ContainerName: name, User: "discourse"}, files)
+			if err != nil {
+				return err
+			}
+			if promptFromFile != "" {
+				promptFromFile = appendAttachments(promptFromFile, attached)
+			} else if len(rawArgs) == 0 && len(rest) > 0 {
+				rest = []string{appendAttachments(strings.Join(rest, " "), attached)}
+			} else {
+				fmt.Fprintln(cmd.ErrOrStderr(), "Attached files (reference them in your prompt):")
+				for _, p := range attached {
+					fmt.Fprintf(cmd.ErrOrStderr(), "  %s\n", p)
+				}
+			}
+		}
+
 		// Build the argv to run inside the container using internal rules.
 		var argv []string
 		switch {
 		case len(rawArgs) > 0:
 			argv = buildAgentRawWithConfig(cfg, agent, rawArgs)
 			// If this is a pure help request, capture output via non-TTY exec
-			if isHelpArgs(rawArgs) {
+			if isHelpArgs(rawArgs) && !printCommand {
 				shellCmd := withUserPaths(shellJoin(argv))
 				out, err := docker.ExecOutput(name, workdir, envs, []string{"bash", "-lc", shellCmd})
 				if err != nil {
@@ -203,31 +309,76 @@ var runAgentCmd = &cobra.Command{
 			}
 		case promptFromFile != "":
 			// Prompt from file -> construct one-shot invocation with implicit bypass flags
-			argv = buildAgentArgsWithConfig(cfg, agent, promptFromFile)
+			argv = buildAgentArgsWithModel(cfg, agent, promptFromFile, model)
 		case len(rest) == 0:
 			// No prompt provided -> run interactively with implicit bypass flags
-			argv = buildAgentInteractiveWithConfig(cfg, agent)
+			argv = buildAgentInteractiveWithModel(cfg, agent, model)
 		default:
 			// Prompt provided -> construct one-shot invocation with implicit bypass flags
 			prompt := strings.Join(rest, " ")
-			argv = buildAgentArgsWithConfig(cfg, agent, prompt)
+			argv = buildAgentArgsWithModel(cfg, agent, prompt, model)
 		}
 
 		// Execute inside container through a login shell to pick up PATH/rc files
 		shellCmd := withUserPaths(shellJoin(argv))
+		if printCommand {
+			fmt.Fprintln(cmd.OutOrStdout(), formatAgentCommand(name, workdir, envs, shellCmd))
+			return nil
+		}
+
+		// One-shot runs can be bounded with --timeout; interactive sessions
+		// are left alone since the user is at the keyboard.
+		interactive := len(rawArgs) == 0 && promptFromFile == "" && len(rest) == 0
+		ctx := context.Background()
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		if timeout > 0 {
+			if interactive || len(rawArgs) > 0 {
+				fmt.Fprintln(cmd.ErrOrStderr(), "Note: --timeout only applies to one-shot prompt runs; ignoring it.")
+			} else {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+		}
 
 		// Check if paste support is enabled
 		pasteEnabled, _ := cmd.Flags().GetBool("paste")
-		if pasteEnabled {
-			return paste.ExecWithPaste(paste.DockerExecConfig{
-				ContainerName: name,
-				Workdir:       workdir,
-				Envs:          envs,
-				Argv:          []string{"bash", "-lc", shellCmd},
-				User:          "discourse",
-			})
-		}
-		return docker.ExecInteractive(name, workdir, envs, []string{"bash", "-lc", shellCmd})
+		transcriptPath, _ := cmd.Flags().GetString("transcript")
+		execCfg := paste.DockerExecConfig{
+			ContainerName: name,
+			Workdir:       workdir,
+			Envs:          envs,
+			Argv:          []string{"bash", "-lc", shellCmd},
+			User:          "discourse",
+			Context:       ctx,
+		}
+		switch {
+		case strings.TrimSpace(transcriptPath) != "":
+			// The transcript is teed from dv's own PTY, so the agent still sees a
+			// real terminal. Without one on the host we can only pipe output.
+			if interactive && !(stdinIsTTY && term.IsTerminal(int(os.Stdout.Fd()))) {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: --transcript needs a terminal for interactive sessions; %s will run without a TTY and may refuse to start. Pass a prompt for a one-shot run instead.\n", agent)
+			}
+			f, err := os.Create(expandHostPath(transcriptPath))
+			if err != nil {
+				return fmt.Errorf("open transcript: %w", err)
+			}
+			defer f.Close()
+			execCfg.Transcript = f
+			execCfg.DisablePaste = !pasteEnabled
+			err = paste.ExecWithPaste(execCfg)
+		case pasteEnabled:
+			err = paste.ExecWithPaste(execCfg)
+		default:
+			err = docker.ExecInteractiveContext(ctx, name, workdir, envs, []string{"bash", "-lc", shellCmd})
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return &ExitStatusError{
+				Code: timeoutExitCode,
+				Err:  fmt.Errorf("%s did not finish within %s; the agent was stopped", agent, timeout),
+			}
+		}
+		return withExitStatus(err)
 	},
 }
 
@@ -254,7 +405,7 @@ func buildAgentEnv(cfg config.Config, agent string) docker.Envs {
 	envs := collectEnvPassthrough(cfg)
 
 	if rule, ok := agentRules[agent]; ok {
-		envs = append(envs, rule.env...)
+		envs = append(envs, effectiveAgentEnv(cfg, agent, rule)...)
 	}
 	if custom, ok := customAgentConfig(cfg, agent); ok {
 		envs = append(envs, custom.Env...)
@@ -278,19 +429,28 @@ func buildAgentEnv(cfg config.Config, agent string) docker.Envs {
 }
 
 // buildAgentArgs uses internal, hard-coded rules per agent to construct argv.
-// If the agent is unknown, falls back to positional prompt.
+// An agent with neither a BYO config entry nor a rule falls back to
+// [agent, prompt]: the prompt is passed as the only positional argument, with
+// no one-shot or auto-approve flags, so most CLIs start an interactive session
+// seeded with the prompt.
 func buildAgentArgs(agent string, prompt string) []string {
 	return buildAgentArgsWithConfig(config.Config{}, agent, prompt)
 }
 
 func buildAgentArgsWithConfig(cfg config.Config, agent string, prompt string) []string {
+	return buildAgentArgsWithModel(cfg, agent, prompt, "")
+}
+
+// buildAgentArgsWithModel is buildAgentArgsWithConfig with an optional model
+// that replaces the rule's default model selection; see agentSupportsModel.
+func buildAgentArgsWithModel(cfg config.Config, agent, prompt, model string) []string {
 	if custom, ok := customAgentConfig(cfg, agent); ok {
 		return buildCustomAgentArgs(agent, custom, prompt)
 	}
 	if rule, ok := agentRules[strings.ToLower(agent)]; ok {
 		base := rule.withPrompt(prompt)
-		if len(rule.defaults) > 0 {
-			base = injectDefaults(base, rule.defaults)
+		if defaults := withAgentModel(rule, effectiveAgentDefaults(cfg, agent, rule), model); len(defaults) > 0 {
+			base = injectDefaults(base, defaults)
 		}
 		return base
 	}
@@ -302,6 +462,10 @@ func buildAgentInteractive(agent string) []string {
 }
 
 func buildAgentInteractiveWithConfig(cfg config.Config, agent string) []string {
+	return buildAgentInteractiveWithModel(cfg, agent, "")
+}
+
+func buildAgentInteractiveWithModel(cfg config.Config, agent, model string) []string {
 	if custom, ok := customAgentConfig(cfg, agent); ok {
 		cmd := custom.Command
 		if strings.TrimSpace(cmd) == "" {
@@ -317,8 +481,8 @@ func buildAgentInteractiveWithConfig(cfg config.Config, agent string) []string {
 			baseBuilder = rule.interactive
 		}
 		base := baseBuilder()
-		if len(rule.defaults) > 0 {
-			base = injectDefaults(base, rule.defaults)
+		if defaults := withAgentModel(rule, effectiveAgentDefaults(cfg, agent, rule), model); len(defaults) > 0 {
+			base = injectDefaults(base, defaults)
 		}
 		return base
 	}
@@ -349,6 +513,83 @@ func buildAgentRawWithConfig(cfg config.Config, agent string, rawArgs []string)
 	return append([]string{agent}, rawArgs...)
 }
 
+// agentOverride returns the AgentOverrides entry for a bundled agent. Keys may
+// be the canonical name or any alias.
+func agentOverride(cfg config.Config, agent string) (config.AgentOverride, bool) {
+	agent = resolveAgentAlias(agent)
+	keys := make([]string, 0, len(cfg.AgentOverrides))
+	for key := range cfg.AgentOverrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if resolveAgentAlias(strings.TrimSpace(key)) == agent {
+			return cfg.AgentOverrides[key], true
+		}
+	}
+	return config.AgentOverride{}, false
+}
+
+// effectiveAgentDefaults merges a rule's built-in default flags with the
+// user's AgentOverrides entry.
+func effectiveAgentDefaults(cfg config.Config, agent string, rule agentRule) []string {
+	override, ok := agentOverride(cfg, agent)
+	if !ok {
+		return rule.defaults
+	}
+	if override.ReplaceDefaults {
+		return override.Defaults
+	}
+	return append(append([]string{}, rule.defaults...), override.Defaults...)
+}
+
+// effectiveAgentEnv is effectiveAgentDefaults for the rule's env entries.
+func effectiveAgentEnv(cfg config.Config, agent string, rule agentRule) []string {
+	override, ok := agentOverride(cfg, agent)
+	if !ok {
+		return rule.env
+	}
+	if override.ReplaceEnv {
+		return override.Env
+	}
+	return append(append([]string{}, rule.env...), override.Env...)
+}
+
+// agentSupportsModel reports whether --model can be translated for agent.
+// BYO agents take their model flag as raw args instead.
+func agentSupportsModel(cfg config.Config, agent string) bool {
+	if _, ok := customAgentConfig(cfg, agent); ok {
+		return false
+	}
+	rule, ok := agentRules[strings.ToLower(agent)]
+	return ok && rule.modelFlag != nil
+}
+
+// withAgentModel swaps any model selection baked into defaults for the
+// rule's modelFlag(model). Baked-in selections are found by probing
+// modelFlag with a placeholder: the flag name must match and, for
+// "-c model=X" style flags, so must the value's prefix.
+func withAgentModel(rule agentRule, defaults []string, model string) []string {
+	if model == "" || rule.modelFlag == nil {
+		return defaults
+	}
+	const placeholder = "\x00"
+	probe := rule.modelFlag(placeholder)
+	valuePrefix := ""
+	if len(probe) > 1 {
+		valuePrefix, _, _ = strings.Cut(probe[1], placeholder)
+	}
+	out := make([]string, 0, len(defaults)+len(probe))
+	for i := 0; i < len(defaults); i++ {
+		if defaults[i] == probe[0] && len(probe) > 1 && i+1 < len(defaults) && strings.HasPrefix(defaults[i+1], valuePrefix) {
+			i++
+			continue
+		}
+		out = append(out, defaults[i])
+	}
+	return append(out, rule.modelFlag(model)...)
+}
+
 func injectDefaults(argv []string, defaults []string) []string {
 	if len(argv) == 0 || len(defaults) == 0 {
 		return argv
@@ -365,35 +606,42 @@ type agentRule struct {
 	interactive   func() []string
 	withPrompt    func(prompt string) []string
 	withoutPrompt func() []string
-	defaults      []string
-	env           []string
-	aliases       []string // alternative names for this agent
+	// modelFlag returns the args that select model, for run-agent --model.
+	modelFlag func(model string) []string
+	defaults  []string
+	env       []string
+	aliases   []string // alternative names for this agent
 }
 
 var agentRules = map[string]agentRule{
 	"cursor": {
 		interactive: func() []string { return []string{"cursor-agent"} },
 		withPrompt:  func(p string) []string { return []string{"cursor-agent", "-p", p} },
+		modelFlag:   func(m string) []string { return []string{"--model", m} },
 		defaults:    []string{"-f"},
 	},
 	"codex": {
 		interactive: func() []string { return []string{"codex"} },
 		withPrompt:  func(p string) []string { return []string{"codex", "exec", "-s", "danger-full-access", p} },
+		modelFlag:   func(m string) []string { return []string{"-m", m} },
 		defaults:    []string{"--search", "--dangerously-bypass-approvals-and-sandbox", "--sandbox", "danger-full-access", "-c", "model_reasoning_effort=xhigh", "-m", "gpt-5.5"},
 	},
 	"claude": {
 		interactive: func() []string { return []string{"claude"} },
 		withPrompt:  func(p string) []string { return []string{"claude", "-p", p} },
+		modelFlag:   func(m string) []string { return []string{"--model", m} },
 		defaults:    []string{"--dangerously-skip-permissions", "--model", "opus", "--effort", "high"},
 	},
 	"opencode": {
 		interactive: func() []string { return []string{"opencode"} },
 		withPrompt:  func(p string) []string { return []string{"opencode", "run", p} },
+		modelFlag:   func(m string) []string { return []string{"--model", m} },
 		defaults:    []string{},
 	},
 	"copilot": {
 		interactive: func() []string { return []string{"copilot"} },
 		withPrompt:  func(p string) []string { return []string{"copilot", "-p", p} },
+		modelFlag:   func(m string) []string { return []string{"--model", m} },
 		defaults:    []string{"--allow-all-tools", "--allow-all-paths"},
 	},
 	"droid": {
@@ -414,6 +662,22 @@ var agentRules = map[string]agentRule{
 		aliases:       []string{"tl"},
 		env:           []string{"GOOGLE_SEARCH_API_KEY", "GOOGLE_SEARCH_CX", "CEREBRAS_API_KEY"},
 	},
+	"grok": {
+		interactive: func() []string { return []string{"grok"} },
+		// Headless --prompt mode runs tools without confirmation.
+		withPrompt: func(p string) []string { return []string{"grok", "--prompt", p} },
+		modelFlag:  func(m string) []string { return []string{"--model", m} },
+		defaults:   []string{},
+		aliases:    []string{"grok-cli"},
+		env:        []string{"GROK_API_KEY"},
+	},
+	"qwen": {
+		interactive: func() []string { return []string{"qwen"} },
+		withPrompt:  func(p string) []string { return []string{"qwen", "-p", p} },
+		modelFlag:   func(m string) []string { return []string{"--model", m} },
+		defaults:    []string{"--yolo"},
+		aliases:     []string{"qwen-code"},
+	},
 }
 
 // agentAliasMap maps aliases to canonical agent names (precomputed at init).
@@ -603,7 +867,141 @@ func isHelpArgs(args []string) bool {
 	return false
 }
 
+// agentWorkdir joins a --cwd subdirectory onto the container workdir,
+// refusing absolute paths and ".." segments that would leave it.
+func agentWorkdir(workdir, cwd string) (string, error) {
+	cwd = strings.TrimSpace(cwd)
+	if path.IsAbs(cwd) {
+		return "", fmt.Errorf("--cwd %q must be relative to the workdir %s", cwd, workdir)
+	}
+	dir := path.Join(workdir, cwd)
+	if dir != path.Clean(workdir) && !strings.HasPrefix(dir, strings.TrimSuffix(path.Clean(workdir), "/")+"/") {
+		return "", fmt.Errorf("--cwd %q escapes the workdir %s", cwd, workdir)
+	}
+	return dir, nil
+}
+
+// formatAgentCommand renders the docker exec run-agent would perform, one
+// option per line so the env list and final shell command are easy to read.
+// Bare env names are passed through from the host, as docker does.
+func formatAgentCommand(name, workdir string, envs docker.Envs, shellCmd string) string {
+	lines := []string{"docker exec -it --user discourse -w " + shellQuote(workdir)}
+	for _, e := range envs {
+		lines = append(lines, "  -e "+shellQuote(e))
+	}
+	lines = append(lines, "  "+shellQuote(name)+" bash -lc "+shellQuote(shellCmd))
+	return strings.Join(lines, " \\\n")
+}
+
+// collectAttachments reads --image files and, with clipboard set, the current
+// clipboard (an image, or text saved as a .txt file) for paste.StageFiles.
+func collectAttachments(images []string, clipboard bool) ([]paste.StagedFile, error) {
+	var files []paste.StagedFile
+	for _, img := range images {
+		path := expandHostPath(img)
+		format := paste.FormatFromExtension(path)
+		if format == "" {
+			return nil, fmt.Errorf("--image %s: not a supported image type", img)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("--image: %w", err)
+		}
+		files = append(files, paste.StagedFile{Data: data, Format: format})
+	}
+	if clipboard {
+		data, format, err := paste.ReadClipboard()
+		if err != nil {
+			return nil, fmt.Errorf("--clipboard: %w", err)
+		}
+		files = append(files, paste.StagedFile{Data: data, Format: format})
+	}
+	return files, nil
+}
+
+// appendAttachments lists staged container paths after the prompt so the
+// agent knows where to find them.
+func appendAttachments(prompt string, paths []string) string {
+	if len(paths) == 0 {
+		return prompt
+	}
+	var b strings.Builder
+	b.WriteString(prompt)
+	b.WriteString("\n\nAttached files:")
+	for _, p := range paths {
+		b.WriteString("\n- ")
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+// readPromptFromStdin reads the whole of stdin as a one-shot prompt.
+func readPromptFromStdin(cmd *cobra.Command) (string, error) {
+	data, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return "", fmt.Errorf("read prompt from stdin: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// applyPromptVars renders a prompt file when --var or --var-missing is given.
+// Otherwise, and always for stdin prompts, the prompt is used verbatim:
+// Discourse prompts routinely quote Handlebars/Glimmer {{ }} snippets.
+func applyPromptVars(cmd *cobra.Command, prompt string, fromStdin bool) (string, error) {
+	if !cmd.Flags().Changed("var") && !cmd.Flags().Changed("var-missing") {
+		return prompt, nil
+	}
+	if fromStdin {
+		return "", fmt.Errorf("--var only applies to prompt files, not prompts read from stdin")
+	}
+	vars, _ := cmd.Flags().GetStringArray("var")
+	missing, _ := cmd.Flags().GetString("var-missing")
+	return renderPromptTemplate(prompt, vars, missing)
+}
+
+// renderPromptTemplate expands a prompt file with text/template, using the
+// --var key=value pairs as data (referenced as {{.key}}). Undefined keys are
+// an error unless missing is "empty", which renders them as "".
+func renderPromptTemplate(prompt string, vars []string, missing string) (string, error) {
+	data := map[string]string{}
+	for _, kv := range vars {
+		key, value, ok := strings.Cut(kv, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return "", fmt.Errorf("invalid --var %q (expected key=value)", kv)
+		}
+		data[key] = value
+	}
+	option := "missingkey=error"
+	switch missing {
+	case "", "error":
+	case "empty":
+		option = "missingkey=zero"
+	default:
+		return "", fmt.Errorf("invalid --var-missing %q (expected error or empty)", missing)
+	}
+	tmpl, err := template.New("prompt").Option(option).Parse(prompt)
+	if err != nil {
+		return "", fmt.Errorf("parse prompt template: %w", err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("render prompt template: %w (pass it with --var, or use --var-missing=empty)", err)
+	}
+	return out.String(), nil
+}
+
 func init() {
 	runAgentCmd.Flags().String("name", "", "Container name (defaults to selected or default)")
+	runAgentCmd.Flags().Bool("list", false, "List bundled and configured agents with their install status and version in the container")
+	runAgentCmd.Flags().String("cwd", "", "Run the agent in this subdirectory of the workdir (e.g. plugins/discourse-ai)")
+	runAgentCmd.Flags().String("model", "", "Model to run, translated to the agent's own model flag (replaces the built-in default model)")
+	runAgentCmd.Flags().StringArray("image", nil, "Copy a host image into the container and reference it in the prompt (repeatable)")
+	runAgentCmd.Flags().Bool("clipboard", false, "Copy the clipboard (image, or text as a .txt file) into the container and reference it in the prompt")
 	runAgentCmd.Flags().Bool("paste", true, "Image paste support (copies pasted images to container); use --paste=false to disable")
+	runAgentCmd.Flags().StringArray("var", nil, "Template variable for the prompt file as key=value, referenced as {{.key}} (repeatable)")
+	runAgentCmd.Flags().String("var-missing", "error", "How to render prompt variables without a --var: error or empty")
+	runAgentCmd.Flags().Duration("timeout", 0, "Stop a one-shot prompt run that has not finished after this long (e.g. 10m)")
+	runAgentCmd.Flags().Bool("print-command", false, "Print the docker exec command (env and agent argv) that would run, without running it")
+	runAgentCmd.Flags().String("transcript", "", "Also write the session's output to this host file (includes terminal escape codes)")
 }
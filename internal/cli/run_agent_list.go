@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"dv/internal/config"
+	"dv/internal/docker"
+)
+
+// agentStatus is one row of `dv run-agent --list`.
+type agentStatus struct {
+	name      string
+	binary    string
+	installed bool
+	version   string
+}
+
+// listableAgents returns the bundled and BYO agents with the executable each
+// one runs, sorted by name. A BYO agent shadowing a bundled one wins, as it
+// does when the agent is run.
+func listableAgents(cfg config.Config) []agentStatus {
+	binaries := map[string]string{}
+	for name, rule := range agentRules {
+		var argv []string
+		if rule.interactive != nil {
+			argv = rule.interactive()
+		} else if rule.withPrompt != nil {
+			argv = rule.withPrompt("")
+		}
+		if len(argv) > 0 {
+			binaries[name] = argv[0]
+		}
+	}
+	for _, name := range sortedCustomAgentNames(cfg) {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if key == "" {
+			continue
+		}
+		command := strings.TrimSpace(cfg.Agents[name].Command)
+		if command == "" {
+			command = key
+		}
+		binaries[key] = command
+	}
+
+	agents := make([]agentStatus, 0, len(binaries))
+	for name, binary := range binaries {
+		agents = append(agents, agentStatus{name: name, binary: binary})
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].name < agents[j].name })
+	return agents
+}
+
+// agentStatusScript probes every agent in one exec: each prints a line of
+// "name<TAB>installed|missing<TAB>first line of --version".
+func agentStatusScript(agents []agentStatus) string {
+	var b strings.Builder
+	for _, a := range agents {
+		fmt.Fprintf(&b, "if command -v %s >/dev/null 2>&1; then v=$(timeout 10 %s --version 2>/dev/null </dev/null | head -n1); printf '%%s\\tinstalled\\t%%s\\n' %s \"$v\"; else printf '%%s\\tmissing\\t\\n' %s; fi; ",
+			shellQuote(a.binary), shellQuote(a.binary), shellQuote(a.name), shellQuote(a.name))
+	}
+	return b.String()
+}
+
+// parseAgentStatus fills in installed/version from agentStatusScript output.
+func parseAgentStatus(agents []agentStatus, out string) []agentStatus {
+	byName := map[string]int{}
+	for i, a := range agents {
+		byName[a.name] = i
+	}
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(strings.TrimRight(line, "\r"), "\t", 3)
+		if len(parts) < 2 {
+			continue
+		}
+		i, ok := byName[parts[0]]
+		if !ok {
+			continue
+		}
+		agents[i].installed = parts[1] == "installed"
+		if len(parts) == 3 {
+			agents[i].version = strings.TrimSpace(parts[2])
+		}
+	}
+	return agents
+}
+
+// listAgents prints which agents are installed in the container.
+func listAgents(cmd *cobra.Command, cfg config.Config, name, workdir string) error {
+	agents := listableAgents(cfg)
+	out, err := docker.ExecOutput(name, workdir, nil, []string{"bash", "-lc", withUserPaths(agentStatusScript(agents))})
+	if err != nil {
+		return fmt.Errorf("check agents in '%s': %w", name, err)
+	}
+	agents = parseAgentStatus(agents, out)
+
+	width := len("AGENT")
+	for _, a := range agents {
+		width = max(width, len(a.name))
+	}
+	w := cmd.OutOrStdout()
+	fmt.Fprintf(w, "%-*s  %-9s  %s\n", width, "AGENT", "STATUS", "VERSION")
+	for _, a := range agents {
+		status := "missing"
+		if a.installed {
+			status = "installed"
+		}
+		fmt.Fprintf(w, "%-*s  %-9s  %s\n", width, a.name, status, a.version)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"dv/internal/config"
+)
+
+func TestListableAgentsIncludesBundledAndBYO(t *testing.T) {
+	cfg := config.Config{Agents: map[string]config.AgentConfig{
+		"My-Agent": {Command: "my-agent-cli"},
+		"claude":   {Command: "claude-wrapper"},
+	}}
+	agents := listableAgents(cfg)
+	binaries := map[string]string{}
+	for i, a := range agents {
+		binaries[a.name] = a.binary
+		if i > 0 && agents[i-1].name >= a.name {
+			t.Fatalf("agents not sorted: %q before %q", agents[i-1].name, a.name)
+		}
+	}
+	if binaries["cursor"] != "cursor-agent" {
+		t.Fatalf("cursor binary = %q, want cursor-agent", binaries["cursor"])
+	}
+	if binaries["my-agent"] != "my-agent-cli" {
+		t.Fatalf("my-agent binary = %q, want my-agent-cli", binaries["my-agent"])
+	}
+	if binaries["claude"] != "claude-wrapper" {
+		t.Fatalf("claude binary = %q, want the BYO override", binaries["claude"])
+	}
+}
+
+func TestParseAgentStatus(t *testing.T) {
+	agents := []agentStatus{{name: "claude", binary: "claude"}, {name: "codex", binary: "codex"}, {name: "qwen", binary: "qwen"}}
+	if script := agentStatusScript(agents); !strings.Contains(script, "command -v 'codex'") {
+		t.Fatalf("agentStatusScript() = %q, want a command -v probe for codex", script)
+	}
+	out := "claude\tinstalled\t2.1.3 (Claude Code)\ncodex\tmissing\t\nqwen\tinstalled\t\nnoise\n"
+	got := parseAgentStatus(agents, out)
+	if !got[0].installed || got[0].version != "2.1.3 (Claude Code)" {
+		t.Fatalf("claude = %+v", got[0])
+	}
+	if got[1].installed || got[1].version != "" {
+		t.Fatalf("codex = %+v", got[1])
+	}
+	if !got[2].installed || got[2].version != "" {
+		t.Fatalf("qwen = %+v", got[2])
+	}
+}
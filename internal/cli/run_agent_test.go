@@ -1,8 +1,13 @@
 package cli
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/spf13/cobra"
+
 	"dv/internal/config"
 )
 
@@ -123,3 +128,226 @@ func TestCustomAgentOverridesBuiltinAlias(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildAgentArgsGrokAndQwen(t *testing.T) {
+	tests := []struct {
+		alias string
+		want  []string
+	}{
+		{alias: "grok-cli", want: []string{"grok", "--prompt", "hi"}},
+		{alias: "qwen-code", want: []string{"qwen", "--yolo", "-p", "hi"}},
+	}
+	for _, tt := range tests {
+		agent := resolveAgentAlias(tt.alias)
+		args := buildAgentArgs(agent, "hi")
+		if len(args) != len(tt.want) {
+			t.Fatalf("buildAgentArgs(%q) = %#v, want %#v", agent, args, tt.want)
+		}
+		for i := range tt.want {
+			if args[i] != tt.want[i] {
+				t.Fatalf("buildAgentArgs(%q) = %#v, want %#v", agent, args, tt.want)
+			}
+		}
+	}
+}
+
+func TestBuildAgentArgsUnknownAgentFallsBackToPositionalPrompt(t *testing.T) {
+	args := buildAgentArgs("some-new-cli", "do it")
+	if len(args) != 2 || args[0] != "some-new-cli" || args[1] != "do it" {
+		t.Fatalf("args = %#v, want [some-new-cli \"do it\"]", args)
+	}
+}
+
+func TestBuildAgentArgsAppliesAgentOverrides(t *testing.T) {
+	replace := config.Config{AgentOverrides: map[string]config.AgentOverride{
+		"claude": {ReplaceDefaults: true, Defaults: []string{"--model", "sonnet"}},
+	}}
+	args := buildAgentArgsWithConfig(replace, "claude", "hi")
+	want := []string{"claude", "--model", "sonnet", "-p", "hi"}
+	if len(args) != len(want) {
+		t.Fatalf("args = %#v, want %#v", args, want)
+	}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Fatalf("args = %#v, want %#v", args, want)
+		}
+	}
+
+	appendByAlias := config.Config{AgentOverrides: map[string]config.AgentOverride{
+		"qwen-code": {Defaults: []string{"--debug"}, Env: []string{"DASHSCOPE_API_KEY"}},
+	}}
+	interactive := buildAgentInteractiveWithConfig(appendByAlias, "qwen")
+	wantInteractive := []string{"qwen", "--yolo", "--debug"}
+	if len(interactive) != len(wantInteractive) {
+		t.Fatalf("interactive = %#v, want %#v", interactive, wantInteractive)
+	}
+	for i := range wantInteractive {
+		if interactive[i] != wantInteractive[i] {
+			t.Fatalf("interactive = %#v, want %#v", interactive, wantInteractive)
+		}
+	}
+	if env := effectiveAgentEnv(appendByAlias, "qwen", agentRules["qwen"]); len(env) != 1 || env[0] != "DASHSCOPE_API_KEY" {
+		t.Fatalf("env = %#v, want override env appended", env)
+	}
+
+	drop := config.Config{AgentOverrides: map[string]config.AgentOverride{"copilot": {ReplaceDefaults: true}}}
+	if args := buildAgentArgsWithConfig(drop, "copilot", "hi"); len(args) != 3 {
+		t.Fatalf("args = %#v, want built-in defaults dropped", args)
+	}
+}
+
+func TestBuildAgentArgsWithModelReplacesBakedInModel(t *testing.T) {
+	args := buildAgentArgsWithModel(config.Config{}, "codex", "hi", "o3")
+	models := 0
+	for i, a := range args {
+		if a == "-m" {
+			models++
+			if i+1 >= len(args) || args[i+1] != "o3" {
+				t.Fatalf("args = %#v, want -m o3", args)
+			}
+		}
+	}
+	if models != 1 {
+		t.Fatalf("args = %#v, want exactly one -m", args)
+	}
+
+	interactive := buildAgentInteractiveWithModel(config.Config{}, "claude", "sonnet")
+	want := []string{"claude", "--dangerously-skip-permissions", "--effort", "high", "--model", "sonnet"}
+	if len(interactive) != len(want) {
+		t.Fatalf("interactive = %#v, want %#v", interactive, want)
+	}
+	for i := range want {
+		if interactive[i] != want[i] {
+			t.Fatalf("interactive = %#v, want %#v", interactive, want)
+		}
+	}
+}
+
+func TestWithAgentModelConfigStyleFlag(t *testing.T) {
+	rule := agentRule{modelFlag: func(m string) []string { return []string{"-c", "model=" + m} }}
+	got := withAgentModel(rule, []string{"-c", "model_reasoning_effort=high", "-c", "model=old"}, "new")
+	want := []string{"-c", "model_reasoning_effort=high", "-c", "model=new"}
+	if len(got) != len(want) {
+		t.Fatalf("withAgentModel() = %#v, want %#v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("withAgentModel() = %#v, want %#v", got, want)
+		}
+	}
+	if !agentSupportsModel(config.Config{}, "claude") || agentSupportsModel(config.Config{}, "droid") {
+		t.Fatal("agentSupportsModel: want claude supported and droid unsupported")
+	}
+}
+
+func TestRenderPromptTemplate(t *testing.T) {
+	got, err := renderPromptTemplate("Fix ticket {{.ticket}} on {{.branch}}", []string{"ticket=1234", "branch=main=stable"}, "")
+	if err != nil {
+		t.Fatalf("renderPromptTemplate() error = %v", err)
+	}
+	if got != "Fix ticket 1234 on main=stable" {
+		t.Fatalf("renderPromptTemplate() = %q", got)
+	}
+
+	if _, err := renderPromptTemplate("Fix {{.ticket}}", nil, "error"); err == nil {
+		t.Fatal("expected error for undefined variable")
+	}
+	got, err = renderPromptTemplate("Fix {{.ticket}}.", nil, "empty")
+	if err != nil || got != "Fix ." {
+		t.Fatalf("renderPromptTemplate(empty) = %q, %v", got, err)
+	}
+
+	if _, err := renderPromptTemplate("x", []string{"novalue"}, ""); err == nil {
+		t.Fatal("expected error for --var without =")
+	}
+	if _, err := renderPromptTemplate("x", nil, "ignore"); err == nil {
+		t.Fatal("expected error for unknown --var-missing mode")
+	}
+}
+
+func TestApplyPromptVarsKeepsLiteralBracesWithoutVars(t *testing.T) {
+	newCmd := func(args ...string) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().StringArray("var", nil, "")
+		cmd.Flags().String("var-missing", "error", "")
+		if err := cmd.Flags().Parse(args); err != nil {
+			t.Fatal(err)
+		}
+		return cmd
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "glimmer.md")
+	if err := os.WriteFile(path, []byte("Fix <template>{{@model.title}}</template> in {{#if x}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prompt := string(content)
+
+	got, err := applyPromptVars(newCmd(), prompt, false)
+	if err != nil || got != prompt {
+		t.Fatalf("applyPromptVars(no --var) = %q, %v; want the file verbatim", got, err)
+	}
+	if _, err := applyPromptVars(newCmd("--var", "x=1"), "Fix {{.x}}", true); err == nil {
+		t.Fatal("expected --var with a stdin prompt to fail")
+	}
+	got, err = applyPromptVars(newCmd("--var-missing", "empty"), "Fix {{.ticket}}.", false)
+	if err != nil || got != "Fix ." {
+		t.Fatalf("applyPromptVars(--var-missing=empty) = %q, %v", got, err)
+	}
+}
+
+func TestReadPromptFromStdin(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.SetIn(strings.NewReader("  fix the bug\nand add a spec\n\n"))
+	got, err := readPromptFromStdin(cmd)
+	if err != nil {
+		t.Fatalf("readPromptFromStdin() error = %v", err)
+	}
+	if got != "fix the bug\nand add a spec" {
+		t.Fatalf("readPromptFromStdin() = %q", got)
+	}
+}
+
+func TestAppendAttachments(t *testing.T) {
+	if got := appendAttachments("fix it", nil); got != "fix it" {
+		t.Fatalf("appendAttachments(no files) = %q", got)
+	}
+	got := appendAttachments("fix it", []string{"/tmp/dv-images/attached-1-1.png", "/tmp/dv-images/attached-1-2.txt"})
+	want := "fix it\n\nAttached files:\n- /tmp/dv-images/attached-1-1.png\n- /tmp/dv-images/attached-1-2.txt"
+	if got != want {
+		t.Fatalf("appendAttachments() = %q, want %q", got, want)
+	}
+}
+
+func TestAgentWorkdir(t *testing.T) {
+	ok := map[string]string{
+		"plugins/discourse-ai":    "/var/www/discourse/plugins/discourse-ai",
+		"./plugins/../themes/foo": "/var/www/discourse/themes/foo",
+		".":                       "/var/www/discourse",
+	}
+	for cwd, want := range ok {
+		got, err := agentWorkdir("/var/www/discourse", cwd)
+		if err != nil || got != want {
+			t.Fatalf("agentWorkdir(%q) = %q, %v; want %q", cwd, got, err, want)
+		}
+	}
+	for _, cwd := range []string{"..", "../discourse-other", "plugins/../../x", "/etc"} {
+		if got, err := agentWorkdir("/var/www/discourse", cwd); err == nil {
+			t.Fatalf("agentWorkdir(%q) = %q, want error", cwd, got)
+		}
+	}
+}
+
+func TestFormatAgentCommand(t *testing.T) {
+	got := formatAgentCommand("dv", "/var/www/discourse", []string{"OPENAI_API_KEY", "HOME=/home/discourse"}, "codex exec fix")
+	want := "docker exec -it --user discourse -w '/var/www/discourse' \\\n" +
+		"  -e 'OPENAI_API_KEY' \\\n" +
+		"  -e 'HOME=/home/discourse' \\\n" +
+		"  'dv' bash -lc 'codex exec fix'"
+	if got != want {
+		t.Fatalf("formatAgentCommand() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -9,10 +9,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"os/signal"
+	"path"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -36,7 +40,13 @@ var serveCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		host, _ := cmd.Flags().GetString("host")
 		port, _ := cmd.Flags().GetInt("port")
+		socketPath, _ := cmd.Flags().GetString("socket")
 		overrideToken, _ := cmd.Flags().GetString("token")
+		logRequests, _ := cmd.Flags().GetBool("log-requests")
+		socketPath = strings.TrimSpace(socketPath)
+		if socketPath != "" && (cmd.Flags().Changed("host") || cmd.Flags().Changed("port")) {
+			return fmt.Errorf("--socket cannot be combined with --host or --port")
+		}
 
 		configDir, err := xdg.ConfigDir()
 		if err != nil {
@@ -55,21 +65,43 @@ var serveCmd = &cobra.Command{
 			fmt.Fprintf(cmd.OutOrStdout(), "Generated dv serve token: %s\n", activeToken)
 		}
 
-		handler := authMiddleware(activeToken, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			handleServeRequest(w, r, configDir)
+		tokens := &serveTokenHolder{token: activeToken}
+		handler := authMiddleware(tokens, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handleServeRequest(w, r, configDir, tokens)
 		}))
+		if logRequests {
+			handler = requestLogMiddleware(cmd.ErrOrStderr(), handler)
+		}
 
 		srv := &http.Server{
 			Addr:    fmt.Sprintf("%s:%d", host, port),
 			Handler: handler,
 		}
 
+		var listener net.Listener
+		if socketPath != "" {
+			listener, err = listenUnixSocket(socketPath)
+			if err != nil {
+				return err
+			}
+			defer os.Remove(socketPath)
+		} else {
+			listener, err = net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return err
+			}
+		}
+
 		errCh := make(chan error, 1)
 		go func() {
-			errCh <- srv.ListenAndServe()
+			errCh <- srv.Serve(listener)
 		}()
 
-		fmt.Fprintf(cmd.OutOrStdout(), "Listening on http://%s\n", srv.Addr)
+		if socketPath != "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "Listening on unix://%s\n", socketPath)
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "Listening on http://%s\n", srv.Addr)
+		}
 
 		sigCh := make(chan os.Signal, 1)
 		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
@@ -93,6 +125,34 @@ func init() {
 	serveCmd.Flags().Int("port", 7373, "Port to listen on")
 	serveCmd.Flags().String("host", "127.0.0.1", "Host to bind to")
 	serveCmd.Flags().String("token", "", "Bearer token to require")
+	serveCmd.Flags().String("socket", "", "Listen on a Unix domain socket at this path instead of host:port")
+	serveCmd.Flags().Bool("log-requests", false, "Log method, path, status, duration and remote address of each request to stderr")
+}
+
+// listenUnixSocket listens on path, replacing a stale socket file left behind
+// by a previous run, and restricts access to the current user.
+func listenUnixSocket(path string) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("%s is already in use by another server", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("remove stale socket %s: %w", path, err)
+		}
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	return listener, nil
 }
 
 type sseWriter struct {
@@ -115,10 +175,80 @@ func (s *sseWriter) writeComment(comment string) {
 	s.flusher.Flush()
 }
 
-func authMiddleware(token string, next http.Handler) http.Handler {
+// serveTokenHolder holds the active bearer token so it can be rotated while
+// the server is running.
+type serveTokenHolder struct {
+	mu    sync.RWMutex
+	token string
+}
+
+func (h *serveTokenHolder) get() string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.token
+}
+
+func (h *serveTokenHolder) set(token string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.token = token
+}
+
+// statusRecorder captures the response status for request logging. It writes
+// straight through (no buffering) and forwards Flush so SSE streams keep working.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func requestLogMiddleware(out io.Writer, next http.Handler) http.Handler {
+	var mu sync.Mutex
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		remote := r.RemoteAddr
+		if remote == "" || remote == "@" {
+			remote = "unix"
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(out, "%s %s %s %d %s %s\n", start.Format(time.RFC3339), r.Method, r.URL.Path, status, time.Since(start).Round(time.Millisecond), remote)
+	})
+}
+
+func authMiddleware(tokens *serveTokenHolder, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		auth := r.Header.Get("Authorization")
-		if !strings.HasPrefix(auth, "Bearer ") || strings.TrimSpace(strings.TrimPrefix(auth, "Bearer ")) != token {
+		if !strings.HasPrefix(auth, "Bearer ") || strings.TrimSpace(strings.TrimPrefix(auth, "Bearer ")) != tokens.get() {
 			writeJSON(w, http.StatusUnauthorized, "unauthorized")
 			return
 		}
@@ -137,11 +267,10 @@ func ensureServeToken(cfg *config.Config, configDir, override string) (string, b
 	if strings.TrimSpace(cfg.ServeToken) != "" {
 		return cfg.ServeToken, false, nil
 	}
-	buf := make([]byte, 32)
-	if _, err := rand.Read(buf); err != nil {
+	token, err := generateServeToken()
+	if err != nil {
 		return "", false, err
 	}
-	token := hex.EncodeToString(buf)
 	cfg.ServeToken = token
 	if err := config.Save(configDir, *cfg); err != nil {
 		return "", false, err
@@ -149,15 +278,29 @@ func ensureServeToken(cfg *config.Config, configDir, override string) (string, b
 	return token, true, nil
 }
 
-func handleServeRequest(w http.ResponseWriter, r *http.Request, configDir string) {
+func generateServeToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func handleServeRequest(w http.ResponseWriter, r *http.Request, configDir string, tokens *serveTokenHolder) {
 	path := strings.Trim(strings.TrimSpace(r.URL.Path), "/")
 	switch {
+	case path == "token/rotate":
+		handleTokenRotate(w, r, configDir, tokens)
+		return
 	case r.Method == http.MethodGet && path == "status":
 		handleStatus(w, r, configDir)
 		return
 	case path == "containers":
 		handleContainers(w, r, configDir)
 		return
+	case path == "containers/actions":
+		handleContainerBulkAction(w, r, configDir)
+		return
 	case strings.HasPrefix(path, "containers/"):
 		handleContainer(w, r, configDir, strings.Split(path, "/"))
 		return
@@ -170,11 +313,41 @@ func handleServeRequest(w http.ResponseWriter, r *http.Request, configDir string
 	case path == "config":
 		handleConfig(w, r, configDir)
 		return
+	case path == "config/schema":
+		handleConfigSchema(w, r, configDir)
+		return
+	case path == "prune":
+		handlePrune(w, r, configDir)
+		return
 	default:
 		writeJSON(w, http.StatusNotFound, "not found")
 	}
 }
 
+func handleTokenRotate(w http.ResponseWriter, r *http.Request, configDir string, tokens *serveTokenHolder) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	cfg, err := config.LoadOrCreate(configDir)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	token, err := generateServeToken()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	cfg.ServeToken = token
+	if err := config.Save(configDir, cfg); err != nil {
+		writeJSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	tokens.set(token)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"token": token})
+}
+
 func handleStatus(w http.ResponseWriter, r *http.Request, configDir string) {
 	cfg, err := config.LoadOrCreate(configDir)
 	if err != nil {
@@ -202,14 +375,25 @@ func handleContainers(w http.ResponseWriter, r *http.Request, configDir string)
 
 	switch r.Method {
 	case http.MethodGet:
-		withSessions := strings.EqualFold(r.URL.Query().Get("sessions"), "true")
-		containers, selected, err := listContainers(cfg, withSessions)
+		opts, err := parseContainerListOptions(r.URL.Query())
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if opts.Image != "" {
+			if _, ok := cfg.Images[opts.Image]; !ok {
+				writeJSON(w, http.StatusBadRequest, fmt.Sprintf("unknown image '%s'", opts.Image))
+				return
+			}
+		}
+		containers, total, selected, err := listContainers(r.Context(), cfg, opts)
 		if err != nil {
 			writeJSON(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 		writeJSON(w, http.StatusOK, map[string]interface{}{
 			"containers": containers,
+			"total":      total,
 			"selected":   selected,
 		})
 	case http.MethodPost:
@@ -226,6 +410,10 @@ func handleContainerCreate(w http.ResponseWriter, r *http.Request, configDir str
 		HostStartingPort int    `json:"host_starting_port"`
 		ContainerPort    int    `json:"container_port"`
 		Reset            bool   `json:"reset"`
+		// Memory and CPUs override cfg.ContainerMemory/ContainerCPUs for this
+		// container; empty falls back to the config (empty there means unlimited).
+		Memory string `json:"memory"`
+		CPUs   string `json:"cpus"`
 	}
 	if err := decodeJSON(r, &req); err != nil {
 		writeJSON(w, http.StatusBadRequest, err.Error())
@@ -239,6 +427,27 @@ func handleContainerCreate(w http.ResponseWriter, r *http.Request, configDir str
 		writeJSON(w, http.StatusBadRequest, "container name required")
 		return
 	}
+	if !docker.ValidContainerName(name) {
+		writeJSON(w, http.StatusBadRequest, fmt.Sprintf("invalid container name '%s': must match %s (lowercase letters, digits, '_', '.', '-', starting with a letter or digit)", name, docker.ContainerNamePattern))
+		return
+	}
+
+	memory := strings.TrimSpace(req.Memory)
+	if memory == "" {
+		memory = cfg.ContainerMemory
+	}
+	cpus := strings.TrimSpace(req.CPUs)
+	if cpus == "" {
+		cpus = cfg.ContainerCPUs
+	}
+	if err := docker.ValidateMemoryLimit(memory); err != nil {
+		writeJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := docker.ValidateCPULimit(cpus); err != nil {
+		writeJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	hostPort := req.HostStartingPort
 	if hostPort == 0 {
@@ -270,16 +479,15 @@ func handleContainerCreate(w http.ResponseWriter, r *http.Request, configDir str
 		createdContainer := false
 		startedContainer := false
 		hookHostPort := 0
-		if req.Reset && docker.Exists(name) {
+		if req.Reset && docker.ExistsContext(r.Context(), name) {
 			logger(fmt.Sprintf("Stopping and removing container '%s'...\n", name))
 			_ = docker.Stop(name)
 			_ = docker.Remove(name)
 		}
-		if !docker.Exists(name) {
-			allocated, _ := docker.AllocatedPorts()
-			chosenPort := hostPort
-			for isPortInUse(chosenPort, allocated) {
-				chosenPort++
+		if !docker.ExistsContext(r.Context(), name) {
+			chosenPort, err := docker.FindFreeHostPort(hostPort)
+			if err != nil {
+				return err
 			}
 			if chosenPort != hostPort {
 				logger(fmt.Sprintf("Port %d in use, using %d.\n", hostPort, chosenPort))
@@ -293,13 +501,24 @@ func handleContainerCreate(w http.ResponseWriter, r *http.Request, configDir str
 				"DISCOURSE_PORT": strconv.Itoa(chosenPort),
 			}
 			logger(fmt.Sprintf("Creating and starting container '%s' with image '%s'...\n", name, imgCfg.Tag))
-			if err := docker.RunDetached(name, workdir, imgCfg.Tag, chosenPort, containerPort, labels, envs, nil, "", nil); err != nil {
+			if err := docker.RunDetached(docker.RunOptions{
+				Name:          name,
+				Workdir:       workdir,
+				Image:         imgCfg.Tag,
+				HostPort:      chosenPort,
+				ContainerPort: containerPort,
+				Labels:        labels,
+				Envs:          envs,
+				Memory:        memory,
+				CPUs:          cpus,
+				Platform:      imagePlatform(cfg, imgName),
+			}); err != nil {
 				return err
 			}
 			createdContainer = true
 			startedContainer = true
 			hookHostPort = chosenPort
-		} else if !docker.Running(name) {
+		} else if !docker.RunningContext(r.Context(), name) {
 			logger(fmt.Sprintf("Starting existing container '%s'...\n", name))
 			if err := startContainerWithPostStartHook(hookCmd, cfg, configDir, name, "serve start"); err != nil {
 				return err
@@ -347,6 +566,95 @@ func handleContainerCreate(w http.ResponseWriter, r *http.Request, configDir str
 	}, true)
 }
 
+func handleContainerBulkAction(w http.ResponseWriter, r *http.Request, configDir string) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req struct {
+		Action string   `json:"action"`
+		Names  []string `json:"names"`
+		Force  bool     `json:"force"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	action := strings.ToLower(strings.TrimSpace(req.Action))
+	switch action {
+	case "start", "stop", "restart", "delete":
+	default:
+		writeJSON(w, http.StatusBadRequest, "action must be one of start, stop, restart, delete")
+		return
+	}
+	var names []string
+	for _, n := range req.Names {
+		if n = strings.TrimSpace(n); n != "" {
+			names = append(names, n)
+		}
+	}
+	names = uniqueStrings(names)
+	if len(names) == 0 {
+		writeJSON(w, http.StatusBadRequest, "names required")
+		return
+	}
+
+	streamSequence(w, func(sse *sseWriter) error {
+		type failure struct {
+			Container string `json:"container"`
+			Error     string `json:"error"`
+		}
+		failures := []failure{}
+		for _, name := range names {
+			err := runBulkContainerAction(r.Context(), sse, configDir, action, name, req.Force)
+			done := map[string]interface{}{"container": name, "exit_code": exitCode(err)}
+			if err != nil {
+				done["error"] = err.Error()
+				failures = append(failures, failure{Container: name, Error: err.Error()})
+			}
+			sse.writeEvent("done", done)
+		}
+		summaryCode := 0
+		if len(failures) > 0 {
+			summaryCode = 1
+		}
+		sse.writeEvent("summary", map[string]interface{}{
+			"action":    action,
+			"total":     len(names),
+			"failed":    failures,
+			"exit_code": summaryCode,
+		})
+		return nil
+	}, false)
+}
+
+func runBulkContainerAction(ctx context.Context, sse *sseWriter, configDir, action, name string, force bool) error {
+	cfg, err := config.LoadOrCreate(configDir)
+	if err != nil {
+		return err
+	}
+	var execFn func(stdout, stderr io.Writer) error
+	switch action {
+	case "start":
+		execFn, err = containerStartExec(ctx, cfg, configDir, name, false)
+		if err != nil {
+			return err
+		}
+	case "stop":
+		execFn = containerStopExec(ctx, name)
+	case "restart":
+		execFn = containerRestartExec(ctx, cfg, configDir, name)
+	case "delete":
+		execFn = func(stdout, stderr io.Writer) error {
+			fmt.Fprintf(stdout, "Deleting container '%s'...\n", name)
+			return deleteContainer(ctx, configDir, name, false, force)
+		}
+	default:
+		return fmt.Errorf("unsupported action '%s'", action)
+	}
+	return runContainerExecWithSSE(sse, name, execFn)
+}
+
 func handleContainer(w http.ResponseWriter, r *http.Request, configDir string, parts []string) {
 	if len(parts) < 2 {
 		writeJSON(w, http.StatusNotFound, "not found")
@@ -391,6 +699,22 @@ func handleContainer(w http.ResponseWriter, r *http.Request, configDir string, p
 			handleContainerReset(w, r, configDir, name)
 		case "ps":
 			handleContainerPS(w, r, name)
+		case "env":
+			handleContainerEnv(w, r, name)
+		case "cp":
+			handleContainerCopy(w, r, name)
+		case "network":
+			handleContainerNetwork(w, r, configDir, name)
+		case "stats":
+			if len(parts) >= 4 {
+				if parts[3] == "stream" {
+					handleContainerStatsStream(w, r, name)
+					return
+				}
+				writeJSON(w, http.StatusNotFound, "not found")
+				return
+			}
+			handleContainerStats(w, r, name)
 		case "update":
 			if len(parts) >= 4 && parts[3] == "agents" {
 				handleContainerUpdateAgents(w, r, configDir, name)
@@ -404,6 +728,8 @@ func handleContainer(w http.ResponseWriter, r *http.Request, configDir string, p
 					handleContainerLogTail(w, r, name, "/var/www/discourse/log/rails.log")
 				case "ember":
 					handleContainerLogTail(w, r, name, "/var/www/discourse/log/ember.log")
+				case "docker":
+					handleContainerDockerLogs(w, r, name)
 				default:
 					writeJSON(w, http.StatusNotFound, "not found")
 				}
@@ -424,7 +750,7 @@ func handleContainerInfo(w http.ResponseWriter, r *http.Request, configDir, name
 		writeJSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	containers, selected, err := listContainers(cfg, false)
+	containers, _, _, err := listContainers(r.Context(), cfg, containerListOptions{})
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, err.Error())
 		return
@@ -435,7 +761,6 @@ func handleContainerInfo(w http.ResponseWriter, r *http.Request, configDir, name
 			return
 		}
 	}
-	_ = selected
 	writeJSON(w, http.StatusNotFound, "container not found")
 }
 
@@ -453,33 +778,42 @@ func handleContainerStart(w http.ResponseWriter, r *http.Request, configDir, nam
 		return
 	}
 
+	execFn, err := containerStartExec(r.Context(), cfg, configDir, name, req.Reset)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	streamExec(w, execFn, true)
+}
+
+// containerStartExec returns the exec function that creates or starts name,
+// resolving its image up front so callers can reject unknown images early.
+func containerStartExec(ctx context.Context, cfg config.Config, configDir, name string, reset bool) (func(stdout, stderr io.Writer) error, error) {
 	imgName := cfg.ContainerImages[name]
 	if imgName == "" {
 		imgName = cfg.SelectedImage
 	}
 	imgCfg, ok := cfg.Images[imgName]
 	if !ok {
-		writeJSON(w, http.StatusBadRequest, "unknown image")
-		return
+		return nil, fmt.Errorf("unknown image")
 	}
 	workdir := imgCfg.Workdir
 	if strings.TrimSpace(workdir) == "" {
 		workdir = "/var/www/discourse"
 	}
 
-	streamExec(w, func(stdout, stderr io.Writer) error {
+	return func(stdout, stderr io.Writer) error {
 		logger := func(line string) { fmt.Fprint(stdout, line) }
 		hookCmd := newHostHookCommand("serve", strings.NewReader(""), stdout, stderr)
-		if req.Reset && docker.Exists(name) {
+		if reset && docker.ExistsContext(ctx, name) {
 			logger(fmt.Sprintf("Resetting container '%s'...\n", name))
 			_ = docker.Stop(name)
 			_ = docker.Remove(name)
 		}
-		if !docker.Exists(name) {
-			allocated, _ := docker.AllocatedPorts()
-			chosenPort := cfg.HostStartingPort
-			for isPortInUse(chosenPort, allocated) {
-				chosenPort++
+		if !docker.ExistsContext(ctx, name) {
+			chosenPort, err := docker.FindFreeHostPort(cfg.HostStartingPort)
+			if err != nil {
+				return err
 			}
 			labels := map[string]string{
 				"com.dv.owner":      "dv",
@@ -490,7 +824,18 @@ func handleContainerStart(w http.ResponseWriter, r *http.Request, configDir, nam
 				"DISCOURSE_PORT": strconv.Itoa(chosenPort),
 			}
 			logger(fmt.Sprintf("Creating and starting container '%s'...\n", name))
-			if err := docker.RunDetached(name, workdir, imgCfg.Tag, chosenPort, cfg.ContainerPort, labels, envs, nil, "", nil); err != nil {
+			if err := docker.RunDetached(docker.RunOptions{
+				Name:          name,
+				Workdir:       workdir,
+				Image:         imgCfg.Tag,
+				HostPort:      chosenPort,
+				ContainerPort: cfg.ContainerPort,
+				Labels:        labels,
+				Envs:          envs,
+				Memory:        cfg.ContainerMemory,
+				CPUs:          cfg.ContainerCPUs,
+				Platform:      imagePlatform(cfg, imgName),
+			}); err != nil {
 				return err
 			}
 			hookCtx := hostHookContext{
@@ -508,24 +853,28 @@ func handleContainerStart(w http.ResponseWriter, r *http.Request, configDir, nam
 			}
 			return runHostHooksForContainer(hookCmd, cfg, hostHookPostStart, hookCtx)
 		}
-		if !docker.Running(name) {
+		if !docker.RunningContext(ctx, name) {
 			logger(fmt.Sprintf("Starting container '%s'...\n", name))
 			return startContainerWithPostStartHook(hookCmd, cfg, configDir, name, "serve start")
 		}
 		logger(fmt.Sprintf("Container '%s' already running.\n", name))
 		return nil
-	}, true)
+	}, nil
 }
 
 func handleContainerStop(w http.ResponseWriter, r *http.Request, name string) {
-	streamExec(w, func(stdout, stderr io.Writer) error {
+	streamExec(w, containerStopExec(r.Context(), name), true)
+}
+
+func containerStopExec(ctx context.Context, name string) func(stdout, stderr io.Writer) error {
+	return func(stdout, stderr io.Writer) error {
 		fmt.Fprintf(stdout, "Stopping container '%s'...\n", name)
-		if docker.Running(name) {
+		if docker.RunningContext(ctx, name) {
 			return docker.Stop(name)
 		}
 		fmt.Fprintln(stdout, "Container already stopped.")
 		return nil
-	}, true)
+	}
 }
 
 func handleContainerRestart(w http.ResponseWriter, r *http.Request, configDir, name string) {
@@ -534,9 +883,13 @@ func handleContainerRestart(w http.ResponseWriter, r *http.Request, configDir, n
 		writeJSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	streamExec(w, func(stdout, stderr io.Writer) error {
+	streamExec(w, containerRestartExec(r.Context(), cfg, configDir, name), true)
+}
+
+func containerRestartExec(ctx context.Context, cfg config.Config, configDir, name string) func(stdout, stderr io.Writer) error {
+	return func(stdout, stderr io.Writer) error {
 		hookCmd := newHostHookCommand("serve", strings.NewReader(""), stdout, stderr)
-		if docker.Running(name) {
+		if docker.RunningContext(ctx, name) {
 			fmt.Fprintf(stdout, "Stopping container '%s'...\n", name)
 			if err := docker.Stop(name); err != nil {
 				return err
@@ -544,15 +897,12 @@ func handleContainerRestart(w http.ResponseWriter, r *http.Request, configDir, n
 		}
 		fmt.Fprintf(stdout, "Starting container '%s'...\n", name)
 		return startContainerWithPostStartHook(hookCmd, cfg, configDir, name, "serve restart")
-	}, true)
+	}
 }
 
+var errContainerHasSessions = errors.New("container has active sessions")
+
 func handleContainerDelete(w http.ResponseWriter, r *http.Request, configDir, name string) {
-	cfg, err := config.LoadOrCreate(configDir)
-	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, err.Error())
-		return
-	}
 	var req struct {
 		RemoveImage bool `json:"remove_image"`
 		Force       bool `json:"force"`
@@ -561,23 +911,41 @@ func handleContainerDelete(w http.ResponseWriter, r *http.Request, configDir, na
 		writeJSON(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	if docker.Exists(name) && !req.Force {
+	if err := deleteContainer(r.Context(), configDir, name, req.RemoveImage, req.Force); err != nil {
+		if errors.Is(err, errContainerHasSessions) {
+			writeJSON(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{})
+}
+
+// deleteContainer removes name (and optionally its image) and drops it from
+// the config. Unless force is set, containers with active exec sessions are
+// left alone and errContainerHasSessions is returned.
+func deleteContainer(ctx context.Context, configDir, name string, removeImage, force bool) error {
+	cfg, err := config.LoadOrCreate(configDir)
+	if err != nil {
+		return err
+	}
+	if docker.ExistsContext(ctx, name) && !force {
 		sessions, err := docker.ExecSessions(name)
 		if err == nil && len(sessions) > 0 {
-			writeJSON(w, http.StatusConflict, "container has active sessions")
-			return
+			return errContainerHasSessions
 		}
 	}
 
-	if docker.Exists(name) {
-		if docker.Running(name) {
+	if docker.ExistsContext(ctx, name) {
+		if docker.RunningContext(ctx, name) {
 			_ = docker.RemoveForce(name)
 		} else {
 			_ = docker.Remove(name)
 		}
 	}
 
-	if req.RemoveImage {
+	if removeImage {
 		imageTag := ""
 		if imgName := cfg.ContainerImages[name]; imgName != "" {
 			if imgCfg, ok := cfg.Images[imgName]; ok {
@@ -591,6 +959,13 @@ func handleContainerDelete(w http.ResponseWriter, r *http.Request, configDir, na
 		}
 	}
 
+	forgetContainer(&cfg, name)
+	_ = config.Save(configDir, cfg)
+	return nil
+}
+
+// forgetContainer drops the per-container config entries for a removed container.
+func forgetContainer(cfg *config.Config, name string) {
 	if cfg.ContainerImages != nil {
 		delete(cfg.ContainerImages, name)
 	}
@@ -600,9 +975,145 @@ func handleContainerDelete(w http.ResponseWriter, r *http.Request, configDir, na
 	if cfg.SelectedAgent == name {
 		cfg.SelectedAgent = ""
 	}
-	_ = config.Save(configDir, cfg)
+}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{})
+// prunableStatuses are the container states prune may remove; running and
+// paused containers are never touched.
+var prunableStatuses = docker.StoppedStatuses
+
+func handlePrune(w http.ResponseWriter, r *http.Request, configDir string) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req struct {
+		Images bool `json:"images"`
+		DryRun bool `json:"dry_run"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	cfg, err := config.LoadOrCreate(configDir)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	stopped, err := docker.ListContainersWithLabel("com.dv.owner=dv", prunableStatuses...)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var images []string
+	if req.Images {
+		all, err := docker.ListContainersWithLabel("com.dv.owner=dv")
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		stopped, images = pruneCandidates(stopped, all, cfg)
+	} else {
+		stopped, _ = pruneCandidates(stopped, nil, cfg)
+	}
+
+	streamSequence(w, func(sse *sseWriter) error {
+		failed := 0
+		report := func(kind, name string, err error) {
+			event := map[string]interface{}{"type": kind, "name": name, "dry_run": req.DryRun}
+			if err != nil {
+				failed++
+				event["error"] = err.Error()
+			}
+			sse.writeEvent("prune", event)
+		}
+
+		var removed []string
+		if req.DryRun {
+			for _, c := range stopped {
+				report("container", c.Name, nil)
+			}
+		} else {
+			// Failures are reported per container; the joined error adds nothing.
+			removed, _ = docker.RemoveContainers(stopped, false, func(name string, err error) {
+				if err == nil {
+					forgetContainer(&cfg, name)
+				}
+				report("container", name, err)
+			})
+		}
+		if len(removed) > 0 {
+			if err := config.Save(configDir, cfg); err != nil {
+				return err
+			}
+		}
+		for _, tag := range images {
+			if req.DryRun {
+				report("image", tag, nil)
+				continue
+			}
+			report("image", tag, docker.RemoveImageIfUnused(tag))
+		}
+
+		sse.writeEvent("summary", map[string]interface{}{
+			"containers": len(stopped),
+			"images":     len(images),
+			"failed":     failed,
+			"dry_run":    req.DryRun,
+		})
+		if failed > 0 {
+			return fmt.Errorf("%d removal(s) failed", failed)
+		}
+		return nil
+	}, true)
+}
+
+// pruneCandidates drops the selected agent (defaultContainerName when none
+// is selected, as handleStatus shows it) from stopped and, when all (every
+// dv-owned container) is given, returns the images used only by the
+// containers being pruned. Configured base images (cfg.Images) are never
+// returned, even when no container uses them.
+func pruneCandidates(stopped, all []docker.ContainerSummary, cfg config.Config) ([]docker.ContainerSummary, []string) {
+	selected := strings.TrimSpace(cfg.SelectedAgent)
+	if selected == "" {
+		selected = cfg.DefaultContainer
+	}
+	var containers []docker.ContainerSummary
+	pruned := map[string]bool{}
+	for _, c := range stopped {
+		if c.Name == selected {
+			continue
+		}
+		containers = append(containers, c)
+		pruned[c.Name] = true
+	}
+	if all == nil {
+		return containers, nil
+	}
+	inUse := map[string]bool{}
+	for _, img := range cfg.Images {
+		if img.Tag == "" {
+			continue
+		}
+		inUse[img.Tag] = true
+		if !strings.Contains(img.Tag, ":") {
+			inUse[img.Tag+":latest"] = true
+		}
+	}
+	for _, c := range all {
+		if !pruned[c.Name] {
+			inUse[c.Image] = true
+		}
+	}
+	var images []string
+	seen := map[string]bool{}
+	for _, c := range containers {
+		if c.Image == "" || inUse[c.Image] || seen[c.Image] {
+			continue
+		}
+		seen[c.Image] = true
+		images = append(images, c.Image)
+	}
+	return containers, images
 }
 
 func handleContainerSelect(w http.ResponseWriter, r *http.Request, configDir, name string) {
@@ -662,10 +1173,11 @@ func handleContainerRename(w http.ResponseWriter, r *http.Request, configDir, na
 
 func handleContainerRun(w http.ResponseWriter, r *http.Request, configDir, name string) {
 	var req struct {
-		Cmd     string            `json:"cmd"`
-		Workdir string            `json:"workdir"`
-		AsRoot  bool              `json:"as_root"`
-		Env     map[string]string `json:"env"`
+		Cmd            string            `json:"cmd"`
+		Workdir        string            `json:"workdir"`
+		AsRoot         bool              `json:"as_root"`
+		Env            map[string]string `json:"env"`
+		TimeoutSeconds int               `json:"timeout_seconds"`
 	}
 	if err := decodeJSON(r, &req); err != nil {
 		writeJSON(w, http.StatusBadRequest, err.Error())
@@ -675,10 +1187,14 @@ func handleContainerRun(w http.ResponseWriter, r *http.Request, configDir, name
 		writeJSON(w, http.StatusBadRequest, "cmd required")
 		return
 	}
+	if req.TimeoutSeconds < 0 {
+		writeJSON(w, http.StatusBadRequest, "timeout_seconds must be positive")
+		return
+	}
 
 	argv := []string{"bash", "-lc", req.Cmd}
 	streamExec(w, func(stdout, stderr io.Writer) error {
-		ctx, err := ensureContainerExecContext(configDir, name, stdout, stderr)
+		ctx, err := ensureContainerExecContext(r.Context(), configDir, name, stdout, stderr)
 		if err != nil {
 			return err
 		}
@@ -696,10 +1212,23 @@ func handleContainerRun(w http.ResponseWriter, r *http.Request, configDir, name
 			envs = append(envs, fmt.Sprintf("%s=%s", k, v))
 		}
 
+		runCtx := r.Context()
+		if req.TimeoutSeconds > 0 {
+			var cancel context.CancelFunc
+			runCtx, cancel = context.WithTimeout(runCtx, time.Duration(req.TimeoutSeconds)*time.Second)
+			defer cancel()
+		}
+
 		if req.AsRoot {
-			return execStreamAsUserContext(r.Context(), "root", name, workdir, envs, argv, stdout, stderr)
+			err = execStreamAsUserContext(runCtx, "root", name, workdir, envs, argv, stdout, stderr)
+		} else {
+			err = docker.ExecStreamContext(runCtx, name, workdir, envs, argv, stdout, stderr)
 		}
-		return docker.ExecStreamContext(r.Context(), name, workdir, envs, argv, stdout, stderr)
+		if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+			fmt.Fprintf(stderr, "command timed out after %ds\n", req.TimeoutSeconds)
+			return errExecTimeout
+		}
+		return err
 	}, true)
 }
 
@@ -726,7 +1255,7 @@ func handleContainerRunAgent(w http.ResponseWriter, r *http.Request, configDir,
 	agent = resolveAgentAliasWithConfig(cfg, agent)
 
 	streamExec(w, func(stdout, stderr io.Writer) error {
-		ctx, err := ensureContainerExecContext(configDir, name, stdout, stderr)
+		ctx, err := ensureContainerExecContext(r.Context(), configDir, name, stdout, stderr)
 		if err != nil {
 			return err
 		}
@@ -813,7 +1342,7 @@ func handleContainerBranch(w http.ResponseWriter, r *http.Request, configDir, na
 	argv := []string{"bash", "-lc", script}
 
 	streamExec(w, func(stdout, stderr io.Writer) error {
-		ctx, _, err := ensureDiscourseContainer(configDir, name, stdout, stderr)
+		ctx, _, err := ensureDiscourseContainer(r.Context(), configDir, name, stdout, stderr)
 		if err != nil {
 			return err
 		}
@@ -823,7 +1352,7 @@ func handleContainerBranch(w http.ResponseWriter, r *http.Request, configDir, na
 
 func handleContainerCatchup(w http.ResponseWriter, r *http.Request, configDir, name string) {
 	streamExec(w, func(stdout, stderr io.Writer) error {
-		ctx, _, err := ensureDiscourseContainer(configDir, name, stdout, stderr)
+		ctx, _, err := ensureDiscourseContainer(r.Context(), configDir, name, stdout, stderr)
 		if err != nil {
 			return err
 		}
@@ -866,7 +1395,7 @@ func handleContainerReset(w http.ResponseWriter, r *http.Request, configDir, nam
 	argv := []string{"bash", "-lc", script}
 
 	streamExec(w, func(stdout, stderr io.Writer) error {
-		ctx, _, err := ensureDiscourseContainer(configDir, name, stdout, stderr)
+		ctx, _, err := ensureDiscourseContainer(r.Context(), configDir, name, stdout, stderr)
 		if err != nil {
 			return err
 		}
@@ -886,13 +1415,264 @@ func handleContainerPS(w http.ResponseWriter, r *http.Request, name string) {
 			"pid":     s.PID,
 			"command": s.Command,
 			"user":    s.User,
-			"cpu":     "",
-			"mem":     "",
+			"cpu":     strconv.FormatFloat(s.CPU, 'f', 1, 64),
+			"mem":     strconv.FormatFloat(s.Mem, 'f', 1, 64),
 		})
 	}
 	writeJSON(w, http.StatusOK, map[string]interface{}{"sessions": out})
 }
 
+func handleContainerEnv(w http.ResponseWriter, r *http.Request, name string) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !docker.ExistsContext(r.Context(), name) {
+		writeJSON(w, http.StatusNotFound, docker.ErrContainerNotFound)
+		return
+	}
+	env, err := docker.GetContainerEnv(name)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if r.URL.Query().Get("reveal") != "true" {
+		env = maskSecretEnv(env)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"env": env})
+}
+
+// maskSecretEnv returns a copy of env with values of credential-looking keys
+// (*_KEY, *_TOKEN, *_SECRET) masked.
+func maskSecretEnv(env map[string]string) map[string]string {
+	out := make(map[string]string, len(env))
+	for k, v := range env {
+		if isSecretEnvKey(k) && v != "" {
+			v = maskValue(v)
+		}
+		out[k] = v
+	}
+	return out
+}
+
+func isSecretEnvKey(key string) bool {
+	upper := strings.ToUpper(key)
+	for _, suffix := range []string{"_KEY", "_TOKEN", "_SECRET"} {
+		if strings.HasSuffix(upper, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func handleContainerNetwork(w http.ResponseWriter, r *http.Request, configDir, name string) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !docker.ExistsContext(r.Context(), name) {
+		writeJSON(w, http.StatusNotFound, docker.ErrContainerNotFound)
+		return
+	}
+	cfg, err := config.LoadOrCreate(configDir)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	ci, err := docker.Inspect(name)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	networks := ci.Networks()
+	ports := []map[string]interface{}{}
+	if hostPort, err := ci.HostPort(cfg.ContainerPort); err == nil {
+		ports = append(ports, map[string]interface{}{
+			"container_port": cfg.ContainerPort,
+			"host_port":      hostPort,
+		})
+	}
+	var ip interface{}
+	if v := docker.PrimaryIP(networks); v != "" {
+		ip = v
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"ip":       ip,
+		"networks": networks,
+		"ports":    ports,
+	})
+}
+
+// maxCopyUploadBytes caps uploads to POST /containers/{name}/cp.
+const maxCopyUploadBytes = 100 << 20
+
+func handleContainerCopy(w http.ResponseWriter, r *http.Request, name string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if err := docker.CheckRunning(r.Context(), name); err != nil {
+		writeJSON(w, httpStatusForError(err), err)
+		return
+	}
+	if r.Method == http.MethodGet {
+		handleContainerCopyOut(w, r, name)
+		return
+	}
+	handleContainerCopyIn(w, r, name)
+}
+
+func handleContainerCopyOut(w http.ResponseWriter, r *http.Request, name string) {
+	src := r.URL.Query().Get("path")
+	if err := validateContainerCopyPath(src); err != nil {
+		writeJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	rc, hdr, err := docker.ReadFileFromContainer(r.Context(), name, src)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			writeJSON(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer rc.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.FormatInt(hdr.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(hdr.Name)}))
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, rc)
+}
+
+func handleContainerCopyIn(w http.ResponseWriter, r *http.Request, name string) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxCopyUploadBytes)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			writeJSON(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("upload exceeds %d bytes", maxCopyUploadBytes))
+			return
+		}
+		writeJSON(w, http.StatusBadRequest, "multipart field 'file' required")
+		return
+	}
+	defer file.Close()
+	if r.MultipartForm != nil {
+		defer r.MultipartForm.RemoveAll()
+	}
+
+	dst := r.FormValue("path")
+	if dst == "" {
+		dst = r.URL.Query().Get("path")
+	}
+	if err := validateContainerCopyPath(dst); err != nil {
+		writeJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// A trailing slash names a directory; keep the uploaded file's base name.
+	if strings.HasSuffix(dst, "/") {
+		base := path.Base(strings.ReplaceAll(header.Filename, "\\", "/"))
+		if base == "." || base == "/" || base == ".." {
+			writeJSON(w, http.StatusBadRequest, "uploaded file has no usable name; pass a full destination path")
+			return
+		}
+		dst += base
+	}
+
+	tmp, err := os.CreateTemp("", "dv-cp-*")
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer os.Remove(tmp.Name())
+	size, err := io.Copy(tmp, file)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := docker.CopyToContainerWithOwnershipContext(r.Context(), name, tmp.Name(), dst, false); err != nil {
+		writeJSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"path": dst, "size_bytes": size})
+}
+
+// validateContainerCopyPath rejects empty paths and any path with a ".."
+// segment so copies can't be steered outside the directory the client named.
+func validateContainerCopyPath(p string) error {
+	if strings.TrimSpace(p) == "" {
+		return fmt.Errorf("path required")
+	}
+	for _, seg := range strings.Split(p, "/") {
+		if seg == ".." {
+			return fmt.Errorf("path must not contain '..'")
+		}
+	}
+	return nil
+}
+
+func handleContainerStats(w http.ResponseWriter, r *http.Request, name string) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if err := docker.CheckRunning(r.Context(), name); err != nil {
+		writeJSON(w, httpStatusForError(err), err)
+		return
+	}
+	stats, err := docker.StatsContext(r.Context(), name)
+	if err != nil {
+		writeJSON(w, httpStatusForError(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+func handleContainerStatsStream(w http.ResponseWriter, r *http.Request, name string) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if err := docker.CheckRunning(r.Context(), name); err != nil {
+		writeJSON(w, httpStatusForError(err), err)
+		return
+	}
+
+	sse, stop, err := startSSE(w)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer stop()
+
+	ctx := r.Context()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		stats, err := docker.StatsContext(ctx, name)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			sse.writeEvent("error", map[string]string{"error": err.Error()})
+			return
+		}
+		sse.writeEvent("stats", stats)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 func handleContainerUpdateAgents(w http.ResponseWriter, r *http.Request, configDir, name string) {
 	cfg, err := config.LoadOrCreate(configDir)
 	if err != nil {
@@ -919,7 +1699,7 @@ func handleContainerUpdateAgents(w http.ResponseWriter, r *http.Request, configD
 		var ctx containerExecContext
 		if err := runExecWithSSE(sse, func(stdout, stderr io.Writer) error {
 			var ensureErr error
-			ctx, ensureErr = ensureContainerExecContext(configDir, name, stdout, stderr)
+			ctx, ensureErr = ensureContainerExecContext(r.Context(), configDir, name, stdout, stderr)
 			return ensureErr
 		}); err != nil {
 			return err
@@ -962,6 +1742,35 @@ func handleContainerLogTail(w http.ResponseWriter, r *http.Request, name, logPat
 	}, false)
 }
 
+// handleContainerDockerLogs streams the container's own stdout/stderr via
+// `docker logs`. Query params: tail (default 0, everything, as with docker
+// logs), since, and follow (default true).
+func handleContainerDockerLogs(w http.ResponseWriter, r *http.Request, name string) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	q := r.URL.Query()
+	opts := docker.LogsOptions{
+		Since:  strings.TrimSpace(q.Get("since")),
+		Follow: q.Get("follow") != "false",
+	}
+	if v := strings.TrimSpace(q.Get("tail")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeJSON(w, http.StatusBadRequest, "tail must be a non-negative integer")
+			return
+		}
+		opts.Tail = n
+	}
+
+	streamExec(w, func(stdout, stderr io.Writer) error {
+		opts.Stdout = stdout
+		opts.Stderr = stderr
+		return docker.Logs(r.Context(), name, opts)
+	}, !opts.Follow)
+}
+
 func handleImages(w http.ResponseWriter, r *http.Request, configDir string) {
 	if r.Method != http.MethodGet {
 		writeJSON(w, http.StatusMethodNotAllowed, "method not allowed")
@@ -980,12 +1789,19 @@ func handleImages(w http.ResponseWriter, r *http.Request, configDir string) {
 	var images []map[string]interface{}
 	for _, name := range names {
 		img := cfg.Images[name]
+		var sizeBytes, createdAt interface{}
+		if info, err := docker.ImageInfo(img.Tag); err == nil && info != nil {
+			sizeBytes = info.SizeBytes
+			createdAt = info.CreatedAt.UTC().Format(time.RFC3339)
+		}
 		images = append(images, map[string]interface{}{
-			"name":     name,
-			"tag":      img.Tag,
-			"kind":     img.Kind,
-			"workdir":  img.Workdir,
-			"selected": name == cfg.SelectedImage,
+			"name":       name,
+			"tag":        img.Tag,
+			"kind":       img.Kind,
+			"workdir":    img.Workdir,
+			"selected":   name == cfg.SelectedImage,
+			"size_bytes": sizeBytes,
+			"created_at": createdAt,
 		})
 	}
 	writeJSON(w, http.StatusOK, map[string]interface{}{
@@ -1018,6 +1834,9 @@ func handleImageBuild(w http.ResponseWriter, r *http.Request, configDir string)
 		Tag          string   `json:"tag"`
 		ClassicBuild bool     `json:"classic_build"`
 		Builder      string   `json:"builder"`
+		CacheFrom    *string  `json:"cache_from"`
+		CacheTo      *string  `json:"cache_to"`
+		Platform     *string  `json:"platform"`
 		RmExisting   bool     `json:"rm_existing"`
 	}
 	if err := decodeJSON(r, &req); err != nil {
@@ -1030,7 +1849,7 @@ func handleImageBuild(w http.ResponseWriter, r *http.Request, configDir string)
 		return
 	}
 
-	if req.RmExisting && docker.Exists(cfg.DefaultContainer) {
+	if req.RmExisting && docker.ExistsContext(r.Context(), cfg.DefaultContainer) {
 		_ = docker.Stop(cfg.DefaultContainer)
 		_ = docker.Remove(cfg.DefaultContainer)
 	}
@@ -1099,9 +1918,70 @@ func handleImageBuild(w http.ResponseWriter, r *http.Request, configDir string)
 		buildArgs = append(buildArgs, "--build-arg", kv)
 	}
 
-	cmdName, cmdArgs, cmdEnv := buildDockerBuildCommand(imageTag, dockerfilePath, contextDir, req.ClassicBuild, req.Builder, buildArgs)
+	cacheFrom, cacheTo := cfg.BuildCacheFrom, cfg.BuildCacheTo
+	if req.CacheFrom != nil {
+		cacheFrom = *req.CacheFrom
+	}
+	if req.CacheTo != nil {
+		cacheTo = *req.CacheTo
+	}
+	cacheArgs, err := docker.BuildxCacheArgs(cacheFrom, cacheTo)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	platform := cfg.BuildPlatform
+	if req.Platform != nil {
+		platform = strings.TrimSpace(*req.Platform)
+	}
+	if err := docker.ValidatePlatform(platform); err != nil {
+		writeJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if platform != "" {
+		buildArgs = append(buildArgs, "--platform", platform)
+	}
+
+	cmdName, cmdArgs, cmdEnv := buildDockerBuildCommand(imageTag, dockerfilePath, contextDir, req.ClassicBuild, req.Builder, cacheArgs, buildArgs)
+
+	streamSequence(w, func(sse *sseWriter) error {
+		return runExecWithSSELines(sse, "", buildProgressReporter(sse), func(stdout, stderr io.Writer) error {
+			cmd := exec.CommandContext(r.Context(), cmdName, cmdArgs...)
+			cmd.Stdout = stdout
+			cmd.Stderr = stderr
+			cmd.Env = append(os.Environ(), cmdEnv...)
+			if err := cmd.Run(); err != nil {
+				return err
+			}
+			if latest, err := config.LoadOrCreate(configDir); err == nil && recordImagePlatform(&latest, imageTag, platform) {
+				return config.Save(configDir, latest)
+			}
+			return nil
+		})
+	}, true)
+}
 
-	streamHostCommandWithEnv(w, r.Context(), cmdName, cmdArgs, cmdEnv, true)
+// buildProgressReporter returns a line hook that emits a "progress" event the
+// first time each build step marker is seen. Raw lines are still sent as
+// "output" events by the caller.
+func buildProgressReporter(sse *sseWriter) func(stream, line string) {
+	var mu sync.Mutex
+	seen := map[string]bool{}
+	return func(stream, line string) {
+		p, ok := docker.ParseBuildProgressLine(line)
+		if !ok {
+			return
+		}
+		key := fmt.Sprintf("%d/%s/%d/%d", p.Vertex, p.Stage, p.Step, p.Total)
+		mu.Lock()
+		dup := seen[key]
+		seen[key] = true
+		mu.Unlock()
+		if !dup {
+			sse.writeEvent("progress", p)
+		}
+	}
 }
 
 func handleImagePull(w http.ResponseWriter, r *http.Request, configDir string) {
@@ -1205,10 +2085,83 @@ func handleConfig(w http.ResponseWriter, r *http.Request, configDir string) {
 	}
 }
 
-func listContainers(cfg config.Config, includeSessions bool) ([]map[string]interface{}, string, error) {
-	imgName, imgCfg, err := resolveImage(cfg, "")
+func handleConfigSchema(w http.ResponseWriter, r *http.Request, configDir string) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	cfg, err := config.LoadOrCreate(configDir)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"fields": configSchema(cfg)})
+}
+
+// containerListOptions filters and pages the containers returned by
+// listContainers. The zero value lists every container for the selected image.
+type containerListOptions struct {
+	Image           string
+	Status          string // "", "running" or "stopped"
+	Limit           int    // 0 means no limit
+	Offset          int
+	IncludeSessions bool
+}
+
+func parseContainerListOptions(query url.Values) (containerListOptions, error) {
+	opts := containerListOptions{
+		Image:           strings.TrimSpace(query.Get("image")),
+		Status:          strings.ToLower(strings.TrimSpace(query.Get("status"))),
+		IncludeSessions: strings.EqualFold(query.Get("sessions"), "true"),
+	}
+	switch opts.Status {
+	case "", "running", "stopped":
+	default:
+		return containerListOptions{}, fmt.Errorf("invalid status %q (expected running or stopped)", opts.Status)
+	}
+	for _, param := range []struct {
+		key string
+		dst *int
+	}{{"limit", &opts.Limit}, {"offset", &opts.Offset}} {
+		raw := strings.TrimSpace(query.Get(param.key))
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return containerListOptions{}, fmt.Errorf("invalid %s %q", param.key, raw)
+		}
+		*param.dst = n
+	}
+	return opts, nil
+}
+
+// filterAgents applies the status filter and limit/offset paging, returning
+// the page along with the number of agents that matched the filter.
+func filterAgents(agents []agentInfo, opts containerListOptions) ([]agentInfo, int) {
+	var matched []agentInfo
+	for _, agent := range agents {
+		running := agent.status == "Running"
+		if (opts.Status == "running" && !running) || (opts.Status == "stopped" && running) {
+			continue
+		}
+		matched = append(matched, agent)
+	}
+	total := len(matched)
+	if opts.Offset >= total {
+		return nil, total
+	}
+	matched = matched[opts.Offset:]
+	if opts.Limit > 0 && opts.Limit < len(matched) {
+		matched = matched[:opts.Limit]
+	}
+	return matched, total
+}
+
+func listContainers(ctx context.Context, cfg config.Config, opts containerListOptions) ([]map[string]interface{}, int, string, error) {
+	imgName, imgCfg, err := resolveImage(cfg, opts.Image)
 	if err != nil {
-		return nil, "", err
+		return nil, 0, "", err
 	}
 	proxyActive := cfg.LocalProxy.Enabled && localproxy.Running(cfg.LocalProxy)
 
@@ -1297,17 +2250,9 @@ func listContainers(cfg config.Config, includeSessions bool) ([]map[string]inter
 	}
 
 	sortAgents(agents)
-	if includeSessions {
-		for i, agent := range agents {
-			if agent.status == "Running" {
-				s, err := docker.ExecSessions(agent.name)
-				if err != nil {
-					agents[i].sessions = -1
-				} else {
-					agents[i].sessions = len(s)
-				}
-			}
-		}
+	agents, total := filterAgents(agents, opts)
+	if opts.IncludeSessions {
+		countAgentSessions(ctx, agents, dockerSessionCounter(agents), nil)
 	}
 
 	var outContainers []map[string]interface{}
@@ -1320,16 +2265,16 @@ func listContainers(cfg config.Config, includeSessions bool) ([]map[string]inter
 			"urls":     agent.urls,
 			"selected": agent.selected,
 		}
-		if includeSessions {
+		if opts.IncludeSessions {
 			data["sessions"] = agent.sessions
 		}
 		outContainers = append(outContainers, data)
 	}
 
-	return outContainers, selected, nil
+	return outContainers, total, selected, nil
 }
 
-func ensureContainerExecContext(configDir, name string, hookWriters ...io.Writer) (containerExecContext, error) {
+func ensureContainerExecContext(reqCtx context.Context, configDir, name string, hookWriters ...io.Writer) (containerExecContext, error) {
 	cfg, err := config.LoadOrCreate(configDir)
 	if err != nil {
 		return containerExecContext{}, err
@@ -1337,10 +2282,10 @@ func ensureContainerExecContext(configDir, name string, hookWriters ...io.Writer
 	if strings.TrimSpace(name) == "" {
 		return containerExecContext{}, fmt.Errorf("container name required")
 	}
-	if !docker.Exists(name) {
+	if !docker.ExistsContext(reqCtx, name) {
 		return containerExecContext{}, fmt.Errorf("container '%s' does not exist", name)
 	}
-	if !docker.Running(name) {
+	if !docker.RunningContext(reqCtx, name) {
 		hookOut, hookErr := hookWritersForServeEnsure(hookWriters)
 		hookCmd := newHostHookCommand("serve", strings.NewReader(""), hookOut, hookErr)
 		if err := startContainerWithPostStartHook(hookCmd, cfg, configDir, name, "serve"); err != nil {
@@ -1378,12 +2323,12 @@ func hookWritersForServeEnsure(writers []io.Writer) (io.Writer, io.Writer) {
 	return out, errOut
 }
 
-func ensureDiscourseContainer(configDir, name string, hookWriters ...io.Writer) (containerExecContext, config.ImageConfig, error) {
+func ensureDiscourseContainer(reqCtx context.Context, configDir, name string, hookWriters ...io.Writer) (containerExecContext, config.ImageConfig, error) {
 	cfg, err := config.LoadOrCreate(configDir)
 	if err != nil {
 		return containerExecContext{}, config.ImageConfig{}, err
 	}
-	ctx, err := ensureContainerExecContext(configDir, name, hookWriters...)
+	ctx, err := ensureContainerExecContext(reqCtx, configDir, name, hookWriters...)
 	if err != nil {
 		return containerExecContext{}, config.ImageConfig{}, err
 	}
@@ -1401,7 +2346,10 @@ func ensureDiscourseContainer(configDir, name string, hookWriters ...io.Writer)
 	return ctx, imgCfg, nil
 }
 
-func buildDockerBuildCommand(tag, dockerfilePath, contextDir string, classic bool, builder string, extraArgs []string) (string, []string, []string) {
+// buildDockerBuildCommand returns the docker invocation for an image build.
+// cacheArgs (see docker.BuildxCacheArgs) are only passed to buildx; classic
+// builds silently build without them.
+func buildDockerBuildCommand(tag, dockerfilePath, contextDir string, classic bool, builder string, cacheArgs, extraArgs []string) (string, []string, []string) {
 	useBuildx := false
 	if !classic {
 		if err := exec.Command("docker", "buildx", "version").Run(); err == nil {
@@ -1409,10 +2357,11 @@ func buildDockerBuildCommand(tag, dockerfilePath, contextDir string, classic boo
 		}
 	}
 	if useBuildx {
-		args := []string{"buildx", "build", "--load", "-t", tag, "-f", dockerfilePath}
+		args := []string{"buildx", "build", "--load", "--progress", "plain", "-t", tag, "-f", dockerfilePath}
 		if strings.TrimSpace(builder) != "" {
 			args = append(args, "--builder", strings.TrimSpace(builder))
 		}
+		args = append(args, cacheArgs...)
 		args = append(args, extraArgs...)
 		args = append(args, contextDir)
 		return "docker", args, []string{"DOCKER_BUILDKIT=1"}
@@ -1476,12 +2425,24 @@ func startSSE(w http.ResponseWriter) (*sseWriter, func(), error) {
 }
 
 func runExecWithSSE(sse *sseWriter, execFn func(stdout, stderr io.Writer) error) error {
+	return runContainerExecWithSSE(sse, "", execFn)
+}
+
+// runContainerExecWithSSE is like runExecWithSSE but tags every output event
+// with the container name so clients can demultiplex combined streams.
+func runContainerExecWithSSE(sse *sseWriter, container string, execFn func(stdout, stderr io.Writer) error) error {
+	return runExecWithSSELines(sse, container, nil, execFn)
+}
+
+// runExecWithSSELines is like runContainerExecWithSSE but also hands every
+// output line to onLine (when non-nil) after it has been sent.
+func runExecWithSSELines(sse *sseWriter, container string, onLine func(stream, line string), execFn func(stdout, stderr io.Writer) error) error {
 	stdoutR, stdoutW := io.Pipe()
 	stderrR, stderrW := io.Pipe()
 	var wg sync.WaitGroup
 	wg.Add(2)
-	go scanStream(stdoutR, "stdout", sse, &wg)
-	go scanStream(stderrR, "stderr", sse, &wg)
+	go scanStream(stdoutR, "stdout", container, sse, onLine, &wg)
+	go scanStream(stderrR, "stderr", container, sse, onLine, &wg)
 
 	err := execFn(stdoutW, stderrW)
 	_ = stdoutW.Close()
@@ -1490,14 +2451,21 @@ func runExecWithSSE(sse *sseWriter, execFn func(stdout, stderr io.Writer) error)
 	return err
 }
 
-func scanStream(r io.Reader, stream string, sse *sseWriter, wg *sync.WaitGroup) {
+func scanStream(r io.Reader, stream, container string, sse *sseWriter, onLine func(stream, line string), wg *sync.WaitGroup) {
 	defer wg.Done()
 	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
-		sse.writeEvent("output", map[string]string{
+		event := map[string]string{
 			"stream": stream,
 			"text":   scanner.Text() + "\n",
-		})
+		}
+		if container != "" {
+			event["container"] = container
+		}
+		sse.writeEvent("output", event)
+		if onLine != nil {
+			onLine(stream, scanner.Text())
+		}
 	}
 }
 
@@ -1522,31 +2490,15 @@ func execStreamAsUser(user, name, workdir string, envs docker.Envs, argv []strin
 }
 
 func execStreamAsUserContext(ctx context.Context, user, name, workdir string, envs docker.Envs, argv []string, stdout, stderr io.Writer) error {
-	args := []string{"exec", "--user", user, "-w", workdir}
-	for _, e := range envs {
-		args = append(args, "-e", e)
-	}
-	args = append(args, name)
-	args = append(args, argv...)
-	cmd := exec.CommandContext(ctx, "docker", args...)
-	cmd.Stdout = stdout
-	cmd.Stderr = stderr
-	return cmd.Run()
+	return docker.ExecStreamAsUserContext(ctx, user, name, workdir, envs, argv, stdout, stderr)
 }
 
 func execStreamContext(ctx context.Context, name, workdir string, envs docker.Envs, argv []string, stdout, stderr io.Writer) error {
-	args := []string{"exec", "--user", "discourse", "-w", workdir}
-	for _, e := range envs {
-		args = append(args, "-e", e)
-	}
-	args = append(args, name)
-	args = append(args, argv...)
-	cmd := exec.CommandContext(ctx, "docker", args...)
-	cmd.Stdout = stdout
-	cmd.Stderr = stderr
-	return cmd.Run()
+	return docker.ExecStreamContext(ctx, name, workdir, envs, argv, stdout, stderr)
 }
 
+// writeJSON writes the standard {ok, data|error} envelope with status. An
+// error payload is reported by its message.
 func writeJSON(w http.ResponseWriter, status int, payload interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -1596,10 +2548,31 @@ func decodeJSON(r *http.Request, dst interface{}) error {
 	return nil
 }
 
+// httpStatusForError maps docker sentinel errors to HTTP statuses, defaulting
+// to 500.
+func httpStatusForError(err error) int {
+	switch {
+	case errors.Is(err, docker.ErrContainerNotFound), errors.Is(err, docker.ErrImageNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, docker.ErrContainerNotRunning):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// timeoutExitCode mirrors coreutils timeout(1) so clients can tell a
+// server-side deadline apart from the command's own failures.
+const timeoutExitCode = 124
+
+var errExecTimeout = errors.New("command timed out")
+
 func exitCode(err error) int {
 	if err == nil {
 		return 0
 	}
+	if errors.Is(err, errExecTimeout) {
+		return timeoutExitCode
+	}
 	var exitErr *exec.ExitError
 	if errors.As(err, &exitErr) {
 		return exitErr.ExitCode()
@@ -0,0 +1,421 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"dv/internal/config"
+	"dv/internal/docker"
+)
+
+func TestParseContainerListOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		query    string
+		expected containerListOptions
+		wantErr  bool
+	}{
+		{
+			name:     "no params",
+			query:    "",
+			expected: containerListOptions{},
+		},
+		{
+			name:  "all params",
+			query: "status=Running&image=discourse&limit=5&offset=10&sessions=true",
+			expected: containerListOptions{
+				Image:           "discourse",
+				Status:          "running",
+				Limit:           5,
+				Offset:          10,
+				IncludeSessions: true,
+			},
+		},
+		{
+			name:    "unknown status",
+			query:   "status=paused",
+			wantErr: true,
+		},
+		{
+			name:    "negative limit",
+			query:   "limit=-1",
+			wantErr: true,
+		},
+		{
+			name:    "non-numeric offset",
+			query:   "offset=abc",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("parse query: %v", err)
+			}
+			got, err := parseContainerListOptions(query)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("parseContainerListOptions() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFilterAgents(t *testing.T) {
+	t.Parallel()
+
+	agents := []agentInfo{
+		{name: "a", status: "Running"},
+		{name: "b", status: "Stopped"},
+		{name: "c", status: "Running"},
+		{name: "d", status: "Created"},
+		{name: "e", status: "Running"},
+	}
+
+	names := func(list []agentInfo) []string {
+		var out []string
+		for _, a := range list {
+			out = append(out, a.name)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name          string
+		opts          containerListOptions
+		expectedNames []string
+		expectedTotal int
+	}{
+		{
+			name:          "no filter returns everything",
+			opts:          containerListOptions{},
+			expectedNames: []string{"a", "b", "c", "d", "e"},
+			expectedTotal: 5,
+		},
+		{
+			name:          "running only",
+			opts:          containerListOptions{Status: "running"},
+			expectedNames: []string{"a", "c", "e"},
+			expectedTotal: 3,
+		},
+		{
+			name:          "stopped includes created",
+			opts:          containerListOptions{Status: "stopped"},
+			expectedNames: []string{"b", "d"},
+			expectedTotal: 2,
+		},
+		{
+			name:          "limit and offset page after filtering",
+			opts:          containerListOptions{Status: "running", Limit: 1, Offset: 1},
+			expectedNames: []string{"c"},
+			expectedTotal: 3,
+		},
+		{
+			name:          "offset past the end",
+			opts:          containerListOptions{Offset: 10},
+			expectedNames: nil,
+			expectedTotal: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, total := filterAgents(agents, tt.opts)
+			if total != tt.expectedTotal {
+				t.Errorf("total = %d, want %d", total, tt.expectedTotal)
+			}
+			if !reflect.DeepEqual(names(got), tt.expectedNames) {
+				t.Errorf("names = %v, want %v", names(got), tt.expectedNames)
+			}
+		})
+	}
+}
+
+func TestHandleTokenRotateInvalidatesOldToken(t *testing.T) {
+	t.Parallel()
+
+	configDir := t.TempDir()
+	tokens := &serveTokenHolder{token: "old-token"}
+	handler := authMiddleware(tokens, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleServeRequest(w, r, configDir, tokens)
+	}))
+
+	do := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/token/rotate", "old-token")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("rotate status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Data struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode rotate response: %v", err)
+	}
+	newToken := body.Data.Token
+	if len(newToken) != 64 || newToken == "old-token" {
+		t.Fatalf("unexpected rotated token %q", newToken)
+	}
+
+	cfg, err := config.LoadOrCreate(configDir)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if cfg.ServeToken != newToken {
+		t.Fatalf("saved token = %q, want %q", cfg.ServeToken, newToken)
+	}
+
+	if rec := do(http.MethodGet, "/status", "old-token"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("old token status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if rec := do(http.MethodGet, "/status", newToken); rec.Code != http.StatusOK {
+		t.Fatalf("new token status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestListenUnixSocketReplacesStaleSocket(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "dv.sock")
+
+	// Leave a stale socket file behind without a listener.
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("create stale socket: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	listener, err := listenUnixSocket(path)
+	if err != nil {
+		t.Fatalf("listenUnixSocket: %v", err)
+	}
+	defer listener.Close()
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat socket: %v", err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("socket permissions = %o, want 600", perm)
+	}
+
+	if _, err := listenUnixSocket(path); err == nil {
+		t.Fatal("expected error when socket is already in use")
+	}
+}
+
+func TestListenUnixSocketRejectsRegularFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "not-a-socket")
+	if err := os.WriteFile(path, []byte("data"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if _, err := listenUnixSocket(path); err == nil {
+		t.Fatal("expected error for a regular file")
+	}
+}
+
+func TestRequestLogMiddlewareRecordsStatusAndKeepsFlusher(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	handler := requestLogMiddleware(&out, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(http.Flusher); !ok {
+			t.Error("expected wrapped writer to implement http.Flusher")
+		}
+		writeJSON(w, http.StatusConflict, "busy")
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/containers/agent/stop", nil)
+	req.RemoteAddr = "127.0.0.1:5555"
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	line := out.String()
+	for _, want := range []string{"POST", "/containers/agent/stop", "409", "127.0.0.1:5555"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("log line %q missing %q", line, want)
+		}
+	}
+}
+
+func TestMaskSecretEnv(t *testing.T) {
+	t.Parallel()
+
+	env := map[string]string{
+		"ANTHROPIC_API_KEY":     "sk-ant-1234567890",
+		"GH_TOKEN":              "ghp",
+		"AWS_SECRET_ACCESS_KEY": "abcdefghijkl",
+		"CLIENT_SECRET":         "shh",
+		"EMPTY_TOKEN":           "",
+		"PATH":                  "/usr/bin",
+		"KEYBOARD":              "us",
+	}
+	want := map[string]string{
+		"ANTHROPIC_API_KEY":     "sk-a********",
+		"GH_TOKEN":              "********",
+		"AWS_SECRET_ACCESS_KEY": "abcd********",
+		"CLIENT_SECRET":         "********",
+		"EMPTY_TOKEN":           "",
+		"PATH":                  "/usr/bin",
+		"KEYBOARD":              "us",
+	}
+	if got := maskSecretEnv(env); !reflect.DeepEqual(got, want) {
+		t.Errorf("maskSecretEnv() = %v, want %v", got, want)
+	}
+	if env["GH_TOKEN"] != "ghp" {
+		t.Error("maskSecretEnv() modified its input")
+	}
+}
+
+func TestExitCodeTimeout(t *testing.T) {
+	t.Parallel()
+
+	if got := exitCode(nil); got != 0 {
+		t.Errorf("exitCode(nil) = %d, want 0", got)
+	}
+	if got := exitCode(errExecTimeout); got != timeoutExitCode {
+		t.Errorf("exitCode(errExecTimeout) = %d, want %d", got, timeoutExitCode)
+	}
+	if got := exitCode(fmt.Errorf("run: %w", errExecTimeout)); got != timeoutExitCode {
+		t.Errorf("exitCode(wrapped timeout) = %d, want %d", got, timeoutExitCode)
+	}
+	if got := exitCode(errors.New("boom")); got != -1 {
+		t.Errorf("exitCode(other) = %d, want -1", got)
+	}
+}
+
+func TestValidateContainerCopyPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{path: "/var/www/discourse/tmp/upload.txt"},
+		{path: "/tmp/dir/"},
+		{path: "relative/file.txt"},
+		{path: "/tmp/..hidden"},
+		{path: "", wantErr: true},
+		{path: "  ", wantErr: true},
+		{path: "/var/www/../../etc/passwd", wantErr: true},
+		{path: "../outside", wantErr: true},
+		{path: "/tmp/..", wantErr: true},
+	}
+	for _, tt := range tests {
+		err := validateContainerCopyPath(tt.path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateContainerCopyPath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPruneCandidates(t *testing.T) {
+	t.Parallel()
+
+	stopped := []docker.ContainerSummary{
+		{Name: "selected", Image: "ai_agent:latest"},
+		{Name: "old-1", Image: "snap:1"},
+		{Name: "old-2", Image: "snap:1"},
+		{Name: "old-3", Image: "ai_agent:latest"},
+	}
+	all := append([]docker.ContainerSummary{{Name: "running", Image: "ai_agent:latest"}}, stopped...)
+
+	cfg := config.Config{SelectedAgent: "selected"}
+	containers, images := pruneCandidates(stopped, all, cfg)
+	wantContainers := []docker.ContainerSummary{stopped[1], stopped[2], stopped[3]}
+	if !reflect.DeepEqual(containers, wantContainers) {
+		t.Errorf("containers = %+v, want %+v", containers, wantContainers)
+	}
+	if want := []string{"snap:1"}; !reflect.DeepEqual(images, want) {
+		t.Errorf("images = %v, want %v", images, want)
+	}
+
+	// Without a selection, the default container counts as selected.
+	containers, _ = pruneCandidates(stopped, nil, config.Config{DefaultContainer: "selected"})
+	if !reflect.DeepEqual(containers, wantContainers) {
+		t.Errorf("default container: containers = %+v, want %+v", containers, wantContainers)
+	}
+
+	// Configured base images survive even when only pruned containers use them.
+	onlyStopped := []docker.ContainerSummary{{Name: "old", Image: "ai_agent:latest"}, {Name: "theme", Image: "theme_agent"}}
+	cfg = config.Config{Images: map[string]config.ImageConfig{
+		"discourse": {Tag: "ai_agent"},
+		"theme":     {Tag: "theme_agent"},
+	}}
+	if _, images := pruneCandidates(onlyStopped, onlyStopped, cfg); images != nil {
+		t.Errorf("configured images pruned: %v", images)
+	}
+
+	containers, images = pruneCandidates(stopped, nil, config.Config{})
+	if len(containers) != len(stopped) || images != nil {
+		t.Errorf("without images: containers = %+v, images = %v", containers, images)
+	}
+}
+
+func TestHTTPStatusForError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "not found", err: fmt.Errorf("stats: %w", docker.ErrContainerNotFound), want: http.StatusNotFound},
+		{name: "image not found", err: docker.ErrImageNotFound, want: http.StatusNotFound},
+		{name: "not running", err: docker.ErrContainerNotRunning, want: http.StatusConflict},
+		{name: "other error", err: errors.New("boom"), want: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := httpStatusForError(tt.err); got != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWriteJSONKeepsStatus(t *testing.T) {
+	t.Parallel()
+
+	rec := httptest.NewRecorder()
+	writeJSON(rec, http.StatusInternalServerError, docker.ErrContainerNotFound)
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500 as given", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), docker.ErrContainerNotFound.Error()) {
+		t.Fatalf("body = %s, want the error message", rec.Body.String())
+	}
+}
@@ -2,7 +2,6 @@ package cli
 
 import (
 	"fmt"
-	"net"
 	"os"
 	"os/exec"
 	"strconv"
@@ -14,6 +13,7 @@ import (
 
 	"dv/internal/config"
 	"dv/internal/docker"
+	"dv/internal/logging"
 	"dv/internal/session"
 	"dv/internal/xdg"
 )
@@ -139,36 +139,7 @@ func resolveImage(cfg config.Config, override string) (string, config.ImageConfi
 // isPortInUse returns true when the given TCP port cannot be bound on localhost
 // or is already allocated to a Docker container.
 func isPortInUse(port int, dockerAllocated map[int]bool) bool {
-	if dockerAllocated != nil && dockerAllocated[port] {
-		if isTruthyEnv("DV_VERBOSE") {
-			fmt.Fprintf(os.Stderr, "Port %d is already allocated by a Docker container\n", port)
-		}
-		return true
-	}
-	// Try to listen on all interfaces. This is the most conservative check.
-	l, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
-	if err != nil {
-		if isTruthyEnv("DV_VERBOSE") {
-			fmt.Fprintf(os.Stderr, "Port %d is in use (Listen :%d failed: %v)\n", port, port, err)
-		}
-		return true
-	}
-	_ = l.Close()
-
-	// Also specifically check 127.0.0.1 and [::1] because sometimes ':' only
-	// binds to one of them depending on system configuration.
-	for _, host := range []string{"127.0.0.1", "[::1]"} {
-		l, err := net.Listen("tcp", fmt.Sprintf("%s:%d", host, port))
-		if err != nil {
-			if isTruthyEnv("DV_VERBOSE") {
-				fmt.Fprintf(os.Stderr, "Port %d is in use (Listen %s:%d failed: %v)\n", port, host, port, err)
-			}
-			return true
-		}
-		_ = l.Close()
-	}
-
-	return false
+	return docker.PortInUse(port, dockerAllocated)
 }
 
 // completeAgentNames suggests existing container names for the selected image.
@@ -397,7 +368,7 @@ func ensureContainerRunning(cmd *cobra.Command, cfg config.Config, name string,
 	}
 	workdir := imgCfg.Workdir
 	imageTag := imgCfg.Tag
-	result, err := ensureContainerRunningWithWorkdirResult(cmd, cfg, name, workdir, imageTag, imgName, reset, sshAuthSock, nil, nil)
+	result, err := ensureContainerRunningWithWorkdirResult(cmd, cfg, name, workdir, imageTag, imgName, reset, sshAuthSock, nil, nil, nil)
 	if err != nil {
 		return err
 	}
@@ -425,7 +396,7 @@ func ensureContainerRunning(cmd *cobra.Command, cfg config.Config, name string,
 	return nil
 }
 
-func ensureContainerRunningWithWorkdirResult(cmd *cobra.Command, cfg config.Config, name string, workdir string, imageTag string, imgName string, reset bool, sshAuthSock string, templateEnvs map[string]string, templateMounts []docker.Mount) (containerLifecycleResult, error) {
+func ensureContainerRunningWithWorkdirResult(cmd *cobra.Command, cfg config.Config, name string, workdir string, imageTag string, imgName string, reset bool, sshAuthSock string, templateEnvs map[string]string, templateMounts []docker.Mount, extraLabels map[string]string) (containerLifecycleResult, error) {
 	result := containerLifecycleResult{ContainerPort: cfg.ContainerPort, Workdir: workdir}
 	if reset && docker.Exists(name) {
 		_ = docker.Stop(name)
@@ -433,20 +404,14 @@ func ensureContainerRunningWithWorkdirResult(cmd *cobra.Command, cfg config.Conf
 	}
 	if !docker.Exists(name) {
 		// Choose the first available port starting from configured starting port
-		allocated, err := docker.AllocatedPorts()
-		if err != nil {
-			if isTruthyEnv("DV_VERBOSE") {
-				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: failed to detect allocated Docker ports: %v\n", err)
-			}
-		}
-		chosenPort := cfg.HostStartingPort
-		if isTruthyEnv("DV_VERBOSE") {
-			fmt.Fprintf(cmd.OutOrStdout(), "Searching for an available port starting from %d...\n", chosenPort)
+		if logging.DebugEnabled() {
+			fmt.Fprintf(cmd.OutOrStdout(), "Searching for an available port starting from %d...\n", cfg.HostStartingPort)
 		}
-		for isPortInUse(chosenPort, allocated) {
-			chosenPort++
+		chosenPort, err := docker.FindFreeHostPort(cfg.HostStartingPort)
+		if err != nil {
+			return result, err
 		}
-		if isTruthyEnv("DV_VERBOSE") {
+		if logging.DebugEnabled() {
 			fmt.Fprintf(cmd.OutOrStdout(), "Selected port %d.\n", chosenPort)
 		}
 		labels := map[string]string{
@@ -454,6 +419,9 @@ func ensureContainerRunningWithWorkdirResult(cmd *cobra.Command, cfg config.Conf
 			"com.dv.image-name": imgName,
 			"com.dv.image-tag":  imageTag,
 		}
+		for k, v := range extraLabels {
+			labels[k] = v
+		}
 		envs := map[string]string{
 			"DISCOURSE_PORT": strconv.Itoa(chosenPort),
 		}
@@ -466,7 +434,21 @@ func ensureContainerRunningWithWorkdirResult(cmd *cobra.Command, cfg config.Conf
 		if proxyHost != "" {
 			extraHosts = append(extraHosts, fmt.Sprintf("%s:127.0.0.1", proxyHost))
 		}
-		if err := docker.RunDetached(name, workdir, imageTag, chosenPort, cfg.ContainerPort, labels, envs, extraHosts, sshAuthSock, templateMounts); err != nil {
+		if err := docker.RunDetached(docker.RunOptions{
+			Name:          name,
+			Workdir:       workdir,
+			Image:         imageTag,
+			HostPort:      chosenPort,
+			ContainerPort: cfg.ContainerPort,
+			Labels:        labels,
+			Envs:          envs,
+			ExtraHosts:    extraHosts,
+			SSHAuthSock:   sshAuthSock,
+			Mounts:        templateMounts,
+			Memory:        cfg.ContainerMemory,
+			CPUs:          cfg.ContainerCPUs,
+			Platform:      imagePlatform(cfg, imgName),
+		}); err != nil {
 			return result, err
 		}
 		result.Created = true
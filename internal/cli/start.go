@@ -9,6 +9,7 @@ import (
 
 	"dv/internal/config"
 	"dv/internal/docker"
+	"dv/internal/logging"
 	"dv/internal/xdg"
 )
 
@@ -82,20 +83,14 @@ var startCmd = &cobra.Command{
 
 		if !docker.Exists(name) {
 			// Find the first available host port, starting from hostPort
-			allocated, err := docker.AllocatedPorts()
-			if err != nil {
-				if isTruthyEnv("DV_VERBOSE") {
-					fmt.Fprintf(cmd.ErrOrStderr(), "Warning: failed to detect allocated Docker ports: %v\n", err)
-				}
+			if logging.DebugEnabled() {
+				fmt.Fprintf(cmd.OutOrStdout(), "Searching for an available port starting from %d...\n", hostPort)
 			}
-			chosenPort := hostPort
-			if isTruthyEnv("DV_VERBOSE") {
-				fmt.Fprintf(cmd.OutOrStdout(), "Searching for an available port starting from %d...\n", chosenPort)
-			}
-			for isPortInUse(chosenPort, allocated) {
-				chosenPort++
+			chosenPort, err := docker.FindFreeHostPort(hostPort)
+			if err != nil {
+				return err
 			}
-			if isTruthyEnv("DV_VERBOSE") {
+			if logging.DebugEnabled() {
 				fmt.Fprintf(cmd.OutOrStdout(), "Selected port %d.\n", chosenPort)
 			}
 			if chosenPort != hostPort {
@@ -115,7 +110,19 @@ var startCmd = &cobra.Command{
 			if proxyHost != "" {
 				extraHosts = append(extraHosts, fmt.Sprintf("%s:127.0.0.1", proxyHost))
 			}
-			if err := docker.RunDetached(name, workdir, imageTag, chosenPort, containerPort, labels, envs, extraHosts, "", nil); err != nil {
+			if err := docker.RunDetached(docker.RunOptions{
+				Name:          name,
+				Workdir:       workdir,
+				Image:         imageTag,
+				HostPort:      chosenPort,
+				ContainerPort: containerPort,
+				Labels:        labels,
+				Envs:          envs,
+				ExtraHosts:    extraHosts,
+				Memory:        cfg.ContainerMemory,
+				CPUs:          cfg.ContainerCPUs,
+				Platform:      imagePlatform(cfg, imgName),
+			}); err != nil {
 				return err
 			}
 			createdContainer = true
@@ -145,21 +152,30 @@ var startCmd = &cobra.Command{
 					}
 
 					// Find next available port
-					newPort := existingPort
-					for isPortInUse(newPort, allocated) {
-						newPort++
+					newPort, err := docker.NextFreePort(existingPort, allocated)
+					if err != nil {
+						return err
 					}
 
 					fmt.Fprintf(cmd.OutOrStdout(), "Port %d in use, remapping to %d...\n", existingPort, newPort)
 
 					// Get container metadata for recreation
 					labels, _ := labelsWithOverrides(name, cfg)
-					existingWorkdir, _ := docker.GetContainerWorkdir(name)
+					var existingWorkdir string
+					var existingEnvs map[string]string
+					var existingMounts []docker.Mount
+					existingMemory, existingCPUs := cfg.ContainerMemory, cfg.ContainerCPUs
+					if ci, err := docker.Inspect(name); err == nil {
+						existingWorkdir = ci.Config.WorkingDir
+						existingEnvs = ci.Env()
+						existingMounts = ci.BindMounts()
+						// Keep the limits it was created with (which may come
+						// from a per-agent or template override).
+						existingMemory, existingCPUs = ci.ResourceLimits()
+					}
 					if existingWorkdir == "" {
 						existingWorkdir = workdir
 					}
-					existingEnvs, _ := docker.GetContainerEnv(name)
-					existingMounts, _ := docker.GetContainerMounts(name)
 
 					// Commit container to temporary image
 					tempImage := name + "-dv-snapshot"
@@ -184,10 +200,24 @@ var startCmd = &cobra.Command{
 					// existing bind mounts (the snapshot bakes the filesystem but
 					// not mount specs) so a mounted plugin isn't silently dropped.
 					fmt.Fprintf(cmd.OutOrStdout(), "Recreating container with new port...\n")
-					if err := docker.RunDetached(name, existingWorkdir, tempImage, newPort, containerPort, labels, existingEnvs, nil, "", existingMounts); err != nil {
+					runOpts := docker.RunOptions{
+						Name:          name,
+						Workdir:       existingWorkdir,
+						Image:         tempImage,
+						HostPort:      newPort,
+						ContainerPort: containerPort,
+						Labels:        labels,
+						Envs:          existingEnvs,
+						Mounts:        existingMounts,
+						Memory:        existingMemory,
+						CPUs:          existingCPUs,
+						Platform:      imagePlatform(cfg, imgName),
+					}
+					if err := docker.RunDetached(runOpts); err != nil {
 						// Try to restore from snapshot
 						fmt.Fprintf(cmd.ErrOrStderr(), "Failed to recreate, attempting restore...\n")
-						_ = docker.RunDetached(name, existingWorkdir, tempImage, existingPort, containerPort, labels, existingEnvs, nil, "", existingMounts)
+						runOpts.HostPort = existingPort
+						_ = docker.RunDetached(runOpts)
 						_ = docker.RemoveImage(tempImage)
 						return fmt.Errorf("failed to recreate container: %w", err)
 					}
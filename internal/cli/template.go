@@ -1,26 +1,86 @@
 package cli
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
 	"dv/internal/config"
 )
 
 type templateConfig struct {
+	// Extends names a base template (path or URL) that is loaded first and
+	// merged underneath this one; see loadTemplate.
+	Extends   string `yaml:"extends"`
 	Discourse struct {
 		Branch string `yaml:"branch"`
 		PR     int    `yaml:"pr"`
 		Repo   string `yaml:"repo"`
 	} `yaml:"discourse"`
 	Git struct {
-		SSHForward bool `yaml:"ssh_forward"`
+		// SSHForward is a pointer so a child template can turn off a
+		// base's ssh_forward; nil inherits. Read it with sshForward().
+		SSHForward *bool  `yaml:"ssh_forward"`
+		UserName   string `yaml:"user_name"`
+		UserEmail  string `yaml:"user_email"`
 	} `yaml:"git"`
 	Copy     []config.CopyRule `yaml:"copy"`
 	Env      map[string]string `yaml:"env"`
-	OnCreate []string          `yaml:"on_create"`
+	OnCreate []templateCommand `yaml:"on_create"`
 	Plugins  []templatePlugin  `yaml:"plugins"`
 	Themes   []templateTheme   `yaml:"themes"`
 	Settings map[string]any    `yaml:"settings"`
 	MCP      []templateMCP     `yaml:"mcp"`
 	Mounts   []templateMount   `yaml:"mounts"`
+	// Volumes are docker-style "HOST:CONTAINER[:ro]" bind mounts. Unlike
+	// Mounts, the host path must already exist.
+	Volumes []string `yaml:"volumes"`
+	// HealthTimeoutSeconds bounds the wait for /srv/status after services
+	// start; 0 uses defaultHealthTimeoutSeconds.
+	HealthTimeoutSeconds int `yaml:"health_timeout_seconds"`
+	// RequireHealthy fails provisioning when the health wait times out
+	// instead of only warning. nil inherits from a base template; read it
+	// with requireHealthy().
+	RequireHealthy *bool `yaml:"require_healthy"`
+
+	// replace holds the top-level keys tagged !replace, which discard the
+	// base template's value instead of being merged with it.
+	replace map[string]bool
+}
+
+const defaultHealthTimeoutSeconds = 120
+
+// healthTimeout returns the effective health wait in seconds.
+func (t *templateConfig) healthTimeout() int {
+	if t.HealthTimeoutSeconds > 0 {
+		return t.HealthTimeoutSeconds
+	}
+	return defaultHealthTimeoutSeconds
+}
+
+// sshForward reports whether the host SSH agent is forwarded for clones.
+func (t *templateConfig) sshForward() bool {
+	return t.Git.SSHForward != nil && *t.Git.SSHForward
+}
+
+// requireHealthy reports whether a health timeout fails provisioning.
+func (t *templateConfig) requireHealthy() bool {
+	return t.RequireHealthy != nil && *t.RequireHealthy
+}
+
+// waitsForHealth reports whether provisioning blocks on /srv/status after
+// starting services: a later step needs a live Rails/API (site settings,
+// themes, on_create commands, MCP), or a timeout or require_healthy was
+// asked for explicitly.
+func (t *templateConfig) waitsForHealth() bool {
+	return t.requireHealthy() || t.HealthTimeoutSeconds > 0 ||
+		len(t.Settings) > 0 || len(t.Themes) > 0 || len(t.OnCreate) > 0 || len(t.MCP) > 0
 }
 
 type templateMount struct {
@@ -45,8 +105,183 @@ type templateTheme struct {
 	AutoWatch bool   `yaml:"auto_watch"`
 }
 
+// templateCommand is an on_create entry. It is written either as a plain
+// string, run as the discourse user, or as {cmd: "...", root: true}.
+type templateCommand struct {
+	Cmd  string `yaml:"cmd"`
+	Root bool   `yaml:"root"`
+
+	// unknownKeys records mapping keys other than cmd/root. Node.Decode
+	// ignores them, so validateTemplate reports them instead.
+	unknownKeys []string
+}
+
+func (c *templateCommand) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*c = templateCommand{Cmd: value.Value}
+		return nil
+	}
+	if value.Kind != yaml.MappingNode {
+		return &yaml.TypeError{Errors: []string{fmt.Sprintf("line %d: on_create entries must be a string or a {cmd, root} mapping", value.Line)}}
+	}
+	type plain templateCommand
+	if err := value.Decode((*plain)(c)); err != nil {
+		return err
+	}
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		if key := value.Content[i].Value; key != "cmd" && key != "root" {
+			c.unknownKeys = append(c.unknownKeys, key)
+		}
+	}
+	return nil
+}
+
 type templateMCP struct {
 	Name    string   `yaml:"name"`
 	Command string   `yaml:"command"`
 	Args    []string `yaml:"args"`
 }
+
+// stockMCPNames are the MCP servers executeTemplate knows how to configure
+// without an explicit command.
+var stockMCPNames = map[string]bool{
+	"playwright":      true,
+	"discourse":       true,
+	"chrome-devtools": true,
+	"filesystem":      true,
+	"fetch":           true,
+}
+
+// parseTemplate decodes template YAML strictly, so misspelled keys are
+// reported instead of silently ignored, and then runs validateTemplate.
+// Unknown-key and validation problems are returned together.
+func parseTemplate(data []byte) (*templateConfig, error) {
+	tpl := &templateConfig{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var decodeProblems []string
+	if err := dec.Decode(tpl); err != nil && !errors.Is(err, io.EOF) {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("parse template YAML: %w", err)
+		}
+		decodeProblems = typeErr.Errors
+		// Decode again leniently so the remaining sections still get checked.
+		tpl = &templateConfig{}
+		_ = yaml.Unmarshal(data, tpl)
+	}
+	err := validateTemplate(tpl)
+	if len(decodeProblems) == 0 {
+		if err != nil {
+			return nil, err
+		}
+		tpl.replace = templateReplaceKeys(data)
+		return tpl, nil
+	}
+	problems := decodeProblems
+	var validationErr *templateValidationError
+	if errors.As(err, &validationErr) {
+		problems = append(problems, validationErr.Problems...)
+	}
+	return nil, &templateValidationError{Problems: problems}
+}
+
+// templateValidationError lists every problem found in a template.
+type templateValidationError struct {
+	Problems []string
+}
+
+func (e *templateValidationError) Error() string {
+	return "invalid template:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// validateTemplate checks the required fields of each template section and
+// returns a *templateValidationError describing all problems at once.
+func validateTemplate(tpl *templateConfig) error {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if tpl.Discourse.PR < 0 {
+		add("discourse.pr must be a positive number, got %d", tpl.Discourse.PR)
+	}
+	if tpl.Discourse.PR != 0 && strings.TrimSpace(tpl.Discourse.Branch) != "" {
+		add("discourse: set either branch or pr, not both")
+	}
+	if tpl.HealthTimeoutSeconds < 0 {
+		add("health_timeout_seconds must not be negative, got %d", tpl.HealthTimeoutSeconds)
+	}
+	envKeys := make([]string, 0, len(tpl.Env))
+	for k := range tpl.Env {
+		envKeys = append(envKeys, k)
+	}
+	sort.Strings(envKeys)
+	for _, k := range envKeys {
+		if strings.TrimSpace(k) == "" || strings.Contains(k, "=") {
+			add("env: invalid variable name %q", k)
+		}
+	}
+	for i, rule := range tpl.Copy {
+		if strings.TrimSpace(rule.Host) == "" {
+			add("copy[%d]: host (source) is required", i)
+		}
+		if strings.TrimSpace(rule.Container) == "" {
+			add("copy[%d]: container (destination) is required", i)
+		}
+	}
+	for i, c := range tpl.OnCreate {
+		for _, key := range c.unknownKeys {
+			add("on_create[%d]: unknown field %s (expected cmd, root)", i, key)
+		}
+		if strings.TrimSpace(c.Cmd) == "" {
+			add("on_create[%d]: command is empty", i)
+		}
+	}
+	for i, p := range tpl.Plugins {
+		if strings.TrimSpace(p.Repo) == "" {
+			add("plugins[%d]: repo is required", i)
+		}
+	}
+	for i, t := range tpl.Themes {
+		if strings.TrimSpace(t.Repo) == "" {
+			add("themes[%d]: repo is required", i)
+		}
+		if t.PR < 0 {
+			add("themes[%d]: pr must be a positive number, got %d", i, t.PR)
+		}
+		if t.PR != 0 && strings.TrimSpace(t.Branch) != "" {
+			add("themes[%d]: set either branch or pr, not both", i)
+		}
+	}
+	for i, m := range tpl.MCP {
+		name := strings.TrimSpace(m.Name)
+		switch {
+		case name == "":
+			add("mcp[%d]: name is required", i)
+		case m.Command == "" && !stockMCPNames[name]:
+			add("mcp[%d]: %q is not a stock MCP server (playwright, discourse, chrome-devtools, filesystem, fetch); set command for custom servers", i, name)
+		}
+		if m.Command == "" && len(m.Args) > 0 {
+			add("mcp[%d]: args require a command", i)
+		}
+	}
+	for i, m := range tpl.Mounts {
+		if strings.TrimSpace(m.Host) == "" {
+			add("mounts[%d]: host is required", i)
+		}
+		if !path.IsAbs(m.Container) {
+			add("mounts[%d]: container must be an absolute path, got %q", i, m.Container)
+		}
+	}
+	for i, v := range tpl.Volumes {
+		if strings.TrimSpace(v) == "" {
+			add("volumes[%d]: volume spec is empty", i)
+		}
+	}
+
+	if len(problems) > 0 {
+		return &templateValidationError{Problems: problems}
+	}
+	return nil
+}
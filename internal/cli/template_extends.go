@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// templateReplaceTag marks a top-level key whose value replaces the base
+// template's value instead of being merged with it.
+const templateReplaceTag = "!replace"
+
+// loadTemplate reads a template from a local path or http(s) URL and resolves
+// its extends chain, merging each child on top of its base.
+func loadTemplate(ref string) (*templateConfig, error) {
+	if !isTemplateURL(ref) {
+		if abs, err := filepath.Abs(ref); err == nil {
+			ref = abs
+		}
+	}
+	return loadTemplateChain(ref, nil)
+}
+
+func loadTemplateChain(ref string, chain []string) (*templateConfig, error) {
+	for _, prev := range chain {
+		if prev == ref {
+			return nil, fmt.Errorf("template extends cycle: %s", strings.Join(append(chain, ref), " -> "))
+		}
+	}
+	data, err := readTemplateSource(ref)
+	if err != nil {
+		return nil, err
+	}
+	tpl, err := parseTemplate(data)
+	if err != nil {
+		if len(chain) > 0 {
+			return nil, fmt.Errorf("base template %s: %w", ref, err)
+		}
+		return nil, err
+	}
+	if strings.TrimSpace(tpl.Extends) == "" {
+		return tpl, nil
+	}
+	baseRef, err := resolveTemplateRef(ref, strings.TrimSpace(tpl.Extends))
+	if err != nil {
+		return nil, err
+	}
+	base, err := loadTemplateChain(baseRef, append(chain, ref))
+	if err != nil {
+		return nil, err
+	}
+	return mergeTemplates(base, tpl), nil
+}
+
+func isTemplateURL(ref string) bool {
+	return strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://")
+}
+
+func readTemplateSource(ref string) ([]byte, error) {
+	if !isTemplateURL(ref) {
+		data, err := os.ReadFile(ref)
+		if err != nil {
+			return nil, fmt.Errorf("read template: %w", err)
+		}
+		return data, nil
+	}
+	resp, err := http.Get(ref)
+	if err != nil {
+		return nil, fmt.Errorf("fetch template URL: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch template URL: %s returned status %d", ref, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read template body: %w", err)
+	}
+	return data, nil
+}
+
+// resolveTemplateRef resolves an extends value relative to the template that
+// declared it. Local paths are made absolute so cycles are detected no matter
+// how a file is referenced; remote templates may only extend other URLs.
+func resolveTemplateRef(parent, ref string) (string, error) {
+	if isTemplateURL(ref) {
+		return ref, nil
+	}
+	if isTemplateURL(parent) {
+		if filepath.IsAbs(ref) {
+			return "", fmt.Errorf("remote template %s cannot extend local path %s", parent, ref)
+		}
+		base, err := url.Parse(parent)
+		if err != nil {
+			return "", fmt.Errorf("parse template URL %s: %w", parent, err)
+		}
+		rel, err := url.Parse(filepath.ToSlash(ref))
+		if err != nil {
+			return "", fmt.Errorf("parse extends %q: %w", ref, err)
+		}
+		return base.ResolveReference(rel).String(), nil
+	}
+	if !filepath.IsAbs(ref) {
+		ref = filepath.Join(filepath.Dir(parent), ref)
+	}
+	return filepath.Abs(ref)
+}
+
+// templateReplaceKeys returns the top-level keys whose values carry the
+// !replace tag. data has already been decoded successfully.
+func templateReplaceKeys(data []byte) map[string]bool {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil
+	}
+	var keys map[string]bool
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i+1].Tag != templateReplaceTag {
+			continue
+		}
+		if keys == nil {
+			keys = map[string]bool{}
+		}
+		keys[root.Content[i].Value] = true
+	}
+	return keys
+}
+
+// mergeTemplates layers child on top of base. Scalars set in child win (for
+// booleans, including an explicit false), maps
+// are merged key by key, and lists are appended; a !replace tag on a list or
+// map key drops the base value entirely.
+func mergeTemplates(base, child *templateConfig) *templateConfig {
+	out := *base
+	out.Extends = ""
+	out.replace = nil
+
+	// Branch and PR are mutually exclusive, so a child setting either one
+	// replaces the pair.
+	if child.Discourse.PR != 0 || strings.TrimSpace(child.Discourse.Branch) != "" {
+		out.Discourse.PR = child.Discourse.PR
+		out.Discourse.Branch = child.Discourse.Branch
+	}
+	if child.Discourse.Repo != "" {
+		out.Discourse.Repo = child.Discourse.Repo
+	}
+	if child.Git.SSHForward != nil {
+		out.Git.SSHForward = child.Git.SSHForward
+	}
+	if child.Git.UserName != "" {
+		out.Git.UserName = child.Git.UserName
+	}
+	if child.Git.UserEmail != "" {
+		out.Git.UserEmail = child.Git.UserEmail
+	}
+	if child.HealthTimeoutSeconds > 0 {
+		out.HealthTimeoutSeconds = child.HealthTimeoutSeconds
+	}
+	if child.RequireHealthy != nil {
+		out.RequireHealthy = child.RequireHealthy
+	}
+
+	replace := child.replace
+	out.Env = mergeTemplateMap(base.Env, child.Env, replace["env"])
+	out.Settings = mergeTemplateMap(base.Settings, child.Settings, replace["settings"])
+	out.Copy = mergeTemplateList(base.Copy, child.Copy, replace["copy"])
+	out.OnCreate = mergeTemplateList(base.OnCreate, child.OnCreate, replace["on_create"])
+	out.Plugins = mergeTemplateList(base.Plugins, child.Plugins, replace["plugins"])
+	out.Themes = mergeTemplateList(base.Themes, child.Themes, replace["themes"])
+	out.MCP = mergeTemplateList(base.MCP, child.MCP, replace["mcp"])
+	out.Mounts = mergeTemplateList(base.Mounts, child.Mounts, replace["mounts"])
+	out.Volumes = mergeTemplateList(base.Volumes, child.Volumes, replace["volumes"])
+	return &out
+}
+
+func mergeTemplateList[T any](base, child []T, replace bool) []T {
+	if replace {
+		return child
+	}
+	if len(child) == 0 {
+		return base
+	}
+	return append(append([]T{}, base...), child...)
+}
+
+func mergeTemplateMap[V any](base, child map[string]V, replace bool) map[string]V {
+	if replace {
+		return child
+	}
+	if len(child) == 0 {
+		return base
+	}
+	out := make(map[string]V, len(base)+len(child))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range child {
+		out[k] = v
+	}
+	return out
+}
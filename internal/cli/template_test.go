@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseTemplateBundledTemplates(t *testing.T) {
+	t.Parallel()
+
+	files, err := filepath.Glob(filepath.Join("..", "..", "templates", "*.yaml"))
+	if err != nil {
+		t.Fatalf("glob templates: %v", err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no bundled templates found")
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatalf("read %s: %v", f, err)
+		}
+		if _, err := parseTemplate(data); err != nil {
+			t.Errorf("parseTemplate(%s) error = %v", filepath.Base(f), err)
+		}
+	}
+}
+
+func TestParseTemplateAggregatesProblems(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`discourse:
+  branch: main
+  pr: 12
+plugins:
+  - path: plugins/foo
+themes:
+  - name: no-repo
+copy:
+  - container: /home/discourse/x
+mcp:
+  - name: unknown-server
+  - command: /bin/tool
+pluigns: []
+`)
+	_, err := parseTemplate(data)
+	var vErr *templateValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("parseTemplate() error = %v, want *templateValidationError", err)
+	}
+	want := []string{
+		"field pluigns not found",
+		"discourse: set either branch or pr, not both",
+		"plugins[0]: repo is required",
+		"themes[0]: repo is required",
+		"copy[0]: host (source) is required",
+		`mcp[0]: "unknown-server" is not a stock MCP server`,
+		"mcp[1]: name is required",
+	}
+	msg := err.Error()
+	for _, w := range want {
+		if !strings.Contains(msg, w) {
+			t.Errorf("error missing %q:\n%s", w, msg)
+		}
+	}
+	if len(vErr.Problems) != len(want) {
+		t.Errorf("got %d problems, want %d:\n%s", len(vErr.Problems), len(want), msg)
+	}
+}
+
+func TestParseTemplateEmpty(t *testing.T) {
+	t.Parallel()
+
+	tpl, err := parseTemplate(nil)
+	if err != nil || tpl == nil {
+		t.Fatalf("parseTemplate(nil) = %v, %v; want empty template", tpl, err)
+	}
+}
+
+func TestParseTemplateSyntaxError(t *testing.T) {
+	t.Parallel()
+
+	if _, err := parseTemplate([]byte("plugins: [")); err == nil || !strings.Contains(err.Error(), "parse template YAML") {
+		t.Fatalf("parseTemplate() error = %v, want YAML parse error", err)
+	}
+}
+
+func TestValidateTemplateHealthTimeout(t *testing.T) {
+	t.Parallel()
+
+	tpl, err := parseTemplate([]byte("health_timeout_seconds: 300\nrequire_healthy: true\n"))
+	if err != nil {
+		t.Fatalf("parseTemplate() error = %v", err)
+	}
+	if tpl.HealthTimeoutSeconds != 300 || !tpl.requireHealthy() {
+		t.Fatalf("parsed health settings = %d/%v", tpl.HealthTimeoutSeconds, tpl.requireHealthy())
+	}
+	if _, err := parseTemplate([]byte("health_timeout_seconds: -1\n")); err == nil || !strings.Contains(err.Error(), "health_timeout_seconds") {
+		t.Fatalf("parseTemplate() error = %v, want health_timeout_seconds problem", err)
+	}
+}
+
+func TestLoadTemplateExtends(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTemplate := func(name, body string) string {
+		t.Helper()
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	writeTemplate("base/base.yaml", `discourse:
+  branch: main
+env:
+  A: base
+  B: base
+plugins:
+  - repo: discourse/discourse-ai
+on_create:
+  - echo base
+settings:
+  title: Base
+`)
+	child := writeTemplate("child.yaml", `extends: base/base.yaml
+discourse:
+  pr: 42
+env:
+  B: child
+plugins:
+  - repo: discourse/discourse-kanban
+on_create: !replace
+  - echo child
+`)
+
+	tpl, err := loadTemplate(child)
+	if err != nil {
+		t.Fatalf("loadTemplate() error = %v", err)
+	}
+	if tpl.Discourse.PR != 42 || tpl.Discourse.Branch != "" {
+		t.Errorf("discourse = %+v, want pr 42 without branch", tpl.Discourse)
+	}
+	if tpl.Env["A"] != "base" || tpl.Env["B"] != "child" {
+		t.Errorf("env = %v, want A from base and B from child", tpl.Env)
+	}
+	if len(tpl.Plugins) != 2 || tpl.Plugins[0].Repo != "discourse/discourse-ai" || tpl.Plugins[1].Repo != "discourse/discourse-kanban" {
+		t.Errorf("plugins = %+v, want base then child", tpl.Plugins)
+	}
+	if len(tpl.OnCreate) != 1 || tpl.OnCreate[0].Cmd != "echo child" {
+		t.Errorf("on_create = %v, want only the child entry", tpl.OnCreate)
+	}
+	if tpl.Settings["title"] != "Base" {
+		t.Errorf("settings = %v, want inherited title", tpl.Settings)
+	}
+}
+
+func TestMergeTemplatesChildOverridesBooleans(t *testing.T) {
+	t.Parallel()
+
+	base, err := parseTemplate([]byte("git:\n  ssh_forward: true\nrequire_healthy: true\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	disabling, err := parseTemplate([]byte("git:\n  ssh_forward: false\nrequire_healthy: false\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mergeTemplates(base, disabling); got.sshForward() || got.requireHealthy() {
+		t.Errorf("child false did not win: ssh_forward=%v require_healthy=%v", got.sshForward(), got.requireHealthy())
+	}
+	if got := mergeTemplates(base, &templateConfig{}); !got.sshForward() || !got.requireHealthy() {
+		t.Errorf("unset child booleans should inherit: ssh_forward=%v require_healthy=%v", got.sshForward(), got.requireHealthy())
+	}
+}
+
+func TestLoadTemplateExtendsCycle(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.yaml")
+	b := filepath.Join(dir, "b.yaml")
+	if err := os.WriteFile(a, []byte("extends: b.yaml\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("extends: ./a.yaml\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadTemplate(a); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("loadTemplate() error = %v, want cycle error", err)
+	}
+}
+
+func TestResolveTemplateRef(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		parent  string
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{name: "relative local", parent: "/tpl/child.yaml", ref: "../base.yaml", want: "/base.yaml"},
+		{name: "absolute local", parent: "/tpl/child.yaml", ref: "/other/base.yaml", want: "/other/base.yaml"},
+		{name: "url from local", parent: "/tpl/child.yaml", ref: "https://example.com/base.yaml", want: "https://example.com/base.yaml"},
+		{name: "relative url", parent: "https://example.com/tpl/child.yaml", ref: "base.yaml", want: "https://example.com/tpl/base.yaml"},
+		{name: "local from url", parent: "https://example.com/child.yaml", ref: "/etc/base.yaml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := resolveTemplateRef(tt.parent, tt.ref)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("resolveTemplateRef() = %q, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveTemplateRef() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveTemplateRef() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTemplateOnCreateForms(t *testing.T) {
+	t.Parallel()
+
+	tpl, err := parseTemplate([]byte(`on_create:
+  - echo user
+  - cmd: apt-get install -y htop
+    root: true
+  - cmd: echo mapping
+`))
+	if err != nil {
+		t.Fatalf("parseTemplate() error = %v", err)
+	}
+	want := []templateCommand{
+		{Cmd: "echo user"},
+		{Cmd: "apt-get install -y htop", Root: true},
+		{Cmd: "echo mapping"},
+	}
+	if len(tpl.OnCreate) != len(want) {
+		t.Fatalf("on_create = %+v, want %+v", tpl.OnCreate, want)
+	}
+	for i := range want {
+		if got := tpl.OnCreate[i]; got.Cmd != want[i].Cmd || got.Root != want[i].Root {
+			t.Errorf("on_create[%d] = %+v, want %+v", i, tpl.OnCreate[i], want[i])
+		}
+	}
+
+	_, err = parseTemplate([]byte("on_create:\n  - cmd: echo hi\n    rooot: true\n  - root: true\n"))
+	if err == nil || !strings.Contains(err.Error(), "on_create[0]: unknown field rooot") || !strings.Contains(err.Error(), "on_create[1]: command is empty") {
+		t.Fatalf("parseTemplate() error = %v, want unknown field and empty command problems", err)
+	}
+}
@@ -12,7 +12,15 @@ import (
 	"strings"
 )
 
+// CurrentVersion is the config schema version this build writes. Bump it and
+// append to migrations whenever a field is added or renamed in a way older
+// configs need rewriting for.
+const CurrentVersion = 1
+
 type Config struct {
+	// Version is the schema version the file was written with; 0 means a
+	// config from before versioning.
+	Version          int               `json:"version"`
 	ImageTag         string            `json:"imageTag"`
 	DefaultContainer string            `json:"defaultContainerName"`
 	Workdir          string            `json:"workdir"`
@@ -29,6 +37,23 @@ type Config struct {
 	ExtractBranchPrefix string            `json:"extractBranchPrefix"`
 	ServeToken          string            `json:"serveToken,omitempty"`
 	DefaultTemplate     string            `json:"defaultTemplate,omitempty"`
+	// ContainerMemory and ContainerCPUs cap resources for newly created
+	// containers (docker run --memory / --cpus, e.g. "2g" and "1.5"). Empty
+	// means unlimited.
+	ContainerMemory string `json:"containerMemory,omitempty"`
+	ContainerCPUs   string `json:"containerCpus,omitempty"`
+	// GitUserName and GitUserEmail are the git committer identity configured
+	// in every new agent. Template git.user_name/git.user_email override them.
+	GitUserName  string `json:"gitUserName,omitempty"`
+	GitUserEmail string `json:"gitUserEmail,omitempty"`
+	// BuildCacheFrom and BuildCacheTo are buildx cache sources/destinations
+	// for image builds: a local directory, a registry ref, or a full
+	// type=... spec. Ignored by classic docker build.
+	BuildCacheFrom string `json:"buildCacheFrom,omitempty"`
+	BuildCacheTo   string `json:"buildCacheTo,omitempty"`
+	// BuildPlatform is the default --platform for image builds (e.g.
+	// linux/amd64 on Apple Silicon). Empty means the host's platform.
+	BuildPlatform string `json:"buildPlatform,omitempty"`
 
 	// New image model (supersedes legacy fields above)
 	// SelectedImage is the name of the currently selected image (must always be set)
@@ -57,6 +82,10 @@ type Config struct {
 	// Agents defines user-provided run-agent shortcuts. Keys are the names used
 	// with `dv run-agent` / `dv ra`.
 	Agents map[string]AgentConfig `json:"agents,omitempty"`
+
+	// AgentOverrides adjusts the default flags and env of bundled run-agent
+	// rules, keyed by agent name or alias.
+	AgentOverrides map[string]AgentOverride `json:"agentOverrides,omitempty"`
 }
 
 // HooksConfig stores host-side lifecycle hooks.
@@ -109,6 +138,17 @@ type AgentConfig struct {
 	Aliases []string `json:"aliases,omitempty"`
 }
 
+// AgentOverride changes a bundled agent's defaults without redefining it as a
+// BYO agent. Defaults and Env are appended to the built-in values unless the
+// matching Replace flag is set, in which case they replace them (an empty list
+// with ReplaceDefaults drops every built-in flag).
+type AgentOverride struct {
+	Defaults        []string `json:"defaults,omitempty"`
+	ReplaceDefaults bool     `json:"replaceDefaults,omitempty"`
+	Env             []string `json:"env,omitempty"`
+	ReplaceEnv      bool     `json:"replaceEnv,omitempty"`
+}
+
 // CopyFallback specifies an alternative source when the primary host path doesn't exist.
 type CopyFallback struct {
 	Type string `json:"type"` // "command"
@@ -144,6 +184,9 @@ type ImageConfig struct {
 	Workdir       string      `json:"workdir"`
 	ContainerPort int         `json:"containerPort"`
 	Dockerfile    ImageSource `json:"dockerfile"`
+	// Platform records the --platform the image was last built for, so
+	// containers are created with the same one. Empty means the host's.
+	Platform string `json:"platform,omitempty"`
 }
 
 type LocalProxyConfig struct {
@@ -160,6 +203,7 @@ type LocalProxyConfig struct {
 
 func Default() Config {
 	return Config{
+		Version:          CurrentVersion,
 		ImageTag:         "ai_agent",
 		DefaultContainer: "ai_agent",
 		Workdir:          "/var/www/discourse",
@@ -214,24 +258,49 @@ func LoadOrCreate(configDir string) (Config, error) {
 		}
 		return Config{}, err
 	}
-	var cfg Config
+	cfg, migrated, err := parse(data)
+	if err != nil {
+		return Config{}, err
+	}
+	if migrated {
+		if err := Save(configDir, cfg); err != nil {
+			return Config{}, fmt.Errorf("save migrated config: %w", err)
+		}
+	}
+	return cfg, nil
+}
+
+// Load reads the config like LoadOrCreate but never writes: a missing file is
+// an fs.ErrNotExist error and migrations are applied in memory only.
+func Load(configDir string) (Config, error) {
+	data, err := os.ReadFile(Path(configDir))
+	if err != nil {
+		return Config{}, err
+	}
+	cfg, _, err := parse(data)
+	return cfg, err
+}
+
+// parse decodes a config file, migrating and defaulting it. migrated reports
+// whether the stored layout was older than CurrentVersion.
+func parse(data []byte) (cfg Config, migrated bool, err error) {
 	if err := json.Unmarshal(data, &cfg); err != nil {
-		return Config{}, fmt.Errorf("invalid config: %w", err)
+		return Config{}, false, fmt.Errorf("invalid config: %w", err)
 	}
-	migrateLegacyEmberCLIPort(&cfg)
-	// Migration to new image model if needed
-	// Ensure Images map is initialized and contains at least discourse
-	if cfg.Images == nil || len(cfg.Images) == 0 {
-		cfg.Images = map[string]ImageConfig{}
+	migrated = Migrate(&cfg)
+	// Normalizations below are idempotent and run on every load, whatever
+	// the version: customWorkdir is still settable and images can be emptied.
+	if len(cfg.Images) == 0 {
 		// Seed from legacy fields
-		discourse := ImageConfig{
-			Kind:          "discourse",
-			Tag:           defaultIfEmpty(cfg.ImageTag, "ai_agent"),
-			Workdir:       defaultIfEmpty(cfg.Workdir, "/var/www/discourse"),
-			ContainerPort: valueOrDefault(cfg.ContainerPort, 3000),
-			Dockerfile:    ImageSource{Source: "stock", StockName: "discourse"},
+		cfg.Images = map[string]ImageConfig{
+			"discourse": {
+				Kind:          "discourse",
+				Tag:           defaultIfEmpty(cfg.ImageTag, "ai_agent"),
+				Workdir:       defaultIfEmpty(cfg.Workdir, "/var/www/discourse"),
+				ContainerPort: valueOrDefault(cfg.ContainerPort, 3000),
+				Dockerfile:    ImageSource{Source: "stock", StockName: "discourse"},
+			},
 		}
-		cfg.Images["discourse"] = discourse
 	}
 	if cfg.SelectedImage == "" {
 		cfg.SelectedImage = "discourse"
@@ -258,7 +327,7 @@ func LoadOrCreate(configDir string) (Config, error) {
 		cfg.CustomWorkdir = ""
 	}
 	cfg.LocalProxy.ApplyDefaults()
-	return cfg, nil
+	return cfg, migrated, nil
 }
 
 func Save(configDir string, cfg Config) error {
@@ -273,6 +342,33 @@ func Save(configDir string, cfg Config) error {
 	return os.WriteFile(Path(configDir), b, 0o644)
 }
 
+// migrations[i] upgrades a version-i config to version i+1.
+var migrations = []func(*Config){
+	migrateToV1,
+}
+
+// Migrate brings cfg up to CurrentVersion, reporting whether it changed.
+// Configs written by a newer dv are left alone.
+func Migrate(cfg *Config) bool {
+	if cfg.Version >= len(migrations) {
+		return false
+	}
+	for _, step := range migrations[cfg.Version:] {
+		step(cfg)
+	}
+	cfg.Version = CurrentVersion
+	return true
+}
+
+// migrateToV1 makes the one-time rewrites for pre-versioning configs: the
+// ember-cli port and defaultContainerName as the selected agent.
+func migrateToV1(cfg *Config) {
+	migrateLegacyEmberCLIPort(cfg)
+	if strings.TrimSpace(cfg.SelectedAgent) == "" {
+		cfg.SelectedAgent = strings.TrimSpace(cfg.DefaultContainer)
+	}
+}
+
 // Helpers for migration/defaulting
 func defaultIfEmpty(value string, fallback string) string {
 	if strings.TrimSpace(value) == "" {
@@ -3,6 +3,8 @@ package config
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"testing"
@@ -679,4 +681,168 @@ func TestDefault(t *testing.T) {
 	if cfg.DefaultTemplate != "" {
 		t.Fatalf("expected DefaultTemplate to be empty, got %q", cfg.DefaultTemplate)
 	}
+	if cfg.Version != CurrentVersion {
+		t.Fatalf("expected Version %d, got %d", CurrentVersion, cfg.Version)
+	}
+}
+
+// loadFixture copies testdata/name into a fresh config dir and loads it.
+func loadFixture(t *testing.T, name string) (Config, string) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(Path(dir), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadOrCreate(dir)
+	if err != nil {
+		t.Fatalf("LoadOrCreate(%s): %v", name, err)
+	}
+	return cfg, dir
+}
+
+func TestLoadOrCreate_MigratesLegacySingleImageFixture(t *testing.T) {
+	t.Parallel()
+
+	cfg, dir := loadFixture(t, "v0_legacy_single_image.json")
+
+	if cfg.Version != CurrentVersion {
+		t.Fatalf("Version = %d, want %d", cfg.Version, CurrentVersion)
+	}
+	discourse, ok := cfg.Images["discourse"]
+	if !ok || discourse.Tag != "old-tag" || discourse.Workdir != "/old/workdir" || discourse.ContainerPort != 3000 {
+		t.Fatalf("backfilled discourse image = %+v (ok=%v)", discourse, ok)
+	}
+	if cfg.SelectedAgent != "my_agent" {
+		t.Fatalf("SelectedAgent = %q, want the legacy default container", cfg.SelectedAgent)
+	}
+	if cfg.CustomWorkdir != "" || cfg.CustomWorkdirs["my_agent"] != "/var/www/discourse/plugins/chat" {
+		t.Fatalf("customWorkdir not moved: %q / %v", cfg.CustomWorkdir, cfg.CustomWorkdirs)
+	}
+	if cfg.HostStartingPort != 3000 {
+		t.Fatalf("HostStartingPort = %d, want 3000", cfg.HostStartingPort)
+	}
+	if len(cfg.CopyFiles) != 0 || len(cfg.CopyRules) == 0 || cfg.CopyRules[0].Host != "~/.gitconfig" {
+		t.Fatalf("copyFiles not migrated: %v / %+v", cfg.CopyFiles, cfg.CopyRules)
+	}
+
+	// The upgrade is written back, and loading it again changes nothing.
+	written, err := os.ReadFile(Path(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var onDisk Config
+	if err := json.Unmarshal(written, &onDisk); err != nil {
+		t.Fatal(err)
+	}
+	if onDisk.Version != CurrentVersion || onDisk.SelectedAgent != "my_agent" || len(onDisk.Images) == 0 {
+		t.Fatalf("migrated config not saved: %s", written)
+	}
+	if _, err := LoadOrCreate(dir); err != nil {
+		t.Fatal(err)
+	}
+	again, err := os.ReadFile(Path(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(written, again) {
+		t.Fatal("reloading a current config rewrote it")
+	}
+}
+
+func TestLoadOrCreate_MigratesImagesFixture(t *testing.T) {
+	t.Parallel()
+
+	cfg, _ := loadFixture(t, "v0_images.json")
+
+	if cfg.Version != CurrentVersion {
+		t.Fatalf("Version = %d, want %d", cfg.Version, CurrentVersion)
+	}
+	if cfg.SelectedAgent != "feature_x" {
+		t.Fatalf("SelectedAgent = %q, want the explicit selection kept", cfg.SelectedAgent)
+	}
+	if got := cfg.Images["discourse"].ContainerPort; got != 3000 {
+		t.Fatalf("images.discourse.containerPort = %d, want 3000", got)
+	}
+	if cfg.ContainerImages["feature_x"] != "discourse" {
+		t.Fatalf("ContainerImages = %v", cfg.ContainerImages)
+	}
+}
+
+func TestLoadOrCreate_NormalizesCurrentVersion(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	data := []byte(`{"version": 1, "selectedAgent": "feature_x", "customWorkdir": "/var/www/discourse/plugins/chat", "images": {}}`)
+	if err := os.WriteFile(Path(dir), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadOrCreate(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.CustomWorkdir != "" || cfg.CustomWorkdirs["feature_x"] != "/var/www/discourse/plugins/chat" {
+		t.Fatalf("customWorkdir not moved: %q / %v", cfg.CustomWorkdir, cfg.CustomWorkdirs)
+	}
+	if _, ok := cfg.Images["discourse"]; !ok {
+		t.Fatalf("empty images not backfilled: %v", cfg.Images)
+	}
+}
+
+func TestLoadOrCreate_LeavesNewerVersionAlone(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "future_version.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, dir := loadFixture(t, "future_version.json")
+
+	if cfg.Version != 99 || cfg.ContainerPort != 4200 || cfg.SelectedAgent != "" {
+		t.Fatalf("newer config was migrated: %+v", cfg)
+	}
+	written, err := os.ReadFile(Path(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(written, data) {
+		t.Fatal("newer config was rewritten")
+	}
+}
+
+func TestLoad_DoesNotWrite(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if _, err := Load(dir); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Load on empty dir: err = %v, want fs.ErrNotExist", err)
+	}
+	if _, err := os.Stat(Path(dir)); !os.IsNotExist(err) {
+		t.Fatal("Load created a config file")
+	}
+
+	data, err := os.ReadFile(filepath.Join("testdata", "v0_legacy_single_image.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(Path(dir), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Version != CurrentVersion || cfg.SelectedAgent != "my_agent" {
+		t.Fatalf("Load did not migrate in memory: %+v", cfg)
+	}
+	after, err := os.ReadFile(Path(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(after, data) {
+		t.Fatal("Load rewrote the config file")
+	}
 }
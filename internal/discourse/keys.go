@@ -222,3 +222,24 @@ func EnsureAPIKeyForService(containerName, workdir, description, keyPath string,
 
 	return generated.Key, generated.Username, nil
 }
+
+// RegenerateAPIKeyForService ignores any cached key and always generates a new
+// one (GenerateAPIKey revokes earlier keys with the same description), then
+// overwrites the cache at keyPath. Use it when a key leaked or the database
+// was reset.
+func RegenerateAPIKeyForService(containerName, workdir, description, keyPath string, envs docker.Envs, verbose bool) (string, string, error) {
+	generated, err := GenerateAPIKey(GenerateAPIKeyOptions{
+		ContainerName: containerName,
+		Workdir:       workdir,
+		Description:   description,
+		Envs:          envs,
+		Verbose:       verbose,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	if err := SaveKeyToContainer(containerName, workdir, keyPath, generated.Key+"\n", envs); err != nil {
+		return "", "", fmt.Errorf("save key to %s: %w", keyPath, err)
+	}
+	return generated.Key, generated.Username, nil
+}
@@ -35,8 +35,25 @@ type CreateLLMInput struct {
 	SetAsDefault       bool
 	ExistingID         int64
 	ExistingAiSecretID int64
+	SecretAction       AiSecretAction
 }
 
+// AiSecretAction says what saving a model should do with its API key.
+type AiSecretAction int
+
+const (
+	// AiSecretDefault stores APIKey when one is given and otherwise leaves
+	// the credentials alone.
+	AiSecretDefault AiSecretAction = iota
+	// AiSecretKeep leaves the model's current credentials untouched.
+	AiSecretKeep
+	// AiSecretRotate replaces the value of the model's current AiSecret,
+	// which other models may share.
+	AiSecretRotate
+	// AiSecretNew moves the model onto an AiSecret of its own.
+	AiSecretNew
+)
+
 // ListLLMs retrieves all configured LLM models
 func (c *Client) ListLLMs() ([]ai.LLMModel, ai.LLMMetadata, error) {
 	resp, body, err := c.doRequest("GET", "/admin/plugins/discourse-ai/ai-llms.json", nil)
@@ -250,7 +267,9 @@ func buildLLMPayload(input CreateLLMInput) map[string]interface{} {
 	}
 
 	llm := payload["ai_llm"].(map[string]interface{})
-	if input.AiSecretID > 0 {
+	if input.SecretAction == AiSecretKeep {
+		// Omit credentials entirely so Discourse keeps what it has.
+	} else if input.AiSecretID > 0 {
 		llm["ai_secret_id"] = input.AiSecretID
 	} else if apiKey := strings.TrimSpace(input.APIKey); apiKey != "" {
 		llm["api_key"] = apiKey
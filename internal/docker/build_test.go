@@ -0,0 +1,132 @@
+package docker
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestParseBuildProgressLine(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		line   string
+		want   BuildProgress
+		wantOK bool
+	}{
+		{
+			name:   "buildx step",
+			line:   "#7 [3/12] RUN apt-get update",
+			want:   BuildProgress{Vertex: 7, Step: 3, Total: 12, Text: "RUN apt-get update"},
+			wantOK: true,
+		},
+		{
+			name:   "buildx step with stage",
+			line:   "#12 [builder 4/9] COPY --chown=discourse . /var/www/discourse\r\n",
+			want:   BuildProgress{Vertex: 12, Stage: "builder", Step: 4, Total: 9, Text: "COPY --chown=discourse . /var/www/discourse"},
+			wantOK: true,
+		},
+		{
+			name:   "classic step",
+			line:   "Step 2/7 : RUN bundle install",
+			want:   BuildProgress{Step: 2, Total: 7, Text: "RUN bundle install"},
+			wantOK: true,
+		},
+		{name: "buildx internal vertex", line: "#1 [internal] load build definition from Dockerfile"},
+		{name: "buildx done line", line: "#7 DONE 12.3s"},
+		{name: "buildx step output", line: "#7 0.512 Reading package lists..."},
+		{name: "plain output", line: "Successfully tagged ai_agent:latest"},
+		{name: "empty", line: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := ParseBuildProgressLine(tt.line)
+			if ok != tt.wantOK {
+				t.Fatalf("ParseBuildProgressLine(%q) ok = %v, want %v", tt.line, ok, tt.wantOK)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseBuildProgressLine(%q) = %+v, want %+v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildxCacheArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		from, to  string
+		want      []string
+		wantError bool
+	}{
+		{name: "none"},
+		{
+			name: "local directory",
+			from: "/tmp/cache",
+			to:   "./cache",
+			want: []string{"--cache-from", "type=local,src=/tmp/cache", "--cache-to", "type=local,dest=./cache,mode=max"},
+		},
+		{
+			name: "registry ref",
+			from: "ghcr.io/acme/dv:cache",
+			want: []string{"--cache-from", "type=registry,ref=ghcr.io/acme/dv:cache"},
+		},
+		{
+			name: "full spec",
+			to:   "type=gha,mode=max",
+			want: []string{"--cache-to", "type=gha,mode=max"},
+		},
+		{name: "spec without type", from: "ref=ghcr.io/acme/dv", wantError: true},
+		{name: "bare list", to: "a,b", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := BuildxCacheArgs(tt.from, tt.to)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("BuildxCacheArgs(%q, %q) error = %v, wantError %v", tt.from, tt.to, err, tt.wantError)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildxCacheArgs(%q, %q) = %q, want %q", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePlatform(t *testing.T) {
+	t.Parallel()
+
+	for _, ok := range []string{"", "linux/amd64", "linux/arm64", "linux/arm/v7"} {
+		if err := ValidatePlatform(ok); err != nil {
+			t.Errorf("ValidatePlatform(%q) = %v, want nil", ok, err)
+		}
+	}
+	for _, bad := range []string{"amd64", "linux/amd64,linux/arm64", "darwin/arm64"} {
+		if err := ValidatePlatform(bad); err == nil {
+			t.Errorf("ValidatePlatform(%q) = nil, want error", bad)
+		}
+	}
+}
+
+func TestPlatformEmulated(t *testing.T) {
+	t.Parallel()
+
+	if PlatformEmulated("") {
+		t.Error("empty platform should not be emulated")
+	}
+	if PlatformEmulated("linux/" + runtime.GOARCH) {
+		t.Errorf("linux/%s should be native on a %s host", runtime.GOARCH, runtime.GOARCH)
+	}
+	other := "linux/amd64"
+	if runtime.GOARCH == "amd64" {
+		other = "linux/arm64"
+	}
+	if !PlatformEmulated(other) {
+		t.Errorf("%s should be emulated on a %s host", other, runtime.GOARCH)
+	}
+}
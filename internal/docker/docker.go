@@ -1,21 +1,32 @@
 package docker
 
 import (
+	"archive/tar"
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"golang.org/x/term"
+
+	"dv/internal/logging"
 )
 
 // getIdentityAgent parses ~/.ssh/config for a global IdentityAgent setting.
@@ -62,54 +73,209 @@ func getIdentityAgent() string {
 	return ""
 }
 
+// sshAgentForward describes how the host SSH agent reaches a container.
+type sshAgentForward struct {
+	HostSocket string // agent socket on the host, exported as SSH_AUTH_SOCK to docker
+	MountPath  string // path bind-mounted at /tmp/ssh-agent.sock
+	Source     string // where HostSocket came from, for verbose output
+}
+
+// resolveSSHAgentForward picks the agent socket to forward. An IdentityAgent
+// from ~/.ssh/config (e.g. 1Password's agent) wins over SSH_AUTH_SOCK, as it
+// does for ssh itself. On macOS Docker Desktop/OrbStack always expose the
+// agent at a magic path and pick the socket from the docker command's
+// SSH_AUTH_SOCK; on Linux the socket is bind-mounted directly, so an
+// IdentityAgent that doesn't exist falls back to SSH_AUTH_SOCK.
+func resolveSSHAgentForward(goos, sshAuthSock, identityAgent string, exists func(string) bool) sshAgentForward {
+	switch identityAgent {
+	case "none", "SSH_AUTH_SOCK":
+		// ssh_config keywords meaning "no agent" and "use the environment".
+		identityAgent = ""
+	}
+	if goos == "darwin" {
+		forward := sshAgentForward{HostSocket: sshAuthSock, MountPath: "/run/host-services/ssh-auth.sock", Source: "SSH_AUTH_SOCK"}
+		if identityAgent != "" {
+			forward.HostSocket = identityAgent
+			forward.Source = "IdentityAgent from ~/.ssh/config"
+		}
+		return forward
+	}
+	if identityAgent != "" {
+		if exists(identityAgent) {
+			return sshAgentForward{HostSocket: identityAgent, MountPath: identityAgent, Source: "IdentityAgent from ~/.ssh/config"}
+		}
+		return sshAgentForward{HostSocket: sshAuthSock, MountPath: sshAuthSock, Source: "SSH_AUTH_SOCK; IdentityAgent " + identityAgent + " not found"}
+	}
+	return sshAgentForward{HostSocket: sshAuthSock, MountPath: sshAuthSock, Source: "SSH_AUTH_SOCK"}
+}
+
 // BuildOptions controls how docker images are built.
 type BuildOptions struct {
 	ExtraArgs    []string // additional docker build args supplied by callers
 	ForceClassic bool     // skip buildx/BuildKit helpers and use legacy docker build
 	Builder      string   // optional buildx builder name
+	CacheFrom    string   // buildx --cache-from spec; see NormalizeBuildCacheSpec
+	CacheTo      string   // buildx --cache-to spec; see NormalizeBuildCacheSpec
+	Platform     string   // target platform such as linux/amd64; empty means the host's
+}
+
+// commonPlatforms are the --platform values dv accepts for builds and runs.
+var commonPlatforms = []string{
+	"linux/amd64",
+	"linux/arm64",
+	"linux/arm/v7",
+	"linux/386",
+	"linux/ppc64le",
+	"linux/s390x",
+	"linux/riscv64",
+}
+
+// ValidatePlatform checks a docker --platform value. Empty means the host's
+// platform and is accepted.
+func ValidatePlatform(v string) error {
+	if v == "" || slices.Contains(commonPlatforms, v) {
+		return nil
+	}
+	return fmt.Errorf("unsupported platform %q: use one of %s", v, strings.Join(commonPlatforms, ", "))
+}
+
+// PlatformEmulated reports whether containers for platform run under
+// emulation (QEMU/Rosetta) on this host, which makes builds and Discourse
+// itself considerably slower.
+func PlatformEmulated(platform string) bool {
+	if platform == "" {
+		return false
+	}
+	_, arch, _ := strings.Cut(platform, "/")
+	arch, _, _ = strings.Cut(arch, "/")
+	return arch != runtime.GOARCH
+}
+
+func warnIfEmulated(platform, what string) {
+	if PlatformEmulated(platform) {
+		fmt.Fprintf(os.Stderr, "Warning: %s %s on a %s host runs under emulation and will be slow.\n", what, platform, runtime.GOARCH)
+	}
+}
+
+// NormalizeBuildCacheSpec expands a build cache setting into a buildx
+// --cache-from (export false) or --cache-to (export true) value. Full specs
+// such as "type=gha" pass through; a path becomes a local cache directory and
+// anything else a registry ref. Exports use mode=max so intermediate stages
+// are cached too. Empty input yields "".
+func NormalizeBuildCacheSpec(spec string, export bool) (string, error) {
+	spec = strings.TrimSpace(spec)
+	switch {
+	case spec == "":
+		return "", nil
+	case strings.Contains(spec, "="):
+		if !strings.HasPrefix(spec, "type=") && !strings.Contains(spec, ",type=") {
+			return "", fmt.Errorf("invalid build cache %q: a full spec needs type=... (e.g. type=local,src=DIR)", spec)
+		}
+		return spec, nil
+	case strings.ContainsAny(spec, " \t,"):
+		return "", fmt.Errorf("invalid build cache %q: use a directory, an image ref, or a full type=... spec", spec)
+	case filepath.IsAbs(spec) || strings.HasPrefix(spec, ".") || strings.HasPrefix(spec, "~"):
+		if rest, ok := strings.CutPrefix(spec, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				spec = filepath.Join(home, rest)
+			}
+		}
+		if export {
+			return "type=local,dest=" + spec + ",mode=max", nil
+		}
+		return "type=local,src=" + spec, nil
+	default:
+		if export {
+			return "type=registry,ref=" + spec + ",mode=max", nil
+		}
+		return "type=registry,ref=" + spec, nil
+	}
+}
+
+// BuildxCacheArgs returns the --cache-from/--cache-to arguments for a buildx
+// build. Classic builds have no equivalent, so callers only add these when
+// buildx is used.
+func BuildxCacheArgs(cacheFrom, cacheTo string) ([]string, error) {
+	var args []string
+	from, err := NormalizeBuildCacheSpec(cacheFrom, false)
+	if err != nil {
+		return nil, err
+	}
+	if from != "" {
+		args = append(args, "--cache-from", from)
+	}
+	to, err := NormalizeBuildCacheSpec(cacheTo, true)
+	if err != nil {
+		return nil, err
+	}
+	if to != "" {
+		args = append(args, "--cache-to", to)
+	}
+	return args, nil
+}
+
+// ContainerNamePattern matches the Docker-compatible container names dv
+// accepts. The local proxy (internal/assets/localproxy) is built as a
+// standalone module and carries its own copy of this pattern; a test in the
+// assets package keeps the two in sync.
+const ContainerNamePattern = `^[a-z0-9][a-z0-9_.-]*$`
+
+var containerNameRegexp = regexp.MustCompile(ContainerNamePattern)
+
+// ValidContainerName reports whether name matches ContainerNamePattern.
+func ValidContainerName(name string) bool {
+	return containerNameRegexp.MatchString(name)
 }
 
 func Exists(name string) bool {
-	out, _ := exec.Command("bash", "-lc", "docker ps -aq -f name=^"+shellEscape(name)+"$").Output()
+	return ExistsContext(context.Background(), name)
+}
+
+// ExistsContext is like Exists but gives up (reporting false) when ctx is
+// cancelled, so a hung docker daemon can't block the caller indefinitely.
+func ExistsContext(ctx context.Context, name string) bool {
+	out, _ := exec.CommandContext(ctx, "bash", "-lc", "docker ps -aq -f name=^"+shellEscape(name)+"$").Output()
 	return strings.TrimSpace(string(out)) != ""
 }
 
 func Running(name string) bool {
-	out, _ := exec.Command("bash", "-lc", "docker ps -q -f status=running -f name=^"+shellEscape(name)+"$").Output()
+	return RunningContext(context.Background(), name)
+}
+
+// RunningContext is like Running but gives up (reporting false) when ctx is
+// cancelled.
+func RunningContext(ctx context.Context, name string) bool {
+	out, _ := exec.CommandContext(ctx, "bash", "-lc", "docker ps -q -f status=running -f name=^"+shellEscape(name)+"$").Output()
 	return strings.TrimSpace(string(out)) != ""
 }
 
 func Stop(name string) error {
-	if isTruthyEnv("DV_VERBOSE") {
-		fmt.Fprintf(os.Stderr, "Running: docker stop %s\n", name)
-	}
-	cmd := exec.Command("docker", "stop", name)
-	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
-	return cmd.Run()
+	logCommand([]string{"stop", name})
+	return runTeeStderr(os.Stdout, "stop", name)
 }
 
 func Remove(name string) error {
-	if isTruthyEnv("DV_VERBOSE") {
-		fmt.Fprintf(os.Stderr, "Running: docker rm %s\n", name)
-	}
-	cmd := exec.Command("docker", "rm", name)
-	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
-	return cmd.Run()
+	logCommand([]string{"rm", name})
+	return runTeeStderr(os.Stdout, "rm", name)
+}
+
+// runQuiet runs a docker command with output discarded, folding stderr into
+// the returned error.
+func runQuiet(args ...string) error {
+	logCommand(args)
+	var stderr bytes.Buffer
+	cmd := exec.Command("docker", args...)
+	cmd.Stdout, cmd.Stderr = io.Discard, &stderr
+	return newCommandError(args, stderr.String(), cmd.Run())
 }
 
 func RemoveForce(name string) error {
-	if isTruthyEnv("DV_VERBOSE") {
-		fmt.Fprintf(os.Stderr, "Running: docker rm -f %s\n", name)
-	}
-	cmd := exec.Command("docker", "rm", "-f", name)
-	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
-	return cmd.Run()
+	logCommand([]string{"rm", "-f", name})
+	return runTeeStderr(os.Stdout, "rm", "-f", name)
 }
 
 func Rename(oldName, newName string) error {
-	if isTruthyEnv("DV_VERBOSE") {
-		fmt.Fprintf(os.Stderr, "Running: docker rename %s %s\n", oldName, newName)
-	}
+	logCommand([]string{"rename", oldName, newName})
 	cmd := exec.Command("docker", "rename", oldName, newName)
 	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
 	return cmd.Run()
@@ -117,9 +283,7 @@ func Rename(oldName, newName string) error {
 
 // Pull applies to an image ref (repo:tag or repo@digest)
 func Pull(ref string) error {
-	if isTruthyEnv("DV_VERBOSE") {
-		fmt.Fprintf(os.Stderr, "Running: docker pull %s\n", ref)
-	}
+	logCommand([]string{"pull", ref})
 	cmd := exec.Command("docker", "pull", ref)
 	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
 	return cmd.Run()
@@ -183,9 +347,7 @@ func Build(tag string, args []string) error {
 	argv := []string{"build", "-t", tag}
 	argv = append(argv, args...)
 	argv = append(argv, ".")
-	if isTruthyEnv("DV_VERBOSE") {
-		fmt.Fprintf(os.Stderr, "Running: docker %s\n", strings.Join(argv, " "))
-	}
+	logCommand(argv)
 	cmd := exec.Command("docker", argv...)
 	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
 	return cmd.Run()
@@ -209,6 +371,10 @@ func BuildFrom(tag, dockerfilePath, contextDir string, opts BuildOptions) error
 			opts.Builder = env
 		}
 	}
+	if err := ValidatePlatform(opts.Platform); err != nil {
+		return err
+	}
+	warnIfEmulated(opts.Platform, "building for")
 	useClassic := opts.ForceClassic || isTruthyEnv("DV_DISABLE_BUILDX")
 	buildxOK := buildxAvailable()
 	if !useClassic && buildxOK {
@@ -221,16 +387,17 @@ func BuildFrom(tag, dockerfilePath, contextDir string, opts BuildOptions) error
 			fmt.Fprintln(os.Stderr, "buildx unavailable; falling back to 'docker build'.")
 		}
 	}
-	return runClassicBuild(tag, dockerfilePath, contextDir, opts.ExtraArgs)
+	return runClassicBuild(tag, dockerfilePath, contextDir, opts.Platform, opts.ExtraArgs)
 }
 
-func runClassicBuild(tag, dockerfilePath, contextDir string, args []string) error {
+func runClassicBuild(tag, dockerfilePath, contextDir, platform string, args []string) error {
 	argv := []string{"build", "-t", tag, "-f", dockerfilePath}
+	if platform != "" {
+		argv = append(argv, "--platform", platform)
+	}
 	argv = append(argv, args...)
 	argv = append(argv, contextDir)
-	if isTruthyEnv("DV_VERBOSE") {
-		fmt.Fprintf(os.Stderr, "Running: docker %s\n", strings.Join(argv, " "))
-	}
+	logCommand(argv)
 	cmd := exec.Command("docker", argv...)
 	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
 	cmd.Env = append(os.Environ(), "DOCKER_BUILDKIT=1")
@@ -242,11 +409,17 @@ func runBuildx(tag, dockerfilePath, contextDir string, opts BuildOptions) error
 	if builder := strings.TrimSpace(opts.Builder); builder != "" {
 		argv = append(argv, "--builder", builder)
 	}
+	if opts.Platform != "" {
+		argv = append(argv, "--platform", opts.Platform)
+	}
+	cacheArgs, err := BuildxCacheArgs(opts.CacheFrom, opts.CacheTo)
+	if err != nil {
+		return err
+	}
+	argv = append(argv, cacheArgs...)
 	argv = append(argv, opts.ExtraArgs...)
 	argv = append(argv, contextDir)
-	if isTruthyEnv("DV_VERBOSE") {
-		fmt.Fprintf(os.Stderr, "Running: docker %s\n", strings.Join(argv, " "))
-	}
+	logCommand(argv)
 	cmd := exec.Command("docker", argv...)
 	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
 	cmd.Env = append(os.Environ(), "DOCKER_BUILDKIT=1")
@@ -274,6 +447,39 @@ func buildxError() error {
 	return buildxErr
 }
 
+// CheckBuildx returns why `docker buildx version` failed, or nil when buildx
+// is available. Like buildxAvailable, the probe runs once per process.
+func CheckBuildx() error { return buildxError() }
+
+// ServerVersion returns the docker daemon's version, failing when the CLI
+// cannot reach the daemon.
+func ServerVersion(ctx context.Context) (string, error) {
+	args := []string{"version", "--format", "{{.Server.Version}}"}
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", newCommandError(args, stderr.String(), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// SSHAgentSocket returns the host socket RunDetached forwards for
+// sshAuthSock and a description of where it came from.
+func SSHAgentSocket(sshAuthSock string) (socket, source string) {
+	forward := resolveSSHAgentForward(runtime.GOOS, sshAuthSock, getIdentityAgent(), func(p string) bool {
+		_, err := os.Stat(p)
+		return err == nil
+	})
+	return forward.HostSocket, forward.Source
+}
+
+// logCommand logs a docker invocation at debug level.
+func logCommand(argv []string) {
+	logging.Debugf("Running: docker %s", strings.Join(argv, " "))
+}
+
 func isTruthyEnv(key string) bool {
 	val := strings.TrimSpace(os.Getenv(key))
 	switch strings.ToLower(val) {
@@ -289,56 +495,127 @@ func ImageExists(tag string) bool {
 	return strings.TrimSpace(string(out)) != ""
 }
 
-func RemoveImage(tag string) error {
-	if isTruthyEnv("DV_VERBOSE") {
-		fmt.Fprintf(os.Stderr, "Running: docker rmi %s\n", tag)
+// ImageDetails is the on-disk metadata of a local image.
+type ImageDetails struct {
+	ID        string    `json:"id"`
+	SizeBytes int64     `json:"size_bytes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ImageInfo returns size and creation time for a local image. It returns
+// (nil, nil) when the image has not been built or pulled.
+func ImageInfo(tag string) (*ImageDetails, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("docker", "image", "inspect", "-f", "{{.Id}}|{{.Size}}|{{.Created}}", tag)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if strings.Contains(strings.ToLower(stderr.String()), "no such image") {
+			return nil, nil
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("docker image inspect %s: %s", tag, msg)
+		}
+		return nil, err
 	}
-	cmd := exec.Command("docker", "rmi", tag)
-	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
-	return cmd.Run()
+	return parseImageInspect(string(out))
+}
+
+func parseImageInspect(out string) (*ImageDetails, error) {
+	parts := strings.Split(strings.TrimSpace(out), "|")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("unexpected image inspect output: %q", out)
+	}
+	size, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image size %q: %w", parts[1], err)
+	}
+	created, err := time.Parse(time.RFC3339Nano, parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid image created time %q: %w", parts[2], err)
+	}
+	return &ImageDetails{ID: parts[0], SizeBytes: size, CreatedAt: created}, nil
+}
+
+func RemoveImage(tag string) error {
+	logCommand([]string{"rmi", tag})
+	return runTeeStderr(os.Stdout, "rmi", tag)
 }
 
 // RemoveImageQuiet removes an image, suppressing output and errors.
 // Useful for cleanup where failure is acceptable.
 func RemoveImageQuiet(tag string) error {
-	if isTruthyEnv("DV_VERBOSE") {
-		fmt.Fprintf(os.Stderr, "Running: docker rmi -f %s\n", tag)
-	}
+	logCommand([]string{"rmi", "-f", tag})
 	cmd := exec.Command("docker", "rmi", "-f", tag)
 	cmd.Stdout, cmd.Stderr = io.Discard, io.Discard
 	return cmd.Run()
 }
 
+// RemoveImageIfUnused removes an image without -f, so docker refuses when a
+// container still uses it or it has other tags. Docker's error message is
+// returned rather than printed.
+func RemoveImageIfUnused(tag string) error {
+	return runQuiet("rmi", tag)
+}
+
 // TagImage applies a new tag to an existing image (docker tag src dst)
 func TagImage(srcTag, dstTag string) error {
-	if isTruthyEnv("DV_VERBOSE") {
-		fmt.Fprintf(os.Stderr, "Running: docker tag %s %s\n", srcTag, dstTag)
-	}
+	logCommand([]string{"tag", srcTag, dstTag})
 	cmd := exec.Command("docker", "tag", srcTag, dstTag)
 	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
 	return cmd.Run()
 }
 
 func Start(name string) error {
-	if isTruthyEnv("DV_VERBOSE") {
-		fmt.Fprintf(os.Stderr, "Running: docker start %s\n", name)
-	}
-	cmd := exec.Command("docker", "start", name)
-	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
-	return cmd.Run()
+	logCommand([]string{"start", name})
+	return runTeeStderr(os.Stdout, "start", name)
 }
 
 // ContainerIP returns the IP address of a running container on the default bridge network.
 func ContainerIP(name string) (string, error) {
-	out, err := exec.Command("docker", "inspect", name, "--format", "{{range .NetworkSettings.Networks}}{{.IPAddress}}{{end}}").Output()
+	ci, err := Inspect(name)
 	if err != nil {
 		return "", err
 	}
-	ip := strings.TrimSpace(string(out))
-	if ip == "" {
-		return "", fmt.Errorf("container %s has no IP address", name)
+	return ci.IP()
+}
+
+// ContainerNetwork describes one network a container is attached to.
+type ContainerNetwork struct {
+	Name       string `json:"name"`
+	IPAddress  string `json:"ip_address"`
+	Gateway    string `json:"gateway"`
+	MacAddress string `json:"mac_address"`
+}
+
+// ContainerNetworks returns the networks a container is attached to, sorted by
+// network name. Stopped containers report networks with empty addresses.
+func ContainerNetworks(name string) ([]ContainerNetwork, error) {
+	ci, err := Inspect(name)
+	if err != nil {
+		return nil, err
+	}
+	return ci.Networks(), nil
+}
+
+// parseContainerNetworks parses the JSON of .NetworkSettings.Networks.
+func parseContainerNetworks(data []byte) ([]ContainerNetwork, error) {
+	var ci ContainerInspect
+	if err := json.Unmarshal(data, &ci.NetworkSettings.Networks); err != nil {
+		return nil, err
+	}
+	return ci.Networks(), nil
+}
+
+// PrimaryIP returns the first non-empty IP address in networks, which are
+// expected in ContainerNetworks order.
+func PrimaryIP(networks []ContainerNetwork) string {
+	for _, n := range networks {
+		if n.IPAddress != "" {
+			return n.IPAddress
+		}
 	}
-	return ip, nil
+	return ""
 }
 
 // Mount describes a bind mount to apply when running a container.
@@ -407,46 +684,116 @@ func ensureMountHostPaths(mounts []Mount, home string) {
 	}
 }
 
-func RunDetached(name, workdir, image string, hostPort, containerPort int, labels map[string]string, envs map[string]string, extraHosts []string, sshAuthSock string, mounts []Mount) error {
+// RunOptions configures a detached agent container started by RunDetached.
+type RunOptions struct {
+	Name          string
+	Workdir       string
+	Image         string
+	HostPort      int
+	ContainerPort int
+	Labels        map[string]string
+	Envs          map[string]string
+	ExtraHosts    []string
+	// SSHAuthSock, when set, forwards the host SSH agent into the container.
+	SSHAuthSock string
+	// Mounts are extra bind mounts (volumes) applied with -v.
+	Mounts []Mount
+	// Memory and CPUs are passed to docker run as --memory and --cpus.
+	// Empty means unlimited.
+	Memory string
+	CPUs   string
+	// Platform is passed to docker run as --platform, e.g. for an amd64
+	// image on Apple Silicon. Empty means the host's platform.
+	Platform string
+}
+
+func (o RunOptions) validate() error {
+	if strings.TrimSpace(o.Name) == "" {
+		return fmt.Errorf("container name required")
+	}
+	if strings.TrimSpace(o.Image) == "" {
+		return fmt.Errorf("image required")
+	}
+	if err := ValidateMemoryLimit(o.Memory); err != nil {
+		return err
+	}
+	if err := ValidatePlatform(o.Platform); err != nil {
+		return err
+	}
+	return ValidateCPULimit(o.CPUs)
+}
+
+// memoryLimitPattern mirrors the size grammar docker parses --memory with
+// (go-units RAMInBytes): "2g", "2gb", "1.5GiB", "1t", "512 m".
+var memoryLimitPattern = regexp.MustCompile(`^(?i)[0-9]+(\.[0-9]+)?\s*[kmgtp]?i?b?$`)
+
+// ValidateMemoryLimit checks a docker --memory value such as "512m" or "2g".
+// Empty means unlimited and is accepted.
+func ValidateMemoryLimit(v string) error {
+	if v == "" {
+		return nil
+	}
+	if !memoryLimitPattern.MatchString(v) {
+		return fmt.Errorf("invalid memory limit %q: use a number with an optional k, m, g, t or p suffix (e.g. 2g or 2gb)", v)
+	}
+	return nil
+}
+
+// ValidateCPULimit checks a docker --cpus value such as "1.5". Empty means
+// unlimited and is accepted.
+func ValidateCPULimit(v string) error {
+	if v == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || !(f > 0) || math.IsInf(f, 0) {
+		return fmt.Errorf("invalid CPU limit %q: use a positive number of CPUs (e.g. 1.5)", v)
+	}
+	return nil
+}
+
+func RunDetached(opts RunOptions) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	name, workdir, image := opts.Name, opts.Workdir, opts.Image
+	hostPort, containerPort := opts.HostPort, opts.ContainerPort
+	labels, envs, extraHosts := opts.Labels, opts.Envs, opts.ExtraHosts
+	sshAuthSock, mounts := opts.SSHAuthSock, opts.Mounts
 	args := []string{"run", "-d",
 		"--name", name,
 		"-w", workdir,
 		"-p", fmt.Sprintf("127.0.0.1:%d:%d", hostPort, containerPort),
 	}
+	if opts.Memory != "" {
+		args = append(args, "--memory", opts.Memory)
+	}
+	if opts.CPUs != "" {
+		args = append(args, "--cpus", opts.CPUs)
+	}
+	if opts.Platform != "" {
+		args = append(args, "--platform", opts.Platform)
+		warnIfEmulated(opts.Platform, "running")
+	}
 	home, _ := os.UserHomeDir()
 	ensureMountHostPaths(mounts, home)
 	args = append(args, mountArgs(mounts, home)...)
 	// hostSSHAuthSock tracks what SSH_AUTH_SOCK should be on the host for Docker to forward
 	hostSSHAuthSock := sshAuthSock
 	if sshAuthSock != "" {
-		var mountPath string
-		var socketSource string
-		if runtime.GOOS == "darwin" {
-			// On macOS, Docker Desktop/OrbStack provide a magic socket that forwards
-			// the host's SSH agent. We always mount this path.
-			mountPath = "/run/host-services/ssh-auth.sock"
-
-			// Check if there's an IdentityAgent configured (e.g., 1Password).
-			// If so, we need to tell Docker to use that socket instead of SSH_AUTH_SOCK.
-			identityAgent := getIdentityAgent()
-			if identityAgent != "" {
-				hostSSHAuthSock = identityAgent
-				socketSource = "IdentityAgent from ~/.ssh/config"
-			} else {
-				socketSource = "SSH_AUTH_SOCK"
-			}
-		} else {
-			// On Linux, mount the host socket directly
-			mountPath = sshAuthSock
-			socketSource = "SSH_AUTH_SOCK"
-		}
-		if isTruthyEnv("DV_VERBOSE") {
-			fmt.Fprintf(os.Stderr, "SSH agent: forwarding %s (%s)\n", hostSSHAuthSock, socketSource)
+		forward := resolveSSHAgentForward(runtime.GOOS, sshAuthSock, getIdentityAgent(), func(p string) bool {
+			_, err := os.Stat(p)
+			return err == nil
+		})
+		hostSSHAuthSock = forward.HostSocket
+		mountPath := forward.MountPath
+		if logging.DebugEnabled() {
+			logging.Debugf("SSH agent: forwarding %s (%s)", hostSSHAuthSock, forward.Source)
 			// Check if socket exists on host
 			if _, err := os.Stat(hostSSHAuthSock); err != nil {
-				fmt.Fprintf(os.Stderr, "SSH agent: WARNING - socket does not exist: %v\n", err)
+				logging.Debugf("SSH agent: WARNING - socket does not exist: %v", err)
 			} else {
-				fmt.Fprintf(os.Stderr, "SSH agent: socket exists at %s\n", hostSSHAuthSock)
+				logging.Debugf("SSH agent: socket exists at %s", hostSSHAuthSock)
 			}
 		}
 		args = append(args, "-v", mountPath+":/tmp/ssh-agent.sock")
@@ -471,9 +818,7 @@ func RunDetached(name, workdir, image string, hostPort, containerPort int, label
 		args = append(args, "--label", fmt.Sprintf("%s=%s", k, v))
 	}
 	args = append(args, image, "--sysctl", "kernel.unprivileged_userns_clone=1")
-	if isTruthyEnv("DV_VERBOSE") {
-		fmt.Fprintf(os.Stderr, "Running: docker %s\n", strings.Join(args, " "))
-	}
+	logCommand(args)
 	cmd := exec.Command("docker", args...)
 	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
 	// If we detected a different SSH agent (e.g., 1Password), set SSH_AUTH_SOCK
@@ -488,14 +833,84 @@ func RunDetached(name, workdir, image string, hostPort, containerPort int, label
 			}
 		}
 		cmd.Env = append(filteredEnv, "SSH_AUTH_SOCK="+hostSSHAuthSock)
-		if isTruthyEnv("DV_VERBOSE") {
-			fmt.Fprintf(os.Stderr, "SSH agent: setting SSH_AUTH_SOCK=%s for docker command\n", hostSSHAuthSock)
-		}
+		logging.Debugf("SSH agent: setting SSH_AUTH_SOCK=%s for docker command", hostSSHAuthSock)
 	}
 	return cmd.Run()
 }
 
+// LogsOptions controls which container log output Logs returns.
+type LogsOptions struct {
+	// Since is passed to `docker logs --since` (a duration like "10m" or a timestamp).
+	Since string
+	// Tail limits output to the last N lines; 0 means all lines.
+	Tail int
+	// Follow keeps streaming new output until ctx is cancelled.
+	Follow bool
+	// Timestamps prefixes each line with its RFC3339 timestamp.
+	Timestamps bool
+	// Stdout receives the container's stdout. Stderr receives its stderr and
+	// defaults to Stdout when nil.
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+func logsArgs(name string, opts LogsOptions) []string {
+	args := []string{"logs"}
+	if opts.Since != "" {
+		args = append(args, "--since", opts.Since)
+	}
+	if opts.Tail > 0 {
+		args = append(args, "--tail", strconv.Itoa(opts.Tail))
+	}
+	if opts.Follow {
+		args = append(args, "--follow")
+	}
+	if opts.Timestamps {
+		args = append(args, "--timestamps")
+	}
+	return append(args, name)
+}
+
+// Logs streams the container's own stdout/stderr (as captured by docker) to
+// the writers in opts. With Follow set it blocks until ctx is cancelled or the
+// container stops.
+func Logs(ctx context.Context, name string, opts LogsOptions) error {
+	if opts.Stdout == nil {
+		return fmt.Errorf("logs: no output writer")
+	}
+	stderr := opts.Stderr
+	if stderr == nil {
+		stderr = opts.Stdout
+	}
+	args := logsArgs(name, opts)
+	logCommand(args)
+	// docker logs writes the container's stderr and its own errors to the same
+	// stream, so look the container up first rather than classifying output.
+	if !ExistsContext(ctx, name) {
+		return ErrContainerNotFound
+	}
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = ExecStreamWaitDelay
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("docker logs %s: %w", name, err)
+	}
+	return nil
+}
+
 func ExecInteractive(name, workdir string, envs Envs, argv []string) error {
+	return ExecInteractiveContext(context.Background(), name, workdir, envs, argv)
+}
+
+// ExecInteractiveContext runs an interactive command inside the container as
+// the discourse user. When ctx is cancelled the docker client is killed and
+// the exec'd processes are terminated, as in ExecStreamAsUserContext.
+func ExecInteractiveContext(ctx context.Context, name, workdir string, envs Envs, argv []string) error {
+	envs, execID := TagExec(envs)
 	args := []string{"exec", "-i", "--user", "discourse", "-w", workdir}
 	// Add -t only when both stdin and stdout are TTYs
 	if term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd())) {
@@ -506,9 +921,16 @@ func ExecInteractive(name, workdir string, envs Envs, argv []string) error {
 	}
 	args = append(args, name)
 	args = append(args, argv...)
-	cmd := exec.Command("docker", args...)
+	cmd := exec.CommandContext(ctx, "docker", args...)
 	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
-	return cmd.Run()
+	cmd.WaitDelay = ExecStreamWaitDelay
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		if killErr := KillExecTree(name, execID); killErr != nil {
+			logging.Debugf("Failed to terminate exec %s in %s: %v", execID, name, killErr)
+		}
+	}
+	return err
 }
 
 // ExecStream runs a command inside the container as the discourse user and streams output to writers.
@@ -518,9 +940,27 @@ func ExecStream(name, workdir string, envs Envs, argv []string, stdout, stderr i
 }
 
 // ExecStreamContext runs a command inside the container as the discourse user and streams output to writers.
-// The docker exec process is killed when ctx is cancelled.
+// The command is terminated when ctx is cancelled; see ExecStreamAsUserContext.
 func ExecStreamContext(ctx context.Context, name, workdir string, envs Envs, argv []string, stdout, stderr io.Writer) error {
-	args := []string{"exec", "--user", "discourse", "-w", workdir}
+	return ExecStreamAsUserContext(ctx, "discourse", name, workdir, envs, argv, stdout, stderr)
+}
+
+// execStreamEnv tags every process started by ExecStreamAsUserContext so the
+// whole process tree can be found again inside the container on cancellation.
+const execStreamEnv = "DV_EXEC_ID"
+
+// ExecStreamWaitDelay bounds how long Wait blocks on output pipes after the
+// docker client is killed, in case something still holds them open. Callers
+// running their own `docker exec` (e.g. paste) use it as cmd.WaitDelay too.
+const ExecStreamWaitDelay = 5 * time.Second
+
+// ExecStreamAsUserContext runs a command inside the container as user and
+// streams output to writers. Killing the docker client alone leaves the exec'd
+// process running inside the container, so when ctx is cancelled every
+// process carrying this exec's DV_EXEC_ID is also sent SIGTERM.
+func ExecStreamAsUserContext(ctx context.Context, user, name, workdir string, envs Envs, argv []string, stdout, stderr io.Writer) error {
+	execID := newExecID()
+	args := []string{"exec", "--user", user, "-w", workdir, "-e", execStreamEnv + "=" + execID}
 	for _, e := range envs {
 		args = append(args, "-e", e)
 	}
@@ -529,7 +969,45 @@ func ExecStreamContext(ctx context.Context, name, workdir string, envs Envs, arg
 	cmd := exec.CommandContext(ctx, "docker", args...)
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
-	return cmd.Run()
+	cmd.WaitDelay = ExecStreamWaitDelay
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		if killErr := KillExecTree(name, execID); killErr != nil {
+			logging.Debugf("Failed to terminate exec %s in %s: %v", execID, name, killErr)
+		}
+	}
+	return err
+}
+
+func newExecID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// TagExec returns a copy of envs carrying a fresh DV_EXEC_ID, and that ID, so
+// an exec started outside this package can later be stopped with KillExecTree.
+func TagExec(envs Envs) (Envs, string) {
+	execID := newExecID()
+	return append(append(Envs{}, envs...), execStreamEnv+"="+execID), execID
+}
+
+// KillExecTree sends SIGTERM to every process in the container whose
+// environment contains the given exec ID.
+func KillExecTree(name, execID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	logging.Debugf("Running: docker exec --user root %s sh -c <kill %s=%s>", name, execStreamEnv, execID)
+	return exec.CommandContext(ctx, "docker", "exec", "--user", "root", name, "sh", "-c", execKillScript(execID)).Run()
+}
+
+func execKillScript(execID string) string {
+	marker := execStreamEnv + "=" + execID
+	return `for d in /proc/[0-9]*; do ` +
+		`{ tr '\0' '\n' < "$d/environ"; } 2>/dev/null | grep -qx '` + marker + `' && kill -TERM "${d#/proc/}" 2>/dev/null; ` +
+		`done; true`
 }
 
 // ExecInteractiveAsRoot runs an interactive command inside the container as root.
@@ -697,6 +1175,56 @@ func ExpandGlobInContainer(containerName, pattern string) ([]string, error) {
 	return paths, nil
 }
 
+// GlobMatch is one path matched by ExpandGlobInContainerDetailed.
+type GlobMatch struct {
+	Path string
+	Type string // stat's %F: "regular file", "directory", "symbolic link", ...
+	Size int64
+}
+
+// IsDir reports whether the match is a directory.
+func (m GlobMatch) IsDir() bool { return m.Type == "directory" }
+
+// ExpandGlobInContainerDetailed is ExpandGlobInContainer with the type and
+// size of each match, for showing users what a copy would transfer.
+func ExpandGlobInContainerDetailed(containerName, pattern string) ([]GlobMatch, error) {
+	// Same positional-argument script as ExpandGlobInContainer, printing
+	// "path<TAB>type<TAB>size" per match.
+	cmd := exec.Command("docker", "exec", containerName, "bash", "-c",
+		`pattern=$1; pattern=${pattern/#\~/$HOME}; shopt -s nullglob; for f in $pattern; do [ -e "$f" ] && stat --printf '%n\t%F\t%s\n' -- "$f"; done`,
+		"--", pattern)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseGlobMatches(string(out))
+}
+
+// parseGlobMatches parses "path<TAB>type<TAB>size" lines. Fields are split
+// from the right so paths containing tabs survive.
+func parseGlobMatches(out string) ([]GlobMatch, error) {
+	var matches []GlobMatch
+	for _, line := range strings.Split(out, "\n") {
+		if line == "" {
+			continue
+		}
+		sizeAt := strings.LastIndex(line, "\t")
+		if sizeAt < 0 {
+			return nil, fmt.Errorf("unexpected glob output line %q", line)
+		}
+		typeAt := strings.LastIndex(line[:sizeAt], "\t")
+		if typeAt < 0 {
+			return nil, fmt.Errorf("unexpected glob output line %q", line)
+		}
+		size, err := strconv.ParseInt(line[sizeAt+1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid size in glob output line %q", line)
+		}
+		matches = append(matches, GlobMatch{Path: line[:typeAt], Type: line[typeAt+1 : sizeAt], Size: size})
+	}
+	return matches, nil
+}
+
 // ContainsGlobMeta returns true if the path contains glob metacharacters (* ? [ {).
 // Also detects brace expansion patterns like {a,b}.
 func ContainsGlobMeta(path string) bool {
@@ -705,7 +1233,7 @@ func ContainsGlobMeta(path string) bool {
 
 func CopyFromContainer(name, srcInContainer, dstOnHost string) error {
 	cmd := exec.Command("docker", "cp", fmt.Sprintf("%s:%s", name, srcInContainer), dstOnHost)
-	if isTruthyEnv("DV_VERBOSE") {
+	if logging.DebugEnabled() {
 		cmd.Stdout = os.Stdout
 	} else {
 		cmd.Stdout = io.Discard
@@ -716,7 +1244,7 @@ func CopyFromContainer(name, srcInContainer, dstOnHost string) error {
 
 func CopyFromContainerContext(ctx context.Context, name, srcInContainer, dstOnHost string) error {
 	cmd := exec.CommandContext(ctx, "docker", "cp", fmt.Sprintf("%s:%s", name, srcInContainer), dstOnHost)
-	if isTruthyEnv("DV_VERBOSE") {
+	if logging.DebugEnabled() {
 		cmd.Stdout = os.Stdout
 	} else {
 		cmd.Stdout = io.Discard
@@ -727,7 +1255,7 @@ func CopyFromContainerContext(ctx context.Context, name, srcInContainer, dstOnHo
 
 func CopyToContainer(name, srcOnHost, dstInContainer string) error {
 	cmd := exec.Command("docker", "cp", srcOnHost, fmt.Sprintf("%s:%s", name, dstInContainer))
-	if isTruthyEnv("DV_VERBOSE") {
+	if logging.DebugEnabled() {
 		cmd.Stdout = os.Stdout
 	} else {
 		cmd.Stdout = io.Discard
@@ -738,7 +1266,7 @@ func CopyToContainer(name, srcOnHost, dstInContainer string) error {
 
 func CopyToContainerContext(ctx context.Context, name, srcOnHost, dstInContainer string) error {
 	cmd := exec.CommandContext(ctx, "docker", "cp", srcOnHost, fmt.Sprintf("%s:%s", name, dstInContainer))
-	if isTruthyEnv("DV_VERBOSE") {
+	if logging.DebugEnabled() {
 		cmd.Stdout = os.Stdout
 	} else {
 		cmd.Stdout = io.Discard
@@ -786,6 +1314,59 @@ func CopyToContainerWithOwnershipContext(ctx context.Context, name, srcOnHost, d
 	return nil
 }
 
+// ReadFileFromContainer opens a single regular file inside the container for
+// streaming. It returns the file contents, its size and its mode; the caller
+// must Close the reader. Missing paths report an error wrapping os.ErrNotExist
+// and anything other than a regular file (e.g. a directory) is rejected.
+func ReadFileFromContainer(ctx context.Context, name, srcInContainer string) (io.ReadCloser, *tar.Header, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	cmd := exec.CommandContext(ctx, "docker", "cp", fmt.Sprintf("%s:%s", name, srcInContainer), "-")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	abort := func() {
+		cancel()
+		_ = cmd.Wait()
+	}
+
+	tr := tar.NewReader(stdout)
+	hdr, err := tr.Next()
+	if err != nil {
+		abort()
+		msg := strings.TrimSpace(stderr.String())
+		if strings.Contains(msg, "Could not find the file") || strings.Contains(msg, "No such container:path") {
+			return nil, nil, fmt.Errorf("%s: %w", srcInContainer, os.ErrNotExist)
+		}
+		if msg != "" {
+			return nil, nil, fmt.Errorf("docker cp %s: %s", srcInContainer, msg)
+		}
+		return nil, nil, fmt.Errorf("docker cp %s: %w", srcInContainer, err)
+	}
+	if hdr.Typeflag != tar.TypeReg {
+		abort()
+		return nil, nil, fmt.Errorf("%s is not a regular file", srcInContainer)
+	}
+	return &containerFileReader{Reader: tr, close: abort}, hdr, nil
+}
+
+type containerFileReader struct {
+	io.Reader
+	close func()
+}
+
+func (r *containerFileReader) Close() error {
+	r.close()
+	return nil
+}
+
 func shellEscape(s string) string {
 	var b bytes.Buffer
 	for _, r := range s {
@@ -798,41 +1379,109 @@ func shellEscape(s string) string {
 }
 
 func Labels(name string) (map[string]string, error) {
-	cmd := exec.Command("docker", "inspect", "-f", "{{json .Config.Labels}}", name)
-	out, err := cmd.Output()
+	ci, err := Inspect(name)
 	if err != nil {
 		return nil, err
 	}
-	labels := map[string]string{}
-	if err := json.Unmarshal(out, &labels); err != nil {
+	return ci.Labels(), nil
+}
+
+// ContainerSummary is a container's name and image as listed by docker ps.
+type ContainerSummary struct {
+	Name  string
+	Image string
+}
+
+// ListContainersWithLabel returns containers carrying label (key or key=value).
+// When statuses are given (e.g. "exited", "created") only containers in one of
+// those states are returned.
+func ListContainersWithLabel(label string, statuses ...string) ([]ContainerSummary, error) {
+	args := []string{"ps", "-a", "--filter", "label=" + label}
+	for _, st := range statuses {
+		args = append(args, "--filter", "status="+st)
+	}
+	args = append(args, "--format", "{{.Names}}\t{{.Image}}")
+	out, err := exec.Command("docker", args...).Output()
+	if err != nil {
 		return nil, err
 	}
-	if labels == nil {
-		labels = map[string]string{}
+	return parseContainerSummaries(string(out)), nil
+}
+
+// StoppedStatuses are the container states that can be removed without
+// stopping anything first; running, paused and restarting containers are not.
+var StoppedStatuses = []string{"created", "exited", "dead"}
+
+// RemoveContainersByLabel removes the containers carrying label (key or
+// key=value) and returns the names it removed. Only stopped containers are
+// removed unless force is set, in which case running ones are killed and
+// removed too. Individual failures don't stop the rest; they are joined into
+// the returned error alongside the partial list.
+func RemoveContainersByLabel(label string, force bool) ([]string, error) {
+	var statuses []string
+	if !force {
+		statuses = StoppedStatuses
+	}
+	containers, err := ListContainersWithLabel(label, statuses...)
+	if err != nil {
+		return nil, err
+	}
+	return RemoveContainers(containers, force, nil)
+}
+
+// RemoveContainers removes each of containers, killing running ones first
+// when force is set, and returns the names it removed. report, when non-nil,
+// is called after every attempt with that container's error (nil on
+// success). Individual failures don't stop the rest; they are joined into
+// the returned error alongside the partial list.
+func RemoveContainers(containers []ContainerSummary, force bool, report func(name string, err error)) ([]string, error) {
+	return removeContainers(containers, func(name string) error {
+		if force {
+			return runQuiet("rm", "-f", name)
+		}
+		return runQuiet("rm", name)
+	}, report)
+}
+
+func removeContainers(containers []ContainerSummary, remove func(name string) error, report func(name string, err error)) ([]string, error) {
+	var removed []string
+	var errs []error
+	for _, c := range containers {
+		err := remove(c.Name)
+		if report != nil {
+			report(c.Name, err)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("remove %s: %w", c.Name, err))
+			continue
+		}
+		removed = append(removed, c.Name)
+	}
+	return removed, errors.Join(errs...)
+}
+
+func parseContainerSummaries(out string) []ContainerSummary {
+	var containers []ContainerSummary
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, image, _ := strings.Cut(line, "\t")
+		containers = append(containers, ContainerSummary{Name: name, Image: image})
 	}
-	return labels, nil
+	return containers
 }
 
 // GetContainerHostPort returns the host port mapped to the given container port.
 // Returns 0 if no mapping found or container doesn't exist.
 // Works on both running and stopped containers by inspecting HostConfig.
 func GetContainerHostPort(name string, containerPort int) (int, error) {
-	// Use docker inspect to get port bindings - works even when container is stopped
-	portKey := fmt.Sprintf("%d/tcp", containerPort)
-	format := fmt.Sprintf("{{(index .HostConfig.PortBindings \"%s\" 0).HostPort}}", portKey)
-	out, err := exec.Command("docker", "inspect", "-f", format, name).Output()
+	ci, err := Inspect(name)
 	if err != nil {
 		return 0, err
 	}
-	portStr := strings.TrimSpace(string(out))
-	if portStr == "" || portStr == "<no value>" {
-		return 0, fmt.Errorf("no port mapping found")
-	}
-	var port int
-	if _, err := fmt.Sscanf(portStr, "%d", &port); err != nil {
-		return 0, fmt.Errorf("invalid port number: %s", portStr)
-	}
-	return port, nil
+	return ci.HostPort(containerPort)
 }
 
 // CommitContainer creates an image from a container's current filesystem state.
@@ -897,13 +1546,73 @@ func allocatedPortsOneByOne(ids []string) (map[int]bool, error) {
 	return ports, nil
 }
 
+// maxPortScanAttempts bounds how many consecutive ports FindFreeHostPort and
+// NextFreePort try before giving up.
+const maxPortScanAttempts = 1000
+
+// PortInUse returns true when the given TCP port cannot be bound on localhost
+// or is already allocated to a Docker container.
+func PortInUse(port int, dockerAllocated map[int]bool) bool {
+	if dockerAllocated != nil && dockerAllocated[port] {
+		logging.Debugf("Port %d is already allocated by a Docker container", port)
+		return true
+	}
+	// Try to listen on all interfaces. This is the most conservative check.
+	l, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		logging.Debugf("Port %d is in use (Listen :%d failed: %v)", port, port, err)
+		return true
+	}
+	_ = l.Close()
+
+	// Also specifically check 127.0.0.1 and [::1] because sometimes ':' only
+	// binds to one of them depending on system configuration.
+	for _, host := range []string{"127.0.0.1", "[::1]"} {
+		l, err := net.Listen("tcp", fmt.Sprintf("%s:%d", host, port))
+		if err != nil {
+			logging.Debugf("Port %d is in use (Listen %s:%d failed: %v)", port, host, port, err)
+			return true
+		}
+		_ = l.Close()
+	}
+
+	return false
+}
+
+// NextFreePort returns the first port at or above start that is neither in
+// dockerAllocated nor bound on the host, trying at most maxPortScanAttempts
+// ports.
+func NextFreePort(start int, dockerAllocated map[int]bool) (int, error) {
+	if start <= 0 {
+		return 0, fmt.Errorf("invalid starting port %d", start)
+	}
+	for port := start; port < start+maxPortScanAttempts && port <= 65535; port++ {
+		if !PortInUse(port, dockerAllocated) {
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("no free host port found in %d-%d", start, min(start+maxPortScanAttempts-1, 65535))
+}
+
+// FindFreeHostPort returns the first host port at or above start that is not
+// allocated to any Docker container (see AllocatedPorts) and can be bound on
+// the host. A failure to list Docker's allocations is not fatal; the listen
+// check still runs.
+func FindFreeHostPort(start int) (int, error) {
+	allocated, err := AllocatedPorts()
+	if err != nil {
+		logging.Debugf("Warning: failed to detect allocated Docker ports: %v", err)
+	}
+	return NextFreePort(start, allocated)
+}
+
 // GetContainerWorkdir returns the working directory configured for a container.
 func GetContainerWorkdir(name string) (string, error) {
-	out, err := exec.Command("docker", "inspect", "-f", "{{.Config.WorkingDir}}", name).Output()
+	ci, err := Inspect(name)
 	if err != nil {
 		return "", err
 	}
-	return strings.TrimSpace(string(out)), nil
+	return strings.TrimSpace(ci.Config.WorkingDir), nil
 }
 
 // TopProcess represents a single process from docker top output.
@@ -911,6 +1620,8 @@ type TopProcess struct {
 	PID  int
 	PPID int
 	User string
+	CPU  float64 // %CPU as reported by ps; zero when the column is absent
+	Mem  float64 // %MEM as reported by ps; zero when the column is absent
 	Args string
 }
 
@@ -918,12 +1629,20 @@ type TopProcess struct {
 type ExecSession struct {
 	PID     int
 	User    string
+	CPU     float64
+	Mem     float64
 	Command string
 }
 
-// TopProcesses runs `docker top <name> -o pid,ppid,user,args` and parses the output.
+// TopProcesses runs `docker top <name> -o pid,ppid,user,pcpu,pmem,args` and parses the output.
 func TopProcesses(name string) ([]TopProcess, error) {
-	cmd := exec.Command("docker", "top", name, "-o", "pid,ppid,user,args")
+	return TopProcessesContext(context.Background(), name)
+}
+
+// TopProcessesContext is like TopProcesses but kills docker top when ctx is
+// cancelled.
+func TopProcessesContext(ctx context.Context, name string) ([]TopProcess, error) {
+	cmd := exec.CommandContext(ctx, "docker", "top", name, "-o", "pid,ppid,user,pcpu,pmem,args")
 	out, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("docker top %s: %w", name, err)
@@ -931,32 +1650,58 @@ func TopProcesses(name string) ([]TopProcess, error) {
 	return ParseTopOutput(string(out))
 }
 
-// ParseTopOutput parses the text output of `docker top` with columns pid,ppid,user,args.
+// ParseTopOutput parses the text output of `docker top`. Columns are located
+// via the header row, so both the pid,ppid,user,args layout and the extended
+// pid,ppid,user,pcpu,pmem,args layout are accepted. The last column (args)
+// may contain spaces and absorbs the remainder of each line.
 func ParseTopOutput(output string) ([]TopProcess, error) {
 	var procs []TopProcess
+	columns := []string{"PID", "PPID", "USER", "ARGS"}
 	for i, line := range strings.Split(output, "\n") {
 		line = strings.TrimSpace(line)
-		if line == "" || i == 0 { // skip header
+		if i == 0 {
+			if header := strings.Fields(strings.ToUpper(line)); len(header) >= 4 {
+				columns = header
+			}
 			continue
 		}
-		fields := strings.Fields(line)
-		if len(fields) < 4 {
+		if line == "" {
 			continue
 		}
-		pid, err := strconv.Atoi(fields[0])
-		if err != nil {
+		fields := strings.Fields(line)
+		if len(fields) < len(columns) {
 			continue
 		}
-		ppid, err := strconv.Atoi(fields[1])
-		if err != nil {
+		last := len(columns) - 1
+		proc := TopProcess{Args: strings.Join(fields[last:], " ")}
+		valid := true
+		for idx, col := range columns[:last] {
+			value := fields[idx]
+			switch col {
+			case "PID":
+				pid, err := strconv.Atoi(value)
+				if err != nil {
+					valid = false
+				}
+				proc.PID = pid
+			case "PPID":
+				ppid, err := strconv.Atoi(value)
+				if err != nil {
+					valid = false
+				}
+				proc.PPID = ppid
+			case "USER", "UID":
+				proc.User = value
+			case "%CPU", "PCPU":
+				proc.CPU, _ = strconv.ParseFloat(value, 64)
+			case "%MEM", "PMEM":
+				proc.Mem, _ = strconv.ParseFloat(value, 64)
+			}
+		}
+		if !valid {
 			continue
 		}
-		procs = append(procs, TopProcess{
-			PID:  pid,
-			PPID: ppid,
-			User: fields[2],
-			Args: strings.Join(fields[3:], " "),
-		})
+		procs = append(procs, proc)
 	}
 	return procs, nil
 }
@@ -964,11 +1709,11 @@ func ParseTopOutput(output string) ([]TopProcess, error) {
 // containerInitPID returns the host PID of the container's init process
 // via `docker inspect`.
 func containerInitPID(name string) (int, error) {
-	out, err := exec.Command("docker", "inspect", "-f", "{{.State.Pid}}", name).Output()
+	ci, err := Inspect(name)
 	if err != nil {
 		return 0, err
 	}
-	return strconv.Atoi(strings.TrimSpace(string(out)))
+	return ci.State.Pid, nil
 }
 
 // ExecSessions detects docker exec'd processes by finding processes whose PPID
@@ -976,13 +1721,22 @@ func containerInitPID(name string) (int, error) {
 // The container's init process is excluded since it also has an external PPID.
 // docker top shows host PIDs, so we use docker inspect to find the init PID.
 func ExecSessions(name string) ([]ExecSession, error) {
-	procs, err := TopProcesses(name)
+	return ExecSessionsFor(context.Background(), name, nil)
+}
+
+// ExecSessionsFor is ExecSessions reading the init PID from an earlier
+// Inspect/InspectAll result, saving a docker call per container. A nil ci
+// inspects the container itself. docker top is killed when ctx is cancelled.
+func ExecSessionsFor(ctx context.Context, name string, ci *ContainerInspect) ([]ExecSession, error) {
+	procs, err := TopProcessesContext(ctx, name)
 	if err != nil {
 		return nil, err
 	}
 
-	initPID, err := containerInitPID(name)
-	if err != nil {
+	var initPID int
+	if ci != nil {
+		initPID = ci.State.Pid
+	} else if initPID, err = containerInitPID(name); err != nil {
 		return nil, fmt.Errorf("cannot determine container init PID for %s: %w", name, err)
 	}
 
@@ -1007,6 +1761,8 @@ func FindExecSessions(procs []TopProcess, initPID int) []ExecSession {
 			sessions = append(sessions, ExecSession{
 				PID:     p.PID,
 				User:    p.User,
+				CPU:     p.CPU,
+				Mem:     p.Mem,
 				Command: p.Args,
 			})
 		}
@@ -1014,24 +1770,204 @@ func FindExecSessions(procs []TopProcess, initPID int) []ExecSession {
 	return sessions
 }
 
-// GetContainerEnv returns environment variables set on a container as a map.
-func GetContainerEnv(name string) (map[string]string, error) {
-	out, err := exec.Command("docker", "inspect", "-f", "{{json .Config.Env}}", name).Output()
+// ContainerStats is a single `docker stats --no-stream` sample for a container.
+// Sizes are in bytes and percentages are plain numbers (12.5 means 12.5%).
+type ContainerStats struct {
+	Name            string  `json:"name"`
+	CPUPercent      float64 `json:"cpu_percent"`
+	MemUsageBytes   uint64  `json:"mem_usage_bytes"`
+	MemLimitBytes   uint64  `json:"mem_limit_bytes"`
+	MemPercent      float64 `json:"mem_percent"`
+	NetRxBytes      uint64  `json:"net_rx_bytes"`
+	NetTxBytes      uint64  `json:"net_tx_bytes"`
+	BlockReadBytes  uint64  `json:"block_read_bytes"`
+	BlockWriteBytes uint64  `json:"block_write_bytes"`
+	PIDs            int     `json:"pids"`
+}
+
+// Stats returns a single resource usage sample for a running container.
+func Stats(name string) (ContainerStats, error) {
+	return StatsContext(context.Background(), name)
+}
+
+// StatsContext is like Stats but kills the docker stats process when ctx is cancelled.
+func StatsContext(ctx context.Context, name string) (ContainerStats, error) {
+	args := []string{"stats", "--no-stream", "--format", "{{json .}}", name}
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
 	if err != nil {
-		return nil, err
+		return ContainerStats{}, newCommandError(args, stderr.String(), err)
 	}
-	var envList []string
-	if err := json.Unmarshal(out, &envList); err != nil {
-		return nil, err
+	stats, err := ParseDockerStatsLine(string(out))
+	if err != nil {
+		return ContainerStats{}, fmt.Errorf("docker stats %s: %w", name, err)
 	}
-	envMap := make(map[string]string)
-	for _, e := range envList {
-		parts := strings.SplitN(e, "=", 2)
-		if len(parts) == 2 {
-			envMap[parts[0]] = parts[1]
+	return stats, nil
+}
+
+// BuildProgress is a step marker recognised in docker build output.
+type BuildProgress struct {
+	// Vertex is the buildx vertex number (the NN in "#NN"); zero for classic builds.
+	Vertex int    `json:"vertex,omitempty"`
+	Stage  string `json:"stage,omitempty"`
+	Step   int    `json:"step"`
+	Total  int    `json:"total"`
+	Text   string `json:"text"`
+}
+
+var (
+	buildxStepPattern  = regexp.MustCompile(`^#(\d+) \[(?:(\S+) )?(\d+)/(\d+)\] (.*)$`)
+	classicStepPattern = regexp.MustCompile(`^Step (\d+)/(\d+) ?: (.*)$`)
+)
+
+// ParseBuildProgressLine recognises step markers in plain buildx output
+// ("#12 [builder 3/9] RUN ...") and in classic builder output
+// ("Step 3/9 : RUN ..."). Other lines return false.
+func ParseBuildProgressLine(line string) (BuildProgress, bool) {
+	line = strings.TrimRight(line, "\r\n")
+	if m := buildxStepPattern.FindStringSubmatch(line); m != nil {
+		vertex, _ := strconv.Atoi(m[1])
+		step, _ := strconv.Atoi(m[3])
+		total, _ := strconv.Atoi(m[4])
+		return BuildProgress{Vertex: vertex, Stage: m[2], Step: step, Total: total, Text: m[5]}, true
+	}
+	if m := classicStepPattern.FindStringSubmatch(line); m != nil {
+		step, _ := strconv.Atoi(m[1])
+		total, _ := strconv.Atoi(m[2])
+		return BuildProgress{Step: step, Total: total, Text: m[3]}, true
+	}
+	return BuildProgress{}, false
+}
+
+// ParseDockerStatsLine parses one line of `docker stats --format '{{json .}}'`
+// output, converting the human-readable sizes and percentages into numbers.
+// Fields docker reports as "--" (e.g. for a container that just stopped) are
+// left at zero.
+func ParseDockerStatsLine(line string) (ContainerStats, error) {
+	var raw struct {
+		Name     string `json:"Name"`
+		CPUPerc  string `json:"CPUPerc"`
+		MemUsage string `json:"MemUsage"`
+		MemPerc  string `json:"MemPerc"`
+		NetIO    string `json:"NetIO"`
+		BlockIO  string `json:"BlockIO"`
+		PIDs     string `json:"PIDs"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(line)), &raw); err != nil {
+		return ContainerStats{}, fmt.Errorf("invalid stats line: %w", err)
+	}
+
+	stats := ContainerStats{Name: raw.Name}
+	var err error
+	if stats.CPUPercent, err = parsePercent(raw.CPUPerc); err != nil {
+		return ContainerStats{}, fmt.Errorf("cpu: %w", err)
+	}
+	if stats.MemPercent, err = parsePercent(raw.MemPerc); err != nil {
+		return ContainerStats{}, fmt.Errorf("mem: %w", err)
+	}
+	if stats.MemUsageBytes, stats.MemLimitBytes, err = parseSizePair(raw.MemUsage); err != nil {
+		return ContainerStats{}, fmt.Errorf("mem usage: %w", err)
+	}
+	if stats.NetRxBytes, stats.NetTxBytes, err = parseSizePair(raw.NetIO); err != nil {
+		return ContainerStats{}, fmt.Errorf("net io: %w", err)
+	}
+	if stats.BlockReadBytes, stats.BlockWriteBytes, err = parseSizePair(raw.BlockIO); err != nil {
+		return ContainerStats{}, fmt.Errorf("block io: %w", err)
+	}
+	if pids := strings.TrimSpace(raw.PIDs); pids != "" && pids != "--" {
+		if stats.PIDs, err = strconv.Atoi(pids); err != nil {
+			return ContainerStats{}, fmt.Errorf("pids: invalid value %q", raw.PIDs)
 		}
 	}
-	return envMap, nil
+	return stats, nil
+}
+
+func parsePercent(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "--" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid percentage %q", s)
+	}
+	return v, nil
+}
+
+// parseSizePair parses docker's "<a> / <b>" size columns (MemUsage, NetIO, BlockIO).
+func parseSizePair(s string) (uint64, uint64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "--" {
+		return 0, 0, nil
+	}
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid size pair %q", s)
+	}
+	a, err := ParseSize(parts[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	b, err := ParseSize(parts[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	return a, b, nil
+}
+
+var sizeUnits = map[string]float64{
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"pb":  1e15,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+	"pib": 1 << 50,
+}
+
+// ParseSize converts a docker human-readable size such as "1.5GiB", "512kB"
+// or "0B" into bytes. Decimal (kB, MB) and binary (KiB, MiB) units are both
+// accepted, case-insensitively.
+func ParseSize(s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "--" {
+		return 0, nil
+	}
+	i := 0
+	for i < len(s) && (s[i] == '.' || (s[i] >= '0' && s[i] <= '9')) {
+		i++
+	}
+	num, unit := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+	if num == "" {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	v, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	if unit == "" {
+		unit = "b"
+	}
+	mult, ok := sizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown size unit in %q", s)
+	}
+	return uint64(v*mult + 0.5), nil
+}
+
+// GetContainerEnv returns environment variables set on a container as a map.
+func GetContainerEnv(name string) (map[string]string, error) {
+	ci, err := Inspect(name)
+	if err != nil {
+		return nil, err
+	}
+	return ci.Env(), nil
 }
 
 // GetContainerMounts returns the bind mounts of an existing container, read back
@@ -1040,32 +1976,18 @@ func GetContainerEnv(name string) (map[string]string, error) {
 // env that are already recovered the same way. Anonymous/named volumes and the
 // forwarded SSH agent socket (re-established separately) are excluded.
 func GetContainerMounts(name string) ([]Mount, error) {
-	out, err := exec.Command("docker", "inspect", "-f", "{{json .Mounts}}", name).Output()
+	ci, err := Inspect(name)
 	if err != nil {
 		return nil, err
 	}
-	return parseContainerMounts(out)
+	return ci.BindMounts(), nil
 }
 
+// parseContainerMounts parses the JSON of .Mounts.
 func parseContainerMounts(data []byte) ([]Mount, error) {
-	var raw []struct {
-		Type        string `json:"Type"`
-		Source      string `json:"Source"`
-		Destination string `json:"Destination"`
-		RW          bool   `json:"RW"`
-	}
-	if err := json.Unmarshal(data, &raw); err != nil {
+	var ci ContainerInspect
+	if err := json.Unmarshal(data, &ci.Mounts); err != nil {
 		return nil, err
 	}
-	var mounts []Mount
-	for _, m := range raw {
-		// Only re-apply host bind mounts; anonymous/named volumes are managed by
-		// docker. Skip the forwarded SSH agent socket (see RunDetached) — it is
-		// re-established via the sshAuthSock path, not as a persisted bind mount.
-		if m.Type != "bind" || m.Destination == "/tmp/ssh-agent.sock" {
-			continue
-		}
-		mounts = append(mounts, Mount{Host: m.Source, Container: m.Destination, ReadOnly: !m.RW})
-	}
-	return mounts, nil
+	return ci.BindMounts(), nil
 }
@@ -1,9 +1,16 @@
 package docker
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestShellEscape(t *testing.T) {
@@ -249,6 +256,61 @@ func TestGetIdentityAgentMissingConfig(t *testing.T) {
 	}
 }
 
+func TestResolveSSHAgentForward(t *testing.T) {
+	const envSock = "/tmp/ssh-XXXX/agent.123"
+	const opSock = "/home/me/.1password/agent.sock"
+	exists := func(p string) bool { return p == opSock }
+
+	tests := []struct {
+		name          string
+		goos          string
+		identityAgent string
+		want          sshAgentForward
+	}{
+		{
+			name: "linux without IdentityAgent",
+			goos: "linux",
+			want: sshAgentForward{HostSocket: envSock, MountPath: envSock, Source: "SSH_AUTH_SOCK"},
+		},
+		{
+			name:          "linux with IdentityAgent",
+			goos:          "linux",
+			identityAgent: opSock,
+			want:          sshAgentForward{HostSocket: opSock, MountPath: opSock, Source: "IdentityAgent from ~/.ssh/config"},
+		},
+		{
+			name:          "linux with missing IdentityAgent socket",
+			goos:          "linux",
+			identityAgent: "/nope/agent.sock",
+			want:          sshAgentForward{HostSocket: envSock, MountPath: envSock, Source: "SSH_AUTH_SOCK; IdentityAgent /nope/agent.sock not found"},
+		},
+		{
+			name:          "linux IdentityAgent SSH_AUTH_SOCK keyword",
+			goos:          "linux",
+			identityAgent: "SSH_AUTH_SOCK",
+			want:          sshAgentForward{HostSocket: envSock, MountPath: envSock, Source: "SSH_AUTH_SOCK"},
+		},
+		{
+			name:          "darwin with IdentityAgent",
+			goos:          "darwin",
+			identityAgent: opSock,
+			want:          sshAgentForward{HostSocket: opSock, MountPath: "/run/host-services/ssh-auth.sock", Source: "IdentityAgent from ~/.ssh/config"},
+		},
+		{
+			name: "darwin without IdentityAgent",
+			goos: "darwin",
+			want: sshAgentForward{HostSocket: envSock, MountPath: "/run/host-services/ssh-auth.sock", Source: "SSH_AUTH_SOCK"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveSSHAgentForward(tt.goos, envSock, tt.identityAgent, exists); got != tt.want {
+				t.Errorf("resolveSSHAgentForward() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestIsTruthyEnv(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -486,3 +548,306 @@ func TestParseContainerMountsEmpty(t *testing.T) {
 		t.Fatalf("expected no mounts, got %+v", got)
 	}
 }
+
+func TestValidContainerName(t *testing.T) {
+	t.Parallel()
+
+	valid := []string{"ai_agent", "agent-1", "my.agent", "0day"}
+	for _, name := range valid {
+		if !ValidContainerName(name) {
+			t.Errorf("ValidContainerName(%q) = false, want true", name)
+		}
+	}
+	invalid := []string{"", "-agent", ".agent", "_agent", "Agent", "my agent", "agent/1", "agent:1"}
+	for _, name := range invalid {
+		if ValidContainerName(name) {
+			t.Errorf("ValidContainerName(%q) = true, want false", name)
+		}
+	}
+}
+
+func TestExecKillScriptTargetsExecID(t *testing.T) {
+	t.Parallel()
+
+	id := newExecID()
+	if len(id) != 16 {
+		t.Fatalf("newExecID() = %q, want 16 hex chars", id)
+	}
+	if other := newExecID(); other == id {
+		t.Fatalf("newExecID() returned the same id twice: %q", id)
+	}
+
+	script := execKillScript(id)
+	if !strings.Contains(script, "grep -qx '"+execStreamEnv+"="+id+"'") {
+		t.Errorf("execKillScript() = %q, want exact match on %s=%s", script, execStreamEnv, id)
+	}
+	if !strings.Contains(script, "kill -TERM") {
+		t.Errorf("execKillScript() = %q, want kill -TERM", script)
+	}
+}
+
+func TestParseContainerNetworks(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`{
+		"zz-custom": {"IPAddress": "10.0.0.5", "Gateway": "10.0.0.1", "MacAddress": "02:42:0a:00:00:05"},
+		"bridge": {"IPAddress": "", "Gateway": "", "MacAddress": ""},
+		"dv-net": {"IPAddress": "172.18.0.3", "Gateway": "172.18.0.1", "MacAddress": "02:42:ac:12:00:03"}
+	}`)
+	got, err := parseContainerNetworks(data)
+	if err != nil {
+		t.Fatalf("parseContainerNetworks() error = %v", err)
+	}
+	want := []ContainerNetwork{
+		{Name: "bridge"},
+		{Name: "dv-net", IPAddress: "172.18.0.3", Gateway: "172.18.0.1", MacAddress: "02:42:ac:12:00:03"},
+		{Name: "zz-custom", IPAddress: "10.0.0.5", Gateway: "10.0.0.1", MacAddress: "02:42:0a:00:00:05"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseContainerNetworks() = %+v, want %+v", got, want)
+	}
+	if ip := PrimaryIP(got); ip != "172.18.0.3" {
+		t.Errorf("PrimaryIP() = %q, want 172.18.0.3", ip)
+	}
+	if ip := PrimaryIP(nil); ip != "" {
+		t.Errorf("PrimaryIP(nil) = %q, want empty", ip)
+	}
+	if _, err := parseContainerNetworks([]byte("null")); err != nil {
+		t.Errorf("parseContainerNetworks(null) error = %v", err)
+	}
+}
+
+func TestNextFreePortSkipsAllocatedAndBoundPorts(t *testing.T) {
+	t.Parallel()
+
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Skipf("cannot bind a test port: %v", err)
+	}
+	defer l.Close()
+	bound := l.Addr().(*net.TCPAddr).Port
+	if bound+2 > 65535 {
+		t.Skipf("ephemeral port %d too close to the top of the range", bound)
+	}
+
+	got, err := NextFreePort(bound, map[int]bool{bound + 1: true})
+	if err != nil {
+		t.Fatalf("NextFreePort() error = %v", err)
+	}
+	if got == bound || got == bound+1 {
+		t.Errorf("NextFreePort() = %d, want a port other than %d and %d", got, bound, bound+1)
+	}
+}
+
+func TestNextFreePortErrors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NextFreePort(0, nil); err == nil {
+		t.Error("NextFreePort(0) expected an error")
+	}
+
+	allocated := map[int]bool{}
+	for p := 40000; p < 40000+maxPortScanAttempts; p++ {
+		allocated[p] = true
+	}
+	if _, err := NextFreePort(40000, allocated); err == nil {
+		t.Error("NextFreePort() expected an error once every attempt is allocated")
+	}
+}
+
+func TestParseImageInspect(t *testing.T) {
+	t.Parallel()
+
+	got, err := parseImageInspect("sha256:abc123|4123456789|2026-03-04T05:06:07.123456789Z\n")
+	if err != nil {
+		t.Fatalf("parseImageInspect() error = %v", err)
+	}
+	want := &ImageDetails{
+		ID:        "sha256:abc123",
+		SizeBytes: 4123456789,
+		CreatedAt: time.Date(2026, 3, 4, 5, 6, 7, 123456789, time.UTC),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseImageInspect() = %+v, want %+v", got, want)
+	}
+
+	for _, bad := range []string{"", "sha256:abc|12", "sha256:abc|big|2026-03-04T05:06:07Z", "sha256:abc|12|yesterday"} {
+		if _, err := parseImageInspect(bad); err == nil {
+			t.Errorf("parseImageInspect(%q) expected an error", bad)
+		}
+	}
+}
+
+func TestParseContainerSummaries(t *testing.T) {
+	t.Parallel()
+
+	got := parseContainerSummaries("ai_agent\tai_agent:latest\n\nold-agent\tdv-old:snap\n")
+	want := []ContainerSummary{
+		{Name: "ai_agent", Image: "ai_agent:latest"},
+		{Name: "old-agent", Image: "dv-old:snap"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseContainerSummaries() = %+v, want %+v", got, want)
+	}
+	if got := parseContainerSummaries(""); got != nil {
+		t.Errorf("parseContainerSummaries(\"\") = %+v, want nil", got)
+	}
+}
+
+func TestExistsRunningContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if ExistsContext(ctx, "dv-test-container") {
+		t.Error("ExistsContext() with a cancelled context = true, want false")
+	}
+	if RunningContext(ctx, "dv-test-container") {
+		t.Error("RunningContext() with a cancelled context = true, want false")
+	}
+}
+
+func TestRunDetachedRejectsMissingNameOrImage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts RunOptions
+		want string
+	}{
+		{name: "empty name", opts: RunOptions{Image: "ai_agent:latest"}, want: "container name required"},
+		{name: "blank name", opts: RunOptions{Name: "  ", Image: "ai_agent:latest"}, want: "container name required"},
+		{name: "empty image", opts: RunOptions{Name: "ai_agent"}, want: "image required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := RunDetached(tt.opts)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("RunDetached() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateResourceLimits(t *testing.T) {
+	t.Parallel()
+
+	for _, v := range []string{"", "2g", "2G", "512m", "1.5g", "1073741824", "100k", "64b", "2gb", "1t", "1.5GiB", "512 m"} {
+		if err := ValidateMemoryLimit(v); err != nil {
+			t.Errorf("ValidateMemoryLimit(%q) unexpected error: %v", v, err)
+		}
+	}
+	for _, v := range []string{"-1g", "g", "two", "1.g", "2x", "2gbb"} {
+		if err := ValidateMemoryLimit(v); err == nil {
+			t.Errorf("ValidateMemoryLimit(%q) expected an error", v)
+		}
+	}
+	for _, v := range []string{"", "1", "1.5", "0.25"} {
+		if err := ValidateCPULimit(v); err != nil {
+			t.Errorf("ValidateCPULimit(%q) unexpected error: %v", v, err)
+		}
+	}
+	for _, v := range []string{"0", "-1", "abc", "Inf", "NaN"} {
+		if err := ValidateCPULimit(v); err == nil {
+			t.Errorf("ValidateCPULimit(%q) expected an error", v)
+		}
+	}
+}
+
+func TestLogsArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts LogsOptions
+		want []string
+	}{
+		{name: "defaults", want: []string{"logs", "c1"}},
+		{
+			name: "all options",
+			opts: LogsOptions{Since: "10m", Tail: 100, Follow: true, Timestamps: true},
+			want: []string{"logs", "--since", "10m", "--tail", "100", "--follow", "--timestamps", "c1"},
+		},
+		{name: "zero tail omitted", opts: LogsOptions{Tail: 0, Follow: true}, want: []string{"logs", "--follow", "c1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := logsArgs("c1", tt.opts); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("logsArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagExecCopiesEnvs(t *testing.T) {
+	t.Parallel()
+
+	base := Envs{"A=1"}
+	tagged, id := TagExec(base)
+	if len(base) != 1 {
+		t.Fatalf("TagExec modified its input: %#v", base)
+	}
+	want := Envs{"A=1", execStreamEnv + "=" + id}
+	if len(tagged) != len(want) || tagged[0] != want[0] || tagged[1] != want[1] {
+		t.Fatalf("TagExec() = %#v, want %#v", tagged, want)
+	}
+}
+
+func TestRemoveContainersReturnsPartialResults(t *testing.T) {
+	containers := []ContainerSummary{{Name: "a"}, {Name: "busy"}, {Name: "c"}}
+	var attempted []string
+	errBusy := errors.New("container is running: stop the container before removing")
+	var reported []string
+	removed, err := removeContainers(containers, func(name string) error {
+		attempted = append(attempted, name)
+		if name == "busy" {
+			return errBusy
+		}
+		return nil
+	}, func(name string, err error) {
+		reported = append(reported, fmt.Sprintf("%s:%v", name, err))
+	})
+	if !reflect.DeepEqual(attempted, []string{"a", "busy", "c"}) {
+		t.Fatalf("attempted = %v, want every container tried", attempted)
+	}
+	if !reflect.DeepEqual(removed, []string{"a", "c"}) {
+		t.Fatalf("removed = %v, want [a c]", removed)
+	}
+	if !errors.Is(err, errBusy) || !strings.Contains(err.Error(), "remove busy") {
+		t.Fatalf("err = %v, want the busy failure", err)
+	}
+	if want := []string{"a:<nil>", "busy:" + errBusy.Error(), "c:<nil>"}; !reflect.DeepEqual(reported, want) {
+		t.Fatalf("reported = %v, want %v", reported, want)
+	}
+}
+
+func TestParseGlobMatches(t *testing.T) {
+	t.Parallel()
+
+	got, err := parseGlobMatches("/tmp/a.txt\tregular file\t3\n/tmp/d\tdirectory\t4096\n/tmp/odd\tname\tregular empty file\t0\n")
+	if err != nil {
+		t.Fatalf("parseGlobMatches: %v", err)
+	}
+	want := []GlobMatch{
+		{Path: "/tmp/a.txt", Type: "regular file", Size: 3},
+		{Path: "/tmp/d", Type: "directory", Size: 4096},
+		{Path: "/tmp/odd\tname", Type: "regular empty file", Size: 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseGlobMatches() = %+v, want %+v", got, want)
+	}
+	if !got[1].IsDir() || got[0].IsDir() {
+		t.Error("IsDir() should only report the directory")
+	}
+	if got, err := parseGlobMatches(""); err != nil || got != nil {
+		t.Errorf("parseGlobMatches(\"\") = %+v, %v; want nil, nil", got, err)
+	}
+	for _, bad := range []string{"/tmp/a.txt\n", "/tmp/a.txt\t3\n", "/tmp/a.txt\tregular file\tbig\n"} {
+		if _, err := parseGlobMatches(bad); err == nil {
+			t.Errorf("parseGlobMatches(%q) succeeded, want error", bad)
+		}
+	}
+}
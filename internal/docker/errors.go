@@ -0,0 +1,102 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Sentinel errors for docker failures callers commonly need to tell apart.
+// Match them with errors.Is; the *CommandError carrying them also unwraps to
+// the underlying *exec.ExitError.
+var (
+	ErrContainerNotFound   = errors.New("container not found")
+	ErrContainerNotRunning = errors.New("container is not running")
+	ErrImageNotFound       = errors.New("image not found")
+)
+
+// CommandError is returned when a docker CLI invocation fails. Stderr holds
+// docker's own message; Kind is one of the sentinel errors above when the
+// message was recognised, or nil.
+type CommandError struct {
+	Args   []string
+	Stderr string
+	Kind   error
+	Err    error
+}
+
+func (e *CommandError) Error() string {
+	sub := "docker"
+	if len(e.Args) > 0 {
+		sub = "docker " + e.Args[0]
+	}
+	if e.Stderr != "" {
+		return fmt.Sprintf("%s: %s", sub, e.Stderr)
+	}
+	return fmt.Sprintf("%s: %v", sub, e.Err)
+}
+
+func (e *CommandError) Unwrap() []error {
+	errs := []error{e.Err}
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	return errs
+}
+
+// classifyStderr maps docker's error output to a sentinel error, or nil when
+// the message isn't recognised.
+func classifyStderr(stderr string) error {
+	msg := strings.ToLower(stderr)
+	switch {
+	case strings.Contains(msg, "no such image"),
+		strings.Contains(msg, "unable to find image"),
+		strings.Contains(msg, "pull access denied"),
+		strings.Contains(msg, "manifest unknown"),
+		strings.Contains(msg, "repository does not exist"):
+		return ErrImageNotFound
+	case strings.Contains(msg, "no such container"):
+		return ErrContainerNotFound
+	case strings.Contains(msg, "is not running"):
+		return ErrContainerNotRunning
+	}
+	return nil
+}
+
+// newCommandError wraps err from running docker args, classifying stderr.
+// It returns nil when err is nil.
+func newCommandError(args []string, stderr string, err error) error {
+	if err == nil {
+		return nil
+	}
+	stderr = strings.TrimSpace(stderr)
+	return &CommandError{Args: args, Stderr: stderr, Kind: classifyStderr(stderr), Err: err}
+}
+
+// runTeeStderr runs docker args with stdout going to stdout and stderr both
+// to os.Stderr and a buffer, so failures can be classified while users still
+// see docker's output.
+func runTeeStderr(stdout io.Writer, args ...string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("docker", args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
+	return newCommandError(args, stderr.String(), cmd.Run())
+}
+
+// CheckRunning reports ErrContainerNotFound or ErrContainerNotRunning for
+// name, or nil when the container is running.
+func CheckRunning(ctx context.Context, name string) error {
+	if !ExistsContext(ctx, name) {
+		return ErrContainerNotFound
+	}
+	if !RunningContext(ctx, name) {
+		return ErrContainerNotRunning
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package docker
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+)
+
+func TestClassifyStderr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		stderr string
+		want   error
+	}{
+		{name: "stop missing container", stderr: "Error response from daemon: No such container: ai_agent", want: ErrContainerNotFound},
+		{name: "exec on stopped container", stderr: "Error response from daemon: container 3f2a9c1d is not running", want: ErrContainerNotRunning},
+		{name: "exec on stopped container (older daemon)", stderr: "Error response from daemon: Container 3f2a9c1d is not running", want: ErrContainerNotRunning},
+		{name: "rmi missing image", stderr: "Error response from daemon: No such image: ai_agent:missing", want: ErrImageNotFound},
+		{name: "inspect missing image", stderr: "Error: No such image: ai_agent:missing", want: ErrImageNotFound},
+		{name: "run unknown image", stderr: "Unable to find image 'nope:latest' locally\ndocker: Error response from daemon: pull access denied for nope, repository does not exist or may require 'docker login'", want: ErrImageNotFound},
+		{name: "pull unknown tag", stderr: "Error response from daemon: manifest unknown", want: ErrImageNotFound},
+		{name: "daemon down", stderr: "Cannot connect to the Docker daemon at unix:///var/run/docker.sock. Is the docker daemon running?"},
+		{name: "conflict", stderr: "Error response from daemon: conflict: unable to remove repository reference \"x\" (must force) - container 1 is using its referenced image 2"},
+		{name: "empty", stderr: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := classifyStderr(tt.stderr); got != tt.want {
+				t.Errorf("classifyStderr(%q) = %v, want %v", tt.stderr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandErrorUnwrap(t *testing.T) {
+	t.Parallel()
+
+	exitErr := &exec.ExitError{}
+	err := newCommandError([]string{"stop", "ai_agent"}, "Error response from daemon: No such container: ai_agent\n", exitErr)
+	if !errors.Is(err, ErrContainerNotFound) {
+		t.Errorf("errors.Is(%v, ErrContainerNotFound) = false", err)
+	}
+	if errors.Is(err, ErrContainerNotRunning) {
+		t.Errorf("errors.Is(%v, ErrContainerNotRunning) = true", err)
+	}
+	var asExit *exec.ExitError
+	if !errors.As(err, &asExit) {
+		t.Errorf("errors.As(%v, *exec.ExitError) = false", err)
+	}
+	if got, want := err.Error(), "docker stop: Error response from daemon: No such container: ai_agent"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+
+	if newCommandError([]string{"stop"}, "ignored", nil) != nil {
+		t.Error("newCommandError() with nil err should return nil")
+	}
+	plain := newCommandError([]string{"start", "x"}, "", errors.New("exit status 1"))
+	if got, want := plain.Error(), "docker start: exit status 1"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
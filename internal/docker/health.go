@@ -0,0 +1,65 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultHealthProbe succeeds once Rails answers /srv/status inside the
+// container.
+const DefaultHealthProbe = "curl -s -f -o /dev/null http://localhost:3000/srv/status"
+
+// healthProbeInterval is the pause between failed health probes.
+var healthProbeInterval = 2 * time.Second
+
+// HealthTimeoutError is returned by WaitHealthy when the probe never
+// succeeded within the timeout. It unwraps to the last probe failure.
+type HealthTimeoutError struct {
+	Container string
+	Timeout   time.Duration
+	LastErr   error
+}
+
+func (e *HealthTimeoutError) Error() string {
+	return fmt.Sprintf("container %s did not become healthy within %s", e.Container, e.Timeout)
+}
+
+func (e *HealthTimeoutError) Unwrap() error { return e.LastErr }
+
+// WaitHealthy runs probeCmd (a bash command, DefaultHealthProbe when empty)
+// in the container as the discourse user until it exits 0. It returns a
+// *HealthTimeoutError once timeout elapses, or ctx's error if ctx ends first.
+func WaitHealthy(ctx context.Context, name, workdir, probeCmd string, timeout time.Duration) error {
+	if probeCmd == "" {
+		probeCmd = DefaultHealthProbe
+	}
+	return waitHealthy(ctx, name, timeout, func(ctx context.Context) error {
+		_, err := ExecOutputContext(ctx, name, workdir, nil, []string{"bash", "-lc", probeCmd})
+		return err
+	})
+}
+
+func waitHealthy(ctx context.Context, name string, timeout time.Duration, probe func(context.Context) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	deadline, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var lastErr error
+	for {
+		if lastErr = probe(deadline); lastErr == nil {
+			return nil
+		}
+		select {
+		case <-deadline.Done():
+		case <-time.After(healthProbeInterval):
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return &HealthTimeoutError{Container: name, Timeout: timeout, LastErr: lastErr}
+	}
+}
@@ -0,0 +1,66 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func withHealthProbeInterval(t *testing.T, d time.Duration) {
+	t.Helper()
+	old := healthProbeInterval
+	healthProbeInterval = d
+	t.Cleanup(func() { healthProbeInterval = old })
+}
+
+func TestWaitHealthyRetriesUntilProbeSucceeds(t *testing.T) {
+	withHealthProbeInterval(t, time.Millisecond)
+
+	attempts := 0
+	err := waitHealthy(context.Background(), "agent", time.Second, func(context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("waitHealthy() error = %v", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("probe ran %d times, want 3", attempts)
+	}
+}
+
+func TestWaitHealthyReturnsTypedTimeout(t *testing.T) {
+	withHealthProbeInterval(t, time.Millisecond)
+
+	probeErr := errors.New("connection refused")
+	err := waitHealthy(context.Background(), "agent", 20*time.Millisecond, func(context.Context) error {
+		return probeErr
+	})
+	var timeoutErr *HealthTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("waitHealthy() error = %v, want *HealthTimeoutError", err)
+	}
+	if timeoutErr.Container != "agent" || timeoutErr.Timeout != 20*time.Millisecond {
+		t.Errorf("timeout error = %+v", timeoutErr)
+	}
+	if !errors.Is(err, probeErr) {
+		t.Errorf("timeout error should unwrap to the last probe failure, got %v", err)
+	}
+}
+
+func TestWaitHealthyStopsWhenCallerCancels(t *testing.T) {
+	withHealthProbeInterval(t, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := waitHealthy(ctx, "agent", time.Hour, func(context.Context) error {
+		cancel()
+		return errors.New("connection refused")
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("waitHealthy() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,203 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ContainerInspect is the subset of `docker inspect` output dv reads. One
+// Inspect call answers what the single-field helpers (ContainerIP, Labels,
+// GetContainerEnv, ...) would otherwise each spawn docker for.
+type ContainerInspect struct {
+	ID              string                `json:"Id"`
+	Name            string                `json:"Name"` // without docker's leading "/"
+	State           InspectState          `json:"State"`
+	Config          InspectConfig         `json:"Config"`
+	HostConfig      InspectHostConfig     `json:"HostConfig"`
+	NetworkSettings InspectNetworkSetting `json:"NetworkSettings"`
+	Mounts          []InspectMount        `json:"Mounts"`
+}
+
+type InspectState struct {
+	Status  string `json:"Status"`
+	Running bool   `json:"Running"`
+	Pid     int    `json:"Pid"`
+}
+
+type InspectConfig struct {
+	Image      string            `json:"Image"`
+	WorkingDir string            `json:"WorkingDir"`
+	Env        []string          `json:"Env"`
+	Labels     map[string]string `json:"Labels"`
+}
+
+type InspectHostConfig struct {
+	PortBindings map[string][]InspectPortBinding `json:"PortBindings"`
+	Memory       int64                           `json:"Memory"`
+	NanoCpus     int64                           `json:"NanoCpus"`
+}
+
+type InspectPortBinding struct {
+	HostIP   string `json:"HostIp"`
+	HostPort string `json:"HostPort"`
+}
+
+type InspectNetworkSetting struct {
+	Networks map[string]InspectNetwork `json:"Networks"`
+}
+
+type InspectNetwork struct {
+	IPAddress  string `json:"IPAddress"`
+	Gateway    string `json:"Gateway"`
+	MacAddress string `json:"MacAddress"`
+}
+
+type InspectMount struct {
+	Type        string `json:"Type"`
+	Source      string `json:"Source"`
+	Destination string `json:"Destination"`
+	RW          bool   `json:"RW"`
+}
+
+// Inspect runs `docker inspect` once for a container and parses the result.
+func Inspect(name string) (*ContainerInspect, error) {
+	// --type container: the default agent and its image are both "ai_agent".
+	out, err := exec.Command("docker", "inspect", "--type", "container", name).Output()
+	if err != nil {
+		return nil, err
+	}
+	all, err := parseContainerInspect(out)
+	if err != nil {
+		return nil, err
+	}
+	if len(all) == 0 {
+		return nil, fmt.Errorf("docker inspect %s returned no containers", name)
+	}
+	return all[0], nil
+}
+
+// InspectAll inspects several containers with a single docker call and
+// returns them keyed by name. Containers that no longer exist are left out
+// rather than failing the whole batch.
+func InspectAll(names ...string) (map[string]*ContainerInspect, error) {
+	result := map[string]*ContainerInspect{}
+	if len(names) == 0 {
+		return result, nil
+	}
+	out, err := exec.Command("docker", append([]string{"inspect", "--type", "container"}, names...)...).Output()
+	// docker inspect exits non-zero when any name is missing but still
+	// prints the ones it found.
+	if err != nil && len(bytes.TrimSpace(out)) == 0 {
+		return nil, err
+	}
+	all, perr := parseContainerInspect(out)
+	if perr != nil {
+		return nil, perr
+	}
+	for _, ci := range all {
+		result[ci.Name] = ci
+	}
+	return result, nil
+}
+
+func parseContainerInspect(data []byte) ([]*ContainerInspect, error) {
+	var all []*ContainerInspect
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	for _, ci := range all {
+		ci.Name = strings.TrimPrefix(ci.Name, "/")
+	}
+	return all, nil
+}
+
+// IP returns the container's first IP address across its networks.
+func (ci *ContainerInspect) IP() (string, error) {
+	ip := PrimaryIP(ci.Networks())
+	if ip == "" {
+		return "", fmt.Errorf("container %s has no IP address", ci.Name)
+	}
+	return ip, nil
+}
+
+// Networks returns the container's networks sorted by name.
+func (ci *ContainerInspect) Networks() []ContainerNetwork {
+	networks := make([]ContainerNetwork, 0, len(ci.NetworkSettings.Networks))
+	for name, n := range ci.NetworkSettings.Networks {
+		networks = append(networks, ContainerNetwork{
+			Name:       name,
+			IPAddress:  n.IPAddress,
+			Gateway:    n.Gateway,
+			MacAddress: n.MacAddress,
+		})
+	}
+	sort.Slice(networks, func(i, j int) bool { return networks[i].Name < networks[j].Name })
+	return networks
+}
+
+// Labels returns the container's labels; never nil.
+func (ci *ContainerInspect) Labels() map[string]string {
+	labels := make(map[string]string, len(ci.Config.Labels))
+	for k, v := range ci.Config.Labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// Env returns the container's environment as a map.
+func (ci *ContainerInspect) Env() map[string]string {
+	env := make(map[string]string, len(ci.Config.Env))
+	for _, e := range ci.Config.Env {
+		if k, v, ok := strings.Cut(e, "="); ok {
+			env[k] = v
+		}
+	}
+	return env
+}
+
+// HostPort returns the host port bound to containerPort/tcp. It reads
+// HostConfig, so it works on stopped containers too.
+func (ci *ContainerInspect) HostPort(containerPort int) (int, error) {
+	bindings := ci.HostConfig.PortBindings[fmt.Sprintf("%d/tcp", containerPort)]
+	if len(bindings) == 0 || strings.TrimSpace(bindings[0].HostPort) == "" {
+		return 0, fmt.Errorf("no port mapping found")
+	}
+	port, err := strconv.Atoi(strings.TrimSpace(bindings[0].HostPort))
+	if err != nil {
+		return 0, fmt.Errorf("invalid port number: %s", bindings[0].HostPort)
+	}
+	return port, nil
+}
+
+// ResourceLimits returns the container's memory and CPU limits as docker run
+// --memory and --cpus values, or "" for each one that is unlimited.
+func (ci *ContainerInspect) ResourceLimits() (memory, cpus string) {
+	if ci.HostConfig.Memory > 0 {
+		memory = strconv.FormatInt(ci.HostConfig.Memory, 10)
+	}
+	if ci.HostConfig.NanoCpus > 0 {
+		cpus = strconv.FormatFloat(float64(ci.HostConfig.NanoCpus)/1e9, 'f', -1, 64)
+	}
+	return memory, cpus
+}
+
+// BindMounts returns the container's host bind mounts as GetContainerMounts
+// describes them.
+func (ci *ContainerInspect) BindMounts() []Mount {
+	var mounts []Mount
+	for _, m := range ci.Mounts {
+		// Only re-apply host bind mounts; anonymous/named volumes are managed by
+		// docker. Skip the forwarded SSH agent socket (see RunDetached) — it is
+		// re-established via the sshAuthSock path, not as a persisted bind mount.
+		if m.Type != "bind" || m.Destination == "/tmp/ssh-agent.sock" {
+			continue
+		}
+		mounts = append(mounts, Mount{Host: m.Source, Container: m.Destination, ReadOnly: !m.RW})
+	}
+	return mounts
+}
@@ -0,0 +1,83 @@
+package docker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseContainerInspect(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`[{
+		"Id": "abc123",
+		"Name": "/ai_agent",
+		"State": {"Status": "running", "Running": true, "Pid": 4242},
+		"Config": {
+			"Image": "ai_agent:latest",
+			"WorkingDir": "/var/www/discourse",
+			"Env": ["PATH=/usr/bin", "DISCOURSE_PORT=4201", "EMPTY="],
+			"Labels": {"com.dv.owner": "dv"}
+		},
+		"HostConfig": {"PortBindings": {"4200/tcp": [{"HostIp": "127.0.0.1", "HostPort": "4201"}]}, "Memory": 2147483648, "NanoCpus": 1500000000},
+		"NetworkSettings": {"Networks": {
+			"zeta": {"IPAddress": "", "Gateway": "", "MacAddress": ""},
+			"bridge": {"IPAddress": "172.17.0.2", "Gateway": "172.17.0.1", "MacAddress": "02:42:ac:11:00:02"}
+		}},
+		"Mounts": [
+			{"Type": "bind", "Source": "/home/me/plugin", "Destination": "/var/www/discourse/plugins/x", "RW": false},
+			{"Type": "volume", "Source": "/var/lib/docker/volumes/v", "Destination": "/data", "RW": true}
+		]
+	}]`)
+
+	all, err := parseContainerInspect(data)
+	if err != nil {
+		t.Fatalf("parseContainerInspect() error = %v", err)
+	}
+	if len(all) != 1 {
+		t.Fatalf("parseContainerInspect() returned %d containers, want 1", len(all))
+	}
+	ci := all[0]
+	if ci.Name != "ai_agent" || ci.State.Pid != 4242 || ci.Config.WorkingDir != "/var/www/discourse" {
+		t.Fatalf("unexpected basics: %+v", ci)
+	}
+	if ip, err := ci.IP(); err != nil || ip != "172.17.0.2" {
+		t.Errorf("IP() = %q, %v; want 172.17.0.2", ip, err)
+	}
+	if got := ci.Env(); !reflect.DeepEqual(got, map[string]string{"PATH": "/usr/bin", "DISCOURSE_PORT": "4201", "EMPTY": ""}) {
+		t.Errorf("Env() = %v", got)
+	}
+	if got := ci.Labels(); got["com.dv.owner"] != "dv" {
+		t.Errorf("Labels() = %v", got)
+	}
+	if port, err := ci.HostPort(4200); err != nil || port != 4201 {
+		t.Errorf("HostPort(4200) = %d, %v; want 4201", port, err)
+	}
+	if _, err := ci.HostPort(3000); err == nil {
+		t.Error("HostPort(3000) should fail without a binding")
+	}
+	want := []Mount{{Host: "/home/me/plugin", Container: "/var/www/discourse/plugins/x", ReadOnly: true}}
+	if got := ci.BindMounts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("BindMounts() = %+v, want %+v", got, want)
+	}
+	if memory, cpus := ci.ResourceLimits(); memory != "2147483648" || cpus != "1.5" {
+		t.Errorf("ResourceLimits() = %q, %q; want 2147483648, 1.5", memory, cpus)
+	}
+	if memory, cpus := (&ContainerInspect{}).ResourceLimits(); memory != "" || cpus != "" {
+		t.Errorf("unlimited ResourceLimits() = %q, %q; want empty", memory, cpus)
+	}
+}
+
+func TestContainerInspectLabelsNeverNil(t *testing.T) {
+	t.Parallel()
+
+	all, err := parseContainerInspect([]byte(`[{"Name": "/x", "Config": {"Labels": null}}]`))
+	if err != nil {
+		t.Fatalf("parseContainerInspect() error = %v", err)
+	}
+	if labels := all[0].Labels(); labels == nil {
+		t.Error("Labels() = nil, want empty map")
+	}
+	if _, err := all[0].IP(); err == nil {
+		t.Error("IP() should fail without networks")
+	}
+}
@@ -68,6 +68,27 @@ abc   1     root  something
 				{PID: 100, PPID: 50, User: "root", Args: "claude --dangerously-skip-permissions -p hello world"},
 			},
 		},
+		{
+			name: "cpu and mem columns",
+			output: `PID   PPID  USER       %CPU  %MEM  COMMAND
+2811456  2811430  root       0.0   0.1   /bin/bash /sbin/boot
+2815711  2815690  discourse  12.5  3.4   claude --dangerously-skip-permissions
+`,
+			expected: []TopProcess{
+				{PID: 2811456, PPID: 2811430, User: "root", CPU: 0.0, Mem: 0.1, Args: "/bin/bash /sbin/boot"},
+				{PID: 2815711, PPID: 2815690, User: "discourse", CPU: 12.5, Mem: 3.4, Args: "claude --dangerously-skip-permissions"},
+			},
+		},
+		{
+			name: "cpu and mem columns with short lines skipped",
+			output: `PID   PPID  USER  %CPU  %MEM  COMMAND
+100   1     root  0.0   0.1
+200   1     root  1.0   0.2   valid line
+`,
+			expected: []TopProcess{
+				{PID: 200, PPID: 1, User: "root", CPU: 1.0, Mem: 0.2, Args: "valid line"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -155,6 +176,17 @@ func TestFindExecSessions(t *testing.T) {
 				{PID: 500, User: "discourse", Command: "bash -l"},
 			},
 		},
+		{
+			name: "exec session carries cpu and mem",
+			procs: []TopProcess{
+				{PID: 100, PPID: 1, User: "root", CPU: 0.1, Mem: 0.2, Args: "/bin/bash /sbin/boot"},
+				{PID: 500, PPID: 99, User: "discourse", CPU: 45.2, Mem: 6.1, Args: "claude"},
+			},
+			initPID: 100,
+			expected: []ExecSession{
+				{PID: 500, User: "discourse", CPU: 45.2, Mem: 6.1, Command: "claude"},
+			},
+		},
 		{
 			name: "init PID 0 does not match real processes",
 			procs: []TopProcess{
@@ -0,0 +1,106 @@
+package docker
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input    string
+		expected uint64
+		wantErr  bool
+	}{
+		{input: "0B", expected: 0},
+		{input: "512B", expected: 512},
+		{input: "1.5kB", expected: 1500},
+		{input: "1.5KiB", expected: 1536},
+		{input: "2MB", expected: 2_000_000},
+		{input: "1.5GiB", expected: 1_610_612_736},
+		{input: " 7.77GiB ", expected: 8_342_973_972},
+		{input: "1TB", expected: 1_000_000_000_000},
+		{input: "3", expected: 3},
+		{input: "--", expected: 0},
+		{input: "", expected: 0},
+		{input: "GiB", wantErr: true},
+		{input: "1.2XB", wantErr: true},
+		{input: "1..2MB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseSize(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseSize(%q) expected error, got %d", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSize(%q) unexpected error: %v", tt.input, err)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseSize(%q) = %d, want %d", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseDockerStatsLine(t *testing.T) {
+	t.Parallel()
+
+	line := `{"BlockIO":"12.3MB / 4.1kB","CPUPerc":"12.50%","Container":"abc123","ID":"abc123","MemPerc":"3.91%","MemUsage":"312.4MiB / 7.77GiB","Name":"agent","NetIO":"1.2kB / 648B","PIDs":"42"}`
+	got, err := ParseDockerStatsLine(line)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := ContainerStats{
+		Name:            "agent",
+		CPUPercent:      12.5,
+		MemUsageBytes:   327_575_142,
+		MemLimitBytes:   8_342_973_972,
+		MemPercent:      3.91,
+		NetRxBytes:      1200,
+		NetTxBytes:      648,
+		BlockReadBytes:  12_300_000,
+		BlockWriteBytes: 4100,
+		PIDs:            42,
+	}
+	if got != want {
+		t.Errorf("ParseDockerStatsLine() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseDockerStatsLineStoppedContainer(t *testing.T) {
+	t.Parallel()
+
+	line := `{"BlockIO":"--","CPUPerc":"--","MemPerc":"--","MemUsage":"-- / --","Name":"agent","NetIO":"--","PIDs":"--"}`
+	got, err := ParseDockerStatsLine(line)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != (ContainerStats{Name: "agent"}) {
+		t.Errorf("expected zeroed stats, got %+v", got)
+	}
+}
+
+func TestParseDockerStatsLineErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"invalid json":    `not json`,
+		"bad percent":     `{"CPUPerc":"abc%"}`,
+		"bad size pair":   `{"MemUsage":"12MiB"}`,
+		"bad size unit":   `{"NetIO":"1QB / 2kB"}`,
+		"bad pids number": `{"PIDs":"many"}`,
+	}
+	for name, line := range tests {
+		line := line
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := ParseDockerStatsLine(line); err == nil {
+				t.Fatalf("expected error for %s", line)
+			}
+		})
+	}
+}
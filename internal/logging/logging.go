@@ -0,0 +1,84 @@
+// Package logging is dv's small leveled logger for diagnostic output on
+// stderr. Debug messages are shown when DV_VERBOSE is truthy (or the level is
+// set explicitly); info messages are always shown.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Level orders message severities; a logger shows messages at or above its
+// level.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+)
+
+var (
+	mu       sync.Mutex
+	out      io.Writer = os.Stderr
+	levelSet bool
+	level    Level
+)
+
+// SetOutput redirects log output, returning the previous writer.
+func SetOutput(w io.Writer) io.Writer {
+	mu.Lock()
+	defer mu.Unlock()
+	prev := out
+	out = w
+	return prev
+}
+
+// SetLevel fixes the level, overriding DV_VERBOSE.
+func SetLevel(l Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	level, levelSet = l, true
+}
+
+// currentLevel is the explicit level, or debug when DV_VERBOSE is truthy. The
+// environment is read on each call so `DV_VERBOSE=1` set mid-process (e.g. by
+// a --verbose flag) takes effect.
+func currentLevel() Level {
+	mu.Lock()
+	l, set := level, levelSet
+	mu.Unlock()
+	if set {
+		return l
+	}
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("DV_VERBOSE"))) {
+	case "1", "true", "yes", "on":
+		return LevelDebug
+	default:
+		return LevelInfo
+	}
+}
+
+// Enabled reports whether messages at l are shown.
+func Enabled(l Level) bool { return l >= currentLevel() }
+
+// DebugEnabled reports whether debug messages are shown.
+func DebugEnabled() bool { return Enabled(LevelDebug) }
+
+// Debugf logs a debug message; a trailing newline is added.
+func Debugf(format string, args ...any) { logf(LevelDebug, format, args...) }
+
+// Infof logs an informational message; a trailing newline is added.
+func Infof(format string, args ...any) { logf(LevelInfo, format, args...) }
+
+func logf(l Level, format string, args ...any) {
+	if !Enabled(l) {
+		return
+	}
+	msg := strings.TrimSuffix(fmt.Sprintf(format, args...), "\n")
+	mu.Lock()
+	defer mu.Unlock()
+	fmt.Fprintln(out, msg)
+}
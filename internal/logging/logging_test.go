@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDebugFollowsDVVerbose(t *testing.T) {
+	var buf bytes.Buffer
+	prev := SetOutput(&buf)
+	t.Cleanup(func() { SetOutput(prev) })
+
+	t.Setenv("DV_VERBOSE", "")
+	Debugf("hidden")
+	Infof("shown %d", 1)
+	if got := buf.String(); got != "shown 1\n" {
+		t.Fatalf("without DV_VERBOSE got %q", got)
+	}
+
+	buf.Reset()
+	t.Setenv("DV_VERBOSE", "yes")
+	Debugf("Running: docker %s\n", "ps")
+	if got := buf.String(); got != "Running: docker ps\n" {
+		t.Fatalf("with DV_VERBOSE got %q", got)
+	}
+}
+
+func TestSetLevelOverridesEnv(t *testing.T) {
+	var buf bytes.Buffer
+	prev := SetOutput(&buf)
+	t.Cleanup(func() {
+		SetOutput(prev)
+		mu.Lock()
+		levelSet = false
+		mu.Unlock()
+	})
+
+	t.Setenv("DV_VERBOSE", "1")
+	SetLevel(LevelInfo)
+	if DebugEnabled() {
+		t.Fatal("debug enabled despite SetLevel(LevelInfo)")
+	}
+	Debugf("hidden")
+	if buf.Len() != 0 {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
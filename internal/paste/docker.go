@@ -1,8 +1,10 @@
 package paste
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -24,22 +26,108 @@ type DockerExecConfig struct {
 	Argv          []string
 	User          string
 	ImageTempDir  string // Directory in container for temp images (default: /tmp/dv-images)
+	// Transcript, when set, receives a copy of everything the command prints,
+	// including terminal escape sequences when a PTY is used.
+	Transcript io.Writer
+	// DisablePaste skips image interception while keeping the PTY, for
+	// callers that only want a transcript.
+	DisablePaste bool
+	// Context, when set, bounds the exec: on cancellation the docker client is
+	// killed and the processes it started in the container are terminated.
+	Context context.Context
 }
 
-// ExecWithPaste runs docker exec with paste interception enabled.
-// Images pasted/referenced are automatically copied to the container.
-func ExecWithPaste(cfg DockerExecConfig) error {
+// StagedFile is content copied into the container before a session starts,
+// such as an image attached on the command line or the clipboard contents.
+type StagedFile struct {
+	Data   []byte
+	Format string // image format ("png", "jpeg", ...) or "text"
+}
+
+func (cfg DockerExecConfig) withDefaults() DockerExecConfig {
 	if cfg.User == "" {
 		cfg.User = "discourse"
 	}
 	if cfg.ImageTempDir == "" {
 		cfg.ImageTempDir = "/tmp/dv-images"
 	}
+	return cfg
+}
 
-	// Ensure the temp directory exists in the container
+// StageFiles copies files into cfg.ImageTempDir and returns their container
+// paths in order. Files are numbered in the order given
+// (attached-<unix>-1.png, attached-<unix>-2.txt, ...) so several screenshots
+// and clipboard text can be referenced from one prompt.
+func StageFiles(cfg DockerExecConfig, files []StagedFile) ([]string, error) {
+	cfg = cfg.withDefaults()
+	ensureTempDir(cfg)
+	stamp := time.Now().Unix()
+	paths := make([]string, 0, len(files))
+	for i, f := range files {
+		ext := f.Format
+		if ext == "text" {
+			ext = "txt"
+		}
+		path, err := copyToContainer(cfg, f.Data, fmt.Sprintf("attached-%d-%d.%s", stamp, i+1, ext))
+		if err != nil {
+			return paths, fmt.Errorf("stage file %d: %w", i+1, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func ensureTempDir(cfg DockerExecConfig) {
 	mkdirCmd := exec.Command("docker", "exec", "--user", cfg.User, cfg.ContainerName,
 		"mkdir", "-p", cfg.ImageTempDir)
 	mkdirCmd.Run() // Ignore errors, directory might already exist
+}
+
+// copyToContainer writes data to filename under cfg.ImageTempDir in the
+// container, owned by cfg.User, and returns the container path.
+func copyToContainer(cfg DockerExecConfig, data []byte, filename string) (string, error) {
+	containerPath := filepath.Join(cfg.ImageTempDir, filename)
+
+	// Write to temp file on host
+	tmpFile, err := os.CreateTemp("", "dv-paste-*"+filepath.Ext(filename))
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return "", err
+	}
+	tmpFile.Close()
+
+	// Copy to container
+	cpCmd := exec.Command("docker", "cp", tmpFile.Name(),
+		fmt.Sprintf("%s:%s", cfg.ContainerName, containerPath))
+	if err := cpCmd.Run(); err != nil {
+		return "", err
+	}
+
+	// Set ownership
+	chownCmd := exec.Command("docker", "exec", "--user", "root", cfg.ContainerName,
+		"chown", cfg.User+":"+cfg.User, containerPath)
+	chownCmd.Run() // Best effort
+
+	return containerPath, nil
+}
+
+// ExecWithPaste runs docker exec with paste interception enabled.
+// Images pasted/referenced are automatically copied to the container.
+func ExecWithPaste(cfg DockerExecConfig) error {
+	cfg = cfg.withDefaults()
+	ctx := cfg.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	envs, execID := docker.TagExec(cfg.Envs)
+
+	// Ensure the temp directory exists in the container
+	ensureTempDir(cfg)
 
 	// Check if we have a TTY - if not, fall back to non-paste exec
 	isTTY := term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
@@ -50,19 +138,32 @@ func ExecWithPaste(cfg DockerExecConfig) error {
 		args = append(args, "-t") // allocate pseudo-TTY
 	}
 	args = append(args, "--user", cfg.User, "-w", cfg.Workdir)
-	for _, e := range cfg.Envs {
+	for _, e := range envs {
 		args = append(args, "-e", e)
 	}
 	args = append(args, cfg.ContainerName)
 	args = append(args, cfg.Argv...)
 
-	cmd := exec.Command("docker", args...)
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.WaitDelay = docker.ExecStreamWaitDelay
+	defer func() {
+		if ctx.Err() != nil {
+			_ = docker.KillExecTree(cfg.ContainerName, execID)
+		}
+	}()
+
+	var stdout io.Writer = os.Stdout
+	var stderr io.Writer = os.Stderr
+	if cfg.Transcript != nil {
+		stdout = io.MultiWriter(os.Stdout, cfg.Transcript)
+		stderr = io.MultiWriter(os.Stderr, cfg.Transcript)
+	}
 
 	if !isTTY {
 		// No TTY, fall back to simple exec without paste interception
 		cmd.Stdin = os.Stdin
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
 		return cmd.Run()
 	}
 
@@ -71,34 +172,7 @@ func ExecWithPaste(cfg DockerExecConfig) error {
 	imageHandler := func(data []byte, format string) (string, error) {
 		imageCounter++
 		filename := fmt.Sprintf("pasted-%d-%d.%s", time.Now().Unix(), imageCounter, format)
-		containerPath := filepath.Join(cfg.ImageTempDir, filename)
-
-		// Write to temp file on host
-		tmpFile, err := os.CreateTemp("", "dv-paste-*."+format)
-		if err != nil {
-			return "", err
-		}
-		defer os.Remove(tmpFile.Name())
-
-		if _, err := tmpFile.Write(data); err != nil {
-			tmpFile.Close()
-			return "", err
-		}
-		tmpFile.Close()
-
-		// Copy to container
-		cpCmd := exec.Command("docker", "cp", tmpFile.Name(),
-			fmt.Sprintf("%s:%s", cfg.ContainerName, containerPath))
-		if err := cpCmd.Run(); err != nil {
-			return "", err
-		}
-
-		// Set ownership
-		chownCmd := exec.Command("docker", "exec", "--user", "root", cfg.ContainerName,
-			"chown", cfg.User+":"+cfg.User, containerPath)
-		chownCmd.Run() // Best effort
-
-		return containerPath, nil
+		return copyToContainer(cfg, data, filename)
 	}
 
 	// Start PTY for the docker command
@@ -135,20 +209,24 @@ func ExecWithPaste(cfg DockerExecConfig) error {
 	}
 
 	// Enable bracketed paste mode
-	os.Stdout.Write([]byte("\x1b[?2004h"))
+	if !cfg.DisablePaste {
+		os.Stdout.Write([]byte("\x1b[?2004h"))
+	}
 
 	// Create interceptor
 	interceptor := NewInterceptor(imageHandler)
 
 	// Copy PTY output to stdout
+	outputDone := make(chan struct{})
 	go func() {
+		defer close(outputDone)
 		buf := make([]byte, 32*1024)
 		for {
 			n, err := ptmx.Read(buf)
 			if err != nil {
 				return
 			}
-			os.Stdout.Write(buf[:n])
+			stdout.Write(buf[:n])
 		}
 	}()
 
@@ -165,6 +243,10 @@ func ExecWithPaste(cfg DockerExecConfig) error {
 			if err != nil {
 				return
 			}
+			if cfg.DisablePaste {
+				ptmx.Write(buf[:n])
+				continue
+			}
 			processed := interceptor.Process(buf[:n])
 			ptmx.Write(processed)
 		}
@@ -173,10 +255,19 @@ func ExecWithPaste(cfg DockerExecConfig) error {
 	// Wait for command to finish
 	err = cmd.Wait()
 
+	// Let the output copier drain what is still buffered in the PTY so the
+	// tail of the session reaches stdout and the transcript.
+	select {
+	case <-outputDone:
+	case <-time.After(500 * time.Millisecond):
+	}
+
 	// Cleanup
 	close(done)
 	signal.Stop(sigCh)
-	os.Stdout.Write([]byte("\x1b[?2004l"))
+	if !cfg.DisablePaste {
+		os.Stdout.Write([]byte("\x1b[?2004l"))
+	}
 	term.Restore(int(os.Stdin.Fd()), oldState)
 	ptmx.Close()
 
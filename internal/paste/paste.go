@@ -100,6 +100,40 @@ func ReadClipboard() ([]byte, string, error) {
 	return nil, "", fmt.Errorf("clipboard not available: %s", strings.Join(errs, "; "))
 }
 
+// WriteClipboard replaces the clipboard contents with text, using whichever
+// of wl-copy, xclip or pbcopy is available.
+func WriteClipboard(text []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	tools := [][]string{
+		{"wl-copy", "--type", "text/plain"},
+		{"xclip", "-selection", "clipboard", "-i"},
+		{"pbcopy"},
+	}
+	var errs []string
+	for _, tool := range tools {
+		if _, err := exec.LookPath(tool[0]); err != nil {
+			errs = append(errs, tool[0]+" not found")
+			continue
+		}
+		cmd := exec.CommandContext(ctx, tool[0], tool[1:]...)
+		cmd.Stdin = bytes.NewReader(text)
+		var errOut bytes.Buffer
+		cmd.Stderr = &errOut
+		if err := cmd.Run(); err != nil {
+			msg := strings.TrimSpace(errOut.String())
+			if msg == "" {
+				msg = err.Error()
+			}
+			errs = append(errs, tool[0]+": "+msg)
+			continue
+		}
+		return nil
+	}
+	return fmt.Errorf("clipboard not available: %s", strings.Join(errs, "; "))
+}
+
 // Common patterns for image detection
 var (
 	// File path patterns for images
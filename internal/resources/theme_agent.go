@@ -20,6 +20,8 @@ type ThemeAgentData struct {
 	ContainerDiscoursePath string
 	HostDiscoursePath      string
 	RepositoryURL          string
+	Branch                 string
+	ParentTheme            string
 	ServiceName            string
 	IsComponent            bool
 }